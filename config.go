@@ -1,25 +1,220 @@
 package securityevent
 
 import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/featuregate"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/attack"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/benchmark"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/cef"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/ceflog"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/gatekeeper"
 	"github.com/henrikrexed/securitylogeventprocessor/internal/openreports"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/profile"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/runtimelog"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/sariflog"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/semconv"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/validation"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/vulnintel"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/vulnscan"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Error modes define how log records that fail transformation are handled
+const (
+	// ErrorModePropagate returns the error to the pipeline, rejecting the whole batch
+	ErrorModePropagate = "propagate"
+	// ErrorModeDrop removes the failing log record from the batch
+	ErrorModeDrop = "drop"
+	// ErrorModePassthrough keeps the failing log record unchanged
+	ErrorModePassthrough = "passthrough"
+	// ErrorModeDeadLetter keeps the failing log record annotated with the error and the failing stage
+	ErrorModeDeadLetter = "dead_letter"
+)
+
+// Dead letter attributes set on log records that failed transformation in dead_letter mode
+const (
+	attrDeadLetterError     = "securityevent.error"
+	attrDeadLetterStage     = "securityevent.error.stage"
+	attrDeadLetterProcessor = "securityevent.error.processor"
 )
 
 // Config defines the configuration for the security event processor
 type Config struct {
+	// ErrorMode defines how log records that fail transformation are handled
+	// Valid values: "propagate", "drop", "passthrough", "dead_letter"
+	// If empty, failing log records are dropped
+	ErrorMode string `mapstructure:"error_mode"`
+
 	// Processors defines the list of enabled processors
 	Processors ProcessorConfig `mapstructure:"processors"`
+
+	// Enrichment defines reference data used to enrich the security events of all processors
+	Enrichment EnrichmentConfig `mapstructure:"enrichment"`
+
+	// Output defines how the security events are laid out in the outgoing logs
+	Output OutputConfig `mapstructure:"output"`
+}
+
+// OutputConfig contains configuration for the layout of the outgoing security events
+type OutputConfig struct {
+	// GroupByResource moves the security events of each scoped Kubernetes object into their own
+	// ResourceLogs, with the Kubernetes fields set once as resource attributes instead of on every event
+	// Only the security events of OpenReports reports, which scope a single object, are grouped
+	GroupByResource bool `mapstructure:"group_by_resource"`
+
+	// Profile selects the attribute layout of the security events for the backend ingesting them
+	// Valid values: "dynatrace", "splunk_cim" (Splunk CIM Vulnerabilities and Alerts data models),
+	// "google_udm" (Google SecOps Unified Data Model), "asim" (Microsoft Sentinel ASIM Audit Event and Alert schemas)
+	// If empty, defaults to "dynatrace"
+	Profile string `mapstructure:"profile"`
+
+	// AttributeNames selects the attribute names of the security events
+	// Valid values: "legacy", "semconv" (OpenTelemetry semantic conventions), "both"
+	// If empty, defaults to "legacy", or to "semconv" when the processor.securityevent.semconvAttributes
+	// feature gate is enabled
+	AttributeNames string `mapstructure:"attribute_names"`
+
+	// BodyFormat selects the body of the security events
+	// Valid values: "message" (the finding message), "map" (all the event fields as a map),
+	// "json" (all the event fields serialized as a JSON object string)
+	// If empty, defaults to "message"
+	BodyFormat string `mapstructure:"body_format"`
+
+	// SIEM renders the security events in CEF or LEEF for legacy SIEM syslog ingestion
+	SIEM cef.Config `mapstructure:"siem"`
+
+	// Validation checks the security events against the JSON Schema of the output
+	Validation validation.Config `mapstructure:"validation"`
+}
+
+// semconvAttributesGate switches the default attribute names to the OpenTelemetry semantic conventions
+var semconvAttributesGate = featuregate.GlobalRegistry().MustRegister(
+	"processor.securityevent.semconvAttributes",
+	featuregate.StageAlpha,
+	featuregate.WithRegisterDescription("When enabled, security events use the OpenTelemetry semantic convention "+
+		"attribute names unless output.attribute_names is set"),
+)
+
+// attributeNames returns the configured attribute name mode, falling back to the feature gate default
+func (cfg *OutputConfig) attributeNames() string {
+	if cfg.AttributeNames != "" {
+		return cfg.AttributeNames
+	}
+	if semconvAttributesGate.IsEnabled() {
+		return semconv.ModeSemconv
+	}
+	return semconv.ModeLegacy
+}
+
+// profile returns the configured output profile, defaulting to dynatrace
+func (cfg *OutputConfig) profile() string {
+	if cfg.Profile != "" {
+		return cfg.Profile
+	}
+	return profile.Dynatrace
+}
+
+// output returns the layout of the security events shared by the sub-processors
+func (cfg *OutputConfig) output() processing.Output {
+	return processing.Output{
+		Profile:        cfg.profile(),
+		AttributeNames: cfg.attributeNames(),
+		BodyFormat:     cfg.BodyFormat,
+		SIEM:           cfg.SIEM,
+	}
 }
 
 // ProcessorConfig contains configuration for individual processor types
 type ProcessorConfig struct {
 	// OpenReports configuration
 	OpenReports openreports.Config `mapstructure:"openreports"`
+
+	// CEF and LEEF syslog records configuration
+	CEF ceflog.Config `mapstructure:"cef"`
+
+	// SARIF static analysis logs configuration
+	SARIF sariflog.Config `mapstructure:"sarif"`
+
+	// Grype and CycloneDX vulnerability reports configuration
+	VulnScan vulnscan.Config `mapstructure:"vulnscan"`
+
+	// kube-bench and Kubescape benchmark reports configuration
+	Benchmark benchmark.Config `mapstructure:"benchmark"`
+
+	// OPA Gatekeeper constraint violations configuration
+	Gatekeeper gatekeeper.Config `mapstructure:"gatekeeper"`
+
+	// Tetragon and Tracee runtime events configuration
+	Runtime runtimelog.Config `mapstructure:"runtime"`
+}
+
+// EnrichmentConfig contains configuration for security event enrichment
+type EnrichmentConfig struct {
+	// Compliance framework catalog configuration
+	Compliance compliance.Config `mapstructure:"compliance"`
+
+	// MITRE ATT&CK technique tagging configuration
+	Attack attack.Config `mapstructure:"attack"`
+
+	// Offline EPSS and CISA KEV vulnerability intelligence configuration
+	Vulnerability vulnintel.Config `mapstructure:"vulnerability"`
 }
 
 // Validate checks if the configuration is valid
 func (cfg *Config) Validate() error {
+	switch cfg.ErrorMode {
+	case "", ErrorModePropagate, ErrorModeDrop, ErrorModePassthrough, ErrorModeDeadLetter:
+	default:
+		return fmt.Errorf("invalid error_mode: %s. Valid values are: propagate, drop, passthrough, dead_letter", cfg.ErrorMode)
+	}
+
+	if cfg.Output.Profile != "" && !profile.Valid(cfg.Output.Profile) {
+		return fmt.Errorf("invalid output profile: %s. Valid values are: %s, %s, %s, %s",
+			cfg.Output.Profile, profile.Dynatrace, profile.SplunkCIM, profile.GoogleUDM, profile.ASIM)
+	}
+	if cfg.Output.AttributeNames != "" && !semconv.ValidMode(cfg.Output.AttributeNames) {
+		return fmt.Errorf("invalid output attribute_names: %s. Valid values are: legacy, semconv, both", cfg.Output.AttributeNames)
+	}
+	if cfg.Output.profile() != profile.Dynatrace && cfg.Output.AttributeNames != "" && cfg.Output.AttributeNames != semconv.ModeLegacy {
+		return fmt.Errorf("output attribute_names %s requires the %s profile", cfg.Output.AttributeNames, profile.Dynatrace)
+	}
+	if cfg.Output.BodyFormat != "" && !schema.ValidBodyFormat(cfg.Output.BodyFormat) {
+		return fmt.Errorf("invalid output body_format: %s. Valid values are: message, map, json", cfg.Output.BodyFormat)
+	}
+	if err := cfg.Output.SIEM.Validate(); err != nil {
+		return err
+	}
+	if cfg.Output.SIEM.Enabled() && cfg.Output.SIEM.Target != cef.TargetAttribute &&
+		cfg.Output.BodyFormat != "" && cfg.Output.BodyFormat != schema.BodyFormatMessage {
+		return fmt.Errorf("output siem target body conflicts with body_format: %s. Set siem target to attribute", cfg.Output.BodyFormat)
+	}
+	if err := cfg.Output.Validation.Validate(); err != nil {
+		return err
+	}
 	if err := cfg.Processors.OpenReports.Validate(); err != nil {
 		return err
 	}
+	if err := cfg.Processors.VulnScan.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Processors.Benchmark.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Processors.Gatekeeper.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Enrichment.Compliance.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Enrichment.Attack.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Enrichment.Vulnerability.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
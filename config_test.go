@@ -3,9 +3,20 @@ package securityevent
 import (
 	"testing"
 
+	"github.com/henrikrexed/securitylogeventprocessor/internal/benchmark"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/cef"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/ceflog"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/gatekeeper"
 	"github.com/henrikrexed/securitylogeventprocessor/internal/openreports"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/runtimelog"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/sariflog"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/semconv"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/validation"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/vulnscan"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/featuregate"
 )
 
 func TestConfig_Validate_ValidConfig(t *testing.T) {
@@ -41,6 +52,73 @@ func TestConfig_Validate_ValidConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "openreports and cef enabled",
+			config: Config{
+				Processors: ProcessorConfig{
+					OpenReports: openreports.Config{Enabled: true},
+					CEF:         ceflog.Config{Enabled: true},
+				},
+			},
+		},
+		{
+			name: "sarif enabled",
+			config: Config{
+				Processors: ProcessorConfig{
+					SARIF: sariflog.Config{Enabled: true},
+				},
+			},
+		},
+		{
+			name: "vulnscan enabled with vex annotation",
+			config: Config{
+				Processors: ProcessorConfig{
+					VulnScan: vulnscan.Config{Enabled: true, VEX: vulnscan.VEXAnnotate},
+				},
+			},
+		},
+		{
+			name: "benchmark enabled with status filter",
+			config: Config{
+				Processors: ProcessorConfig{
+					Benchmark: benchmark.Config{Enabled: true, StatusFilter: []string{"fail", "warn"}},
+				},
+			},
+		},
+		{
+			name: "gatekeeper enabled with enforcement action filter",
+			config: Config{
+				Processors: ProcessorConfig{
+					Gatekeeper: gatekeeper.Config{Enabled: true, EnforcementActionFilter: []string{"deny", "warn"}},
+				},
+			},
+		},
+		{
+			name: "runtime enabled with policy filter",
+			config: Config{
+				Processors: ProcessorConfig{
+					Runtime: runtimelog.Config{Enabled: true, PolicyFilter: []string{"file-monitoring"}},
+				},
+			},
+		},
+		{
+			name: "semconv attribute names",
+			config: Config{
+				Output: OutputConfig{AttributeNames: "both"},
+			},
+		},
+		{
+			name: "dead letter error mode",
+			config: Config{
+				ErrorMode: ErrorModeDeadLetter,
+			},
+		},
+		{
+			name: "splunk cim profile with legacy attribute names",
+			config: Config{
+				Output: OutputConfig{Profile: "splunk_cim", AttributeNames: "legacy"},
+			},
+		},
 		{
 			name: "openreports disabled",
 			config: Config{
@@ -81,6 +159,126 @@ func TestConfig_Validate_InvalidConfig(t *testing.T) {
 			wantErr: true,
 			errMsg:  "invalid status in status_filter: invalid",
 		},
+		{
+			name: "invalid vulnscan vex",
+			config: Config{
+				Processors: ProcessorConfig{
+					VulnScan: vulnscan.Config{Enabled: true, VEX: "drop"},
+				},
+			},
+			wantErr: true,
+			errMsg:  "invalid vulnscan vex: drop",
+		},
+		{
+			name: "invalid benchmark status filter",
+			config: Config{
+				Processors: ProcessorConfig{
+					Benchmark: benchmark.Config{Enabled: true, StatusFilter: []string{"info"}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "invalid status in benchmark status_filter: info",
+		},
+		{
+			name: "invalid gatekeeper enforcement action filter",
+			config: Config{
+				Processors: ProcessorConfig{
+					Gatekeeper: gatekeeper.Config{Enabled: true, EnforcementActionFilter: []string{"audit"}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "invalid enforcement action in enforcement_action_filter: audit",
+		},
+		{
+			name: "missing compliance catalog file",
+			config: Config{
+				Enrichment: EnrichmentConfig{
+					Compliance: compliance.Config{CatalogFile: "/nonexistent/catalog.yaml"},
+				},
+			},
+			wantErr: true,
+			errMsg:  "invalid compliance catalog_file",
+		},
+		{
+			name: "invalid attribute names",
+			config: Config{
+				Output: OutputConfig{AttributeNames: "ecs"},
+			},
+			wantErr: true,
+			errMsg:  "invalid output attribute_names: ecs",
+		},
+		{
+			name: "invalid profile",
+			config: Config{
+				Output: OutputConfig{Profile: "ecs"},
+			},
+			wantErr: true,
+			errMsg:  "invalid output profile: ecs",
+		},
+		{
+			name: "both attribute names with asim profile",
+			config: Config{
+				Output: OutputConfig{Profile: "asim", AttributeNames: "both"},
+			},
+			wantErr: true,
+			errMsg:  "output attribute_names both requires the dynatrace profile",
+		},
+		{
+			name: "semconv attribute names with google udm profile",
+			config: Config{
+				Output: OutputConfig{Profile: "google_udm", AttributeNames: "semconv"},
+			},
+			wantErr: true,
+			errMsg:  "output attribute_names semconv requires the dynatrace profile",
+		},
+		{
+			name: "invalid body format",
+			config: Config{
+				Output: OutputConfig{BodyFormat: "xml"},
+			},
+			wantErr: true,
+			errMsg:  "invalid output body_format: xml",
+		},
+		{
+			name: "invalid siem format",
+			config: Config{
+				Output: OutputConfig{SIEM: cef.Config{Format: "syslog"}},
+			},
+			wantErr: true,
+			errMsg:  "invalid siem format: syslog",
+		},
+		{
+			name: "siem body conflicts with body format",
+			config: Config{
+				Output: OutputConfig{BodyFormat: "json", SIEM: cef.Config{Format: cef.FormatCEF}},
+			},
+			wantErr: true,
+			errMsg:  "output siem target body conflicts with body_format: json",
+		},
+		{
+			name: "invalid validation action",
+			config: Config{
+				Output: OutputConfig{Validation: validation.Config{Enabled: true, Action: "drop"}},
+			},
+			wantErr: true,
+			errMsg:  "invalid validation action: drop",
+		},
+		{
+			name: "missing validation schema file",
+			config: Config{
+				Output: OutputConfig{Validation: validation.Config{Enabled: true, SchemaFile: "/nonexistent/schema.json"}},
+			},
+			wantErr: true,
+			errMsg:  "invalid validation schema_file",
+		},
+		{
+			name: "invalid error mode",
+			config: Config{
+				ErrorMode: "ignore",
+			},
+			wantErr: true,
+			errMsg:  "invalid error_mode: ignore",
+		},
 	}
 
 	for _, tt := range tests {
@@ -108,3 +306,17 @@ func TestProcessorConfig_Empty(t *testing.T) {
 	// Should default to disabled
 	assert.False(t, config.OpenReports.Enabled)
 }
+
+func TestOutputConfig_AttributeNames(t *testing.T) {
+	assert.Equal(t, semconv.ModeLegacy, (&OutputConfig{}).attributeNames())
+	assert.Equal(t, semconv.ModeBoth, (&OutputConfig{AttributeNames: semconv.ModeBoth}).attributeNames())
+
+	require.NoError(t, featuregate.GlobalRegistry().Set(semconvAttributesGate.ID(), true))
+	t.Cleanup(func() {
+		require.NoError(t, featuregate.GlobalRegistry().Set(semconvAttributesGate.ID(), false))
+	})
+
+	// The feature gate changes the default, an explicit mode still wins
+	assert.Equal(t, semconv.ModeSemconv, (&OutputConfig{}).attributeNames())
+	assert.Equal(t, semconv.ModeLegacy, (&OutputConfig{AttributeNames: semconv.ModeLegacy}).attributeNames())
+}
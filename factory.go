@@ -25,6 +25,7 @@ func NewFactory() processor.Factory {
 
 func createDefaultConfig() component.Config {
 	return &Config{
+		ErrorMode:  ErrorModeDrop,
 		Processors: ProcessorConfig{},
 	}
 }
@@ -49,5 +50,7 @@ func createLogsProcessor(
 		nextConsumer,
 		processorInstance.processLogs,
 		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}),
+		processorhelper.WithStart(processorInstance.start),
+		processorhelper.WithShutdown(processorInstance.shutdown),
 	)
 }
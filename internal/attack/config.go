@@ -0,0 +1,32 @@
+package attack
+
+import "fmt"
+
+// Config defines the configuration for MITRE ATT&CK technique tagging
+type Config struct {
+	// Enabled indicates whether findings are tagged with MITRE ATT&CK techniques
+	Enabled bool `mapstructure:"enabled"`
+
+	// MappingFile is the path to a YAML or JSON mapping file merged over the embedded defaults
+	// Mappings in this file replace the default mapping of the same policy, rule, policy and rule pair, or tag
+	MappingFile string `mapstructure:"mapping_file"`
+
+	// DisableDefaults disables the embedded mapping for the Kubernetes matrix and Pod Security Standards
+	DisableDefaults bool `mapstructure:"disable_defaults"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.DisableDefaults && cfg.MappingFile == "" {
+		return fmt.Errorf("attack mapping_file is required when disable_defaults is set")
+	}
+	if cfg.MappingFile != "" {
+		if _, err := LoadMapping(cfg.MappingFile, true); err != nil {
+			return fmt.Errorf("invalid attack mapping_file: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+// Package attack tags security findings with MITRE ATT&CK techniques and tactics
+// based on the policy, rule or tags that produced them.
+package attack
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Framework is the value of the threat.framework attribute
+const Framework = "MITRE ATT&CK"
+
+//go:embed default_mapping.yaml
+var defaultMapping []byte
+
+// techniqueIDPattern matches ATT&CK technique and sub-technique IDs (e.g. T1611, T1562.001)
+var techniqueIDPattern = regexp.MustCompile(`^T\d{4}(\.\d{3})?$`)
+
+// Technique describes a MITRE ATT&CK technique
+type Technique struct {
+	ID      string
+	Name    string
+	Tactics []string
+}
+
+// techniqueSpec is the on-disk description of a technique
+type techniqueSpec struct {
+	Name    string   `yaml:"name" json:"name"`
+	Tactics []string `yaml:"tactics" json:"tactics"`
+}
+
+// mappingSpec associates a policy, rule, policy and rule pair, or tag with technique IDs
+type mappingSpec struct {
+	Policy     string   `yaml:"policy" json:"policy"`
+	Rule       string   `yaml:"rule" json:"rule"`
+	Tag        string   `yaml:"tag" json:"tag"`
+	Techniques []string `yaml:"techniques" json:"techniques"`
+}
+
+// mappingFile is the on-disk representation of a mapping
+type mappingFile struct {
+	Techniques map[string]techniqueSpec `yaml:"techniques" json:"techniques"`
+	Mappings   []mappingSpec            `yaml:"mappings" json:"mappings"`
+}
+
+// Mapping resolves policies, rules and tags to ATT&CK techniques
+// A nil *Mapping is valid and matches nothing
+type Mapping struct {
+	techniques   map[string]Technique
+	byPolicyRule map[string][]string
+	byPolicy     map[string][]string
+	byRule       map[string][]string
+	byTag        map[string][]string
+}
+
+// NewMapping builds the mapping described by the configuration
+func NewMapping(cfg *Config) (*Mapping, error) {
+	mapping := newEmptyMapping()
+	if !cfg.DisableDefaults {
+		if err := mapping.merge(defaultMapping); err != nil {
+			return nil, fmt.Errorf("failed to load default attack mapping: %w", err)
+		}
+	}
+	if cfg.MappingFile != "" {
+		data, err := os.ReadFile(cfg.MappingFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attack mapping: %w", err)
+		}
+		if err := mapping.merge(data); err != nil {
+			return nil, err
+		}
+	}
+	return mapping, nil
+}
+
+// LoadMapping reads a mapping file, optionally merged over the embedded defaults
+func LoadMapping(path string, withDefaults bool) (*Mapping, error) {
+	return NewMapping(&Config{Enabled: true, MappingFile: path, DisableDefaults: !withDefaults})
+}
+
+// ParseMapping parses mapping content in YAML or JSON format without the embedded defaults
+func ParseMapping(data []byte) (*Mapping, error) {
+	mapping := newEmptyMapping()
+	if err := mapping.merge(data); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+func newEmptyMapping() *Mapping {
+	return &Mapping{
+		techniques:   make(map[string]Technique),
+		byPolicyRule: make(map[string][]string),
+		byPolicy:     make(map[string][]string),
+		byRule:       make(map[string][]string),
+		byTag:        make(map[string][]string),
+	}
+}
+
+// merge parses mapping content and merges it into m
+// Techniques and mappings of the merged content replace existing entries with the same key; entries with the
+// same key within the merged content add up
+func (m *Mapping) merge(data []byte) error {
+	var file mappingFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse attack mapping: %w", err)
+	}
+
+	for id, spec := range file.Techniques {
+		if !techniqueIDPattern.MatchString(id) {
+			return fmt.Errorf("attack mapping: invalid technique id %q", id)
+		}
+		m.techniques[id] = Technique{ID: id, Name: spec.Name, Tactics: spec.Tactics}
+	}
+
+	// Keys of previously merged content are replaced, the techniques of a key repeated in this content add up
+	merged := make(map[string]bool)
+	add := func(index map[string][]string, kind, key string, ids []string) {
+		if !merged[kind+"\x00"+key] {
+			merged[kind+"\x00"+key] = true
+			index[key] = nil
+		}
+		index[key] = append(index[key], ids...)
+	}
+
+	for i, spec := range file.Mappings {
+		if len(spec.Techniques) == 0 {
+			return fmt.Errorf("attack mapping %d: at least one technique is required", i)
+		}
+		for _, id := range spec.Techniques {
+			if _, ok := m.techniques[id]; !ok {
+				return fmt.Errorf("attack mapping %d: unknown technique %q", i, id)
+			}
+		}
+
+		switch {
+		case spec.Tag != "" && (spec.Policy != "" || spec.Rule != ""):
+			return fmt.Errorf("attack mapping %d: tag cannot be combined with policy or rule", i)
+		case spec.Policy != "" && spec.Rule != "":
+			add(m.byPolicyRule, "policy_rule", policyRuleKey(spec.Policy, spec.Rule), spec.Techniques)
+		case spec.Policy != "":
+			add(m.byPolicy, "policy", spec.Policy, spec.Techniques)
+		case spec.Rule != "":
+			add(m.byRule, "rule", spec.Rule, spec.Techniques)
+		case spec.Tag != "":
+			add(m.byTag, "tag", spec.Tag, spec.Techniques)
+		default:
+			return fmt.Errorf("attack mapping %d: one of policy, rule or tag is required", i)
+		}
+	}
+
+	return nil
+}
+
+// Lookup returns the de-duplicated techniques matching the policy and rule pair, the policy, the rule or any of the tags
+// Tags that are themselves technique IDs (e.g. "T1611") resolve to that technique
+func (m *Mapping) Lookup(policy, rule string, tags ...string) []Technique {
+	if m == nil {
+		return nil
+	}
+
+	var techniques []Technique
+	seen := make(map[string]bool)
+	add := func(ids []string) {
+		for _, id := range ids {
+			technique, ok := m.techniques[id]
+			if ok && !seen[id] {
+				seen[id] = true
+				techniques = append(techniques, technique)
+			}
+		}
+	}
+
+	if policy != "" && rule != "" {
+		add(m.byPolicyRule[policyRuleKey(policy, rule)])
+	}
+	if policy != "" {
+		add(m.byPolicy[policy])
+	}
+	if rule != "" {
+		add(m.byRule[rule])
+	}
+	for _, tag := range tags {
+		if techniqueIDPattern.MatchString(tag) {
+			add([]string{tag})
+			continue
+		}
+		add(m.byTag[tag])
+	}
+
+	return techniques
+}
+
+// Len returns the number of policy and rule pairs, policies, rules and tags mapped to techniques
+func (m *Mapping) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.byPolicyRule) + len(m.byPolicy) + len(m.byRule) + len(m.byTag)
+}
+
+// policyRuleKey builds the index key for a policy and rule pair
+func policyRuleKey(policy, rule string) string {
+	return policy + "\x00" + rule
+}
+
+// IDs returns the technique IDs
+func IDs(techniques []Technique) []string {
+	ids := make([]string, 0, len(techniques))
+	for _, technique := range techniques {
+		ids = append(ids, technique.ID)
+	}
+	return ids
+}
+
+// Names returns the technique names
+func Names(techniques []Technique) []string {
+	names := make([]string, 0, len(techniques))
+	for _, technique := range techniques {
+		names = append(names, technique.Name)
+	}
+	return names
+}
+
+// Tactics returns the distinct tactic names of the techniques, in order of first appearance
+func Tactics(techniques []Technique) []string {
+	var tactics []string
+	seen := make(map[string]bool)
+	for _, technique := range techniques {
+		for _, tactic := range technique.Tactics {
+			if !seen[tactic] {
+				seen[tactic] = true
+				tactics = append(tactics, tactic)
+			}
+		}
+	}
+	return tactics
+}
@@ -0,0 +1,166 @@
+package attack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMapping_Defaults(t *testing.T) {
+	mapping, err := NewMapping(&Config{Enabled: true})
+	require.NoError(t, err)
+	assert.Positive(t, mapping.Len())
+
+	techniques := mapping.Lookup("disallow-privileged-containers", "privileged-containers")
+	require.Len(t, techniques, 2)
+	assert.Equal(t, []string{"T1611", "T1610"}, IDs(techniques))
+	assert.Equal(t, []string{"Escape to Host", "Deploy Container"}, Names(techniques))
+	assert.Equal(t, []string{"Privilege Escalation", "Defense Evasion", "Execution"}, Tactics(techniques))
+}
+
+func TestNewMapping_FileOverridesDefaults(t *testing.T) {
+	mappingFile := filepath.Join(t.TempDir(), "attack.yaml")
+	require.NoError(t, os.WriteFile(mappingFile, []byte(`
+techniques:
+  T1068:
+    name: Exploitation for Privilege Escalation
+    tactics: [Privilege Escalation]
+mappings:
+  - policy: disallow-privileged-containers
+    techniques: [T1068]
+  - tag: runtime-shell
+    techniques: [T1609]
+`), 0o600))
+
+	mapping, err := NewMapping(&Config{Enabled: true, MappingFile: mappingFile})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"T1068"}, IDs(mapping.Lookup("disallow-privileged-containers", "")))
+	assert.Equal(t, []string{"T1609"}, IDs(mapping.Lookup("", "", "runtime-shell")), "file can reference default techniques")
+	assert.Equal(t, []string{"T1611"}, IDs(mapping.Lookup("disallow-host-path", "")), "other defaults are kept")
+}
+
+func TestNewMapping_DisableDefaults(t *testing.T) {
+	mappingFile := filepath.Join(t.TempDir(), "attack.yaml")
+	require.NoError(t, os.WriteFile(mappingFile, []byte(`
+techniques:
+  T1611:
+    name: Escape to Host
+    tactics: [Privilege Escalation]
+mappings:
+  - rule: host-path
+    techniques: [T1611]
+`), 0o600))
+
+	mapping, err := NewMapping(&Config{Enabled: true, MappingFile: mappingFile, DisableDefaults: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, mapping.Len())
+	assert.Empty(t, mapping.Lookup("disallow-privileged-containers", ""))
+	assert.Equal(t, []string{"T1611"}, IDs(mapping.Lookup("any-policy", "host-path")))
+}
+
+func TestMapping_LookupTechniqueIDTags(t *testing.T) {
+	mapping, err := NewMapping(&Config{Enabled: true})
+	require.NoError(t, err)
+
+	techniques := mapping.Lookup("unknown-policy", "unknown-rule", "T1609", "T1611", "T1611", "T9999", "not-a-technique")
+	assert.Equal(t, []string{"T1609", "T1611"}, IDs(techniques), "known technique IDs resolve, duplicates and unknown IDs are dropped")
+}
+
+func TestParseMapping_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		errMsg string
+	}{
+		{
+			name:   "malformed yaml",
+			data:   "mappings: [",
+			errMsg: "failed to parse attack mapping",
+		},
+		{
+			name:   "invalid technique id",
+			data:   "techniques:\n  X1:\n    name: bad\n",
+			errMsg: "invalid technique id",
+		},
+		{
+			name:   "unknown technique",
+			data:   "mappings:\n  - policy: p\n    techniques: [T1611]\n",
+			errMsg: "unknown technique",
+		},
+		{
+			name:   "mapping without key",
+			data:   "techniques:\n  T1611:\n    name: Escape to Host\nmappings:\n  - techniques: [T1611]\n",
+			errMsg: "one of policy, rule or tag is required",
+		},
+		{
+			name:   "tag with policy",
+			data:   "techniques:\n  T1611:\n    name: Escape to Host\nmappings:\n  - policy: p\n    tag: t\n    techniques: [T1611]\n",
+			errMsg: "tag cannot be combined with policy or rule",
+		},
+		{
+			name:   "mapping without techniques",
+			data:   "mappings:\n  - policy: p\n",
+			errMsg: "at least one technique is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMapping([]byte(tt.data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestParseMapping_PolicyRule(t *testing.T) {
+	mapping, err := ParseMapping([]byte(`
+techniques:
+  T1611:
+    name: Escape to Host
+  T1610:
+    name: Deploy Container
+  T1609:
+    name: Container Administration Command
+mappings:
+  - policy: disallow-host-path
+    rule: host-path
+    techniques: [T1611]
+  - policy: disallow-host-path
+    rule: host-path
+    techniques: [T1610]
+  - rule: host-path
+    techniques: [T1609]
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, mapping.Len())
+	assert.Equal(t, []string{"T1611", "T1610", "T1609"}, IDs(mapping.Lookup("disallow-host-path", "host-path")),
+		"repeated entries add up")
+	assert.Equal(t, []string{"T1609"}, IDs(mapping.Lookup("other-policy", "host-path")), "the pair only matches its policy")
+	assert.Empty(t, mapping.Lookup("disallow-host-path", "other-rule"), "the pair only matches its rule")
+}
+
+func TestMapping_NilLookup(t *testing.T) {
+	var mapping *Mapping
+	assert.Nil(t, mapping.Lookup("policy", "rule", "T1611"))
+	assert.Equal(t, 0, mapping.Len())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&Config{}).Validate())
+	assert.NoError(t, (&Config{Enabled: true}).Validate())
+
+	err := (&Config{Enabled: true, DisableDefaults: true}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mapping_file is required")
+
+	err = (&Config{Enabled: true, MappingFile: filepath.Join(t.TempDir(), "missing.yaml")}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid attack mapping_file")
+}
@@ -0,0 +1,109 @@
+package benchmark
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Report formats, also the report kinds of the telemetry
+const (
+	FormatKubeBench = "kube-bench"
+	FormatKubescape = "kubescape"
+)
+
+// Check statuses, named as the OpenReports result statuses
+const (
+	statusPass = "pass"
+	statusFail = "fail"
+	statusWarn = "warn"
+	statusSkip = "skip"
+)
+
+// cisFramework is the framework name of the CIS Kubernetes Benchmark controls
+const cisFramework = "CIS"
+
+// cisControlPattern matches the CIS control ID prefixing the Kubescape control names of the CIS frameworks,
+// e.g. "CIS-1.1.1 Ensure that the API server pod specification file permissions are set to 600"
+var cisControlPattern = regexp.MustCompile(`^CIS-(\d+(?:\.\d+)*)\s+(.*)$`)
+
+// report is a benchmark report decoded from one of the report formats
+type report struct {
+	// tool is the scanner that produced the report
+	tool schema.Source
+
+	// timestamp is the time of the scan, zero if unknown
+	timestamp time.Time
+
+	// checks are the check results of the report, in report order
+	checks []check
+
+	// metadata is shared by the security events of the report
+	metadata map[string]interface{}
+}
+
+// check is the result of a benchmark check on a target, in a format-neutral form
+type check struct {
+	// policy groups the checks: the benchmark of a kube-bench check, the control of a Kubescape check
+	policy string
+
+	// id is the scanner identifier of the check: the kube-bench test number, the Kubescape control ID
+	id string
+
+	title       string
+	description string
+
+	// status is one of the check statuses
+	status string
+
+	// severity is critical, high, medium or low, "" if the scanner does not rate the check
+	severity string
+
+	// cis is the CIS control of the check, zero if the check is not a CIS Benchmark control
+	cis compliance.Control
+
+	// target is the node or Kubernetes object that was checked
+	target schema.Target
+
+	// metadata holds the format specific fields of the check
+	metadata map[string]interface{}
+}
+
+// rule returns the rule of a check, its CIS control ID if known
+func (c *check) rule() string {
+	if c.cis.ID != "" {
+		return c.cis.ID
+	}
+	return c.id
+}
+
+// cisControl returns the CIS control and title of a check name prefixed by its CIS control ID,
+// or a zero control and the name
+func cisControl(name string) (compliance.Control, string) {
+	match := cisControlPattern.FindStringSubmatch(name)
+	if match == nil {
+		return compliance.Control{}, name
+	}
+	return compliance.Control{Framework: cisFramework, ID: match[1]}, match[2]
+}
+
+// severityFromScore maps a 0-10 score to a severity, as Kubescape rates its controls: controls below 1 are unrated
+func severityFromScore(score float64) string {
+	if score < 1 {
+		return ""
+	}
+	return strings.ToLower(processing.SeverityFromScore(score))
+}
+
+// parseTime parses an RFC 3339 timestamp, zero if absent or invalid
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
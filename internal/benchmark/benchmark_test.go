@@ -0,0 +1,27 @@
+package benchmark
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+)
+
+func TestCISControl(t *testing.T) {
+	control, title := cisControl("CIS-5.2.2 Minimize the admission of privileged containers")
+	assert.Equal(t, compliance.Control{Framework: "CIS", ID: "5.2.2"}, control)
+	assert.Equal(t, "Minimize the admission of privileged containers", title)
+
+	control, title = cisControl("Privileged container")
+	assert.Equal(t, compliance.Control{}, control)
+	assert.Equal(t, "Privileged container", title)
+}
+
+func TestSeverityFromScore(t *testing.T) {
+	assert.Equal(t, "critical", severityFromScore(9))
+	assert.Equal(t, "high", severityFromScore(7))
+	assert.Equal(t, "medium", severityFromScore(6))
+	assert.Equal(t, "low", severityFromScore(1))
+	assert.Empty(t, severityFromScore(0))
+}
@@ -0,0 +1,29 @@
+package benchmark
+
+import "fmt"
+
+// Config defines the configuration for the benchmark processor
+type Config struct {
+	// Enabled indicates whether the benchmark processor is enabled
+	// Log records whose body holds a kube-bench or Kubescape JSON report, as a JSON string or a parsed map,
+	// are expanded into one compliance finding per check result
+	Enabled bool `mapstructure:"enabled"`
+
+	// StatusFilter is an array of check statuses to process
+	// Only checks with statuses in this list will be transformed into security events
+	// Valid values: "pass", "fail", "warn", "skip"
+	// If empty or not specified, all statuses will be processed
+	StatusFilter []string `mapstructure:"status_filter"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	for _, status := range cfg.StatusFilter {
+		switch status {
+		case statusPass, statusFail, statusWarn, statusSkip:
+		default:
+			return fmt.Errorf("invalid status in benchmark status_filter: %s. Valid values are: pass, fail, warn, skip", status)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package benchmark
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := &Config{Enabled: true, StatusFilter: []string{"pass", "fail", "warn", "skip"}}
+	assert.NoError(t, cfg.Validate())
+
+	cfg = &Config{Enabled: true, StatusFilter: []string{"fail", "info"}}
+	assert.EqualError(t, cfg.Validate(), "invalid status in benchmark status_filter: info. Valid values are: pass, fail, warn, skip")
+}
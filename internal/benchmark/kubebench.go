@@ -0,0 +1,143 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/openreports"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// kubeBenchVendor is the vendor of kube-bench, which its reports do not name
+const kubeBenchVendor = "Aqua Security"
+
+// kubeBenchReport is a kube-bench JSON report (kube-bench --json)
+// Only the fields mapped to security events are decoded
+type kubeBenchReport struct {
+	Controls []kubeBenchControls `json:"Controls"`
+}
+
+// kubeBenchControls are the checks of a benchmark section for a node type, e.g. the control plane
+type kubeBenchControls struct {
+	ID       string           `json:"id"`
+	Version  string           `json:"version"`
+	Text     string           `json:"text"`
+	NodeType string           `json:"node_type"`
+	Tests    []kubeBenchGroup `json:"tests"`
+}
+
+// kubeBenchGroup is a group of checks, e.g. 1.1 Control Plane Node Configuration Files
+type kubeBenchGroup struct {
+	Section string            `json:"section"`
+	Desc    string            `json:"desc"`
+	Results []kubeBenchResult `json:"results"`
+}
+
+// kubeBenchResult is the result of a check
+type kubeBenchResult struct {
+	TestNumber     string `json:"test_number"`
+	TestDesc       string `json:"test_desc"`
+	Remediation    string `json:"remediation"`
+	Status         string `json:"status"`
+	ActualValue    string `json:"actual_value"`
+	Scored         bool   `json:"scored"`
+	ExpectedResult string `json:"expected_result"`
+	Reason         string `json:"reason"`
+}
+
+// kubeBenchStatuses maps the kube-bench statuses to check statuses
+// INFO is the status of the checks kube-bench skips
+var kubeBenchStatuses = map[string]string{
+	"PASS": statusPass,
+	"FAIL": statusFail,
+	"WARN": statusWarn,
+	"INFO": statusSkip,
+}
+
+// parseKubeBench decodes a kube-bench JSON report
+// kube-bench runs on the node it checks, so the node is the target of every check
+func parseKubeBench(data []byte, node string, attrs pcommon.Map) (*report, error) {
+	var raw kubeBenchReport
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid kube-bench report: %w", err)
+	}
+	if raw.Controls == nil {
+		return nil, errors.New("invalid kube-bench report: missing Controls")
+	}
+
+	r := &report{
+		tool:     schema.Source{Application: FormatKubeBench, Vendor: kubeBenchVendor},
+		metadata: map[string]interface{}{},
+	}
+	target := schema.Target{}
+	if node != "" {
+		target = schema.Target{
+			ID:           node,
+			Resource:     node,
+			ResourceType: "Node",
+			EntityType:   "K8S_NODE",
+			Kubernetes:   openreports.ObjectK8sFields(attrs, "Node", "", node, ""),
+		}
+	}
+
+	for i := range raw.Controls {
+		controls := &raw.Controls[i]
+		for j := range controls.Tests {
+			group := &controls.Tests[j]
+			for k := range group.Results {
+				r.checks = append(r.checks, controls.check(group, &group.Results[k], target))
+			}
+		}
+	}
+	return r, nil
+}
+
+// check returns the check of a result
+// The test numbers of kube-bench are the CIS control IDs of the benchmark version it runs
+func (c *kubeBenchControls) check(group *kubeBenchGroup, result *kubeBenchResult, target schema.Target) check {
+	status, ok := kubeBenchStatuses[strings.ToUpper(result.Status)]
+	if !ok {
+		status = strings.ToLower(result.Status)
+	}
+	title := result.TestDesc
+	if title == "" {
+		title = result.TestNumber
+	}
+
+	ch := check{
+		policy:      c.Version,
+		id:          result.TestNumber,
+		title:       title,
+		description: title,
+		status:      status,
+		target:      target,
+		metadata: map[string]interface{}{
+			"scored": result.Scored,
+		},
+	}
+	if strings.HasPrefix(strings.ToLower(c.Version), "cis") && result.TestNumber != "" {
+		ch.cis = compliance.Control{Framework: cisFramework, ID: result.TestNumber}
+	}
+	if ch.policy == "" {
+		ch.policy = FormatKubeBench
+	}
+	putString(ch.metadata, "node_type", c.NodeType)
+	putString(ch.metadata, "section", strings.TrimSpace(group.Section+" "+group.Desc))
+	putString(ch.metadata, "remediation", result.Remediation)
+	putString(ch.metadata, "actual_value", result.ActualValue)
+	putString(ch.metadata, "expected_result", result.ExpectedResult)
+	putString(ch.metadata, "reason", result.Reason)
+	return ch
+}
+
+// putString sets a metadata field, skipping empty values
+func putString(metadata map[string]interface{}, key, value string) {
+	if value != "" {
+		metadata[key] = value
+	}
+}
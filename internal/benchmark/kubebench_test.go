@@ -0,0 +1,41 @@
+package benchmark
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+func TestParseKubeBench_WithoutNode(t *testing.T) {
+	report, err := parseKubeBench([]byte(`{"Controls": [{"id": "4", "version": "gke-1.4.0", "node_type": "node", "tests": [
+		{"section": "4.2", "desc": "Kubelet", "results": [
+			{"test_number": "4.2.1", "test_desc": "Ensure that the --anonymous-auth argument is set to false", "status": "INFO", "type": "skip"},
+			{"test_number": "4.2.2", "status": "ERROR"}
+		]}]}]}`), "", pcommon.NewMap())
+	require.NoError(t, err)
+	assert.Equal(t, schema.Source{Application: "kube-bench", Vendor: "Aqua Security"}, report.tool)
+	require.Len(t, report.checks, 2)
+
+	skipped := report.checks[0]
+	assert.Equal(t, schema.Target{}, skipped.target)
+	assert.Equal(t, statusSkip, skipped.status)
+	assert.Equal(t, "gke-1.4.0", skipped.policy)
+	// Only the CIS benchmarks number their checks with CIS control IDs
+	assert.Equal(t, compliance.Control{}, skipped.cis)
+	assert.Equal(t, "4.2.1", skipped.rule())
+
+	assert.Equal(t, "error", report.checks[1].status)
+	assert.Equal(t, "4.2.2", report.checks[1].title)
+}
+
+func TestParseKubeBench_Invalid(t *testing.T) {
+	_, err := parseKubeBench([]byte(`[]`), "", pcommon.NewMap())
+	assert.ErrorContains(t, err, "invalid kube-bench report")
+	_, err = parseKubeBench([]byte(`{"Totals": {}}`), "", pcommon.NewMap())
+	assert.ErrorContains(t, err, "missing Controls")
+}
@@ -0,0 +1,240 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/openreports"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Kubescape identification of the tool, which its reports do not name
+const (
+	kubescapeApplication = "Kubescape"
+	kubescapeVendor      = "ARMO"
+)
+
+// kubescapeReport is a Kubescape JSON report (kubescape scan --format json)
+// Only the fields mapped to security events are decoded
+type kubescapeReport struct {
+	ClusterName    string              `json:"clusterName"`
+	GenerationTime string              `json:"generationTime"`
+	SummaryDetails *kubescapeSummary   `json:"summaryDetails"`
+	Resources      []kubescapeResource `json:"resources"`
+	Results        []kubescapeResult   `json:"results"`
+}
+
+// kubescapeSummary summarizes the scan per control and framework
+type kubescapeSummary struct {
+	Controls   map[string]kubescapeControlSummary `json:"controls"`
+	Frameworks []struct {
+		Name     string                             `json:"name"`
+		Controls map[string]kubescapeControlSummary `json:"controls"`
+	} `json:"frameworks"`
+}
+
+// kubescapeControlSummary describes a control
+type kubescapeControlSummary struct {
+	Name string `json:"name"`
+	// ScoreFactor rates the severity of the control from 1 to 10
+	ScoreFactor float64 `json:"scoreFactor"`
+}
+
+// kubescapeResource is a scanned Kubernetes object
+type kubescapeResource struct {
+	ResourceID string `json:"resourceID"`
+	Object     struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+			UID       string `json:"uid"`
+		} `json:"metadata"`
+	} `json:"object"`
+}
+
+// kubescapeResult holds the controls checked on a Kubernetes object
+type kubescapeResult struct {
+	ResourceID string             `json:"resourceID"`
+	Controls   []kubescapeControl `json:"controls"`
+}
+
+// kubescapeControl is the result of a control on a Kubernetes object
+type kubescapeControl struct {
+	ControlID string `json:"controlID"`
+	Name      string `json:"name"`
+	Status    struct {
+		Status    string `json:"status"`
+		SubStatus string `json:"subStatus"`
+	} `json:"status"`
+	Rules []struct {
+		Name  string `json:"name"`
+		Paths []struct {
+			FailedPath string `json:"failedPath"`
+			FixPath    struct {
+				Path  string `json:"path"`
+				Value string `json:"value"`
+			} `json:"fixPath"`
+		} `json:"paths"`
+	} `json:"rules"`
+}
+
+// parseKubescape decodes a Kubescape JSON report
+// The k8s.* attributes of the log record are set on the scanned objects, as for Gatekeeper violations
+func parseKubescape(data []byte, attrs pcommon.Map) (*report, error) {
+	var raw kubescapeReport
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid Kubescape report: %w", err)
+	}
+	if raw.SummaryDetails == nil {
+		return nil, errors.New("invalid Kubescape report: missing summaryDetails")
+	}
+
+	r := &report{
+		tool:      schema.Source{Application: kubescapeApplication, Vendor: kubescapeVendor},
+		timestamp: parseTime(raw.GenerationTime),
+		metadata:  map[string]interface{}{},
+	}
+	putString(r.metadata, "cluster", raw.ClusterName)
+
+	resources := make(map[string]*kubescapeResource, len(raw.Resources))
+	for i := range raw.Resources {
+		resources[raw.Resources[i].ResourceID] = &raw.Resources[i]
+	}
+	for i := range raw.Results {
+		result := &raw.Results[i]
+		target := kubescapeTarget(result.ResourceID, resources[result.ResourceID], raw.ClusterName, attrs)
+		for j := range result.Controls {
+			r.checks = append(r.checks, raw.SummaryDetails.check(&result.Controls[j], target))
+		}
+	}
+	return r, nil
+}
+
+// check returns the check of a control result
+// The CIS control ID is taken from the control name in the CIS frameworks of the scan
+func (s *kubescapeSummary) check(control *kubescapeControl, target schema.Target) check {
+	ch := check{
+		policy:   control.ControlID,
+		id:       control.ControlID,
+		title:    control.Name,
+		status:   kubescapeStatus(control.Status.Status, control.Status.SubStatus),
+		severity: severityFromScore(s.Controls[control.ControlID].ScoreFactor),
+		target:   target,
+		metadata: map[string]interface{}{},
+	}
+	if ch.title == "" {
+		ch.title = s.Controls[control.ControlID].Name
+	}
+	ch.cis, ch.title = cisControl(ch.title)
+
+	var frameworks []interface{}
+	for i := range s.Frameworks {
+		framework := &s.Frameworks[i]
+		summary, ok := framework.Controls[control.ControlID]
+		if !ok {
+			continue
+		}
+		frameworks = append(frameworks, framework.Name)
+		if ch.cis.ID == "" {
+			if cis, title := cisControl(summary.Name); cis.ID != "" {
+				ch.cis = cis
+				if ch.title == "" {
+					ch.title = title
+				}
+			}
+		}
+	}
+	if ch.title == "" {
+		ch.title = ch.id
+	}
+	ch.description = ch.title
+	if len(frameworks) > 0 {
+		ch.metadata["frameworks"] = frameworks
+	}
+
+	var rules, failedPaths, fixPaths []interface{}
+	for _, rule := range control.Rules {
+		rules = append(rules, rule.Name)
+		for _, path := range rule.Paths {
+			if path.FailedPath != "" {
+				failedPaths = append(failedPaths, path.FailedPath)
+			}
+			if path.FixPath.Path != "" {
+				fixPaths = append(fixPaths, path.FixPath.Path+"="+path.FixPath.Value)
+			}
+		}
+	}
+	if len(rules) > 0 {
+		ch.metadata["rules"] = rules
+	}
+	if len(failedPaths) > 0 {
+		ch.metadata["failed_paths"] = failedPaths
+	}
+	if len(fixPaths) > 0 {
+		ch.metadata["fix_paths"] = fixPaths
+	}
+	putString(ch.metadata, "sub_status", control.Status.SubStatus)
+	return ch
+}
+
+// kubescapeTarget returns the Kubernetes object of a resource ID, described by the scanned resource if known
+// Resource IDs are <group>/<version>/<namespace>/<kind>/<name>, optionally prefixed by path=<n>/api=
+// The object is resolved into the k8s.* fields of an OpenReports report scoping it, in the scanned cluster unless
+// the log record names one
+func kubescapeTarget(resourceID string, resource *kubescapeResource, cluster string, attrs pcommon.Map) schema.Target {
+	id := resourceID
+	if i := strings.Index(id, "api="); i >= 0 {
+		id = id[i+len("api="):]
+	}
+	var kind, name, namespace, uid string
+	if parts := strings.Split(id, "/"); len(parts) >= 5 {
+		parts = parts[len(parts)-5:]
+		namespace, kind, name = parts[2], parts[3], parts[4]
+	}
+	if resource != nil {
+		kind = processing.FirstNonEmpty(resource.Object.Kind, kind)
+		name = processing.FirstNonEmpty(resource.Object.Metadata.Name, name)
+		namespace = processing.FirstNonEmpty(resource.Object.Metadata.Namespace, namespace)
+		uid = resource.Object.Metadata.UID
+	}
+
+	target := schema.Target{
+		ID:           processing.FirstNonEmpty(uid, resourceID),
+		Resource:     name,
+		ResourceType: kind,
+		Kubernetes:   openreports.ObjectK8sFields(attrs, kind, namespace, name, uid),
+	}
+	if _, exists := target.Kubernetes[clusterNameAttribute]; !exists && cluster != "" {
+		target.Kubernetes[clusterNameAttribute] = cluster
+	}
+	if kind == "Pod" {
+		target.EntityType = "K8S_POD"
+	}
+	return target
+}
+
+// kubescapeStatus maps a Kubescape control status to a check status
+// Skipped controls that require a manual review are warnings
+func kubescapeStatus(status, subStatus string) string {
+	switch strings.ToLower(status) {
+	case "passed":
+		return statusPass
+	case "failed":
+		return statusFail
+	case "skipped":
+		if subStatus == "manual_review" {
+			return statusWarn
+		}
+		return statusSkip
+	case "irrelevant":
+		return statusSkip
+	default:
+		return strings.ToLower(status)
+	}
+}
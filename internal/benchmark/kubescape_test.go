@@ -0,0 +1,102 @@
+package benchmark
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+func TestParseKubescape_ClusterScoped(t *testing.T) {
+	report, err := parseKubescape([]byte(`{
+		"summaryDetails": {"controls": {"C-0035": {"name": "Administrative Roles", "scoreFactor": 6}}},
+		"results": [{"resourceID": "rbac.authorization.k8s.io/v1//ClusterRole/admin", "controls": [
+			{"controlID": "C-0035", "status": {"status": "irrelevant"}},
+			{"controlID": "C-0036", "status": {"status": "failed"}}
+		]}]
+	}`), pcommon.NewMap())
+	require.NoError(t, err)
+	assert.True(t, report.timestamp.IsZero())
+	require.Len(t, report.checks, 2)
+
+	// The control name defaults to the summary
+	check := report.checks[0]
+	assert.Equal(t, "Administrative Roles", check.title)
+	assert.Equal(t, statusSkip, check.status)
+	assert.Equal(t, "medium", check.severity)
+	assert.Equal(t, schema.Target{
+		ID:           "rbac.authorization.k8s.io/v1//ClusterRole/admin",
+		Resource:     "admin",
+		ResourceType: "ClusterRole",
+		Kubernetes:   map[string]interface{}{"k8s.resource.kind": "ClusterRole", "k8s.resource.name": "admin"},
+	}, check.target)
+
+	// Unknown controls are identified by their ID
+	assert.Equal(t, "C-0036", report.checks[1].title)
+	assert.Empty(t, report.checks[1].severity)
+}
+
+func TestKubescapeTarget(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.PutStr("k8s.cluster.name", "prod-eu-1")
+
+	// The owning workload of a pod is derived from its name, and the log record names the cluster
+	resource := &kubescapeResource{}
+	resource.Object.Kind = "Pod"
+	resource.Object.Metadata.Name = "checkout-7c5ddbdf54-x2v4z"
+	resource.Object.Metadata.Namespace = "payments"
+	resource.Object.Metadata.UID = "0f1e2d3c"
+	assert.Equal(t, schema.Target{
+		ID:           "0f1e2d3c",
+		Resource:     "checkout-7c5ddbdf54-x2v4z",
+		ResourceType: "Pod",
+		EntityType:   "K8S_POD",
+		Kubernetes: map[string]interface{}{
+			"k8s.cluster.name":       "prod-eu-1",
+			"k8s.namespace.name":     "payments",
+			"k8s.pod.name":           "checkout-7c5ddbdf54-x2v4z",
+			"k8s.resource.kind":      "Pod",
+			"k8s.resource.uid":       "0f1e2d3c",
+			"k8s.deployment.name":    "checkout",
+			"k8s.workload.name":      "checkout",
+			"k8s.workload.kind":      "Deployment",
+			"k8s.workload.namespace": "payments",
+		},
+	}, kubescapeTarget("/v1/payments/Pod/checkout-7c5ddbdf54-x2v4z", resource, "staging", attrs))
+
+	// Otherwise the object is in the scanned cluster
+	assert.Equal(t, schema.Target{
+		ID:           "apps/v1/shop/StatefulSet/db",
+		Resource:     "db",
+		ResourceType: "StatefulSet",
+		Kubernetes: map[string]interface{}{
+			"k8s.cluster.name":       "staging",
+			"k8s.namespace.name":     "shop",
+			"k8s.resource.kind":      "StatefulSet",
+			"k8s.statefulset.name":   "db",
+			"k8s.workload.name":      "db",
+			"k8s.workload.kind":      "StatefulSet",
+			"k8s.workload.namespace": "shop",
+		},
+	}, kubescapeTarget("apps/v1/shop/StatefulSet/db", nil, "staging", pcommon.NewMap()))
+}
+
+func TestParseKubescape_Invalid(t *testing.T) {
+	_, err := parseKubescape([]byte(`"report"`), pcommon.NewMap())
+	assert.ErrorContains(t, err, "invalid Kubescape report")
+	_, err = parseKubescape([]byte(`{"results": []}`), pcommon.NewMap())
+	assert.ErrorContains(t, err, "missing summaryDetails")
+}
+
+func TestKubescapeStatus(t *testing.T) {
+	assert.Equal(t, statusPass, kubescapeStatus("passed", ""))
+	assert.Equal(t, statusPass, kubescapeStatus("passed", "w/exceptions"))
+	assert.Equal(t, statusFail, kubescapeStatus("failed", ""))
+	assert.Equal(t, statusWarn, kubescapeStatus("skipped", "manual_review"))
+	assert.Equal(t, statusSkip, kubescapeStatus("skipped", "integration"))
+	assert.Equal(t, statusSkip, kubescapeStatus("irrelevant", ""))
+	assert.Equal(t, "unknown", kubescapeStatus("Unknown", ""))
+}
@@ -0,0 +1,326 @@
+// Package benchmark expands the reports of Kubernetes benchmark and posture scanners (kube-bench, Kubescape)
+// into compliance findings, one per check result, shaped as the OpenReports findings.
+package benchmark
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Reasons for check results that are not turned into security events
+const (
+	// FilterReasonStatus marks check results excluded by the status_filter configuration
+	FilterReasonStatus = "status_filter"
+)
+
+// Reasons for log records that produce no security events
+const (
+	// SkipReasonNotBenchmark marks log records whose body holds no benchmark report
+	SkipReasonNotBenchmark = "not_benchmark_report"
+	// SkipReasonInvalidReport marks reports that cannot be decoded,
+	// ProcessLogRecord also returns a parse stage error for them
+	SkipReasonInvalidReport = "invalid_report"
+	// SkipReasonNoChecks marks reports without check results
+	SkipReasonNoChecks = "no_checks"
+)
+
+// Security event classification of the findings, as for OpenReports findings
+const (
+	eventCategory = "COMPLIANCE"
+	eventName     = "Compliance finding event"
+	eventType     = "COMPLIANCE_FINDING"
+	actionType    = "policy_evaluation"
+
+	complianceCompliant    = "COMPLIANT"
+	complianceNonCompliant = "NON_COMPLIANT"
+)
+
+// nodeNameAttribute is the resource attribute naming the node a kube-bench job ran on
+const nodeNameAttribute = "k8s.node.name"
+
+// clusterNameAttribute names the cluster of the objects scanned by Kubescape
+const clusterNameAttribute = "k8s.cluster.name"
+
+// findingNamespace is the namespace of the finding IDs derived from the tool, target and check
+var findingNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/henrikrexed/securitylogeventprocessor/benchmark"))
+
+// Processor handles transformation of benchmark reports into security events
+type Processor struct {
+	logger  *zap.Logger
+	config  *Config
+	catalog *compliance.Catalog
+
+	// output lays out the security events in their log records
+	output processing.Output
+}
+
+// Option configures optional dependencies of the Processor
+type Option func(*Processor)
+
+// WithComplianceCatalog enriches findings with the framework controls mapped in the catalog
+func WithComplianceCatalog(catalog *compliance.Catalog) Option {
+	return func(p *Processor) {
+		p.catalog = catalog
+	}
+}
+
+// WithOutput sets the layout of the security events in their log records: the profile, attribute names,
+// body format and SIEM encoding
+func WithOutput(output processing.Output) Option {
+	return func(p *Processor) {
+		p.output = output
+	}
+}
+
+// NewProcessor creates a new benchmark processor
+func NewProcessor(logger *zap.Logger, config *Config, opts ...Option) (*Processor, error) {
+	p := &Processor{
+		logger: logger,
+		config: config,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	// The action and result of the security events are part of the source events
+	p.output.ActionFields = true
+	return p, nil
+}
+
+// Format performs a quick check to determine the format of the benchmark report in the body of a
+// log record, as a JSON string or a map parsed from it: FormatKubeBench, FormatKubescape, or "" if none
+// A report is told by its top-level keys: kube-bench Controls, or Kubescape summaryDetails and results
+func Format(logRecord *plog.LogRecord) string {
+	var values map[string]processing.JSONValue
+	body := logRecord.Body()
+	switch body.Type() {
+	case pcommon.ValueTypeStr:
+		values = processing.TopLevelValues(body.Str(), "Controls", "summaryDetails", "results")
+	case pcommon.ValueTypeMap:
+		values = processing.MapValues(body.Map(), "Controls", "summaryDetails", "results")
+	default:
+		return ""
+	}
+	switch {
+	case values["Controls"].Kind == processing.JSONArray:
+		return FormatKubeBench
+	case values["summaryDetails"].Kind == processing.JSONObject && values["results"].Kind == processing.JSONArray:
+		return FormatKubescape
+	default:
+		return ""
+	}
+}
+
+// ProcessLogRecord expands a log record whose body holds a benchmark report into one security event
+// per check result
+// The security events are appended to dst; nothing is appended if the body holds no report
+// The checks of a kube-bench report target the node named by the k8s.node.name resource attribute
+// A *processing.StageError is returned if the report cannot be decoded or its security events cannot be written;
+// nothing is appended to dst in that case
+func (p *Processor) ProcessLogRecord(
+	_ context.Context, logRecord *plog.LogRecord, resource pcommon.Resource, _ plog.ScopeLogs, dst plog.LogRecordSlice,
+) (processing.Outcome, error) {
+	var outcome processing.Outcome
+	format := Format(logRecord)
+	if format == "" {
+		outcome.SkipReason = SkipReasonNotBenchmark
+		return outcome, nil
+	}
+
+	var node string
+	if value, exists := resource.Attributes().Get(nodeNameAttribute); exists {
+		node = value.AsString()
+	}
+	report, err := parseBody(logRecord, format, node)
+	if err != nil {
+		outcome.SkipReason = SkipReasonInvalidReport
+		return outcome, processing.NewStageError(processing.StageParse, err)
+	}
+	if len(report.checks) == 0 {
+		outcome.SkipReason = SkipReasonNoChecks
+		return outcome, nil
+	}
+	if p.logger.Core().Enabled(zapcore.DebugLevel) {
+		p.logger.Debug("Benchmark report identified - processing",
+			zap.String("format", format),
+			zap.String("node", node),
+			zap.Int("checks", len(report.checks)),
+			zap.Strings("allowed_statuses", p.config.StatusFilter),
+			zap.String("trace_id", logRecord.TraceID().String()))
+	}
+
+	first := dst.Len()
+	for i := range report.checks {
+		c := &report.checks[i]
+		outcome.Results++
+		outcome.Parsed++
+		if !p.isStatusAllowed(c.status) {
+			outcome.AddFiltered(FilterReasonStatus)
+			continue
+		}
+
+		// The record timestamp is the time of the scan
+		newRecord := processing.NewEventRecord(*logRecord, dst, report.timestamp)
+
+		if err := p.output.Write(newRecord, p.buildSecurityEvent(report, c, format)); err != nil {
+			// Remove the security events of the report, including the partially written one
+			processing.Rollback(dst, first)
+			outcome.Created = 0
+			return outcome, processing.NewStageError(processing.StageTransform, err)
+		}
+		outcome.Created++
+	}
+	return outcome, nil
+}
+
+// parseBody decodes the report of a string or map body
+func parseBody(logRecord *plog.LogRecord, format, node string) (*report, error) {
+	body := logRecord.Body()
+	var data []byte
+	if body.Type() == pcommon.ValueTypeStr {
+		data = []byte(body.Str())
+	} else {
+		var err error
+		if data, err = json.Marshal(body.Map().AsRaw()); err != nil {
+			return nil, fmt.Errorf("invalid %s report: %w", format, err)
+		}
+	}
+	if format == FormatKubescape {
+		return parseKubescape(data, logRecord.Attributes())
+	}
+	return parseKubeBench(data, node, logRecord.Attributes())
+}
+
+// buildSecurityEvent builds the compliance finding of a check result
+//
+// The fields are laid out as for OpenReports results: the check group is the policy, the CIS control ID
+// (or the scanner check ID) the rule, and the checked node or object the target
+func (p *Processor) buildSecurityEvent(report *report, c *check, format string) *schema.SecurityEvent {
+	rule := c.rule()
+	title := c.title
+	if title != rule {
+		title = rule + " " + title
+	}
+
+	event := &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event: schema.Event{
+			ID:          uuid.New().String(),
+			Version:     processing.EventVersion,
+			Category:    eventCategory,
+			Name:        eventName,
+			Type:        eventType,
+			Description: eventDescription(c.status, c.target.Resource, rule),
+		},
+		Message: c.description,
+		Source:  report.tool,
+		Target:  c.target,
+		Action: schema.Action{
+			Type: actionType,
+		},
+		Result: schema.Result{
+			Status: c.status,
+		},
+		RiskScore: processing.RiskScore(c.severity),
+		Finding: schema.Finding{
+			ID:          findingID(report.tool.Application, &c.target, c.id),
+			Title:       title,
+			Description: c.description,
+			Type:        c.policy,
+			Severity:    strings.ToUpper(c.severity),
+		},
+		Compliance: schema.Compliance{
+			Control:     rule,
+			Requirement: c.policy,
+			Status:      complianceStatus(c.status),
+		},
+		Metadata: map[string]interface{}{"format": format},
+	}
+	event.Action.Description = event.Event.Description
+	if !report.timestamp.IsZero() {
+		event.Timestamp = report.timestamp.Format(time.RFC3339Nano)
+	}
+
+	// The CIS control of the check, then the framework controls mapped in the compliance catalog
+	var controls []compliance.Control
+	if c.cis.ID != "" {
+		controls = append(controls, c.cis)
+	}
+	for _, control := range p.catalog.Lookup(c.policy, rule, c.id) {
+		if control != c.cis {
+			controls = append(controls, control)
+		}
+	}
+	if len(controls) > 0 {
+		event.Compliance.Standards = compliance.Standards(controls)
+		event.Compliance.Requirements = compliance.Requirements(controls)
+	}
+
+	for key, value := range report.metadata {
+		event.Metadata[key] = value
+	}
+	for key, value := range c.metadata {
+		event.Metadata[key] = value
+	}
+	return event
+}
+
+// eventDescription describes the result of a check on a target, e.g. "Policy violation on node-1 for rule 1.1.1"
+func eventDescription(status, target, rule string) string {
+	on := ""
+	if target != "" {
+		on = " on " + target
+	}
+	switch status {
+	case statusFail:
+		return fmt.Sprintf("Policy violation%s for rule %s", on, rule)
+	case statusPass:
+		return fmt.Sprintf("Policy check passed%s for rule %s", on, rule)
+	case statusWarn:
+		return fmt.Sprintf("Policy check requires review%s for rule %s", on, rule)
+	case statusSkip:
+		return fmt.Sprintf("Policy check skipped%s for rule %s", on, rule)
+	default:
+		return fmt.Sprintf("Policy evaluation%s for rule %s", on, rule)
+	}
+}
+
+// findingID returns a stable finding ID derived from the tool, the target and the check,
+// so the same finding keeps its ID across scans
+func findingID(tool string, target *schema.Target, checkID string) string {
+	key := strings.Join([]string{tool, processing.FirstNonEmpty(target.ID, target.Resource), checkID}, "\x00")
+	return uuid.NewSHA1(findingNamespace, []byte(key)).String()
+}
+
+// complianceStatus maps a check status to a compliance status: COMPLIANT for pass, NON_COMPLIANT otherwise
+func complianceStatus(status string) string {
+	if status == statusPass {
+		return complianceCompliant
+	}
+	return complianceNonCompliant
+}
+
+// isStatusAllowed checks if a check status is in the allowed filter list
+func (p *Processor) isStatusAllowed(status string) bool {
+	if len(p.config.StatusFilter) == 0 {
+		return true
+	}
+	for _, allowed := range p.config.StatusFilter {
+		if status == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing/processingtest"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// processLogRecord runs ProcessLogRecord with the resource of a kube-bench job on cp-1
+func processLogRecord(t *testing.T, processor *Processor, logRecord *plog.LogRecord) (plog.LogRecordSlice, processing.Outcome, error) {
+	t.Helper()
+	resource := pcommon.NewResource()
+	resource.Attributes().PutStr(nodeNameAttribute, "cp-1")
+	return processingtest.ProcessResource(t, processor.ProcessLogRecord, logRecord, resource)
+}
+
+func TestProcessLogRecord_KubeBench(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewLogRecord(t, "kube-bench.json")
+	events, outcome, err := processLogRecord(t, processor, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{Results: 3, Parsed: 3, Created: 3}, outcome)
+	require.Equal(t, 3, events.Len())
+
+	event := events.At(1)
+	attrs := event.Attributes().AsRaw()
+	assert.Equal(t, "COMPLIANCE_FINDING", attrs["event.type"])
+	assert.Equal(t, "COMPLIANCE", attrs["event.category"])
+	assert.Equal(t, "Compliance finding event", attrs["event.name"])
+	assert.Equal(t, "Policy violation on cp-1 for rule 1.1.12", attrs["event.description"])
+	assert.Equal(t, "kube-bench", attrs["product.name"])
+	assert.Equal(t, "Aqua Security", attrs["product.vendor"])
+	assert.Equal(t, "cp-1", attrs["object.id"])
+	assert.Equal(t, "Node", attrs["object.type"])
+	assert.Equal(t, "K8S_NODE", attrs["smartscape.type"])
+	assert.Equal(t, "cp-1", attrs["k8s.node.name"])
+	assert.Equal(t, "policy_evaluation", attrs["action.type"])
+	assert.Equal(t, "fail", attrs["result.status"])
+	assert.Equal(t, "NON_COMPLIANT", attrs["compliance.status"])
+	assert.Equal(t, "1.1.12", attrs["compliance.control"])
+	assert.Equal(t, []interface{}{"CIS"}, attrs["compliance.standards"])
+	assert.Equal(t, []interface{}{"CIS 1.1.12"}, attrs["compliance.requirements"])
+	assert.Equal(t, "cis-1.8", attrs["finding.type"])
+	assert.Equal(t, "1.1.12 Ensure that the etcd data directory ownership is set to etcd:etcd (Automated)", attrs["finding.title"])
+	assert.NotContains(t, attrs, "finding.severity", "kube-bench does not rate its checks")
+	assert.Equal(t, 0.0, attrs["dt.security.risk.score"])
+	assert.Equal(t, "Ensure that the etcd data directory ownership is set to etcd:etcd (Automated)", event.Body().Str())
+	// kube-bench reports have no scan time
+	assert.Equal(t, logRecord.Timestamp(), event.Timestamp())
+
+	assert.Equal(t, "COMPLIANT", events.At(0).Attributes().AsRaw()["compliance.status"])
+	warn := events.At(2).Attributes().AsRaw()
+	assert.Equal(t, "warn", warn["result.status"])
+	assert.Equal(t, "Policy check requires review on cp-1 for rule 1.2.1", warn["event.description"])
+
+	// The finding ID is derived from the node and the check, so it is stable across scans
+	again, _, err := processLogRecord(t, processor, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, attrs["finding.id"], again.At(1).Attributes().AsRaw()["finding.id"])
+	assert.NotEqual(t, attrs["finding.id"], events.At(0).Attributes().AsRaw()["finding.id"])
+}
+
+func TestProcessLogRecord_KubeBenchMetadata(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithOutput(processing.Output{BodyFormat: schema.BodyFormatJSON}))
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewLogRecord(t, "kube-bench.json")
+	events, _, err := processLogRecord(t, processor, &logRecord)
+	require.NoError(t, err)
+	require.Equal(t, 3, events.Len())
+
+	var body schema.SecurityEvent
+	require.NoError(t, json.Unmarshal([]byte(events.At(1).Body().Str()), &body))
+	assert.Equal(t, FormatKubeBench, body.Metadata["format"])
+	assert.Equal(t, "master", body.Metadata["node_type"])
+	assert.Equal(t, "1.1 Control Plane Node Configuration Files", body.Metadata["section"])
+	assert.Equal(t, "root:root", body.Metadata["actual_value"])
+	assert.Equal(t, "'etcd:etcd' is present", body.Metadata["expected_result"])
+	assert.Equal(t, true, body.Metadata["scored"])
+	assert.Contains(t, body.Metadata["remediation"], "chown etcd:etcd /var/lib/etcd")
+	assert.Equal(t, "cis-1.8", body.Compliance.Requirement)
+}
+
+func TestProcessLogRecord_Kubescape(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewLogRecord(t, "kubescape.json")
+	events, outcome, err := processLogRecord(t, processor, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{Results: 3, Parsed: 3, Created: 3}, outcome)
+	require.Equal(t, 3, events.Len())
+
+	event := events.At(0)
+	attrs := event.Attributes().AsRaw()
+	assert.Equal(t, "Kubescape", attrs["product.name"])
+	assert.Equal(t, "ARMO", attrs["product.vendor"])
+	assert.Equal(t, "Policy violation on checkout for rule C-0017", attrs["event.description"])
+	assert.Equal(t, "6f1d2c3b-4a5e-4f60-8a7b-9c0d1e2f3a4b", attrs["object.id"])
+	assert.Equal(t, "Deployment", attrs["object.type"])
+	assert.Equal(t, "checkout", attrs["k8s.deployment.name"])
+	assert.Equal(t, "checkout", attrs["k8s.workload.name"])
+	assert.Equal(t, "shop", attrs["k8s.namespace.name"])
+	assert.Equal(t, "prod-eu-1", attrs["k8s.cluster.name"])
+	assert.Equal(t, "fail", attrs["result.status"])
+	assert.Equal(t, "C-0017", attrs["compliance.control"])
+	assert.NotContains(t, attrs, "compliance.standards", "C-0017 is not a CIS control")
+	assert.Equal(t, "C-0017", attrs["finding.type"])
+	assert.Equal(t, "C-0017 Immutable container filesystem", attrs["finding.title"])
+	assert.Equal(t, "LOW", attrs["finding.severity"])
+	assert.Equal(t, 3.9, attrs["dt.security.risk.score"])
+	assert.Equal(t, "2025-10-18T12:00:00Z", attrs["finding.time.created"])
+	assert.Equal(t, time.Date(2025, 10, 18, 12, 0, 0, 0, time.UTC), event.Timestamp().AsTime())
+
+	// The CIS control ID comes from the control name in the CIS framework
+	cis := events.At(1).Attributes().AsRaw()
+	assert.Equal(t, "pass", cis["result.status"])
+	assert.Equal(t, "COMPLIANT", cis["compliance.status"])
+	assert.Equal(t, "5.2.2", cis["compliance.control"])
+	assert.Equal(t, []interface{}{"CIS"}, cis["compliance.standards"])
+	assert.Equal(t, []interface{}{"CIS 5.2.2"}, cis["compliance.requirements"])
+	assert.Equal(t, "5.2.2 Privileged container", cis["finding.title"])
+	assert.Equal(t, "HIGH", cis["finding.severity"])
+
+	// Controls requiring a manual review are warnings
+	manual := events.At(2).Attributes().AsRaw()
+	assert.Equal(t, "warn", manual["result.status"])
+	assert.Equal(t, "1.1.1", manual["compliance.control"])
+	assert.Equal(t, "kube-apiserver-cp-1", manual["k8s.pod.name"])
+	assert.Equal(t, "kube-system", manual["k8s.namespace.name"])
+	assert.Equal(t, "K8S_POD", manual["smartscape.type"])
+	assert.Equal(t, "path=3150872245/api=/v1/kube-system/Pod/kube-apiserver-cp-1", manual["object.id"])
+	assert.Equal(t, "MEDIUM", manual["finding.severity"])
+}
+
+func TestProcessLogRecord_StatusFilter(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true, StatusFilter: []string{"fail", "warn"}})
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewLogRecord(t, "kube-bench.json")
+	events, outcome, err := processLogRecord(t, processor, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{
+		Results:  3,
+		Parsed:   3,
+		Filtered: map[string]int{FilterReasonStatus: 1},
+		Created:  2,
+	}, outcome)
+	require.Equal(t, 2, events.Len())
+	assert.Equal(t, "fail", events.At(0).Attributes().AsRaw()["result.status"])
+}
+
+func TestProcessLogRecord_ComplianceCatalog(t *testing.T) {
+	catalog, err := compliance.ParseCatalog([]byte(`
+mappings:
+  - check_id: C-0017
+    controls:
+      - framework: NIST 800-53
+        id: CM-5
+  - rule: "1.1.12"
+    controls:
+      - framework: CIS
+        id: "1.1.12"
+      - framework: PCI-DSS
+        id: "7.2.1"
+`))
+	require.NoError(t, err)
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithComplianceCatalog(catalog))
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewLogRecord(t, "kube-bench.json")
+	events, _, err := processLogRecord(t, processor, &logRecord)
+	require.NoError(t, err)
+	attrs := events.At(1).Attributes().AsRaw()
+	assert.Equal(t, []interface{}{"CIS", "PCI-DSS"}, attrs["compliance.standards"])
+	assert.Equal(t, []interface{}{"CIS 1.1.12", "PCI-DSS 7.2.1"}, attrs["compliance.requirements"])
+
+	logRecord = processingtest.NewLogRecord(t, "kubescape.json")
+	events, _, err = processLogRecord(t, processor, &logRecord)
+	require.NoError(t, err)
+	attrs = events.At(0).Attributes().AsRaw()
+	assert.Equal(t, "NIST 800-53", attrs["compliance.standards"].([]interface{})[0])
+}
+
+func TestProcessLogRecord_MapBody(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewMapLogRecord(t, "kubescape.json", nil)
+	events, outcome, err := processLogRecord(t, processor, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, 3, outcome.Created)
+	assert.Equal(t, "C-0017", events.At(0).Attributes().AsRaw()["compliance.control"])
+}
+
+func TestProcessLogRecord_KubeBenchWithoutNode(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	// kube-bench does not name the node it runs on, so a log record without k8s.node.name has no target
+	logRecord := processingtest.NewLogRecord(t, "kube-bench.json")
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, 3, outcome.Created)
+
+	attrs := events.At(1).Attributes().AsRaw()
+	assert.Equal(t, "Policy violation for rule 1.1.12", attrs["event.description"])
+	assert.NotContains(t, attrs, "object.id")
+	assert.NotContains(t, attrs, "k8s.node.name")
+	assert.NotEqual(t, attrs["finding.id"], events.At(0).Attributes().AsRaw()["finding.id"], "the check identifies the finding")
+}
+
+func TestProcessLogRecord_Skipped(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		stage      string
+		skipReason string
+	}{
+		{name: "plain line", body: "plain log line", skipReason: SkipReasonNotBenchmark},
+		{name: "results without summary", body: `{"results": []}`, skipReason: SkipReasonNotBenchmark},
+		{name: "grype", body: `{"matches": [], "descriptor": {}}`, skipReason: SkipReasonNotBenchmark},
+		{
+			name:       "mentions summaryDetails and results",
+			body:       `{"msg":"ok","summaryDetails":"x","results":7}`,
+			skipReason: SkipReasonNotBenchmark,
+		},
+		{
+			name:       "nested Controls",
+			body:       `{"msg": "scan done", "report": {"Controls": []}}`,
+			skipReason: SkipReasonNotBenchmark,
+		},
+		{
+			name:       "no tests",
+			body:       `{"Controls": [{"id": "4", "version": "cis-1.8", "tests": []}], "Totals": {}}`,
+			skipReason: SkipReasonNoChecks,
+		},
+		{
+			name:       "invalid controls",
+			body:       `{"Controls": [{"tests": {}}]}`,
+			stage:      processing.StageParse,
+			skipReason: SkipReasonInvalidReport,
+		},
+	}
+
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logRecord := processingtest.NewStringLogRecord(tt.body)
+			events, outcome, err := processLogRecord(t, processor, &logRecord)
+			if tt.stage != "" {
+				processingtest.RequireStageError(t, err, tt.stage)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.skipReason, outcome.SkipReason)
+			assert.Zero(t, events.Len())
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	kubeBenchRecord := processingtest.NewLogRecord(t, "kube-bench.json")
+	kubescapeRecord := processingtest.NewLogRecord(t, "kubescape.json")
+	mapRecord := plog.NewLogRecord()
+	mapRecord.Body().SetEmptyMap().PutEmptySlice("Controls")
+	plainRecord := plog.NewLogRecord()
+	plainRecord.Body().SetStr(`{"Controls": "none"}`)
+	emptyRecord := plog.NewLogRecord()
+
+	assert.Equal(t, FormatKubeBench, Format(&kubeBenchRecord))
+	assert.Equal(t, FormatKubescape, Format(&kubescapeRecord))
+	assert.Equal(t, FormatKubeBench, Format(&mapRecord))
+	assert.Empty(t, Format(&plainRecord))
+	assert.Empty(t, Format(&emptyRecord))
+
+	plainRecord.Body().SetStr(`{"msg":"ok","summaryDetails":"x","results":7}`)
+	assert.Empty(t, Format(&plainRecord))
+	mapRecord.Body().SetEmptyMap().PutStr("summaryDetails", "x")
+	mapRecord.Body().Map().PutInt("results", 7)
+	assert.Empty(t, Format(&mapRecord))
+}
+
+func TestEventDescription(t *testing.T) {
+	assert.Equal(t, "Policy violation on cp-1 for rule 1.1.1", eventDescription(statusFail, "cp-1", "1.1.1"))
+	assert.Equal(t, "Policy check passed for rule 1.1.1", eventDescription(statusPass, "", "1.1.1"))
+	assert.Equal(t, "Policy check skipped on cp-1 for rule 4.2.1", eventDescription(statusSkip, "cp-1", "4.2.1"))
+	assert.Equal(t, "Policy evaluation on cp-1 for rule 4.2.1", eventDescription("error", "cp-1", "4.2.1"))
+}
@@ -0,0 +1,48 @@
+package cef
+
+import "fmt"
+
+// Targets of the rendered security events
+const (
+	// TargetBody replaces the log record body with the rendered security event
+	TargetBody = "body"
+	// TargetAttribute stores the rendered security event in the AttrEncoded attribute
+	TargetAttribute = "attribute"
+)
+
+// AttrEncoded is the attribute holding the rendered security event with TargetAttribute
+const AttrEncoded = "securityevent.siem"
+
+// Config defines the configuration for rendering security events in CEF or LEEF
+type Config struct {
+	// Format of the rendered security events
+	// Valid values: "cef", "leef"
+	// If empty, security events are not rendered
+	Format string `mapstructure:"format"`
+
+	// Target where the rendered security event is written
+	// Valid values: "body", "attribute" (securityevent.siem)
+	// If empty, defaults to "body"
+	Target string `mapstructure:"target"`
+}
+
+// Enabled reports whether security events are rendered
+func (cfg *Config) Enabled() bool {
+	return cfg.Format != ""
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Format != "" && !ValidFormat(cfg.Format) {
+		return fmt.Errorf("invalid siem format: %s. Valid values are: %s, %s", cfg.Format, FormatCEF, FormatLEEF)
+	}
+	switch cfg.Target {
+	case "", TargetBody, TargetAttribute:
+	default:
+		return fmt.Errorf("invalid siem target: %s. Valid values are: %s, %s", cfg.Target, TargetBody, TargetAttribute)
+	}
+	if cfg.Target != "" && cfg.Format == "" {
+		return fmt.Errorf("siem target requires a format")
+	}
+	return nil
+}
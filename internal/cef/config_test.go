@@ -0,0 +1,48 @@
+package cef
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{name: "disabled", config: Config{}},
+		{name: "cef", config: Config{Format: FormatCEF}},
+		{name: "leef in attribute", config: Config{Format: FormatLEEF, Target: TargetAttribute}},
+		{name: "cef in body", config: Config{Format: FormatCEF, Target: TargetBody}},
+		{
+			name:    "invalid format",
+			config:  Config{Format: "syslog"},
+			wantErr: "invalid siem format: syslog",
+		},
+		{
+			name:    "invalid target",
+			config:  Config{Format: FormatCEF, Target: "header"},
+			wantErr: "invalid siem target: header",
+		},
+		{
+			name:    "target without format",
+			config:  Config{Target: TargetAttribute},
+			wantErr: "siem target requires a format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,255 @@
+// Package cef renders security events in the ArcSight Common Event Format (CEF) and the
+// IBM QRadar Log Event Extended Format (LEEF) used by legacy SIEM syslog ingestion,
+// and parses the CEF and LEEF records sent by security appliances.
+package cef
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Formats a security event can be rendered in
+const (
+	// FormatCEF is ArcSight Common Event Format version 0
+	FormatCEF = "cef"
+	// FormatLEEF is IBM QRadar Log Event Extended Format version 2.0, tab delimited
+	FormatLEEF = "leef"
+)
+
+// Header defaults for events whose source product is unknown
+const (
+	defaultVendor  = "OpenTelemetry"
+	defaultProduct = "Security Event Processor"
+)
+
+// leefTimeLayout is the default LEEF devTime format, MMM dd yyyy HH:mm:ss.SSS zzz, so devTimeFormat is not needed
+const leefTimeLayout = "Jan 02 2006 15:04:05.000 MST"
+
+// leefDelimiter separates the LEEF attributes; it is declared in the LEEF 2.0 header as x09
+const leefDelimiter = '\t'
+
+// Custom extension labels, in the order they are rendered
+// CEF carries them in the cs1-cs6 custom string fields, LEEF as custom attribute keys
+var customLabels = [...]string{"policy", "rule", "complianceStatus", "resource", "vulnerability", "techniques"}
+
+// ValidFormat reports whether format is a known format
+func ValidFormat(format string) bool {
+	return format == FormatCEF || format == FormatLEEF
+}
+
+// Encode renders a security event in the given format, CEF unless format is FormatLEEF
+func Encode(event *schema.SecurityEvent, format string) string {
+	if format == FormatLEEF {
+		return EncodeLEEF(event)
+	}
+	return EncodeCEF(event)
+}
+
+// EncodeCEF renders a security event as a CEF:0 record
+//
+//	CEF:0|Vendor|Product|Version|SignatureID|Name|Severity|Extension
+//
+// Header fields escape backslashes and pipes; extension values escape backslashes, equal signs
+// and line breaks
+func EncodeCEF(event *schema.SecurityEvent) string {
+	var sb strings.Builder
+	sb.WriteString("CEF:0")
+	for _, field := range []string{
+		vendor(event), product(event), event.Source.Version, signatureID(event), name(event),
+		strconv.Itoa(Severity(event.RiskScore)),
+	} {
+		sb.WriteByte('|')
+		writeEscaped(&sb, field, headerEscapes)
+	}
+	sb.WriteByte('|')
+
+	ext := extensionWriter{sb: &sb, separator: ' ', escapes: cefExtensionEscapes}
+	if t, ok := parseTimestamp(event.Timestamp); ok {
+		ext.write("rt", strconv.FormatInt(t.UnixMilli(), 10))
+	}
+	ext.write("externalId", event.Finding.ID)
+	ext.write("cat", event.Event.Category)
+	ext.write("act", event.Action.Type)
+	ext.write("outcome", event.Result.Status)
+	ext.write("msg", event.Message)
+	ext.write("cfp1", strconv.FormatFloat(event.RiskScore, 'f', -1, 64))
+	ext.write("cfp1Label", "riskScore")
+	for i, value := range customValues(event) {
+		if value == "" {
+			continue
+		}
+		key := "cs" + strconv.Itoa(i+1)
+		ext.write(key, value)
+		ext.write(key+"Label", customLabels[i])
+	}
+	return sb.String()
+}
+
+// EncodeLEEF renders a security event as a LEEF:2.0 record with tab delimited attributes
+//
+//	LEEF:2.0|Vendor|Product|Version|EventID|x09|key=value<tab>key=value
+//
+// Header fields escape backslashes and pipes; attribute values escape backslashes, tabs and line breaks
+func EncodeLEEF(event *schema.SecurityEvent) string {
+	var sb strings.Builder
+	sb.WriteString("LEEF:2.0")
+	for _, field := range []string{vendor(event), product(event), event.Source.Version, signatureID(event)} {
+		sb.WriteByte('|')
+		writeEscaped(&sb, field, headerEscapes)
+	}
+	sb.WriteString("|x09|")
+
+	ext := extensionWriter{sb: &sb, separator: leefDelimiter, escapes: leefAttributeEscapes}
+	if t, ok := parseTimestamp(event.Timestamp); ok {
+		ext.write("devTime", t.UTC().Format(leefTimeLayout))
+	}
+	ext.write("sev", strconv.Itoa(Severity(event.RiskScore)))
+	ext.write("cat", event.Event.Category)
+	ext.write("name", name(event))
+	ext.write("externalId", event.Finding.ID)
+	ext.write("action", event.Action.Type)
+	ext.write("outcome", event.Result.Status)
+	ext.write("msg", event.Message)
+	ext.write("riskScore", strconv.FormatFloat(event.RiskScore, 'f', -1, 64))
+	for i, value := range customValues(event) {
+		ext.write(customLabels[i], value)
+	}
+	return sb.String()
+}
+
+// Severity converts a risk score from 0.0 to 10.0 into a CEF/LEEF severity from 0 to 10
+func Severity(riskScore float64) int {
+	if math.IsNaN(riskScore) || riskScore <= 0 {
+		return 0
+	}
+	if riskScore >= 10 {
+		return 10
+	}
+	return int(math.Round(riskScore))
+}
+
+// vendor returns the vendor of the product reporting the event
+func vendor(event *schema.SecurityEvent) string {
+	if event.Source.Vendor != "" {
+		return event.Source.Vendor
+	}
+	return defaultVendor
+}
+
+// product returns the product reporting the event
+func product(event *schema.SecurityEvent) string {
+	if event.Source.Application != "" {
+		return event.Source.Application
+	}
+	return defaultProduct
+}
+
+// signatureID identifies the kind of event: "<policy>:<rule>", or the event type without policy
+func signatureID(event *schema.SecurityEvent) string {
+	policy, rule := event.Finding.Type, event.Compliance.Control
+	switch {
+	case policy != "" && rule != "":
+		return policy + ":" + rule
+	case policy != "":
+		return policy
+	case rule != "":
+		return rule
+	default:
+		return event.Event.Type
+	}
+}
+
+// name returns the human readable name of the event
+func name(event *schema.SecurityEvent) string {
+	if event.Finding.Title != "" {
+		return event.Finding.Title
+	}
+	return event.Event.Name
+}
+
+// customValues returns the values of the custom extension fields, in the order of customLabels
+func customValues(event *schema.SecurityEvent) [len(customLabels)]string {
+	var values [len(customLabels)]string
+	values[0] = event.Finding.Type
+	values[1] = event.Compliance.Control
+	values[2] = event.Compliance.Status
+	values[3] = resource(event)
+	if event.Vulnerability != nil {
+		values[4] = event.Vulnerability.ID
+	}
+	if event.Threat != nil {
+		values[5] = strings.Join(event.Threat.TechniqueIDs, ",")
+	}
+	return values
+}
+
+// resource identifies the target of the event as "<kind>/<namespace>/<name>", skipping unknown parts
+func resource(event *schema.SecurityEvent) string {
+	namespace, _ := event.Target.Kubernetes["k8s.namespace.name"].(string)
+	parts := make([]string, 0, 3)
+	for _, part := range []string{event.Target.ResourceType, namespace, event.Target.Resource} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// parseTimestamp parses an RFC 3339 timestamp
+func parseTimestamp(timestamp string) (time.Time, bool) {
+	if timestamp == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	return t, err == nil
+}
+
+// Escape sequences of the header fields and extension values
+var (
+	headerEscapes = map[rune]string{
+		'\\': `\\`, '|': `\|`, '\n': " ", '\r': " ",
+	}
+	cefExtensionEscapes = map[rune]string{
+		'\\': `\\`, '=': `\=`, '\n': `\n`, '\r': `\r`,
+	}
+	leefAttributeEscapes = map[rune]string{
+		'\\': `\\`, leefDelimiter: `\t`, '\n': `\n`, '\r': `\r`,
+	}
+)
+
+// writeEscaped writes s, replacing the characters found in escapes
+func writeEscaped(sb *strings.Builder, s string, escapes map[rune]string) {
+	for _, r := range s {
+		if escaped, ok := escapes[r]; ok {
+			sb.WriteString(escaped)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+}
+
+// extensionWriter writes the key=value pairs of a CEF extension or of LEEF attributes
+type extensionWriter struct {
+	sb        *strings.Builder
+	separator byte
+	escapes   map[rune]string
+	written   bool
+}
+
+// write writes a key=value pair, skipping empty values
+func (w *extensionWriter) write(key, value string) {
+	if value == "" {
+		return
+	}
+	if w.written {
+		w.sb.WriteByte(w.separator)
+	}
+	w.written = true
+	w.sb.WriteString(key)
+	w.sb.WriteByte('=')
+	writeEscaped(w.sb, value, w.escapes)
+}
@@ -0,0 +1,150 @@
+package cef
+
+import (
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// newComplianceEvent returns a Kyverno compliance finding on a pod
+func newComplianceEvent() *schema.SecurityEvent {
+	return &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event: schema.Event{
+			ID:          "6f1c2d1e-0000-4000-8000-000000000001",
+			Version:     "1.309",
+			Category:    "COMPLIANCE",
+			Name:        "Compliance finding event",
+			Type:        "COMPLIANCE_FINDING",
+			Description: "Policy violation on app-7d9f8b6c5d-x2k4p for rule validate-resources",
+		},
+		Timestamp: "2025-09-19T06:51:02Z",
+		Message:   "validation error: CPU and memory resource requests and limits are required",
+		Action:    schema.Action{Type: "policy_evaluation"},
+		Result:    schema.Result{Status: "fail"},
+		Target: schema.Target{
+			ID:           "pod-uid-1",
+			Resource:     "app-7d9f8b6c5d-x2k4p",
+			ResourceType: "Pod",
+			Kubernetes:   map[string]interface{}{"k8s.namespace.name": "production"},
+		},
+		RiskScore: 8.9,
+		Finding: schema.Finding{
+			ID:          "0c9a7e52-0000-4000-8000-000000000002",
+			Title:       "require-requests-limits - validate-resources",
+			Description: "validation error: CPU and memory resource requests and limits are required",
+			Severity:    "HIGH",
+			Type:        "require-requests-limits",
+		},
+		Compliance: schema.Compliance{
+			Control:     "validate-resources",
+			Requirement: "require-requests-limits",
+			Status:      "NON_COMPLIANT",
+		},
+	}
+}
+
+// newVulnerabilityEvent returns a Trivy vulnerability finding mapped to ATT&CK techniques,
+// with characters that must be escaped in every part of the record
+func newVulnerabilityEvent() *schema.SecurityEvent {
+	event := newComplianceEvent()
+	event.Source = schema.Source{Application: "Trivy|Operator", Vendor: `Aqua\Security`, Version: "0.29.0"}
+	event.Message = "openssl=3.0.2 is vulnerable\nupgrade to\t3.0.7 | see C:\\advisories"
+	event.RiskScore = 10
+	event.Finding.Title = "CVE-2022-3602 | openssl"
+	event.Finding.Type = "vulnerability"
+	event.Compliance.Control = "CVE-2022-3602"
+	event.Vulnerability = &schema.Vulnerability{ID: "CVE-2022-3602", KEV: true}
+	event.Threat = &schema.Threat{
+		Framework:    "MITRE ATT&CK",
+		TechniqueIDs: []string{"T1190", "T1203"},
+	}
+	return event
+}
+
+// newMinimalEvent returns an event without product, policy, rule, timestamp or target
+func newMinimalEvent() *schema.SecurityEvent {
+	return &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event: schema.Event{
+			ID:      "event-1",
+			Version: "1.309",
+			Name:    "Compliance finding event",
+			Type:    "COMPLIANCE_FINDING",
+		},
+	}
+}
+
+func TestEncode_Golden(t *testing.T) {
+	events := map[string]*schema.SecurityEvent{
+		"compliance":    newComplianceEvent(),
+		"vulnerability": newVulnerabilityEvent(),
+		"minimal":       newMinimalEvent(),
+	}
+
+	for name, event := range events {
+		for _, format := range []string{FormatCEF, FormatLEEF} {
+			t.Run(name+"/"+format, func(t *testing.T) {
+				got := Encode(event, format)
+				golden := filepath.Join("testdata", name+"."+format)
+				if *update {
+					require.NoError(t, os.WriteFile(golden, []byte(got+"\n"), 0o600))
+				}
+				want, err := os.ReadFile(golden)
+				require.NoError(t, err)
+				assert.Equal(t, string(want), got+"\n")
+			})
+		}
+	}
+}
+
+func TestEncodeCEF_Escaping(t *testing.T) {
+	got := EncodeCEF(newVulnerabilityEvent())
+
+	assert.Contains(t, got, `CEF:0|Aqua\\Security|Trivy\|Operator|0.29.0|vulnerability:CVE-2022-3602|CVE-2022-3602 \| openssl|10|`)
+	// Extension values escape equal signs, backslashes and line breaks but not pipes or tabs
+	assert.Contains(t, got, "msg=openssl\\=3.0.2 is vulnerable\\nupgrade to\t3.0.7 | see C:\\\\advisories ")
+}
+
+func TestEncodeLEEF_Escaping(t *testing.T) {
+	got := EncodeLEEF(newVulnerabilityEvent())
+
+	assert.Contains(t, got, `LEEF:2.0|Aqua\\Security|Trivy\|Operator|0.29.0|vulnerability:CVE-2022-3602|x09|`)
+	// Attribute values escape the tab delimiter, backslashes and line breaks
+	assert.Contains(t, got, "\tmsg=openssl=3.0.2 is vulnerable\\nupgrade to\\t3.0.7 | see C:\\\\advisories\t")
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		riskScore float64
+		want      int
+	}{
+		{riskScore: 0, want: 0},
+		{riskScore: -1, want: 0},
+		{riskScore: math.NaN(), want: 0},
+		{riskScore: 3.9, want: 4},
+		{riskScore: 6.9, want: 7},
+		{riskScore: 8.4, want: 8},
+		{riskScore: 10, want: 10},
+		{riskScore: 12, want: 10},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Severity(tt.riskScore), "risk score %v", tt.riskScore)
+	}
+}
+
+func TestValidFormat(t *testing.T) {
+	assert.True(t, ValidFormat(FormatCEF))
+	assert.True(t, ValidFormat(FormatLEEF))
+	assert.False(t, ValidFormat(""))
+	assert.False(t, ValidFormat("syslog"))
+}
@@ -0,0 +1,339 @@
+package cef
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Record prefixes identifying CEF and LEEF records
+const (
+	prefixCEF  = "CEF:"
+	prefixLEEF = "LEEF:"
+)
+
+// Number of header fields following the format version
+const (
+	cefHeaderFields   = 6 // Vendor|Product|Version|SignatureID|Name|Severity
+	leef1HeaderFields = 4 // Vendor|Product|Version|EventID
+	leef2HeaderFields = 5 // Vendor|Product|Version|EventID|Delimiter
+)
+
+var (
+	// recordStart matches the start of a CEF or LEEF record: its prefix and format version, e.g. CEF:0| or LEEF:2.0|
+	recordStart = regexp.MustCompile(`^(?:CEF:\d+|LEEF:\d+(?:\.\d+)?)\|`)
+
+	// syslogHeader matches a syslog header followed by a record: RFC 3164, with an optional tag, or RFC 5424,
+	// with its structured data
+	syslogHeader = regexp.MustCompile(`^\s*(?:<\d{1,3}>)?(?:` +
+		`(?:[A-Z][a-z]{2} +\d{1,2} \d{2}:\d{2}:\d{2}|\d{4}-\d{2}-\d{2}T\S+) \S+(?: [^\s:]+:)?` +
+		`|\d{1,2} \S+ \S+ \S+ \S+ \S+ (?:-|\[.*\])` +
+		`) $`)
+)
+
+// leefVersion1 is the LEEF version without a delimiter header field; its attributes are tab delimited
+const leefVersion1 = "1.0"
+
+// leefKeys maps CEF extension keys to the equivalent LEEF attribute keys, when they differ
+var leefKeys = map[string]string{
+	"suser":    "usrName",
+	"duser":    "dstUsrName",
+	"act":      "action",
+	"spt":      "srcPort",
+	"dpt":      "dstPort",
+	"shost":    "srcHostName",
+	"dhost":    "dstHostName",
+	"rt":       "devTime",
+	"severity": "sev",
+}
+
+// Record is a parsed CEF or LEEF record
+type Record struct {
+	// Format is FormatCEF or FormatLEEF
+	Format string
+
+	// Version is the version of the format, e.g. "0" for CEF or "2.0" for LEEF
+	Version string
+
+	// Vendor, Product and ProductVersion identify the device that sent the record
+	Vendor         string
+	Product        string
+	ProductVersion string
+
+	// SignatureID identifies the kind of event; it is the event ID of LEEF records
+	SignatureID string
+
+	// Name and Severity are only part of the CEF header; LEEF carries them as attributes
+	Name     string
+	Severity string
+
+	// Extensions holds the CEF extension or the LEEF attributes, unescaped
+	Extensions map[string]string
+}
+
+// Get returns the value of an extension by its CEF key, falling back to the equivalent LEEF key
+func (r *Record) Get(key string) string {
+	if value, ok := r.Extensions[key]; ok {
+		return value
+	}
+	if r.Format == FormatLEEF {
+		if leefKey, ok := leefKeys[key]; ok {
+			return r.Extensions[leefKey]
+		}
+	}
+	return ""
+}
+
+// Custom returns the value of the custom extension with the given label, e.g. the cs4 extension of a CEF record
+// whose cs4Label is "resource", falling back to the attribute named by the label as in LEEF records
+func (r *Record) Custom(label string) string {
+	for key, value := range r.Extensions {
+		if strings.HasSuffix(key, "Label") && value == label {
+			if custom, ok := r.Extensions[strings.TrimSuffix(key, "Label")]; ok {
+				return custom
+			}
+		}
+	}
+	return r.Extensions[label]
+}
+
+// Find returns the offset of a CEF or LEEF record in s, or -1 if s holds none
+// The record starts the string, or follows a syslog header, e.g. "<134>Oct 18 12:00:00 fw01 CEF:0|...";
+// a log line merely mentioning CEF or LEEF, e.g. "failed to forward CEF: connection refused", holds none
+func Find(s string) int {
+	for offset := 0; offset < len(s); {
+		i := strings.IndexAny(s[offset:], "CL")
+		if i < 0 {
+			return -1
+		}
+		i += offset
+		if recordStart.MatchString(s[i:]) && (strings.TrimSpace(s[:i]) == "" || syslogHeader.MatchString(s[:i])) {
+			return i
+		}
+		offset = i + 1
+	}
+	return -1
+}
+
+// Parse parses a CEF or LEEF record, optionally preceded by a syslog header
+func Parse(s string) (*Record, error) {
+	start := Find(s)
+	if start < 0 {
+		return nil, errors.New("no CEF or LEEF record found")
+	}
+	s = strings.TrimRight(s[start:], "\r\n")
+	if strings.HasPrefix(s, prefixCEF) {
+		return parseCEF(s[len(prefixCEF):])
+	}
+	return parseLEEF(s[len(prefixLEEF):])
+}
+
+// parseCEF parses a CEF record without its prefix
+//
+//	0|Vendor|Product|Version|SignatureID|Name|Severity|Extension
+func parseCEF(s string) (*Record, error) {
+	fields, extension, err := splitHeader(s, 1+cefHeaderFields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CEF header: %w", err)
+	}
+	extensions, err := parseCEFExtension(extension)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CEF extension: %w", err)
+	}
+	return &Record{
+		Format:         FormatCEF,
+		Version:        fields[0],
+		Vendor:         fields[1],
+		Product:        fields[2],
+		ProductVersion: fields[3],
+		SignatureID:    fields[4],
+		Name:           fields[5],
+		Severity:       fields[6],
+		Extensions:     extensions,
+	}, nil
+}
+
+// parseLEEF parses a LEEF 1.0 or 2.0 record without its prefix
+//
+//	1.0|Vendor|Product|Version|EventID|key=value<tab>key=value
+//	2.0|Vendor|Product|Version|EventID|Delimiter|key=value<delimiter>key=value
+func parseLEEF(s string) (*Record, error) {
+	version, _, _ := strings.Cut(s, "|")
+	n := leef1HeaderFields
+	if version != leefVersion1 {
+		n = leef2HeaderFields
+	}
+	fields, attributes, err := splitHeader(s, 1+n)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEEF header: %w", err)
+	}
+
+	delimiter := byte(leefDelimiter)
+	if n == leef2HeaderFields && fields[5] != "" {
+		delimiter, err = parseLEEFDelimiter(fields[5])
+		if err != nil {
+			return nil, fmt.Errorf("invalid LEEF header: %w", err)
+		}
+	}
+	return &Record{
+		Format:         FormatLEEF,
+		Version:        fields[0],
+		Vendor:         fields[1],
+		Product:        fields[2],
+		ProductVersion: fields[3],
+		SignatureID:    fields[4],
+		Extensions:     parseLEEFAttributes(attributes, delimiter),
+	}, nil
+}
+
+// splitHeader splits the first n pipe-separated header fields of s, unescaped, from the rest of s
+func splitHeader(s string, n int) ([]string, string, error) {
+	fields := make([]string, 0, n)
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '\\' || s[i+1] == '|'):
+			i++
+			sb.WriteByte(s[i])
+		case c == '|':
+			fields = append(fields, sb.String())
+			sb.Reset()
+			if len(fields) == n {
+				return fields, s[i+1:], nil
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return nil, "", fmt.Errorf("expected %d fields, found %d", n, len(fields))
+}
+
+// parseCEFExtension parses the space-separated key=value pairs of a CEF extension
+// Values may contain unescaped spaces: a value ends where the next key starts
+func parseCEFExtension(s string) (map[string]string, error) {
+	extensions := make(map[string]string)
+	key := ""
+	valueStart := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '=':
+			keyStart := strings.LastIndexByte(s[:i], ' ') + 1
+			if keyStart < valueStart || !validKey(s[keyStart:i]) {
+				// An unescaped equal sign within a value
+				continue
+			}
+			if key != "" {
+				extensions[key] = unescapeExtension(strings.TrimRight(s[valueStart:keyStart], " "))
+			} else if strings.TrimSpace(s[:keyStart]) != "" {
+				return nil, fmt.Errorf("unexpected text %q before the first key", strings.TrimSpace(s[:keyStart]))
+			}
+			key, valueStart = s[keyStart:i], i+1
+		}
+	}
+	if key != "" {
+		extensions[key] = unescapeExtension(strings.TrimRight(s[valueStart:], " "))
+	} else if strings.TrimSpace(s) != "" {
+		return nil, fmt.Errorf("no key=value pair in %q", s)
+	}
+	return extensions, nil
+}
+
+// parseLEEFAttributes parses the key=value pairs of LEEF attributes separated by delimiter
+// Pairs without an equal sign are skipped
+func parseLEEFAttributes(s string, delimiter byte) map[string]string {
+	attributes := make(map[string]string)
+	for s != "" {
+		pair := s
+		if i := indexUnescaped(s, delimiter); i >= 0 {
+			pair, s = s[:i], s[i+1:]
+		} else {
+			s = ""
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			continue
+		}
+		attributes[key] = unescapeExtension(value)
+	}
+	return attributes
+}
+
+// parseLEEFDelimiter parses the delimiter of a LEEF 2.0 header: a single character or its hex code
+// (e.g. "^", "x09" or "0x09")
+func parseLEEFDelimiter(s string) (byte, error) {
+	if len(s) == 1 {
+		return s[0], nil
+	}
+	lower := strings.ToLower(s)
+	hex, ok := strings.CutPrefix(lower, "0x")
+	if !ok {
+		hex, ok = strings.CutPrefix(lower, "x")
+	}
+	if ok {
+		if code, err := strconv.ParseUint(hex, 16, 8); err == nil {
+			return byte(code), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid delimiter %q", s)
+}
+
+// indexUnescaped returns the index of the first c in s not preceded by a backslash escape, or -1
+func indexUnescaped(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+	return -1
+}
+
+// validKey reports whether s is a valid extension key: letters, digits, dots, underscores,
+// hyphens and brackets, as in the ArcSight custom keys (e.g. "cs1Label", "ad.user[0]")
+func validKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '_', r == '-', r == '[', r == ']':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// extensionUnescapes maps the escape sequences of CEF extension values and LEEF attribute values
+// to the characters they stand for
+var extensionUnescapes = map[byte]string{
+	'\\': `\`, '=': "=", '|': "|", 'n': "\n", 'r': "\r", 't': "\t",
+}
+
+// unescapeExtension replaces the escape sequences of an extension or attribute value
+// Unknown sequences are kept as is
+func unescapeExtension(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			if unescaped, ok := extensionUnescapes[s[i+1]]; ok {
+				sb.WriteString(unescaped)
+				i++
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
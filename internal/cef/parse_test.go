@@ -0,0 +1,124 @@
+package cef
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_RoundTrip(t *testing.T) {
+	event := newVulnerabilityEvent()
+
+	for _, format := range []string{FormatCEF, FormatLEEF} {
+		t.Run(format, func(t *testing.T) {
+			record, err := Parse(Encode(event, format))
+			require.NoError(t, err)
+
+			assert.Equal(t, format, record.Format)
+			assert.Equal(t, `Aqua\Security`, record.Vendor)
+			assert.Equal(t, "Trivy|Operator", record.Product)
+			assert.Equal(t, "0.29.0", record.ProductVersion)
+			assert.Equal(t, "vulnerability:CVE-2022-3602", record.SignatureID)
+			assert.Equal(t, event.Message, record.Get("msg"))
+			assert.Equal(t, "policy_evaluation", record.Get("act"))
+			assert.Equal(t, "fail", record.Get("outcome"))
+			assert.Equal(t, "0c9a7e52-0000-4000-8000-000000000002", record.Get("externalId"))
+			assert.Equal(t, "Pod/production/app-7d9f8b6c5d-x2k4p", record.Custom("resource"))
+			assert.Equal(t, "CVE-2022-3602", record.Custom("vulnerability"))
+			assert.Empty(t, record.Custom("unknown"))
+		})
+	}
+}
+
+func TestParse_CEF(t *testing.T) {
+	record, err := Parse("<134>Oct 18 12:00:00 fw01 CEF:0|Palo Alto Networks|PAN-OS|10.2|THREAT|Port scan detected|8|" +
+		"src=10.0.0.5 spt=51000 dst=192.168.1.20 dhost=db01 suser=alice act=blocked outcome=failure " +
+		"msg=Scan detected, threshold\\=100 ports cs1=rule 42 cs1Label=Rule Name\n")
+	require.NoError(t, err)
+
+	assert.Equal(t, FormatCEF, record.Format)
+	assert.Equal(t, "0", record.Version)
+	assert.Equal(t, "Palo Alto Networks", record.Vendor)
+	assert.Equal(t, "PAN-OS", record.Product)
+	assert.Equal(t, "THREAT", record.SignatureID)
+	assert.Equal(t, "Port scan detected", record.Name)
+	assert.Equal(t, "8", record.Severity)
+	assert.Equal(t, map[string]string{
+		"src":      "10.0.0.5",
+		"spt":      "51000",
+		"dst":      "192.168.1.20",
+		"dhost":    "db01",
+		"suser":    "alice",
+		"act":      "blocked",
+		"outcome":  "failure",
+		"msg":      "Scan detected, threshold=100 ports",
+		"cs1":      "rule 42",
+		"cs1Label": "Rule Name",
+	}, record.Extensions)
+}
+
+func TestParse_LEEF1(t *testing.T) {
+	record, err := Parse("LEEF:1.0|IBM|QRadar|7.5|Login Failed|src=10.0.0.5\tusrName=bob\tdstHostName=ldap01\tsev=4")
+	require.NoError(t, err)
+
+	assert.Equal(t, FormatLEEF, record.Format)
+	assert.Equal(t, "1.0", record.Version)
+	assert.Equal(t, "Login Failed", record.SignatureID)
+	assert.Equal(t, "bob", record.Get("suser"))
+	assert.Equal(t, "ldap01", record.Get("dhost"))
+	assert.Equal(t, "4", record.Get("severity"))
+	assert.Equal(t, "10.0.0.5", record.Get("src"))
+	assert.Empty(t, record.Get("dst"))
+}
+
+func TestParse_LEEF2Delimiter(t *testing.T) {
+	for _, delimiter := range []string{"^", "x5E", "0x5e"} {
+		record, err := Parse("LEEF:2.0|Lancope|StealthWatch|1.0|41|" + delimiter + "|src=10.0.1.8^dst=10.0.0.5^action=deny")
+		require.NoError(t, err, delimiter)
+		assert.Equal(t, "deny", record.Get("act"), delimiter)
+		assert.Equal(t, "10.0.0.5", record.Get("dst"), delimiter)
+	}
+
+	// An empty delimiter field defaults to tab
+	record, err := Parse("LEEF:2.0|Vendor|Product|1.0|41||src=10.0.1.8\tdst=10.0.0.5")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.5", record.Get("dst"))
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "not a record", input: "Oct 18 12:00:00 fw01 sshd[42]: Accepted publickey", want: "no CEF or LEEF record found"},
+		{name: "short CEF header", input: "CEF:0|Vendor|Product|1.0|100", want: "invalid CEF header: expected 7 fields, found 4"},
+		{name: "CEF extension without key", input: "CEF:0|V|P|1|100|Name|5|garbage", want: "invalid CEF extension"},
+		{name: "text before the first key", input: "CEF:0|V|P|1|100|Name|5|oops src=1.2.3.4", want: "unexpected text"},
+		{name: "short LEEF header", input: "LEEF:2.0|Vendor|Product", want: "invalid LEEF header"},
+		{name: "invalid LEEF delimiter", input: "LEEF:2.0|V|P|1|41|xZZ|src=1.2.3.4", want: `invalid delimiter "xZZ"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	assert.Equal(t, 0, Find("CEF:0|V|P|1|100|Name|5|"))
+	assert.Equal(t, 2, Find("  LEEF:1.0|V|P|1|100|"))
+	assert.Equal(t, 26, Find("<134>Oct 18 12:00:00 fw01 CEF:0|V|P|1|100|Name|5|"))
+	assert.Equal(t, 33, Find("<134>Oct  8 12:00:00 fw01 panos: CEF:0|V|P|1|100|Name|5|"))
+	assert.Equal(t, 45, Find("<134>1 2025-10-18T12:00:00Z fw01 panos - - - LEEF:2.0|V|P|1|100|^|"))
+	assert.Equal(t, 26, Find("2025-10-18T12:00:00Z fw01 CEF:0|V|P|1|100|Name|5|"))
+	assert.Equal(t, -1, Find("user=CEF:0 is not at the start of a token"))
+	assert.Equal(t, -1, Find("level=error msg=failed to forward CEF: connection refused"))
+	assert.Equal(t, -1, Find("level=info msg=forwarding CEF:0|V|P|1|100|Name|5|"))
+	assert.Equal(t, -1, Find("LEEF: disabled"))
+	assert.Equal(t, -1, Find("plain log line"))
+	assert.Equal(t, -1, Find(""))
+}
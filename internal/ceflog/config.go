@@ -0,0 +1,9 @@
+package ceflog
+
+// Config defines the configuration for the CEF and LEEF processor
+type Config struct {
+	// Enabled indicates whether the CEF and LEEF processor is enabled
+	// Log records whose string body holds a CEF:0 or LEEF:1.0/2.0 record, optionally after a syslog
+	// header, are turned into security events
+	Enabled bool `mapstructure:"enabled"`
+}
@@ -0,0 +1,309 @@
+// Package ceflog turns the CEF and LEEF records sent as syslog by security appliances into security events.
+package ceflog
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/cef"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Reasons for log records that produce no security events
+const (
+	// SkipReasonNotCEF marks log records whose body holds no CEF or LEEF record
+	SkipReasonNotCEF = "not_cef"
+)
+
+// Security event classification of the CEF and LEEF records
+const (
+	eventCategory = "DETECTION"
+	eventName     = "Detection finding event"
+	eventType     = "DETECTION_FINDING"
+	targetHost    = "host"
+)
+
+// resourceLabel labels the custom extension identifying the target of the records encoded by this processor
+const resourceLabel = "resource"
+
+// timeLayouts are the layouts of the rt and devTime extensions besides epoch milliseconds
+var timeLayouts = []string{
+	"Jan 02 2006 15:04:05.000 MST",
+	"Jan 02 2006 15:04:05 MST",
+	"Jan 02 2006 15:04:05",
+	time.RFC3339Nano,
+}
+
+// Processor handles transformation of CEF and LEEF log records into security events
+type Processor struct {
+	logger *zap.Logger
+	config *Config
+
+	// output lays out the security events in their log records
+	output processing.Output
+}
+
+// Option configures optional dependencies of the Processor
+type Option func(*Processor)
+
+// WithOutput sets the layout of the security events in their log records: the profile, attribute names,
+// body format and SIEM encoding
+func WithOutput(output processing.Output) Option {
+	return func(p *Processor) {
+		p.output = output
+	}
+}
+
+// NewProcessor creates a new CEF and LEEF processor
+func NewProcessor(logger *zap.Logger, config *Config, opts ...Option) (*Processor, error) {
+	p := &Processor{
+		logger: logger,
+		config: config,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	// The action and result of the security events are part of the source events
+	p.output.ActionFields = true
+	return p, nil
+}
+
+// Format returns the format of the CEF or LEEF record held by the body of a log record,
+// cef.FormatCEF or cef.FormatLEEF, or "" if the body holds none
+func Format(logRecord *plog.LogRecord) string {
+	body := logRecord.Body()
+	if body.Type() != pcommon.ValueTypeStr {
+		return ""
+	}
+	s := body.Str()
+	start := cef.Find(s)
+	switch {
+	case start < 0:
+		return ""
+	case s[start] == 'C':
+		return cef.FormatCEF
+	default:
+		return cef.FormatLEEF
+	}
+}
+
+// ProcessLogRecord transforms a log record whose body holds a CEF or LEEF record into a security event
+// The security event is appended to dst; nothing is appended if the body holds no record
+// The returned outcome counts the record as a single result
+// A *processing.StageError is returned if the record cannot be parsed or transformed;
+// nothing is appended to dst in that case
+func (p *Processor) ProcessLogRecord(
+	_ context.Context, logRecord *plog.LogRecord, _ pcommon.Resource, _ plog.ScopeLogs, dst plog.LogRecordSlice,
+) (processing.Outcome, error) {
+	var outcome processing.Outcome
+	if Format(logRecord) == "" {
+		outcome.SkipReason = SkipReasonNotCEF
+		return outcome, nil
+	}
+
+	outcome.Results = 1
+	record, err := cef.Parse(logRecord.Body().Str())
+	if err != nil {
+		outcome.Malformed = 1
+		return outcome, processing.NewStageError(processing.StageParse, err)
+	}
+	outcome.Parsed = 1
+
+	if p.logger.Core().Enabled(zapcore.DebugLevel) {
+		p.logger.Debug("CEF log identified - processing",
+			zap.String("format", record.Format),
+			zap.String("vendor", record.Vendor),
+			zap.String("product", record.Product),
+			zap.String("signature_id", record.SignatureID),
+			zap.String("trace_id", logRecord.TraceID().String()))
+	}
+
+	event, timestamp := buildSecurityEvent(record)
+
+	first := dst.Len()
+	// The record timestamp is the time the appliance reported the event
+	newRecord := processing.NewEventRecord(*logRecord, dst, timestamp)
+
+	if err := p.output.Write(newRecord, event); err != nil {
+		// Remove the partially written security event
+		processing.Rollback(dst, first)
+		return outcome, processing.NewStageError(processing.StageTransform, err)
+	}
+	outcome.Created = 1
+	return outcome, nil
+}
+
+// buildSecurityEvent builds the security event of a CEF or LEEF record, and returns the time the
+// event occurred, zero if the record has no parsable rt or devTime extension
+//
+// The header identifies the product and the finding; the src, suser, dst, dhost, act and outcome
+// extensions map onto the source, target, action and result of the event, and the resource custom extension
+// onto the target of records without a destination
+func buildSecurityEvent(record *cef.Record) (*schema.SecurityEvent, time.Time) {
+	title := record.Name
+	if title == "" {
+		title = record.Get("name")
+	}
+	if title == "" {
+		title = record.SignatureID
+	}
+	message := record.Get("msg")
+	if message == "" {
+		message = title
+	}
+
+	event := &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event: schema.Event{
+			ID:          uuid.New().String(),
+			Version:     processing.EventVersion,
+			Category:    eventCategory,
+			Name:        eventName,
+			Type:        eventType,
+			Description: description(title, record),
+		},
+		Message: message,
+		Source: schema.Source{
+			User:        record.Get("suser"),
+			IPAddress:   record.Get("src"),
+			Application: record.Product,
+			Vendor:      record.Vendor,
+			Version:     record.ProductVersion,
+		},
+		Action: schema.Action{
+			Type: record.Get("act"),
+		},
+		Result: schema.Result{
+			Status: record.Get("outcome"),
+		},
+		Finding: schema.Finding{
+			ID:          record.Get("externalId"),
+			Title:       title,
+			Description: message,
+			Type:        record.SignatureID,
+		},
+		Metadata: map[string]interface{}{
+			"format":         record.Format,
+			"format_version": record.Version,
+			"extensions":     extensions(record),
+		},
+	}
+	if event.Finding.ID == "" {
+		event.Finding.ID = uuid.New().String()
+	}
+
+	// Target: the destination host
+	if dst, dhost := record.Get("dst"), record.Get("dhost"); dst != "" || dhost != "" {
+		event.Target = schema.Target{
+			ID:           dhost,
+			Resource:     dhost,
+			ResourceType: targetHost,
+			IPAddress:    dst,
+		}
+		if event.Target.ID == "" {
+			event.Target.ID = dst
+		}
+	} else if resource := record.Custom(resourceLabel); resource != "" {
+		event.Target = resourceTarget(resource)
+	}
+
+	// The CEF header severity, or the LEEF sev attribute
+	severity := record.Severity
+	if severity == "" {
+		severity = record.Get("severity")
+	}
+	event.RiskScore, event.Finding.Severity = parseSeverity(severity)
+
+	timestamp := parseTime(record.Get("rt"))
+	if !timestamp.IsZero() {
+		event.Timestamp = timestamp.Format(time.RFC3339Nano)
+	}
+	return event, timestamp
+}
+
+// resourceTarget returns the target identified by the resource custom extension of the records encoded by this
+// processor, "<kind>/<namespace>/<name>" with unknown parts skipped
+func resourceTarget(resource string) schema.Target {
+	target := schema.Target{ID: resource, Resource: resource}
+	if parts := strings.Split(resource, "/"); len(parts) == 3 {
+		target.ResourceType, target.Resource = parts[0], parts[2]
+		target.Kubernetes = map[string]interface{}{"k8s.namespace.name": parts[1]}
+	}
+	return target
+}
+
+// description describes the event: "<title> from <source> to <target>", skipping unknown parts
+func description(title string, record *cef.Record) string {
+	var sb strings.Builder
+	sb.WriteString(title)
+	if source := processing.FirstNonEmpty(record.Get("suser"), record.Get("src"), record.Get("shost")); source != "" {
+		sb.WriteString(" from ")
+		sb.WriteString(source)
+	}
+	if target := processing.FirstNonEmpty(record.Get("dhost"), record.Get("dst")); target != "" {
+		sb.WriteString(" to ")
+		sb.WriteString(target)
+	}
+	return sb.String()
+}
+
+// extensions returns the extensions of a record as raw values
+func extensions(record *cef.Record) map[string]interface{} {
+	raw := make(map[string]interface{}, len(record.Extensions))
+	for key, value := range record.Extensions {
+		raw[key] = value
+	}
+	return raw
+}
+
+// parseSeverity maps a CEF or LEEF severity, 0 to 10 or Low, Medium, High or Very-High,
+// to a risk score and a finding severity
+// Numeric severities are the risk score; named severities use the risk scores of the OpenReports severities
+func parseSeverity(severity string) (float64, string) {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "":
+		return 0, ""
+	case "low":
+		return processing.RiskScoreLow, processing.SeverityLow
+	case "medium":
+		return processing.RiskScoreMedium, processing.SeverityMedium
+	case "high":
+		return processing.RiskScoreHigh, processing.SeverityHigh
+	case "very-high":
+		return processing.RiskScoreCritical, processing.SeverityCritical
+	}
+
+	score, err := strconv.ParseFloat(strings.TrimSpace(severity), 64)
+	if err != nil || math.IsNaN(score) {
+		return 0, ""
+	}
+	score = min(max(score, 0), 10)
+	return score, processing.SeverityFromScore(score)
+}
+
+// parseTime parses an rt or devTime extension: epoch milliseconds or a date in one of timeLayouts
+// Zero is returned if the value cannot be parsed
+func parseTime(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(millis).UTC()
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
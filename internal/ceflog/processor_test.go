@@ -0,0 +1,265 @@
+package ceflog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/cef"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing/processingtest"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/semconv"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+const (
+	firewallCEF = "<134>Oct 18 12:00:00 fw01 CEF:0|Palo Alto Networks|PAN-OS|10.2|THREAT|Port scan detected|8|" +
+		"rt=1760788800000 src=10.0.0.5 dst=192.168.1.20 dhost=db01 suser=alice act=blocked outcome=failure " +
+		"externalId=threat-42 msg=Port scan from 10.0.0.5"
+	loginLEEF = "LEEF:2.0|IBM|QRadar|7.5|Login Failed|^|devTime=Oct 18 2025 12:00:00.000 UTC^usrName=bob^" +
+		"src=10.0.0.7^dstHostName=ldap01^action=login^outcome=failure^sev=4"
+)
+
+func TestProcessLogRecord_CEF(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewStringLogRecord(firewallCEF)
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{Results: 1, Parsed: 1, Created: 1}, outcome)
+	require.Equal(t, 1, events.Len())
+
+	event := events.At(0)
+	attrs := event.Attributes().AsRaw()
+	assert.Equal(t, "DETECTION_FINDING", attrs["event.type"])
+	assert.Equal(t, "Port scan detected from alice to db01", attrs["event.description"])
+	assert.Equal(t, "PAN-OS", attrs["product.name"])
+	assert.Equal(t, "Palo Alto Networks", attrs["product.vendor"])
+	assert.Equal(t, "alice", attrs["user.name"])
+	assert.Equal(t, "10.0.0.5", attrs["source.address"])
+	assert.Equal(t, "192.168.1.20", attrs["destination.address"])
+	assert.Equal(t, "db01", attrs["object.id"])
+	assert.Equal(t, "host", attrs["object.type"])
+	assert.Equal(t, "blocked", attrs["action.type"])
+	assert.Equal(t, "failure", attrs["result.status"])
+	assert.Equal(t, 8.0, attrs["dt.security.risk.score"])
+	assert.Equal(t, "HIGH", attrs["finding.severity"])
+	assert.Equal(t, "threat-42", attrs["finding.id"])
+	assert.Equal(t, "Port scan detected", attrs["finding.title"])
+	assert.Equal(t, "THREAT", attrs["finding.type"])
+	assert.Equal(t, "2025-10-18T12:00:00Z", attrs["finding.time.created"])
+	assert.NotContains(t, attrs, "compliance.status")
+
+	assert.Equal(t, "Port scan from 10.0.0.5", event.Body().Str())
+	assert.Equal(t, time.Unix(1760788800, 0).UTC(), event.Timestamp().AsTime())
+	assert.Equal(t, logRecord.ObservedTimestamp(), event.ObservedTimestamp())
+}
+
+func TestProcessLogRecord_LEEF(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewStringLogRecord(loginLEEF)
+	events, _, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	require.Equal(t, 1, events.Len())
+
+	attrs := events.At(0).Attributes().AsRaw()
+	assert.Equal(t, "QRadar", attrs["product.name"])
+	assert.Equal(t, "bob", attrs["user.name"])
+	assert.Equal(t, "10.0.0.7", attrs["source.address"])
+	assert.Equal(t, "ldap01", attrs["object.id"])
+	assert.NotContains(t, attrs, "destination.address")
+	assert.Equal(t, "login", attrs["action.type"])
+	assert.Equal(t, "failure", attrs["result.status"])
+	assert.Equal(t, 4.0, attrs["dt.security.risk.score"])
+	assert.Equal(t, "MEDIUM", attrs["finding.severity"])
+	assert.Equal(t, "Login Failed", attrs["finding.title"])
+	assert.Equal(t, "2025-10-18T12:00:00Z", attrs["finding.time.created"])
+	// Without msg, the title is the message
+	assert.Equal(t, "Login Failed", events.At(0).Body().Str())
+}
+
+func TestProcessLogRecord_Minimal(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	// A header without extensions: a textual severity, and neither time nor endpoints
+	logRecord := processingtest.NewStringLogRecord("CEF:0|Acme|Sentinel|2.1|auth-100|Brute force attempt|Low|")
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{Results: 1, Parsed: 1, Created: 1}, outcome)
+	require.Equal(t, 1, events.Len())
+
+	event := events.At(0)
+	attrs := event.Attributes().AsRaw()
+	assert.Equal(t, "Brute force attempt", attrs["event.description"])
+	assert.Equal(t, "LOW", attrs["finding.severity"])
+	assert.Equal(t, 3.9, attrs["dt.security.risk.score"])
+	assert.Equal(t, "auth-100", attrs["finding.type"])
+	assert.NotContains(t, attrs, "object.id")
+	assert.NotContains(t, attrs, "user.name")
+	assert.NotContains(t, attrs, "finding.time.created")
+	assert.Equal(t, logRecord.Timestamp(), event.Timestamp(), "without rt, the record keeps the time of the log record")
+}
+
+func TestProcessLogRecord_EncodedResource(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	// The records encoded by the output of this processor identify their target by the resource custom extension
+	tests := []struct {
+		name string
+		body string
+		want map[string]interface{}
+	}{
+		{
+			name: "CEF",
+			body: "CEF:0|Kyverno|Kyverno|1.11|require-limits|require-limits - validate|7|cs4=Pod/production/app-1 cs4Label=resource",
+			want: map[string]interface{}{"object.type": "Pod", "k8s.namespace.name": "production"},
+		},
+		{
+			name: "LEEF",
+			body: "LEEF:2.0|Kyverno|Kyverno|1.11|require-limits|x09|sev=7\tresource=Node/cp-1",
+			want: map[string]interface{}{"object.id": "Node/cp-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logRecord := processingtest.NewStringLogRecord(tt.body)
+			events, _, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+			require.NoError(t, err)
+			require.Equal(t, 1, events.Len())
+
+			attrs := events.At(0).Attributes().AsRaw()
+			for key, want := range tt.want {
+				assert.Equal(t, want, attrs[key], key)
+			}
+		})
+	}
+}
+
+func TestProcessLogRecord_Skipped(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		stage   string
+		outcome processing.Outcome
+	}{
+		{name: "syslog line", body: "sshd[42]: Accepted publickey for alice", outcome: processing.Outcome{SkipReason: SkipReasonNotCEF}},
+		{name: "empty", outcome: processing.Outcome{SkipReason: SkipReasonNotCEF}},
+		{
+			name:    "mentions CEF",
+			body:    "level=error msg=failed to forward CEF: connection refused",
+			outcome: processing.Outcome{SkipReason: SkipReasonNotCEF},
+		},
+		{
+			name:    "truncated header",
+			body:    "CEF:0|Vendor|Product|1.0|100",
+			stage:   processing.StageParse,
+			outcome: processing.Outcome{Results: 1, Malformed: 1},
+		},
+	}
+
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logRecord := processingtest.NewStringLogRecord(tt.body)
+			events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+			if tt.stage != "" {
+				processingtest.RequireStageError(t, err, tt.stage)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.outcome, outcome)
+			assert.Zero(t, events.Len())
+		})
+	}
+}
+
+func TestProcessLogRecord_Output(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true},
+		WithOutput(processing.Output{
+			AttributeNames: semconv.ModeSemconv,
+			BodyFormat:     schema.BodyFormatJSON,
+			SIEM:           cef.Config{Format: cef.FormatLEEF, Target: cef.TargetAttribute},
+		}))
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewStringLogRecord(firewallCEF)
+	events, _, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	require.Equal(t, 1, events.Len())
+	event := events.At(0)
+
+	assert.Equal(t, "security.detection_finding", event.EventName())
+
+	var body schema.SecurityEvent
+	require.NoError(t, json.Unmarshal([]byte(event.Body().Str()), &body))
+	assert.Equal(t, "alice", body.Source.User)
+	assert.Equal(t, "blocked", body.Action.Type)
+	assert.Equal(t, "failure", body.Result.Status)
+	assert.Equal(t, "192.168.1.20", body.Target.IPAddress)
+	assert.Equal(t, cef.FormatCEF, body.Metadata["format"])
+	assert.Equal(t, "10.2", body.Source.Version)
+	assert.Equal(t, "10.0.0.5", body.Metadata["extensions"].(map[string]interface{})["src"])
+
+	encoded, exists := event.Attributes().Get(cef.AttrEncoded)
+	require.True(t, exists)
+	assert.Contains(t, encoded.Str(), "LEEF:2.0|Palo Alto Networks|PAN-OS|10.2|THREAT|x09|")
+}
+
+func TestFormat(t *testing.T) {
+	cefRecord := processingtest.NewStringLogRecord(firewallCEF)
+	leefRecord := processingtest.NewStringLogRecord(loginLEEF)
+	plainRecord := processingtest.NewStringLogRecord("plain log line")
+	mapRecord := plog.NewLogRecord()
+	mapRecord.Body().SetEmptyMap().PutStr("message", "CEF:0|V|P|1|100|Name|5|")
+
+	assert.Equal(t, cef.FormatCEF, Format(&cefRecord))
+	assert.Equal(t, cef.FormatLEEF, Format(&leefRecord))
+	assert.Empty(t, Format(&plainRecord))
+	assert.Empty(t, Format(&mapRecord))
+}
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		severity     string
+		wantScore    float64
+		wantSeverity string
+	}{
+		{severity: "", wantScore: 0, wantSeverity: ""},
+		{severity: "0", wantScore: 0, wantSeverity: "LOW"},
+		{severity: "3", wantScore: 3, wantSeverity: "LOW"},
+		{severity: "5", wantScore: 5, wantSeverity: "MEDIUM"},
+		{severity: "7", wantScore: 7, wantSeverity: "HIGH"},
+		{severity: "10", wantScore: 10, wantSeverity: "CRITICAL"},
+		{severity: "15", wantScore: 10, wantSeverity: "CRITICAL"},
+		{severity: "High", wantScore: 8.9, wantSeverity: "HIGH"},
+		{severity: "Very-High", wantScore: 10, wantSeverity: "CRITICAL"},
+		{severity: "Unknown", wantScore: 0, wantSeverity: ""},
+		{severity: "NaN", wantScore: 0, wantSeverity: ""},
+	}
+	for _, tt := range tests {
+		score, severity := parseSeverity(tt.severity)
+		assert.Equal(t, tt.wantScore, score, tt.severity)
+		assert.Equal(t, tt.wantSeverity, severity, tt.severity)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2025, 10, 18, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, want, parseTime("1760788800000"))
+	assert.Equal(t, want, parseTime("Oct 18 2025 12:00:00.000 UTC").UTC())
+	assert.Equal(t, want, parseTime("Oct 18 2025 12:00:00"))
+	assert.Equal(t, want, parseTime("2025-10-18T12:00:00Z"))
+	assert.True(t, parseTime("yesterday").IsZero())
+	assert.True(t, parseTime("").IsZero())
+}
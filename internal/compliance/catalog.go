@@ -0,0 +1,176 @@
+// Package compliance maps policy, rule and check identifiers produced by
+// security tools onto compliance framework controls.
+package compliance
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Control identifies a single control of a compliance framework
+type Control struct {
+	// Framework is the name of the compliance framework (e.g. "CIS", "NIST 800-53", "PCI-DSS", "SOC 2")
+	Framework string `yaml:"framework" json:"framework"`
+
+	// ID is the control identifier within the framework (e.g. "5.2.1", "AC-6", "2.2.5", "CC6.1")
+	ID string `yaml:"id" json:"id"`
+}
+
+// String returns the control formatted as "<framework> <id>"
+func (c Control) String() string {
+	return c.Framework + " " + c.ID
+}
+
+// Mapping associates a policy, rule or check identifier with framework controls
+//
+// A mapping matches a finding when:
+//   - policy and rule are both set and equal the finding policy and rule
+//   - only policy is set and equals the finding policy (any rule)
+//   - only rule is set and equals the finding rule (any policy)
+//   - check_id is set and equals one of the finding check identifiers (e.g. Trivy "KSV017")
+type Mapping struct {
+	Policy   string    `yaml:"policy" json:"policy"`
+	Rule     string    `yaml:"rule" json:"rule"`
+	CheckID  string    `yaml:"check_id" json:"check_id"`
+	Controls []Control `yaml:"controls" json:"controls"`
+}
+
+// catalogFile is the on-disk representation of a catalog
+type catalogFile struct {
+	Mappings []Mapping `yaml:"mappings" json:"mappings"`
+}
+
+// Catalog is an indexed, read-only set of compliance mappings
+// A nil *Catalog is valid and matches nothing
+type Catalog struct {
+	byPolicyRule map[string][]Control
+	byPolicy     map[string][]Control
+	byRule       map[string][]Control
+	byCheckID    map[string][]Control
+}
+
+// LoadCatalog reads and parses a catalog file
+func LoadCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from the collector configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compliance catalog: %w", err)
+	}
+	return ParseCatalog(data)
+}
+
+// ParseCatalog parses catalog content in YAML or JSON format
+func ParseCatalog(data []byte) (*Catalog, error) {
+	var file catalogFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse compliance catalog: %w", err)
+	}
+
+	catalog := &Catalog{
+		byPolicyRule: make(map[string][]Control),
+		byPolicy:     make(map[string][]Control),
+		byRule:       make(map[string][]Control),
+		byCheckID:    make(map[string][]Control),
+	}
+
+	for i, mapping := range file.Mappings {
+		if mapping.Policy == "" && mapping.Rule == "" && mapping.CheckID == "" {
+			return nil, fmt.Errorf("compliance catalog mapping %d: one of policy, rule or check_id is required", i)
+		}
+		if len(mapping.Controls) == 0 {
+			return nil, fmt.Errorf("compliance catalog mapping %d: at least one control is required", i)
+		}
+		for j, control := range mapping.Controls {
+			if control.Framework == "" || control.ID == "" {
+				return nil, fmt.Errorf("compliance catalog mapping %d control %d: framework and id are required", i, j)
+			}
+		}
+
+		switch {
+		case mapping.Policy != "" && mapping.Rule != "":
+			key := policyRuleKey(mapping.Policy, mapping.Rule)
+			catalog.byPolicyRule[key] = append(catalog.byPolicyRule[key], mapping.Controls...)
+		case mapping.Policy != "":
+			catalog.byPolicy[mapping.Policy] = append(catalog.byPolicy[mapping.Policy], mapping.Controls...)
+		case mapping.Rule != "":
+			catalog.byRule[mapping.Rule] = append(catalog.byRule[mapping.Rule], mapping.Controls...)
+		}
+		if mapping.CheckID != "" {
+			catalog.byCheckID[mapping.CheckID] = append(catalog.byCheckID[mapping.CheckID], mapping.Controls...)
+		}
+	}
+
+	return catalog, nil
+}
+
+// Lookup returns the de-duplicated controls matching a finding's policy, rule and check identifiers
+// Controls are returned in catalog order: policy+rule matches first, then policy, rule and check ID matches
+func (c *Catalog) Lookup(policy, rule string, checkIDs ...string) []Control {
+	if c == nil {
+		return nil
+	}
+
+	var controls []Control
+	seen := make(map[Control]bool)
+	add := func(matched []Control) {
+		for _, control := range matched {
+			if !seen[control] {
+				seen[control] = true
+				controls = append(controls, control)
+			}
+		}
+	}
+
+	if policy != "" && rule != "" {
+		add(c.byPolicyRule[policyRuleKey(policy, rule)])
+	}
+	if policy != "" {
+		add(c.byPolicy[policy])
+	}
+	if rule != "" {
+		add(c.byRule[rule])
+	}
+	for _, checkID := range checkIDs {
+		if checkID != "" {
+			add(c.byCheckID[checkID])
+		}
+	}
+
+	return controls
+}
+
+// Len returns the number of distinct keys indexed in the catalog
+func (c *Catalog) Len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.byPolicyRule) + len(c.byPolicy) + len(c.byRule) + len(c.byCheckID)
+}
+
+// Standards returns the distinct framework names of the controls, in order of first appearance
+func Standards(controls []Control) []string {
+	var standards []string
+	seen := make(map[string]bool)
+	for _, control := range controls {
+		if !seen[control.Framework] {
+			seen[control.Framework] = true
+			standards = append(standards, control.Framework)
+		}
+	}
+	return standards
+}
+
+// Requirements returns the controls formatted as "<framework> <id>"
+func Requirements(controls []Control) []string {
+	requirements := make([]string, 0, len(controls))
+	for _, control := range controls {
+		requirements = append(requirements, control.String())
+	}
+	return requirements
+}
+
+// policyRuleKey builds the index key for a policy and rule pair
+func policyRuleKey(policy, rule string) string {
+	return policy + "\x00" + rule
+}
@@ -0,0 +1,164 @@
+package compliance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCatalog(t *testing.T) {
+	catalog, err := LoadCatalog(filepath.Join("testdata", "catalog.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, catalog.Len())
+}
+
+func TestLoadCatalog_MissingFile(t *testing.T) {
+	_, err := LoadCatalog(filepath.Join("testdata", "does-not-exist.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read compliance catalog")
+}
+
+func TestParseCatalog_JSON(t *testing.T) {
+	catalog, err := ParseCatalog([]byte(`{"mappings": [{"policy": "p", "controls": [{"framework": "CIS", "id": "1.1"}]}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []Control{{Framework: "CIS", ID: "1.1"}}, catalog.Lookup("p", "any-rule"))
+}
+
+func TestParseCatalog_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		errMsg string
+	}{
+		{
+			name:   "malformed yaml",
+			data:   "mappings: [",
+			errMsg: "failed to parse compliance catalog",
+		},
+		{
+			name:   "mapping without key",
+			data:   "mappings:\n  - controls:\n      - framework: CIS\n        id: '1.1'\n",
+			errMsg: "one of policy, rule or check_id is required",
+		},
+		{
+			name:   "mapping without controls",
+			data:   "mappings:\n  - policy: p\n",
+			errMsg: "at least one control is required",
+		},
+		{
+			name:   "control without id",
+			data:   "mappings:\n  - policy: p\n    controls:\n      - framework: CIS\n",
+			errMsg: "framework and id are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCatalog([]byte(tt.data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestCatalog_Lookup(t *testing.T) {
+	catalog, err := LoadCatalog(filepath.Join("testdata", "catalog.yaml"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		policy   string
+		rule     string
+		checkIDs []string
+		expected []Control
+	}{
+		{
+			name:   "policy match with any rule",
+			policy: "disallow-privileged-containers",
+			rule:   "privileged-containers",
+			expected: []Control{
+				{Framework: "CIS", ID: "5.2.2"},
+				{Framework: "NIST 800-53", ID: "AC-6"},
+				{Framework: "PCI-DSS", ID: "2.2.5"},
+			},
+		},
+		{
+			name:   "policy and rule match",
+			policy: "disallow-host-namespaces",
+			rule:   "host-namespaces",
+			expected: []Control{
+				{Framework: "CIS", ID: "5.2.3"},
+				{Framework: "SOC 2", ID: "CC6.1"},
+			},
+		},
+		{
+			name:     "policy and rule mismatch",
+			policy:   "disallow-host-namespaces",
+			rule:     "other-rule",
+			expected: nil,
+		},
+		{
+			name:     "check id match",
+			policy:   "Privileged",
+			checkIDs: []string{"KSV017"},
+			expected: []Control{
+				{Framework: "CIS", ID: "5.2.2"},
+				{Framework: "NIST 800-53", ID: "CM-7"},
+			},
+		},
+		{
+			name:     "policy and check id matches are de-duplicated",
+			policy:   "disallow-privileged-containers",
+			checkIDs: []string{"KSV017"},
+			expected: []Control{
+				{Framework: "CIS", ID: "5.2.2"},
+				{Framework: "NIST 800-53", ID: "AC-6"},
+				{Framework: "PCI-DSS", ID: "2.2.5"},
+				{Framework: "NIST 800-53", ID: "CM-7"},
+			},
+		},
+		{
+			name:     "no match",
+			policy:   "unknown",
+			rule:     "unknown",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, catalog.Lookup(tt.policy, tt.rule, tt.checkIDs...))
+		})
+	}
+}
+
+func TestCatalog_NilLookup(t *testing.T) {
+	var catalog *Catalog
+	assert.Nil(t, catalog.Lookup("policy", "rule"))
+	assert.Equal(t, 0, catalog.Len())
+}
+
+func TestStandardsAndRequirements(t *testing.T) {
+	controls := []Control{
+		{Framework: "CIS", ID: "5.2.2"},
+		{Framework: "NIST 800-53", ID: "AC-6"},
+		{Framework: "CIS", ID: "5.2.3"},
+	}
+
+	assert.Equal(t, []string{"CIS", "NIST 800-53"}, Standards(controls))
+	assert.Equal(t, []string{"CIS 5.2.2", "NIST 800-53 AC-6", "CIS 5.2.3"}, Requirements(controls))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&Config{}).Validate())
+	assert.NoError(t, (&Config{CatalogFile: filepath.Join("testdata", "catalog.yaml")}).Validate())
+
+	invalid := filepath.Join(t.TempDir(), "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("mappings:\n  - policy: p\n"), 0o600))
+	err := (&Config{CatalogFile: invalid}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid compliance catalog_file")
+}
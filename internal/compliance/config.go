@@ -0,0 +1,22 @@
+package compliance
+
+import "fmt"
+
+// Config defines the configuration for the compliance framework catalog
+type Config struct {
+	// CatalogFile is the path to a YAML or JSON file mapping policy, rule and
+	// check identifiers to compliance framework controls (CIS, NIST 800-53, PCI-DSS, SOC 2, ...)
+	// If empty, findings are not enriched with framework controls
+	CatalogFile string `mapstructure:"catalog_file"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.CatalogFile == "" {
+		return nil
+	}
+	if _, err := LoadCatalog(cfg.CatalogFile); err != nil {
+		return fmt.Errorf("invalid compliance catalog_file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package gatekeeper
+
+import "fmt"
+
+// Config defines the configuration for the Gatekeeper processor
+type Config struct {
+	// Enabled indicates whether the Gatekeeper processor is enabled
+	// Gatekeeper constraints collected by the k8sobjects receiver are expanded into one security event
+	// per audit violation
+	Enabled bool `mapstructure:"enabled"`
+
+	// EnforcementActionFilter is an array of enforcement actions to process
+	// Only violations of constraints with an enforcement action in this list will be transformed into security events
+	// Valid values: "deny", "dryrun", "warn", "scoped"
+	// If empty or not specified, all enforcement actions will be processed
+	EnforcementActionFilter []string `mapstructure:"enforcement_action_filter"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	for _, action := range cfg.EnforcementActionFilter {
+		switch action {
+		case ActionDeny, ActionDryRun, ActionWarn, ActionScoped:
+		default:
+			return fmt.Errorf("invalid enforcement action in enforcement_action_filter: %s. Valid values are: deny, dryrun, warn, scoped", action)
+		}
+	}
+	return nil
+}
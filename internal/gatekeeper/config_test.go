@@ -0,0 +1,15 @@
+package gatekeeper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := &Config{Enabled: true, EnforcementActionFilter: []string{"deny", "dryrun", "warn", "scoped"}}
+	assert.NoError(t, cfg.Validate())
+
+	cfg = &Config{Enabled: true, EnforcementActionFilter: []string{"deny", "audit"}}
+	assert.EqualError(t, cfg.Validate(), "invalid enforcement action in enforcement_action_filter: audit. Valid values are: deny, dryrun, warn, scoped")
+}
@@ -0,0 +1,197 @@
+package gatekeeper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+)
+
+// constraintGroup is the API group of the Gatekeeper constraints
+const constraintGroup = "constraints.gatekeeper.sh"
+
+// watchEventDeleted is the type of the k8sobjects watch events of deleted objects
+const watchEventDeleted = "DELETED"
+
+// constraint is a Gatekeeper constraint, with the violations found by the last audit
+// Only the fields mapped to security events are decoded
+type constraint struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+	Metadata   struct {
+		Name string `json:"name"`
+		UID  string `json:"uid"`
+	} `json:"metadata"`
+	Spec struct {
+		EnforcementAction string `json:"enforcementAction"`
+	} `json:"spec"`
+	Status struct {
+		AuditTimestamp  string      `json:"auditTimestamp"`
+		TotalViolations *int64      `json:"totalViolations"`
+		Violations      []violation `json:"violations"`
+	} `json:"status"`
+}
+
+// violation is an object violating a constraint
+type violation struct {
+	EnforcementAction string `json:"enforcementAction"`
+	// EnforcementActions are the actions of the enforcement points of a constraint with the scoped action
+	EnforcementActions []string `json:"enforcementActions"`
+
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Message   string `json:"message"`
+}
+
+// watchEvent is a k8sobjects watch event, holding the watched object
+type watchEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// IsConstraint performs a quick check to determine if a log record holds a Gatekeeper constraint: in its body,
+// as collected by the k8sobjects receiver (the object, or a watch event holding it), or in its attributes,
+// flattened as OpenReports reports (apiVersion, metadata.name, status.violations, ...)
+func IsConstraint(logRecord *plog.LogRecord) bool {
+	if apiVersion, exists := logRecord.Attributes().Get("apiVersion"); exists {
+		return isConstraintAPIVersion(apiVersion.AsString())
+	}
+
+	// The top-level apiVersion of the object, or of the object of a watch event
+	body := logRecord.Body()
+	switch body.Type() {
+	case pcommon.ValueTypeStr:
+		s := body.Str()
+		values := processing.TopLevelValues(s, "apiVersion", "object")
+		if object := values["object"]; object.Kind == processing.JSONObject {
+			values = processing.TopLevelValues(s[object.Offset:], "apiVersion")
+		}
+		apiVersion := values["apiVersion"]
+		return apiVersion.Kind == processing.JSONString && isConstraintAPIVersion(apiVersion.Str)
+	case pcommon.ValueTypeMap:
+		m := body.Map()
+		if object, exists := m.Get("object"); exists && object.Type() == pcommon.ValueTypeMap {
+			m = object.Map()
+		}
+		apiVersion, exists := m.Get("apiVersion")
+		return exists && apiVersion.Type() == pcommon.ValueTypeStr && isConstraintAPIVersion(apiVersion.Str())
+	default:
+		return false
+	}
+}
+
+// isConstraintAPIVersion reports whether an apiVersion is the one of Gatekeeper constraints
+func isConstraintAPIVersion(apiVersion string) bool {
+	return strings.HasPrefix(apiVersion, constraintGroup+"/")
+}
+
+// parseConstraint decodes the constraint of a log record, or returns nil if the record is a watch event of
+// a deleted constraint
+func parseConstraint(logRecord *plog.LogRecord) (*constraint, error) {
+	attrs := logRecord.Attributes()
+	if _, exists := attrs.Get("apiVersion"); exists {
+		return constraintFromAttributes(attrs)
+	}
+
+	var data []byte
+	body := logRecord.Body()
+	if body.Type() == pcommon.ValueTypeStr {
+		data = []byte(body.Str())
+	} else {
+		var err error
+		if data, err = json.Marshal(body.Map().AsRaw()); err != nil {
+			return nil, fmt.Errorf("invalid constraint: %w", err)
+		}
+	}
+
+	var event watchEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("invalid constraint: %w", err)
+	}
+	if event.Object != nil {
+		if event.Type == watchEventDeleted {
+			return nil, nil
+		}
+		data = event.Object
+	}
+	var c constraint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid constraint: %w", err)
+	}
+	return &c, nil
+}
+
+// constraintFromAttributes decodes a constraint flattened into log record attributes
+// The violations are a slice of JSON strings or maps, or a JSON array string
+func constraintFromAttributes(attrs pcommon.Map) (*constraint, error) {
+	getAttr := func(key string) string {
+		if val, exists := attrs.Get(key); exists {
+			return val.AsString()
+		}
+		return ""
+	}
+
+	c := &constraint{
+		Kind:       getAttr("kind"),
+		APIVersion: getAttr("apiVersion"),
+	}
+	c.Metadata.Name = getAttr("metadata.name")
+	c.Metadata.UID = getAttr("metadata.uid")
+	c.Spec.EnforcementAction = getAttr("spec.enforcementAction")
+	c.Status.AuditTimestamp = getAttr("status.auditTimestamp")
+	if total, exists := attrs.Get("status.totalViolations"); exists && total.Type() == pcommon.ValueTypeInt {
+		value := total.Int()
+		c.Status.TotalViolations = &value
+	}
+
+	violations, exists := attrs.Get("status.violations")
+	if !exists {
+		return c, nil
+	}
+	switch violations.Type() {
+	case pcommon.ValueTypeSlice:
+		slice := violations.Slice()
+		c.Status.Violations = make([]violation, slice.Len())
+		for i := 0; i < slice.Len(); i++ {
+			item := slice.At(i)
+			data := []byte(item.AsString())
+			if item.Type() == pcommon.ValueTypeMap {
+				var err error
+				if data, err = json.Marshal(item.Map().AsRaw()); err != nil {
+					return nil, fmt.Errorf("invalid violation %d: %w", i, err)
+				}
+			}
+			if err := json.Unmarshal(data, &c.Status.Violations[i]); err != nil {
+				return nil, fmt.Errorf("invalid violation %d: %w", i, err)
+			}
+		}
+	case pcommon.ValueTypeStr:
+		if err := json.Unmarshal([]byte(violations.Str()), &c.Status.Violations); err != nil {
+			return nil, fmt.Errorf("invalid violations: %w", err)
+		}
+	default:
+		return nil, errors.New("invalid violations: unexpected type " + violations.Type().String())
+	}
+	return c, nil
+}
+
+// enforcementAction returns the enforcement action of a violation, defaulting to the one of its constraint,
+// then to deny as Gatekeeper does
+func (c *constraint) enforcementAction(v *violation) string {
+	switch {
+	case v.EnforcementAction != "":
+		return v.EnforcementAction
+	case c.Spec.EnforcementAction != "":
+		return c.Spec.EnforcementAction
+	default:
+		return ActionDeny
+	}
+}
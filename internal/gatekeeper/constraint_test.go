@@ -0,0 +1,102 @@
+package gatekeeper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestIsConstraint(t *testing.T) {
+	fileRecord := newLogRecord(t, "k8srequiredlabels.json")
+	watchRecord := plog.NewLogRecord()
+	watchRecord.Body().SetEmptyMap().PutEmptyMap("object").PutStr("apiVersion", "constraints.gatekeeper.sh/v1beta1")
+	attrRecord := plog.NewLogRecord()
+	attrRecord.Attributes().PutStr("apiVersion", "constraints.gatekeeper.sh/v1")
+	reportRecord := plog.NewLogRecord()
+	reportRecord.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	templateRecord := plog.NewLogRecord()
+	templateRecord.Body().SetStr(`{"apiVersion": "templates.gatekeeper.sh/v1", "kind": "ConstraintTemplate"}`)
+	emptyRecord := plog.NewLogRecord()
+	watchStrRecord := plog.NewLogRecord()
+	watchStrRecord.Body().SetStr(`{"type": "ADDED", "object": {"kind": "K8sRequiredLabels",
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1"}}`)
+	mentionRecord := plog.NewLogRecord()
+	mentionRecord.Body().SetStr(`{"msg": "synced constraints.gatekeeper.sh/v1beta1", "apiVersion": "v1"}`)
+	nestedRecord := plog.NewLogRecord()
+	nestedRecord.Body().SetStr(`{"kind": "Event", "involvedObject": {"apiVersion": "constraints.gatekeeper.sh/v1beta1"}}`)
+
+	assert.True(t, IsConstraint(&fileRecord))
+	assert.True(t, IsConstraint(&watchStrRecord))
+	assert.False(t, IsConstraint(&mentionRecord))
+	assert.False(t, IsConstraint(&nestedRecord))
+	assert.True(t, IsConstraint(&watchRecord))
+	assert.True(t, IsConstraint(&attrRecord))
+	assert.False(t, IsConstraint(&reportRecord))
+	assert.False(t, IsConstraint(&templateRecord))
+	assert.False(t, IsConstraint(&emptyRecord))
+}
+
+func TestParseConstraint_WatchEvent(t *testing.T) {
+	logRecord := plog.NewLogRecord()
+	logRecord.Body().SetStr(`{"type": "MODIFIED", "object": {"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind": "K8sPSPPrivilegedContainer", "metadata": {"name": "psp-privileged"},
+		"status": {"violations": [{"kind": "Pod", "namespace": "dev", "name": "debug", "message": "Privileged container is not allowed: shell"}]}}}`)
+
+	c, err := parseConstraint(&logRecord)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "K8sPSPPrivilegedContainer", c.Kind)
+	assert.Equal(t, "psp-privileged", c.Metadata.Name)
+	require.Len(t, c.Status.Violations, 1)
+	assert.Equal(t, "debug", c.Status.Violations[0].Name)
+	// Gatekeeper defaults the enforcement action to deny
+	assert.Equal(t, ActionDeny, c.enforcementAction(&c.Status.Violations[0]))
+
+	logRecord.Body().SetStr(`{"type": "DELETED", "object": {"apiVersion": "constraints.gatekeeper.sh/v1beta1", "kind": "K8sPSPPrivilegedContainer"}}`)
+	c, err = parseConstraint(&logRecord)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+}
+
+func TestParseConstraint_Attributes(t *testing.T) {
+	logRecord := plog.NewLogRecord()
+	attrs := logRecord.Attributes()
+	attrs.PutStr("apiVersion", "constraints.gatekeeper.sh/v1beta1")
+	attrs.PutStr("kind", "K8sAllowedRepos")
+	attrs.PutStr("metadata.name", "prod-repos")
+	attrs.PutStr("spec.enforcementAction", "warn")
+	attrs.PutStr("status.auditTimestamp", "2025-10-18T12:00:00Z")
+	attrs.PutInt("status.totalViolations", 2)
+	violations := attrs.PutEmptySlice("status.violations")
+	violations.AppendEmpty().SetStr(`{"kind": "Pod", "namespace": "shop", "name": "web", "message": "container <web> has an invalid image repo"}`)
+	violation := violations.AppendEmpty().SetEmptyMap()
+	violation.PutStr("kind", "Pod")
+	violation.PutStr("namespace", "shop")
+	violation.PutStr("name", "api")
+	violation.PutStr("enforcementAction", "dryrun")
+
+	c, err := parseConstraint(&logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, "K8sAllowedRepos", c.Kind)
+	assert.Equal(t, "prod-repos", c.Metadata.Name)
+	assert.Equal(t, "2025-10-18T12:00:00Z", c.Status.AuditTimestamp)
+	require.NotNil(t, c.Status.TotalViolations)
+	assert.Equal(t, int64(2), *c.Status.TotalViolations)
+	require.Len(t, c.Status.Violations, 2)
+	assert.Equal(t, "web", c.Status.Violations[0].Name)
+	assert.Equal(t, ActionWarn, c.enforcementAction(&c.Status.Violations[0]))
+	assert.Equal(t, ActionDryRun, c.enforcementAction(&c.Status.Violations[1]))
+
+	// The violations may also be a JSON array string
+	attrs.PutStr("status.violations", `[{"kind": "Namespace", "name": "shop"}]`)
+	c, err = parseConstraint(&logRecord)
+	require.NoError(t, err)
+	require.Len(t, c.Status.Violations, 1)
+	assert.Equal(t, "Namespace", c.Status.Violations[0].Kind)
+
+	attrs.PutStr("status.violations", `{"kind": "Namespace"}`)
+	_, err = parseConstraint(&logRecord)
+	assert.ErrorContains(t, err, "invalid violations")
+}
@@ -0,0 +1,314 @@
+// Package gatekeeper expands the audit violations of OPA Gatekeeper constraints, collected by the k8sobjects
+// receiver, into compliance findings, one per violation, shaped as the OpenReports findings.
+package gatekeeper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/attack"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/openreports"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Kind is the report kind of Gatekeeper constraints in the telemetry
+const Kind = "constraint"
+
+// Enforcement actions of Gatekeeper constraints
+const (
+	ActionDeny   = "deny"
+	ActionDryRun = "dryrun"
+	ActionWarn   = "warn"
+	ActionScoped = "scoped"
+)
+
+// Reasons for violations that are not turned into security events
+const (
+	// FilterReasonEnforcementAction marks violations excluded by the enforcement_action_filter configuration
+	FilterReasonEnforcementAction = "enforcement_action_filter"
+)
+
+// Reasons for log records that produce no security events
+const (
+	// SkipReasonNotConstraint marks log records that hold no Gatekeeper constraint
+	SkipReasonNotConstraint = "not_constraint"
+	// SkipReasonInvalidConstraint marks constraints that cannot be decoded,
+	// ProcessLogRecord also returns a parse stage error for them
+	SkipReasonInvalidConstraint = "invalid_constraint"
+	// SkipReasonDeleted marks watch events of deleted constraints
+	SkipReasonDeleted = "deleted"
+	// SkipReasonNoViolations marks constraints without audit violations
+	SkipReasonNoViolations = "no_violations"
+)
+
+// Security event classification of the findings, as for OpenReports findings
+const (
+	eventCategory = "COMPLIANCE"
+	eventName     = "Compliance finding event"
+	eventType     = "COMPLIANCE_FINDING"
+
+	resultFail             = "fail"
+	complianceNonCompliant = "NON_COMPLIANT"
+)
+
+// gatekeeperSource is the product of the security events
+var gatekeeperSource = schema.Source{
+	Application: "Gatekeeper",
+	Vendor:      "Open Policy Agent",
+}
+
+// findingNamespace is the namespace of the finding IDs derived from the constraint and the violating object
+var findingNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/henrikrexed/securitylogeventprocessor/gatekeeper"))
+
+// Processor handles transformation of Gatekeeper constraints into security events
+type Processor struct {
+	logger     *zap.Logger
+	config     *Config
+	catalog    *compliance.Catalog
+	techniques *attack.Mapping
+
+	// output lays out the security events in their log records
+	output processing.Output
+}
+
+// Option configures optional dependencies of the Processor
+type Option func(*Processor)
+
+// WithComplianceCatalog enriches findings with the framework controls mapped in the catalog
+func WithComplianceCatalog(catalog *compliance.Catalog) Option {
+	return func(p *Processor) {
+		p.catalog = catalog
+	}
+}
+
+// WithTechniqueMapping tags findings with the MITRE ATT&CK techniques mapped to their constraint
+func WithTechniqueMapping(mapping *attack.Mapping) Option {
+	return func(p *Processor) {
+		p.techniques = mapping
+	}
+}
+
+// WithOutput sets the layout of the security events in their log records: the profile, attribute names,
+// body format and SIEM encoding
+func WithOutput(output processing.Output) Option {
+	return func(p *Processor) {
+		p.output = output
+	}
+}
+
+// NewProcessor creates a new Gatekeeper processor
+func NewProcessor(logger *zap.Logger, config *Config, opts ...Option) (*Processor, error) {
+	p := &Processor{
+		logger: logger,
+		config: config,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	// The action and result of the security events are part of the source events
+	p.output.ActionFields = true
+	return p, nil
+}
+
+// ProcessLogRecord expands a log record holding a Gatekeeper constraint into one security event per
+// audit violation in its status
+// The security events are appended to dst; nothing is appended if the record holds no constraint
+// A *processing.StageError is returned if the constraint cannot be decoded or its security events cannot be written;
+// nothing is appended to dst in that case
+func (p *Processor) ProcessLogRecord(
+	_ context.Context, logRecord *plog.LogRecord, _ pcommon.Resource, _ plog.ScopeLogs, dst plog.LogRecordSlice,
+) (processing.Outcome, error) {
+	var outcome processing.Outcome
+	if !IsConstraint(logRecord) {
+		outcome.SkipReason = SkipReasonNotConstraint
+		return outcome, nil
+	}
+
+	c, err := parseConstraint(logRecord)
+	if err != nil {
+		outcome.SkipReason = SkipReasonInvalidConstraint
+		return outcome, processing.NewStageError(processing.StageParse, err)
+	}
+	if c == nil {
+		outcome.SkipReason = SkipReasonDeleted
+		return outcome, nil
+	}
+	if len(c.Status.Violations) == 0 {
+		outcome.SkipReason = SkipReasonNoViolations
+		return outcome, nil
+	}
+	if p.logger.Core().Enabled(zapcore.DebugLevel) {
+		p.logger.Debug("Gatekeeper constraint identified - processing",
+			zap.String("constraint_kind", c.Kind),
+			zap.String("constraint_name", c.Metadata.Name),
+			zap.Int("violations", len(c.Status.Violations)),
+			zap.Strings("allowed_actions", p.config.EnforcementActionFilter),
+			zap.String("trace_id", logRecord.TraceID().String()))
+	}
+
+	auditTime := parseTime(c.Status.AuditTimestamp)
+	first := dst.Len()
+	for i := range c.Status.Violations {
+		v := &c.Status.Violations[i]
+		outcome.Results++
+		outcome.Parsed++
+		if !p.isEnforcementActionAllowed(c.enforcementAction(v)) {
+			outcome.AddFiltered(FilterReasonEnforcementAction)
+			continue
+		}
+
+		// The record timestamp is the time of the audit
+		newRecord := processing.NewEventRecord(*logRecord, dst, auditTime)
+
+		if err := p.output.Write(newRecord, p.buildSecurityEvent(logRecord.Attributes(), c, v, auditTime)); err != nil {
+			// Remove the security events of the constraint, including the partially written one
+			processing.Rollback(dst, first)
+			outcome.Created = 0
+			return outcome, processing.NewStageError(processing.StageTransform, err)
+		}
+		outcome.Created++
+	}
+	return outcome, nil
+}
+
+// buildSecurityEvent builds the compliance finding of a violation
+//
+// The fields are laid out as for OpenReports results: the constraint kind (the constraint template) is the
+// policy, the constraint name the rule, and the violating object the target, with the k8s.* fields of an
+// OpenReports report scoping it
+func (p *Processor) buildSecurityEvent(attrs pcommon.Map, c *constraint, v *violation, auditTime time.Time) *schema.SecurityEvent {
+	policy, rule := c.Kind, c.Metadata.Name
+	action := c.enforcementAction(v)
+
+	event := &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event: schema.Event{
+			ID:          uuid.New().String(),
+			Version:     processing.EventVersion,
+			Category:    eventCategory,
+			Name:        eventName,
+			Type:        eventType,
+			Description: eventDescription(v, rule),
+		},
+		Message: v.Message,
+		Source:  gatekeeperSource,
+		Target: schema.Target{
+			Resource:     v.Name,
+			ResourceType: v.Kind,
+			Kubernetes:   openreports.ObjectK8sFields(attrs, v.Kind, v.Namespace, v.Name, ""),
+		},
+		Action: schema.Action{
+			Type: action,
+		},
+		Result: schema.Result{
+			Status: resultFail,
+		},
+		Finding: schema.Finding{
+			ID:          findingID(c, v),
+			Title:       fmt.Sprintf("%s - %s", policy, rule),
+			Description: v.Message,
+			Type:        policy,
+		},
+		Compliance: schema.Compliance{
+			Control:     rule,
+			Requirement: policy,
+			Status:      complianceNonCompliant,
+		},
+		Metadata: map[string]interface{}{
+			"enforcement_action": action,
+		},
+	}
+	event.Action.Description = event.Event.Description
+	if v.Kind == "Pod" {
+		event.Target.EntityType = "K8S_POD"
+	}
+	if !auditTime.IsZero() {
+		event.Timestamp = auditTime.Format(time.RFC3339Nano)
+	}
+
+	if len(v.EnforcementActions) > 0 {
+		actions := make([]interface{}, len(v.EnforcementActions))
+		for i, a := range v.EnforcementActions {
+			actions[i] = a
+		}
+		event.Metadata["enforcement_actions"] = actions
+	}
+	if c.Status.TotalViolations != nil {
+		// Gatekeeper caps the violations listed in the status, the total counts them all
+		event.Metadata["total_violations"] = *c.Status.TotalViolations
+	}
+	if v.Group != "" {
+		event.Metadata["object_group"] = v.Group
+	}
+	if v.Version != "" {
+		event.Metadata["object_version"] = v.Version
+	}
+	if c.Metadata.UID != "" {
+		event.Metadata["constraint_uid"] = c.Metadata.UID
+	}
+
+	// Framework controls from the compliance catalog
+	if controls := p.catalog.Lookup(policy, rule); len(controls) > 0 {
+		event.Compliance.Standards = compliance.Standards(controls)
+		event.Compliance.Requirements = compliance.Requirements(controls)
+	}
+
+	// MITRE ATT&CK technique tagging
+	if techniques := p.techniques.Lookup(policy, rule); len(techniques) > 0 {
+		event.Threat = &schema.Threat{
+			Framework:      attack.Framework,
+			TechniqueIDs:   attack.IDs(techniques),
+			TechniqueNames: attack.Names(techniques),
+			TacticNames:    attack.Tactics(techniques),
+		}
+	}
+	return event
+}
+
+// eventDescription describes a violation, e.g. "Policy violation on default/nginx for rule must-have-owner"
+func eventDescription(v *violation, rule string) string {
+	object := v.Name
+	if v.Namespace != "" {
+		object = v.Namespace + "/" + v.Name
+	}
+	return fmt.Sprintf("Policy violation on %s for rule %s", object, rule)
+}
+
+// findingID returns a stable finding ID derived from the constraint and the violating object,
+// so the same violation keeps its ID across audits
+func findingID(c *constraint, v *violation) string {
+	key := strings.Join([]string{c.Kind, c.Metadata.Name, v.Group, v.Kind, v.Namespace, v.Name}, "\x00")
+	return uuid.NewSHA1(findingNamespace, []byte(key)).String()
+}
+
+// parseTime parses an RFC 3339 timestamp, zero if absent or invalid
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
+
+// isEnforcementActionAllowed checks if an enforcement action is in the allowed filter list
+func (p *Processor) isEnforcementActionAllowed(action string) bool {
+	if len(p.config.EnforcementActionFilter) == 0 {
+		return true
+	}
+	for _, allowed := range p.config.EnforcementActionFilter {
+		if action == allowed {
+			return true
+		}
+	}
+	return false
+}
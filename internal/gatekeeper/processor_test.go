@@ -0,0 +1,262 @@
+package gatekeeper
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/attack"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing/processingtest"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// newLogRecord returns a log record whose body is the constraint of a test file, collected from prod-eu-1
+func newLogRecord(t *testing.T, name string) plog.LogRecord {
+	t.Helper()
+	logRecord := processingtest.NewLogRecord(t, name)
+	logRecord.Attributes().PutStr("k8s.cluster.name", "prod-eu-1")
+	return logRecord
+}
+
+func TestProcessLogRecord(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := newLogRecord(t, "k8srequiredlabels.json")
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{Results: 3, Parsed: 3, Created: 3}, outcome)
+	require.Equal(t, 3, events.Len())
+
+	event := events.At(1)
+	attrs := event.Attributes().AsRaw()
+	assert.Equal(t, "COMPLIANCE_FINDING", attrs["event.type"])
+	assert.Equal(t, "COMPLIANCE", attrs["event.category"])
+	assert.Equal(t, "Compliance finding event", attrs["event.name"])
+	assert.Equal(t, "Policy violation on payments/checkout-7c5ddbdf54-x2v4z for rule must-have-owner", attrs["event.description"])
+	assert.Equal(t, "Gatekeeper", attrs["product.name"])
+	assert.Equal(t, "Open Policy Agent", attrs["product.vendor"])
+	assert.Equal(t, "deny", attrs["action.type"])
+	assert.Equal(t, "fail", attrs["result.status"])
+	assert.Equal(t, "NON_COMPLIANT", attrs["compliance.status"])
+	assert.Equal(t, "must-have-owner", attrs["compliance.control"])
+	assert.Equal(t, "K8sRequiredLabels", attrs["finding.type"])
+	assert.Equal(t, "K8sRequiredLabels - must-have-owner", attrs["finding.title"])
+	assert.Equal(t, "Pod", attrs["object.type"])
+	assert.Equal(t, "K8S_POD", attrs["smartscape.type"])
+	assert.NotContains(t, attrs, "finding.severity", "Gatekeeper does not rate its constraints")
+	assert.Equal(t, 0.0, attrs["dt.security.risk.score"])
+	assert.Equal(t, "2025-10-18T12:00:00Z", attrs["finding.time.created"])
+	assert.Equal(t, time.Date(2025, 10, 18, 12, 0, 0, 0, time.UTC), event.Timestamp().AsTime())
+	assert.Equal(t, logRecord.ObservedTimestamp(), event.ObservedTimestamp())
+	assert.Equal(t, `you must provide labels: {"owner"}`, event.Body().Str())
+
+	// The violating object is resolved into the k8s.* fields of an OpenReports report scoping it
+	assert.Equal(t, "prod-eu-1", attrs["k8s.cluster.name"])
+	assert.Equal(t, "checkout-7c5ddbdf54-x2v4z", attrs["k8s.pod.name"])
+	assert.Equal(t, "payments", attrs["k8s.namespace.name"])
+	assert.Equal(t, "Pod", attrs["k8s.resource.kind"])
+	assert.Equal(t, "checkout", attrs["k8s.deployment.name"])
+	assert.Equal(t, "Deployment", attrs["k8s.workload.kind"])
+
+	namespace := events.At(0).Attributes().AsRaw()
+	assert.Equal(t, "Policy violation on payments for rule must-have-owner", namespace["event.description"])
+	assert.Equal(t, "payments", namespace["k8s.namespace.name"])
+	assert.Equal(t, "Namespace", namespace["k8s.resource.kind"])
+	assert.NotContains(t, namespace, "smartscape.type")
+
+	deployment := events.At(2).Attributes().AsRaw()
+	assert.Equal(t, "checkout", deployment["k8s.deployment.name"])
+	assert.Equal(t, "checkout", deployment["k8s.workload.name"])
+	assert.Equal(t, "payments", deployment["k8s.workload.namespace"])
+
+	// The finding ID is derived from the constraint and the object, so it is stable across audits
+	again, _, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, attrs["finding.id"], again.At(1).Attributes().AsRaw()["finding.id"])
+	assert.NotEqual(t, attrs["finding.id"], deployment["finding.id"])
+}
+
+func TestProcessLogRecord_Metadata(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithOutput(processing.Output{BodyFormat: schema.BodyFormatJSON}))
+	require.NoError(t, err)
+
+	logRecord := newLogRecord(t, "k8srequiredlabels.json")
+	events, _, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	require.Equal(t, 3, events.Len())
+
+	var body schema.SecurityEvent
+	require.NoError(t, json.Unmarshal([]byte(events.At(2).Body().Str()), &body))
+	assert.Equal(t, "deny", body.Metadata["enforcement_action"])
+	assert.Equal(t, 3.0, body.Metadata["total_violations"])
+	assert.Equal(t, "apps", body.Metadata["object_group"])
+	assert.Equal(t, "v1", body.Metadata["object_version"])
+	assert.Equal(t, "5b0c1f2e-2d8c-4c7e-9a51-0d7f1c2b3a41", body.Metadata["constraint_uid"])
+	assert.Equal(t, "K8sRequiredLabels", body.Compliance.Requirement)
+	assert.Equal(t, "checkout", body.Target.Resource)
+	assert.Equal(t, "Deployment", body.Target.ResourceType)
+}
+
+func TestProcessLogRecord_EnforcementActionFilter(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true, EnforcementActionFilter: []string{"deny", "warn"}})
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewStringLogRecord(`{"apiVersion": "constraints.gatekeeper.sh/v1beta1", "kind": "K8sAllowedRepos",
+		"metadata": {"name": "prod-repos"}, "spec": {"enforcementAction": "dryrun"},
+		"status": {"violations": [
+			{"kind": "Pod", "namespace": "shop", "name": "web", "message": "invalid image repo"},
+			{"kind": "Pod", "namespace": "shop", "name": "api", "message": "invalid image repo", "enforcementAction": "warn"}]}}`)
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{
+		Results:  2,
+		Parsed:   2,
+		Filtered: map[string]int{FilterReasonEnforcementAction: 1},
+		Created:  1,
+	}, outcome)
+	require.Equal(t, 1, events.Len())
+	attrs := events.At(0).Attributes().AsRaw()
+	assert.Equal(t, "warn", attrs["action.type"])
+	assert.Equal(t, "api", attrs["k8s.pod.name"])
+	// Without audit timestamp, the record keeps the time of the log record
+	assert.Equal(t, logRecord.Timestamp(), events.At(0).Timestamp())
+}
+
+func TestProcessLogRecord_CatalogAndTechniques(t *testing.T) {
+	catalog, err := compliance.ParseCatalog([]byte(`
+mappings:
+  - policy: K8sRequiredLabels
+    controls:
+      - framework: NIST 800-53
+        id: CM-8
+`))
+	require.NoError(t, err)
+	techniques, err := attack.ParseMapping([]byte(`
+techniques:
+  T1610:
+    name: Deploy Container
+    tactics: [Defense Evasion, Execution]
+mappings:
+  - rule: must-have-owner
+    techniques: [T1610]
+`))
+	require.NoError(t, err)
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true},
+		WithComplianceCatalog(catalog), WithTechniqueMapping(techniques))
+	require.NoError(t, err)
+
+	logRecord := newLogRecord(t, "k8srequiredlabels.json")
+	events, _, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	attrs := events.At(0).Attributes().AsRaw()
+	assert.Equal(t, []interface{}{"NIST 800-53"}, attrs["compliance.standards"])
+	assert.Equal(t, []interface{}{"NIST 800-53 CM-8"}, attrs["compliance.requirements"])
+	assert.Equal(t, []interface{}{"T1610"}, attrs["threat.technique.id"])
+}
+
+func TestProcessLogRecord_MapBody(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	// The k8sobjects receiver in watch mode wraps the constraint into a watch event
+	logRecord := processingtest.NewMapLogRecord(t, "k8srequiredlabels.json", func(object map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"type": "ADDED", "object": object}
+	})
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, 3, outcome.Created)
+	assert.Equal(t, "must-have-owner", events.At(0).Attributes().AsRaw()["compliance.control"])
+}
+
+func TestProcessLogRecord_DefaultEnforcementAction(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true, EnforcementActionFilter: []string{ActionDeny}})
+	require.NoError(t, err)
+
+	// Gatekeeper denies the violations of constraints without enforcement action
+	logRecord := processingtest.NewStringLogRecord(`{"apiVersion": "constraints.gatekeeper.sh/v1", "kind": "K8sPSPPrivilegedContainer",
+		"metadata": {"name": "no-privileged"},
+		"status": {"violations": [{"kind": "Pod", "namespace": "shop", "name": "debug", "message": "Privileged container is not allowed"}]}}`)
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{Results: 1, Parsed: 1, Created: 1}, outcome)
+	attrs := events.At(0).Attributes().AsRaw()
+	assert.Equal(t, ActionDeny, attrs["action.type"])
+	assert.Equal(t, "Policy violation on shop/debug for rule no-privileged", attrs["event.description"])
+}
+
+func TestProcessLogRecord_OtherKinds(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewStringLogRecord(`{"apiVersion": "constraints.gatekeeper.sh/v1beta1", "kind": "K8sRequiredLabels",
+		"metadata": {"name": "must-have-team"},
+		"status": {"violations": [
+			{"group": "networking.k8s.io", "kind": "Ingress", "namespace": "shop", "name": "storefront", "message": "missing team"},
+			{"group": "rbac.authorization.k8s.io", "kind": "ClusterRole", "name": "admin", "message": "missing team"}]}}`)
+	events, _, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	require.Equal(t, 2, events.Len())
+
+	// Kinds without a name attribute of their own are named by k8s.resource.name
+	ingress := events.At(0).Attributes().AsRaw()
+	assert.Equal(t, "storefront", ingress["k8s.resource.name"])
+	assert.Equal(t, "Ingress", ingress["k8s.resource.kind"])
+	assert.Equal(t, "shop", ingress["k8s.namespace.name"])
+	assert.NotContains(t, ingress, "k8s.pod.name")
+	assert.NotContains(t, ingress, "k8s.resource.uid", "Gatekeeper does not report the UID of the object")
+
+	clusterRole := events.At(1).Attributes().AsRaw()
+	assert.Equal(t, "admin", clusterRole["k8s.resource.name"])
+	assert.NotContains(t, clusterRole, "k8s.namespace.name")
+}
+
+func TestProcessLogRecord_Skipped(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		stage      string
+		skipReason string
+	}{
+		{name: "plain line", body: "plain log line", skipReason: SkipReasonNotConstraint},
+		{
+			name:       "no violations",
+			body:       `{"apiVersion": "constraints.gatekeeper.sh/v1beta1", "kind": "K8sRequiredLabels", "status": {}}`,
+			skipReason: SkipReasonNoViolations,
+		},
+		{
+			name:       "deleted",
+			body:       `{"type": "DELETED", "object": {"apiVersion": "constraints.gatekeeper.sh/v1beta1", "kind": "K8sRequiredLabels"}}`,
+			skipReason: SkipReasonDeleted,
+		},
+		{
+			name:       "invalid violations",
+			body:       `{"apiVersion": "constraints.gatekeeper.sh/v1beta1", "status": {"violations": {}}}`,
+			stage:      processing.StageParse,
+			skipReason: SkipReasonInvalidConstraint,
+		},
+	}
+
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logRecord := processingtest.NewStringLogRecord(tt.body)
+			events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+			if tt.stage != "" {
+				processingtest.RequireStageError(t, err, tt.stage)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.skipReason, outcome.SkipReason)
+			assert.Zero(t, events.Len())
+		})
+	}
+}
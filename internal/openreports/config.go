@@ -12,6 +12,11 @@ type Config struct {
 	// Valid values: "pass", "fail", "error", "skip"
 	// If empty or not specified, all statuses will be processed
 	StatusFilter []string `mapstructure:"status_filter"`
+
+	// Workers is the number of goroutines parsing and transforming the results of a single report
+	// Large reports are split across workers, preserving the order of the security events
+	// If 0 or 1, results are processed serially
+	Workers int `mapstructure:"workers"`
 }
 
 // Validate checks if the configuration is valid
@@ -30,5 +35,9 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.Workers < 0 {
+		return fmt.Errorf("invalid workers: %d. Must be 0 or greater", cfg.Workers)
+	}
+
 	return nil
 }
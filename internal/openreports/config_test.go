@@ -124,3 +124,12 @@ func TestConfig_Validate_InvalidStatuses(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_Validate_Workers(t *testing.T) {
+	require.NoError(t, (&Config{Enabled: true, Workers: 0}).Validate())
+	require.NoError(t, (&Config{Enabled: true, Workers: 8}).Validate())
+
+	err := (&Config{Enabled: true, Workers: -1}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid workers: -1")
+}
@@ -0,0 +1,155 @@
+package openreports
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Security event classification of image verification results
+const (
+	supplyChainCategory = "SUPPLY_CHAIN"
+	supplyChainName     = "Supply chain finding event"
+	supplyChainType     = "SUPPLY_CHAIN_FINDING"
+)
+
+// Result sources of the image verifiers
+const (
+	sourceKyverno = "kyverno"
+)
+
+// policyControllerSources are the result sources of the Sigstore policy-controller, which only verifies images
+var policyControllerSources = map[string]bool{
+	"policy-controller":          true,
+	"sigstore":                   true,
+	"sigstore-policy-controller": true,
+}
+
+// Result properties holding the supply chain fields, taking precedence over the fields found in the message
+var (
+	imagePropertyKeys           = []string{"image", "imageReference"}
+	digestPropertyKeys          = []string{"digest", "imageDigest"}
+	signerPropertyKeys          = []string{"signer", "subject", "identity"}
+	issuerPropertyKeys          = []string{"issuer"}
+	attestationTypePropertyKeys = []string{"attestationType", "predicateType"}
+)
+
+var (
+	// kyvernoVerifyPattern matches the messages of Kyverno verifyImages rules,
+	// e.g. "failed to verify image ghcr.io/org/app:1.0: .attestors[0].entries[0].keys: no matching signatures"
+	kyvernoVerifyPattern = regexp.MustCompile(`(?i)failed to verify (?:image|signature|attestation)|verified image|` +
+		`image (?:signature |attestations? )?verification|unverified image|missing digest|no matching (?:signatures|attestations)`)
+
+	// policyControllerPattern matches the denials of the Sigstore policy-controller,
+	// e.g. "signature keyless validation failed for authority authority-0 for ghcr.io/org/app@sha256:...: no matching signatures"
+	policyControllerPattern = regexp.MustCompile(`(?i)policy\.sigstore\.dev|` +
+		`(?:signature|attestation) (?:key|keyless|static) validation failed for authority`)
+
+	// imageRefPattern matches a registry-qualified image reference with an optional tag and digest
+	imageRefPattern = regexp.MustCompile(`\b(?:[a-z0-9-]+\.)+[a-z0-9-]+(?::[0-9]+)?/[a-z0-9._/-]+` +
+		`(?::\w[\w.-]{0,127})?(?:@sha256:[a-f0-9]{64})?`)
+
+	// digestPattern matches an image digest
+	digestPattern = regexp.MustCompile(`sha256:[a-f0-9]{64}`)
+
+	// signerPattern and issuerPattern match the expected identity and issuer of keyless signature mismatches,
+	// e.g. "subject mismatch: expected https://github.com/org/app/..., received ..."
+	signerPattern = regexp.MustCompile(`(?i)(?:subject|identity) mismatch: expected "?([^\s",]+)`)
+	issuerPattern = regexp.MustCompile(`(?i)issuer mismatch: expected "?([^\s",]+)`)
+
+	// attestationTypePattern matches the predicate type of an attestation, e.g. "predicateType: https://slsa.dev/provenance/v1"
+	attestationTypePattern = regexp.MustCompile(`(?i)(?:predicate|attestation) ?type[=: ]+"?([^\s",]+)`)
+)
+
+// isImageVerification reports whether a result is the verification of the signature or attestations of a
+// container image: a result of the Sigstore policy-controller, or of a Kyverno verifyImages rule
+func (r *Result) isImageVerification() bool {
+	source := strings.ToLower(r.Source)
+	switch {
+	case policyControllerSources[source]:
+		return true
+	case policyControllerPattern.MatchString(r.Message):
+		return true
+	case source == sourceKyverno:
+		return kyvernoVerifyPattern.MatchString(r.Message)
+	default:
+		return false
+	}
+}
+
+// supplyChain returns the image, signer and attestation fields of an image verification result, taken from
+// its properties, or else found in its message
+func (r *Result) supplyChain() *schema.SupplyChain {
+	sc := &schema.SupplyChain{
+		Image:           r.property(imagePropertyKeys),
+		Digest:          r.property(digestPropertyKeys),
+		Signer:          r.property(signerPropertyKeys),
+		Issuer:          r.property(issuerPropertyKeys),
+		AttestationType: r.property(attestationTypePropertyKeys),
+	}
+	if sc.Image == "" {
+		sc.Image = imageRefPattern.FindString(r.Message)
+	}
+	if image, digest, ok := strings.Cut(sc.Image, "@"); ok {
+		sc.Image = image
+		if sc.Digest == "" {
+			sc.Digest = digest
+		}
+	}
+	if sc.Digest == "" {
+		sc.Digest = digestPattern.FindString(r.Message)
+	}
+	if sc.Signer == "" {
+		sc.Signer = submatch(signerPattern, r.Message)
+	}
+	if sc.Issuer == "" {
+		sc.Issuer = submatch(issuerPattern, r.Message)
+	}
+	if sc.AttestationType == "" {
+		sc.AttestationType = strings.TrimRight(submatch(attestationTypePattern, r.Message), ":.")
+	}
+	return sc
+}
+
+// property returns the first non-empty string property among keys, or ""
+func (r *Result) property(keys []string) string {
+	for _, key := range keys {
+		if value, ok := r.Properties[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// submatch returns the first capture group of pattern in s, or ""
+func submatch(pattern *regexp.Regexp, s string) string {
+	if match := pattern.FindStringSubmatch(s); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// setSupplyChain turns a compliance finding into the supply chain finding of an image verification result
+// The description names the verified image when it is known
+func setSupplyChain(event *schema.SecurityEvent, result *Result, scopeName, rule string) {
+	event.Event.Category = supplyChainCategory
+	event.Event.Name = supplyChainName
+	event.Event.Type = supplyChainType
+	event.SupplyChain = result.supplyChain()
+
+	image := event.SupplyChain.Image
+	if image == "" {
+		return
+	}
+	switch result.Result {
+	case resultStatusFail:
+		event.Event.Description = fmt.Sprintf("Image verification failed for %s on %s for rule %s", image, scopeName, rule)
+	case resultStatusPass:
+		event.Event.Description = fmt.Sprintf("Image verification passed for %s on %s for rule %s", image, scopeName, rule)
+	default:
+		return
+	}
+	event.Action.Description = event.Event.Description
+}
@@ -0,0 +1,142 @@
+package openreports
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+const testDigest = "sha256:5b0c1f2e2d8c4c7e9a510d7f1c2b3a415b0c1f2e2d8c4c7e9a510d7f1c2b3a41"
+
+func TestResult_IsImageVerification(t *testing.T) {
+	tests := []struct {
+		name   string
+		result Result
+		want   bool
+	}{
+		{
+			name:   "kyverno signature failure",
+			result: Result{Source: "kyverno", Message: "failed to verify image ghcr.io/org/app:1.0: .attestors[0].entries[0].keys: no matching signatures"},
+			want:   true,
+		},
+		{
+			name:   "kyverno verified image",
+			result: Result{Source: "Kyverno", Message: "verified image signatures for ghcr.io/org/app@" + testDigest},
+			want:   true,
+		},
+		{
+			name:   "kyverno validation",
+			result: Result{Source: "kyverno", Message: "validation error: privileged containers are not allowed"},
+			want:   false,
+		},
+		{
+			name:   "policy-controller source",
+			result: Result{Source: "policy-controller", Message: "no matching policies"},
+			want:   true,
+		},
+		{
+			name:   "policy-controller denial",
+			result: Result{Source: "admission", Message: "signature keyless validation failed for authority authority-0 for ghcr.io/org/app@" + testDigest + ": no matching signatures"},
+			want:   true,
+		},
+		{
+			name:   "trivy vulnerability",
+			result: Result{Source: "Trivy", Message: "no matching signatures in openssl"},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.result.isImageVerification())
+		})
+	}
+}
+
+func TestResult_SupplyChain(t *testing.T) {
+	// Fields found in a Kyverno keyless mismatch message
+	result := Result{
+		Source: "kyverno",
+		Message: "failed to verify image ghcr.io/org/app:1.0@" + testDigest + ": .attestors[0].entries[0].keyless: " +
+			"subject mismatch: expected https://github.com/org/app/.github/workflows/release.yml@refs/tags/v1.0, " +
+			"received https://github.com/fork/app/.github/workflows/release.yml@refs/heads/main",
+	}
+	assert.Equal(t, &schema.SupplyChain{
+		Image:  "ghcr.io/org/app:1.0",
+		Digest: testDigest,
+		Signer: "https://github.com/org/app/.github/workflows/release.yml@refs/tags/v1.0",
+	}, result.supplyChain())
+
+	result = Result{
+		Source:  "kyverno",
+		Message: "failed to verify attestations for ghcr.io/org/app:1.0: predicateType: https://slsa.dev/provenance/v1: no matching attestations",
+	}
+	assert.Equal(t, &schema.SupplyChain{
+		Image:           "ghcr.io/org/app:1.0",
+		AttestationType: "https://slsa.dev/provenance/v1",
+	}, result.supplyChain())
+
+	result = Result{
+		Source:  "policy-controller",
+		Message: "signature keyless validation failed for authority authority-0 for ghcr.io/org/app@" + testDigest + ": issuer mismatch: expected https://token.actions.githubusercontent.com",
+	}
+	assert.Equal(t, &schema.SupplyChain{
+		Image:  "ghcr.io/org/app",
+		Digest: testDigest,
+		Issuer: "https://token.actions.githubusercontent.com",
+	}, result.supplyChain())
+
+	// Properties take precedence over the message
+	result = Result{
+		Source:  "policy-controller",
+		Message: "no matching signatures for ghcr.io/org/app:1.0",
+		Properties: map[string]interface{}{
+			"image":         "ghcr.io/org/app:1.1",
+			"digest":        testDigest,
+			"subject":       "release@example.com",
+			"issuer":        "https://accounts.google.com",
+			"predicateType": "https://cyclonedx.org/bom",
+		},
+	}
+	assert.Equal(t, &schema.SupplyChain{
+		Image:           "ghcr.io/org/app:1.1",
+		Digest:          testDigest,
+		Signer:          "release@example.com",
+		Issuer:          "https://accounts.google.com",
+		AttestationType: "https://cyclonedx.org/bom",
+	}, result.supplyChain())
+}
+
+func TestBuildSecurityEvent_ImageVerification(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	assert.NoError(t, err)
+
+	metadata := map[string]interface{}{"scope.name": "app-7d9f8b6c5d-x2k4p", "scope.kind": "Pod"}
+	result := Result{
+		Source:   "kyverno",
+		Policy:   "verify-image-signatures",
+		Rule:     "check-signature",
+		Result:   "fail",
+		Severity: "high",
+		Message:  "failed to verify image ghcr.io/org/app:1.0: .attestors[0].entries[0].keys: no matching signatures",
+	}
+	event := processor.buildSecurityEvent(result, metadata, nil)
+	assert.Equal(t, "SUPPLY_CHAIN", event.Event.Category)
+	assert.Equal(t, "Supply chain finding event", event.Event.Name)
+	assert.Equal(t, "SUPPLY_CHAIN_FINDING", event.Event.Type)
+	assert.Equal(t, "Image verification failed for ghcr.io/org/app:1.0 on app-7d9f8b6c5d-x2k4p for rule check-signature", event.Event.Description)
+	assert.Equal(t, event.Event.Description, event.Action.Description)
+	assert.Equal(t, &schema.SupplyChain{Image: "ghcr.io/org/app:1.0"}, event.SupplyChain)
+	// The compliance fields are kept
+	assert.Equal(t, "NON_COMPLIANT", event.Compliance.Status)
+	assert.Equal(t, "check-signature", event.Compliance.Control)
+	assert.Equal(t, "HIGH", event.Finding.Severity)
+
+	// Other Kyverno results stay compliance findings
+	result.Message = "validation error: privileged containers are not allowed"
+	event = processor.buildSecurityEvent(result, metadata, nil)
+	assert.Equal(t, "COMPLIANCE_FINDING", event.Event.Type)
+	assert.Nil(t, event.SupplyChain)
+}
@@ -0,0 +1,21 @@
+package openreports
+
+// Reasons for results that are not turned into security events
+const (
+	// FilterReasonStatus marks results excluded by the status_filter configuration
+	FilterReasonStatus = "status_filter"
+)
+
+// Reasons for reports that produce no security events
+const (
+	// SkipReasonNotReport marks log records that are not OpenReports reports
+	SkipReasonNotReport = "not_openreports"
+	// SkipReasonNoResults marks reports without a results field
+	SkipReasonNoResults = "no_results"
+	// SkipReasonEmptyResults marks reports with an empty results array
+	SkipReasonEmptyResults = "empty_results"
+	// SkipReasonInvalidResults marks reports whose results field has an unexpected type
+	SkipReasonInvalidResults = "invalid_results"
+	// SkipReasonMalformedResults marks reports whose results are all malformed
+	SkipReasonMalformedResults = "malformed_results"
+)
@@ -10,6 +10,14 @@ import (
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/attack"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/semconv"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/vulnintel"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
 )
 
 // Constants for repeated string literals
@@ -29,62 +37,150 @@ const (
 	missingValue           = "missing"
 )
 
+// attrResourceName names the objects of kinds without a semantic convention name attribute, e.g. a Service
+const attrResourceName = "k8s.resource.name"
+
+// checkIDPropertyKeys lists the result properties holding scanner check identifiers (e.g. Trivy "KSV017")
+var checkIDPropertyKeys = []string{"id", "checkID", "avdID"}
+
 // Processor handles transformation of OpenReports logs into security events
 type Processor struct {
-	logger *zap.Logger
-	config *Config
+	logger     *zap.Logger
+	config     *Config
+	catalog    *compliance.Catalog
+	techniques *attack.Mapping
+	vulnIntel  *vulnintel.Store
+
+	// k8sOnResource leaves the Kubernetes fields off the security events, as they are set on their resource
+	k8sOnResource bool
+
+	// output lays out the security events in their log records
+	output processing.Output
+}
+
+// Option configures optional dependencies of the Processor
+type Option func(*Processor)
+
+// WithComplianceCatalog enriches findings with the framework controls mapped in the catalog
+func WithComplianceCatalog(catalog *compliance.Catalog) Option {
+	return func(p *Processor) {
+		p.catalog = catalog
+	}
+}
+
+// WithTechniqueMapping tags findings with the MITRE ATT&CK techniques mapped to their policy, rule or category
+func WithTechniqueMapping(mapping *attack.Mapping) Option {
+	return func(p *Processor) {
+		p.techniques = mapping
+	}
+}
+
+// WithVulnerabilityIntel enriches vulnerability findings with EPSS scores and CISA KEV membership
+func WithVulnerabilityIntel(store *vulnintel.Store) Option {
+	return func(p *Processor) {
+		p.vulnIntel = store
+	}
+}
+
+// WithK8sFieldsOnResource leaves the Kubernetes fields off the security events when enabled
+// The caller sets them on the resource of the events with ResourceAttributes
+func WithK8sFieldsOnResource(enabled bool) Option {
+	return func(p *Processor) {
+		p.k8sOnResource = enabled
+	}
+}
+
+// WithOutput sets the layout of the security events in their log records: the profile, attribute names,
+// body format and SIEM encoding
+func WithOutput(output processing.Output) Option {
+	return func(p *Processor) {
+		p.output = output
+	}
 }
 
 // NewProcessor creates a new OpenReports processor
-func NewProcessor(logger *zap.Logger, config *Config) (*Processor, error) {
-	return &Processor{
+func NewProcessor(logger *zap.Logger, config *Config, opts ...Option) (*Processor, error) {
+	p := &Processor{
 		logger: logger,
 		config: config,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+// ScopeKind returns the kind of the object scoped by the report of a log record (e.g. "Pod"), or an empty
+// string for reports without a scope
+func ScopeKind(logRecord *plog.LogRecord) string {
+	if scopeKind, exists := logRecord.Attributes().Get("scope.kind"); exists {
+		return scopeKind.AsString()
+	}
+	return ""
 }
 
 // ProcessLogRecord processes a single log record and transforms it into multiple security events
-// Returns a slice of new log records (one per result) or nil if this is not an OpenReports log
+// One security event per result is built in place at the end of dst; nothing is appended if this is
+// not an OpenReports log. The returned outcome holds the counts of the report results
+// Reports without results to parse, or whose results are all malformed, append nothing and are given a skip reason
+// A *processing.StageError is returned if the security events cannot be transformed;
+// nothing is appended to dst in that case
 //
 //nolint:gocyclo // Complex log parsing and transformation with nested conditionals and loops
-func (p *Processor) ProcessLogRecord(ctx context.Context, logRecord *plog.LogRecord, resource pcommon.Resource, scopeLogs plog.ScopeLogs) ([]plog.LogRecord, error) {
+func (p *Processor) ProcessLogRecord(
+	ctx context.Context, logRecord *plog.LogRecord, resource pcommon.Resource, scopeLogs plog.ScopeLogs,
+	dst plog.LogRecordSlice,
+) (processing.Outcome, error) {
+	var outcome processing.Outcome
+
+	// Per-result debug fields are only built when debug logging is enabled
+	debug := p.logger.Core().Enabled(zapcore.DebugLevel)
+
 	// Check if this is an OpenReports log by looking for the kind field
 	attrs := logRecord.Attributes()
 	kindVal, exists := attrs.Get("kind")
 	if !exists || kindVal.AsString() != "Report" {
 		// Not an OpenReports log, skip
-		p.logger.Debug("Log record does not match OpenReports processor - kind field check",
-			zap.Bool("kind_exists", exists),
-			zap.String("kind_value", func() string {
-				if exists {
-					return kindVal.AsString()
-				}
-				return missingValue
-			}()),
-			zap.String("trace_id", logRecord.TraceID().String()))
-		return nil, nil
+		if debug {
+			p.logger.Debug("Log record does not match OpenReports processor - kind field check",
+				zap.Bool("kind_exists", exists),
+				zap.String("kind_value", func() string {
+					if exists {
+						return kindVal.AsString()
+					}
+					return missingValue
+				}()),
+				zap.String("trace_id", logRecord.TraceID().String()))
+		}
+		outcome.SkipReason = SkipReasonNotReport
+		return outcome, nil
 	}
 
 	apiVersionVal, exists := attrs.Get("apiVersion")
 	if !exists || apiVersionVal.AsString() != "openreports.io/v1alpha1" {
 		// Not an OpenReports log, skip
-		p.logger.Debug("Log record does not match OpenReports processor - apiVersion check",
-			zap.Bool("apiVersion_exists", exists),
-			zap.String("apiVersion_value", func() string {
-				if exists {
-					return apiVersionVal.AsString()
-				}
-				return missingValue
-			}()),
-			zap.String("trace_id", logRecord.TraceID().String()))
-		return nil, nil
+		if debug {
+			p.logger.Debug("Log record does not match OpenReports processor - apiVersion check",
+				zap.Bool("apiVersion_exists", exists),
+				zap.String("apiVersion_value", func() string {
+					if exists {
+						return apiVersionVal.AsString()
+					}
+					return missingValue
+				}()),
+				zap.String("trace_id", logRecord.TraceID().String()))
+		}
+		outcome.SkipReason = SkipReasonNotReport
+		return outcome, nil
 	}
 
 	// Log that we've identified an OpenReports log
-	p.logger.Debug("OpenReports log identified - processing",
-		zap.String("trace_id", logRecord.TraceID().String()),
-		zap.String("span_id", logRecord.SpanID().String()),
-		zap.String("timestamp", logRecord.Timestamp().String()))
+	if debug {
+		p.logger.Debug("OpenReports log identified - processing",
+			zap.String("trace_id", logRecord.TraceID().String()),
+			zap.String("span_id", logRecord.SpanID().String()),
+			zap.String("timestamp", logRecord.Timestamp().String()))
+	}
 
 	// Extract metadata for logging
 	metadataName, metadataNameExists := attrs.Get("metadata.name")
@@ -104,233 +200,393 @@ func (p *Processor) ProcessLogRecord(ctx context.Context, logRecord *plog.LogRec
 		scopeKindStr = scopeKind.AsString()
 	}
 
-	p.logger.Debug("OpenReports log metadata",
-		zap.String("metadata.name", metadataNameStr),
-		zap.String("scope.name", scopeNameStr),
-		zap.String("scope.kind", scopeKindStr))
+	if debug {
+		p.logger.Debug("OpenReports log metadata",
+			zap.String("metadata.name", metadataNameStr),
+			zap.String("scope.name", scopeNameStr),
+			zap.String("scope.kind", scopeKindStr))
+	}
 
 	// Extract the results array
 	resultsVal, exists := attrs.Get("results")
 	if !exists {
 		p.logger.Warn("OpenReports log has no results field",
 			zap.String("metadata.name", metadataNameStr))
-		return nil, nil
+		outcome.SkipReason = SkipReasonNoResults
+		return outcome, nil
 	}
 
-	p.logger.Debug("Parsing OpenReports results array",
-		zap.String("results_type", resultsVal.Type().String()),
-		zap.Bool("results_exists", exists))
+	if debug {
+		p.logger.Debug("Parsing OpenReports results array",
+			zap.String("results_type", resultsVal.Type().String()),
+			zap.Bool("results_exists", exists))
+	}
 
 	// Parse results - it's stored as an array/slice of JSON strings
 	var resultsArray []string
 	if resultsVal.Type() == pcommon.ValueTypeSlice {
 		// If it's a slice, extract each element as a string
 		slice := resultsVal.Slice()
-		p.logger.Debug("Results is a slice type",
-			zap.Int("slice_length", slice.Len()))
+		if debug {
+			p.logger.Debug("Results is a slice type",
+				zap.Int("slice_length", slice.Len()))
+		}
+		resultsArray = make([]string, 0, slice.Len())
 		for i := 0; i < slice.Len(); i++ {
 			resultsArray = append(resultsArray, slice.At(i).AsString())
 		}
 	} else if resultsVal.Type() == pcommon.ValueTypeStr {
 		// If it's a single JSON string containing an array, parse it
 		resultStr := resultsVal.AsString()
-		p.logger.Debug("Results is a string type, attempting JSON parse",
-			zap.Int("string_length", len(resultStr)))
+		if debug {
+			p.logger.Debug("Results is a string type, attempting JSON parse",
+				zap.Int("string_length", len(resultStr)))
+		}
 		var jsonArray []string
 		if err := json.Unmarshal([]byte(resultStr), &jsonArray); err == nil {
 			resultsArray = jsonArray
-			p.logger.Debug("Successfully parsed JSON array from string",
-				zap.Int("array_length", len(resultsArray)))
+			if debug {
+				p.logger.Debug("Successfully parsed JSON array from string",
+					zap.Int("array_length", len(resultsArray)))
+			}
 		} else {
 			// Try as single string
-			p.logger.Debug("JSON parse failed, treating as single string result",
-				zap.Error(err))
+			if debug {
+				p.logger.Debug("JSON parse failed, treating as single string result",
+					zap.Error(err))
+			}
 			resultsArray = []string{resultStr}
 		}
 	} else {
 		p.logger.Warn("OpenReports log results field has unexpected type",
 			zap.String("type", resultsVal.Type().String()),
 			zap.String("metadata.name", metadataNameStr))
-		return nil, nil
+		outcome.SkipReason = SkipReasonInvalidResults
+		return outcome, nil
 	}
 
-	p.logger.Debug("Parsed results array",
-		zap.Int("total_results", len(resultsArray)))
+	if debug {
+		p.logger.Debug("Parsed results array",
+			zap.Int("total_results", len(resultsArray)))
+	}
 
 	if len(resultsArray) == 0 {
-		p.logger.Debug("OpenReports log has empty results array",
-			zap.String("metadata.name", metadataNameStr))
-		return nil, nil
+		if debug {
+			p.logger.Debug("OpenReports log has empty results array",
+				zap.String("metadata.name", metadataNameStr))
+		}
+		outcome.SkipReason = SkipReasonEmptyResults
+		return outcome, nil
 	}
 
-	// Extract remaining metadata from the original log
-	metadataNamespaceVal, metadataNamespaceExists := attrs.Get("metadata.namespace")
-	scopeNamespaceVal, scopeNamespaceExists := attrs.Get("scope.namespace")
-	scopeUIDVal, scopeUIDExists := attrs.Get("scope.uid")
-	scopeAPIVersionVal, scopeAPIVersionExists := attrs.Get("scope.apiVersion")
 	timestamp := logRecord.Timestamp()
 
-	metadataNamespaceStr := ""
-	if metadataNamespaceExists {
-		metadataNamespaceStr = metadataNamespaceVal.AsString()
+	// Report metadata shared by all security events, including workload information from owner references
+	metadata := reportMetadata(attrs)
+
+	// Kubernetes fields shared by all security events, unless they are set on the resource
+	var k8s map[string]interface{}
+	if !p.k8sOnResource {
+		k8s = k8sFields(attrs, metadata)
+	}
+
+	if debug {
+		p.logger.Debug("Workload information extracted",
+			zap.String("workload.name", getString(metadata, "workload.name")),
+			zap.String("workload.kind", getString(metadata, "workload.kind")),
+			zap.String("workload.namespace", getString(metadata, "workload.namespace")),
+			zap.String("workload.uid", getString(metadata, "workload.uid")))
+		p.logger.Debug("Status filter configuration",
+			zap.Strings("allowed_statuses", p.config.StatusFilter),
+			zap.Int("total_results", len(resultsArray)))
 	}
-	scopeNamespaceStr := ""
-	if scopeNamespaceExists {
-		scopeNamespaceStr = scopeNamespaceVal.AsString()
+
+	// Parse the results, in parallel when workers are configured
+	outcome.Results = len(resultsArray)
+	parsed := make([]parsedResult, len(resultsArray))
+	processing.ForEach(p.config.Workers, len(resultsArray), func(i int) {
+		parsed[i] = p.parseResult(i, resultsArray[i], debug)
+	})
+
+	// Aggregate the outcome in order and keep the results to transform
+	kept := make([]*Result, 0, len(parsed))
+	for i := range parsed {
+		switch {
+		case parsed[i].malformed:
+			outcome.Malformed++
+		case parsed[i].filterReason != "":
+			outcome.Parsed++
+			outcome.AddFiltered(parsed[i].filterReason)
+		default:
+			outcome.Parsed++
+			kept = append(kept, &parsed[i].result)
+		}
 	}
-	scopeUIDStr := ""
-	if scopeUIDExists {
-		scopeUIDStr = scopeUIDVal.AsString()
+
+	// A report whose results are all malformed cannot be turned into security events: it passes through
+	// unchanged, its results counted as malformed
+	if outcome.Malformed > 0 && outcome.Parsed == 0 {
+		outcome.SkipReason = SkipReasonMalformedResults
+		return outcome, nil
 	}
-	scopeAPIVersionStr := ""
-	if scopeAPIVersionExists {
-		scopeAPIVersionStr = scopeAPIVersionVal.AsString()
+
+	// Append one log record per kept result to the destination slice, then build the security events
+	// in place; each worker only writes its own records, so the output order matches the results order
+	first := dst.Len()
+	dst.EnsureCapacity(first + len(kept))
+	for range kept {
+		dst.AppendEmpty()
 	}
+	errs := make([]error, len(kept))
+	processing.ForEach(p.config.Workers, len(kept), func(i int) {
+		newRecord := dst.At(first + i)
 
-	// Extract workload information from owner references
-	p.logger.Debug("Extracting workload information",
-		zap.String("scope.name", scopeNameStr),
-		zap.String("scope.namespace", scopeNamespaceStr))
-	workloadInfo := extractWorkloadInfo(attrs, scopeNameStr, scopeNamespaceStr)
+		// Copy basic fields from original
+		processing.CopyRecordFields(*logRecord, newRecord)
+		newRecord.SetTimestamp(timestamp)
 
-	if workloadInfo.name != "" {
-		p.logger.Debug("Workload information extracted",
-			zap.String("workload.name", workloadInfo.name),
-			zap.String("workload.kind", workloadInfo.kind),
-			zap.String("workload.namespace", workloadInfo.namespace),
-			zap.String("workload.uid", workloadInfo.uid))
-	} else {
-		p.logger.Debug("No workload information found - will infer from pod name if applicable")
+		// Transform the result into a security event
+		errs[i] = p.transformToSecurityEvent(&newRecord, *kept[i], metadata, k8s)
+	})
+	for _, err := range errs {
+		if err != nil {
+			// Remove the security events of the report, so the report is handled as a whole
+			processing.Rollback(dst, first)
+			return outcome, processing.NewStageError(processing.StageTransform, err)
+		}
 	}
+	outcome.Created = len(kept)
 
-	// Log status filter configuration
-	if len(p.config.StatusFilter) > 0 {
-		p.logger.Debug("Status filter active",
-			zap.Strings("allowed_statuses", p.config.StatusFilter),
-			zap.Int("total_results", len(resultsArray)))
-	} else {
-		p.logger.Debug("No status filter configured - processing all results",
-			zap.Int("total_results", len(resultsArray)))
+	if debug {
+		p.logger.Debug("OpenReports log transformation summary",
+			zap.Int("original_logs", 1),
+			zap.Int("total_results", len(resultsArray)),
+			zap.Int("processed_results", outcome.Created),
+			zap.Int("filtered_results", outcome.FilteredTotal()),
+			zap.Int("malformed_results", outcome.Malformed),
+			zap.Int("security_events_created", outcome.Created),
+			zap.Bool("status_filter_enabled", len(p.config.StatusFilter) > 0),
+			zap.String("metadata.name", metadataNameStr),
+			zap.String("scope.name", scopeNameStr))
 	}
 
-	// Create a new log record for each result
-	var newRecords []plog.LogRecord
-	processedCount := 0
-	filteredCount := 0
+	return outcome, nil
+}
 
-	for i := 0; i < len(resultsArray); i++ {
-		resultJSONStr := resultsArray[i]
+// Result represents a single result from the OpenReports results array
+type Result struct {
+	Source     string                 `json:"source"`
+	Timestamp  Timestamp              `json:"timestamp"`
+	Message    string                 `json:"message"`
+	Policy     string                 `json:"policy"`
+	Properties map[string]interface{} `json:"properties"`
+	Result     string                 `json:"result"` // pass, fail, error, skip
+	Rule       string                 `json:"rule"`
+	Scored     bool                   `json:"scored"`
+	Severity   string                 `json:"severity,omitempty"`
+	Category   string                 `json:"category,omitempty"`
+}
+
+// reportMetadata extracts the report and scoped object metadata of an OpenReports log
+func reportMetadata(attrs pcommon.Map) map[string]interface{} {
+	getAttr := func(key string) string {
+		if val, exists := attrs.Get(key); exists {
+			return val.AsString()
+		}
+		return ""
+	}
+
+	scopeName := getAttr("scope.name")
+	scopeNamespace := getAttr("scope.namespace")
+	workloadInfo := extractWorkloadInfo(attrs, scopeName, scopeNamespace)
+
+	return map[string]interface{}{
+		"metadata.name":      getAttr("metadata.name"),
+		"metadata.namespace": getAttr("metadata.namespace"),
+		"scope.name":         scopeName,
+		"scope.namespace":    scopeNamespace,
+		"scope.kind":         getAttr("scope.kind"),
+		"scope.uid":          getAttr("scope.uid"),
+		"scope.apiVersion":   getAttr("scope.apiVersion"),
+		"workload.name":      workloadInfo.name,
+		"workload.kind":      workloadInfo.kind,
+		"workload.namespace": workloadInfo.namespace,
+		"workload.uid":       workloadInfo.uid,
+	}
+}
 
+// ObjectKey returns a key identifying the Kubernetes object scoped by an OpenReports log,
+// so the security events of the same object can share a resource
+func ObjectKey(logRecord *plog.LogRecord) string {
+	attrs := logRecord.Attributes()
+	if uid, exists := attrs.Get("scope.uid"); exists && uid.AsString() != "" {
+		return uid.AsString()
+	}
+
+	key := ""
+	for _, field := range []string{"scope.namespace", "scope.kind", "scope.name"} {
+		if val, exists := attrs.Get(field); exists {
+			key += val.AsString()
+		}
+		key += "/"
+	}
+	return key
+}
+
+// ResourceAttributes sets the Kubernetes fields of the object scoped by an OpenReports log on target
+func ResourceAttributes(logRecord *plog.LogRecord, target pcommon.Map) {
+	attrs := logRecord.Attributes()
+	schema.PutFields(target, k8sFields(attrs, reportMetadata(attrs)))
+}
+
+// ObjectK8sFields returns the k8s.* fields of a Kubernetes object identified by its kind, namespace, name and
+// optional UID, as set on the security events of the OpenReports reports scoping it: the k8s.* attributes of the
+// log record, the namespace, kind and UID of the object, and its workload (the object itself for workloads,
+// derived from the pod name for pods)
+// The object is named by the semantic convention attribute of its kind, e.g. k8s.node.name, or else by
+// k8s.resource.name
+func ObjectK8sFields(attrs pcommon.Map, kind, namespace, name, uid string) map[string]interface{} {
+	return objectK8sFields(attrs, kind, namespace, name, uid, nil)
+}
+
+// PodK8sFields returns the k8s.* fields of a pod as ObjectK8sFields does, with the workload owning it as reported
+// by the source (e.g. kube-proxy, a DaemonSet, for pod kube-proxy-x7k2p); the workload is derived from the pod
+// name when its name or kind is unknown
+func PodK8sFields(attrs pcommon.Map, namespace, name, uid, workload, workloadKind string) map[string]interface{} {
+	if workload == "" || workloadKind == "" {
+		return objectK8sFields(attrs, k8sKindPod, namespace, name, uid, nil)
+	}
+	return objectK8sFields(attrs, k8sKindPod, namespace, name, uid,
+		&workloadInfo{name: workload, kind: workloadKind, namespace: namespace})
+}
+
+// objectK8sFields returns the k8s.* fields of a Kubernetes object, with the workload owning a pod if known
+func objectK8sFields(attrs pcommon.Map, kind, namespace, name, uid string, workload *workloadInfo) map[string]interface{} {
+	if kind == "Namespace" && namespace == "" {
+		namespace = name
+	}
+	metadata := make(map[string]interface{})
+	putNonEmpty := func(key, value string) {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+	putNonEmpty("scope.namespace", namespace)
+	putNonEmpty("scope.kind", kind)
+	putNonEmpty("scope.uid", uid)
+
+	switch {
+	case kind == k8sKindPod:
+		putNonEmpty("scope.name", name)
+		info := workload
+		if info == nil {
+			derived := extractWorkloadInfo(pcommon.NewMap(), name, namespace)
+			info = &derived
+		}
+		putNonEmpty("workload.name", info.name)
+		putNonEmpty("workload.kind", info.kind)
+		putNonEmpty("workload.namespace", info.namespace)
+	case isWorkloadKind(kind):
+		putNonEmpty("workload.name", name)
+		putNonEmpty("workload.kind", kind)
+		putNonEmpty("workload.namespace", namespace)
+	}
+	fields := k8sFields(attrs, metadata)
+
+	// Report scopes are named by k8s.pod.name whatever their kind, which only suits pods
+	if name != "" && kind != k8sKindPod {
+		if attr, ok := semconv.NameAttribute(kind); ok {
+			fields[attr] = name
+		} else {
+			fields[attrResourceName] = name
+		}
+	}
+	return fields
+}
+
+// parsedResult is the outcome of parsing a single report result
+type parsedResult struct {
+	result       Result
+	malformed    bool
+	filterReason string
+}
+
+// parseResult parses the JSON result at index i of a report and applies the status filter
+// It is safe for concurrent use
+func (p *Processor) parseResult(i int, resultJSONStr string, debug bool) parsedResult {
+	if debug {
 		p.logger.Debug("Parsing result",
 			zap.Int("result_index", i),
 			zap.Int("result_length", len(resultJSONStr)))
+	}
 
-		// Parse the result JSON
-		var result Result
-		if err := json.Unmarshal([]byte(resultJSONStr), &result); err != nil {
-			p.logger.Warn("Failed to parse result JSON",
-				zap.Int("result_index", i),
-				zap.String("result_preview", func() string {
-					if len(resultJSONStr) > 200 {
-						return resultJSONStr[:200] + "..."
-					}
-					return resultJSONStr
-				}()),
-				zap.Error(err))
-			continue
-		}
+	// Parse the result JSON
+	var parsed parsedResult
+	if err := json.Unmarshal([]byte(resultJSONStr), &parsed.result); err != nil {
+		p.logger.Warn("Failed to parse result JSON",
+			zap.Int("result_index", i),
+			zap.String("result_preview", func() string {
+				if len(resultJSONStr) > 200 {
+					return resultJSONStr[:200] + "..."
+				}
+				return resultJSONStr
+			}()),
+			zap.Error(err))
+		parsed.malformed = true
+		return parsed
+	}
+	result := &parsed.result
 
+	if debug {
 		p.logger.Debug("Parsed result successfully",
 			zap.Int("result_index", i),
 			zap.String("policy", result.Policy),
 			zap.String("rule", result.Rule),
 			zap.String("result", result.Result),
 			zap.String("source", result.Source))
+	}
 
-		// Filter by status if configured
-		if len(p.config.StatusFilter) > 0 {
-			if !p.isStatusAllowed(result.Result) {
-				p.logger.Debug("Skipping result due to status filter",
-					zap.Int("result_index", i),
-					zap.String("status", result.Result),
-					zap.String("policy", result.Policy),
-					zap.String("rule", result.Rule),
-					zap.Strings("allowed_statuses", p.config.StatusFilter))
-				filteredCount++
-				continue
-			}
+	// Filter by status if configured
+	if len(p.config.StatusFilter) > 0 && !p.isStatusAllowed(result.Result) {
+		if debug {
+			p.logger.Debug("Skipping result due to status filter",
+				zap.Int("result_index", i),
+				zap.String("status", result.Result),
+				zap.String("policy", result.Policy),
+				zap.String("rule", result.Rule),
+				zap.Strings("allowed_statuses", p.config.StatusFilter))
 		}
+		parsed.filterReason = FilterReasonStatus
+		return parsed
+	}
 
+	if debug {
 		p.logger.Debug("Result passed status filter, creating security event",
 			zap.Int("result_index", i),
 			zap.String("status", result.Result),
 			zap.String("policy", result.Policy),
 			zap.String("rule", result.Rule))
+	}
+	return parsed
+}
 
-		// Create a new log record for this result
-		newRecord := plog.NewLogRecord()
-
-		// Copy basic fields from original
-		newRecord.SetTimestamp(timestamp)
-		newRecord.SetObservedTimestamp(logRecord.ObservedTimestamp())
-		newRecord.SetSeverityNumber(logRecord.SeverityNumber())
-		newRecord.SetSeverityText(logRecord.SeverityText())
-		newRecord.SetTraceID(logRecord.TraceID())
-		newRecord.SetSpanID(logRecord.SpanID())
-		newRecord.SetFlags(logRecord.Flags())
-
-		// Transform the result into a security event
-		p.transformToSecurityEvent(&newRecord, result, map[string]interface{}{
-			"metadata.name":      metadataNameStr,
-			"metadata.namespace": metadataNamespaceStr,
-			"scope.name":         scopeNameStr,
-			"scope.namespace":    scopeNamespaceStr,
-			"scope.kind":         scopeKindStr,
-			"scope.uid":          scopeUIDStr,
-			"scope.apiVersion":   scopeAPIVersionStr,
-			"workload.name":      workloadInfo.name,
-			"workload.kind":      workloadInfo.kind,
-			"workload.namespace": workloadInfo.namespace,
-			"workload.uid":       workloadInfo.uid,
-		}, attrs)
-
-		newRecords = append(newRecords, newRecord)
-		processedCount++
-	}
-
-	p.logger.Info("OpenReports log processing completed",
-		zap.Int("original_logs", 1),
-		zap.Int("total_results", len(resultsArray)),
-		zap.Int("processed_results", processedCount),
-		zap.Int("filtered_results", filteredCount),
-		zap.Int("security_events_created", len(newRecords)),
-		zap.String("metadata.name", metadataNameStr),
-		zap.String("scope.name", scopeNameStr))
-
-	p.logger.Debug("OpenReports log transformation summary",
-		zap.Int("original_logs", 1),
-		zap.Int("total_results", len(resultsArray)),
-		zap.Int("processed_results", processedCount),
-		zap.Int("filtered_results", filteredCount),
-		zap.Int("security_events_created", len(newRecords)),
-		zap.Bool("status_filter_enabled", len(p.config.StatusFilter) > 0),
-		zap.String("metadata.name", metadataNameStr))
-
-	return newRecords, nil
+// checkIDs returns the scanner check identifiers found in the result properties
+func (r *Result) checkIDs() []string {
+	var ids []string
+	for _, key := range checkIDPropertyKeys {
+		if id, ok := r.Properties[key].(string); ok && id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
 }
 
-// Result represents a single result from the OpenReports results array
-type Result struct {
-	Source     string                 `json:"source"`
-	Timestamp  Timestamp              `json:"timestamp"`
-	Message    string                 `json:"message"`
-	Policy     string                 `json:"policy"`
-	Properties map[string]interface{} `json:"properties"`
-	Result     string                 `json:"result"` // pass, fail, error, skip
-	Rule       string                 `json:"rule"`
-	Scored     bool                   `json:"scored"`
-	Severity   string                 `json:"severity,omitempty"`
-	Category   string                 `json:"category,omitempty"`
+// vulnerabilityID returns the vulnerability identifier found in the result properties
+func (r *Result) vulnerabilityID() string {
+	if id, ok := r.Properties["vulnerabilityID"].(string); ok {
+		return id
+	}
+	return ""
 }
 
 // Timestamp represents the timestamp in the result
@@ -340,20 +596,39 @@ type Timestamp struct {
 }
 
 // transformToSecurityEvent transforms a result into a security event log record
-//
-//nolint:gocyclo // Complex field mapping with multiple conditional branches for schema transformation
-func (p *Processor) transformToSecurityEvent(logRecord *plog.LogRecord, result Result, metadata map[string]interface{}, originalAttrs pcommon.Map) {
-	attrs := logRecord.Attributes()
+// It is safe for concurrent use on distinct log records
+func (p *Processor) transformToSecurityEvent(logRecord *plog.LogRecord, result Result, metadata map[string]interface{}, k8s map[string]interface{}) error {
+	event := p.buildSecurityEvent(result, metadata, k8s)
 
-	// Generate event ID
-	eventID := uuid.New().String()
-	attrs.PutStr("event.id", eventID)
+	// The record timestamp is the time the result was produced
+	if result.Timestamp.Seconds > 0 {
+		logRecord.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(result.Timestamp.Seconds, result.Timestamp.Nanos)))
+	}
+	return p.output.Write(*logRecord, event)
+}
 
-	// Hardcoded event fields
-	attrs.PutStr("event.version", "1.309")
-	attrs.PutStr("event.category", "COMPLIANCE")
-	attrs.PutStr("event.name", "Compliance finding event")
-	attrs.PutStr("event.type", "COMPLIANCE_FINDING")
+// buildSecurityEvent builds the security event of a result
+// The k8s fields are shared by all the events of a report and must not be modified
+//
+//nolint:gocyclo // Complex field mapping with multiple conditional branches for schema transformation
+func (p *Processor) buildSecurityEvent(result Result, metadata map[string]interface{}, k8s map[string]interface{}) *schema.SecurityEvent {
+	event := &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event: schema.Event{
+			ID:       uuid.New().String(),
+			Version:  processing.EventVersion,
+			Category: "COMPLIANCE",
+			Name:     "Compliance finding event",
+			Type:     "COMPLIANCE_FINDING",
+		},
+		Message: result.Message,
+		Action: schema.Action{
+			Type: "policy_evaluation",
+		},
+		Result: schema.Result{
+			Status: result.Result,
+		},
+	}
 
 	// Event description: "Policy violation on <pod> for rule <rule>" or appropriate message based on result
 	scopeName := getString(metadata, "scope.name")
@@ -362,101 +637,106 @@ func (p *Processor) transformToSecurityEvent(logRecord *plog.LogRecord, result R
 		rule = "unknown"
 	}
 
-	var eventDescription string
 	switch result.Result {
 	case resultStatusFail:
-		eventDescription = fmt.Sprintf("Policy violation on %s for rule %s", scopeName, rule)
+		event.Event.Description = fmt.Sprintf("Policy violation on %s for rule %s", scopeName, rule)
 	case resultStatusPass:
-		eventDescription = fmt.Sprintf("Policy check passed on %s for rule %s", scopeName, rule)
+		event.Event.Description = fmt.Sprintf("Policy check passed on %s for rule %s", scopeName, rule)
 	case resultStatusError:
-		eventDescription = fmt.Sprintf("Policy check error on %s for rule %s", scopeName, rule)
+		event.Event.Description = fmt.Sprintf("Policy check error on %s for rule %s", scopeName, rule)
 	case resultStatusSkip:
-		eventDescription = fmt.Sprintf("Policy check skipped on %s for rule %s", scopeName, rule)
+		event.Event.Description = fmt.Sprintf("Policy check skipped on %s for rule %s", scopeName, rule)
 	default:
-		eventDescription = fmt.Sprintf("Policy evaluation on %s for rule %s", scopeName, rule)
+		event.Event.Description = fmt.Sprintf("Policy evaluation on %s for rule %s", scopeName, rule)
 	}
-	attrs.PutStr("event.description", eventDescription)
+	event.Action.Description = event.Event.Description
 
-	// Product fields (empty for now)
-	attrs.PutStr("product.name", "")
-	attrs.PutStr("product.vendor", "")
+	// Target: the scoped Kubernetes object
+	scopeKind := getString(metadata, "scope.kind")
+	event.Target = schema.Target{
+		ID:           getString(metadata, "scope.uid"),
+		Resource:     scopeName,
+		ResourceType: scopeKind,
+		Kubernetes:   k8s,
+	}
 
 	// Smartscape type - K8S_POD if scope.kind is Pod
-	scopeKind := getString(metadata, "scope.kind")
 	if scopeKind == k8sKindPod {
-		attrs.PutStr("smartscape.type", "K8S_POD")
+		event.Target.EntityType = "K8S_POD"
 	}
 
 	// Calculate risk score based on severity (for dt.security.risk.score)
-	// Note: finding.severity is set later from result.Severity
-	riskScore := calculateRiskScoreFromSeverity(result.Severity)
-	attrs.PutDouble("dt.security.risk.score", riskScore)
-
-	// Object fields
-	scopeUID := getString(metadata, "scope.uid")
-	if scopeUID != "" {
-		attrs.PutStr("object.id", scopeUID)
-	}
-	if scopeKind != "" {
-		attrs.PutStr("object.type", scopeKind)
+	event.RiskScore = processing.RiskScore(result.Severity)
+
+	// Exploitation intelligence for vulnerability findings (e.g. Trivy reports keyed by CVE)
+	if p.vulnIntel != nil {
+		if cveID := vulnintel.ExtractCVEID(result.Policy, result.Rule, result.vulnerabilityID()); cveID != "" {
+			event.Vulnerability = &schema.Vulnerability{ID: cveID}
+			if intel, ok := p.vulnIntel.Lookup(cveID); ok {
+				if intel.HasEPSS {
+					event.Vulnerability.EPSS = &schema.EPSS{Score: intel.EPSS.Score, Percentile: intel.EPSS.Percentile}
+				}
+				event.Vulnerability.KEV = intel.KEV
+				if p.vulnIntel.AdjustRiskScores() {
+					event.RiskScore = vulnintel.AdjustRiskScore(event.RiskScore, intel)
+				}
+			}
+		}
 	}
 
 	// Finding fields
-	attrs.PutStr("finding.description", result.Message)
-	findingID := uuid.New().String()
-	attrs.PutStr("finding.id", findingID)
+	event.Finding = schema.Finding{
+		ID:          uuid.New().String(),
+		Description: result.Message,
+		Type:        result.Policy,
+	}
 
 	// Map severity to uppercase: CRITICAL, HIGH, MEDIUM, LOW
 	if result.Severity != "" {
-		severity := mapSeverityToUppercase(result.Severity)
-		attrs.PutStr("finding.severity", severity)
+		event.Finding.Severity = mapSeverityToUppercase(result.Severity)
 	}
 
 	// Finding time.created from result timestamp
 	if result.Timestamp.Seconds > 0 {
-		resultTime := time.Unix(result.Timestamp.Seconds, result.Timestamp.Nanos)
-		logRecord.SetTimestamp(pcommon.NewTimestampFromTime(resultTime))
-		// Also store as finding.time.created
-		attrs.PutStr("finding.time.created", resultTime.Format(time.RFC3339Nano))
+		event.Timestamp = time.Unix(result.Timestamp.Seconds, result.Timestamp.Nanos).Format(time.RFC3339Nano)
 	}
 
 	// Finding title: policy + rule
-	findingTitle := result.Policy
+	event.Finding.Title = result.Policy
 	if result.Rule != "" {
-		findingTitle = fmt.Sprintf("%s - %s", result.Policy, result.Rule)
+		event.Finding.Title = fmt.Sprintf("%s - %s", result.Policy, result.Rule)
 	}
-	attrs.PutStr("finding.title", findingTitle)
 
-	// Finding type is the policy
-	if result.Policy != "" {
-		attrs.PutStr("finding.type", result.Policy)
+	// Compliance fields; the category, if available, is the standard
+	event.Compliance = schema.Compliance{
+		Control:     result.Rule,
+		Requirement: result.Policy,
+		Standard:    result.Category,
+		Status:      mapResultToComplianceStatus(result.Result),
 	}
 
-	// Finding URL (empty for now)
-	attrs.PutStr("finding.url", "")
-
-	// Compliance fields
-	if result.Rule != "" {
-		attrs.PutStr("compliance.control", result.Rule)
-	}
-	if result.Policy != "" {
-		attrs.PutStr("compliance.requirements", result.Policy)
-	}
-	// compliance.standards can be omitted or hardcoded
-	// For now, we'll omit it or use category if available
-	if result.Category != "" {
-		attrs.PutStr("compliance.standards", result.Category)
+	// Framework controls from the compliance catalog override the policy/category defaults
+	if controls := p.catalog.Lookup(result.Policy, result.Rule, result.checkIDs()...); len(controls) > 0 {
+		event.Compliance.Standards = compliance.Standards(controls)
+		event.Compliance.Requirements = compliance.Requirements(controls)
 	}
 
-	// Map result.result to compliance.status
-	complianceStatus := mapResultToComplianceStatus(result.Result)
-	attrs.PutStr("compliance.status", complianceStatus)
+	// MITRE ATT&CK technique tagging
+	if techniques := p.techniques.Lookup(result.Policy, result.Rule, result.Category); len(techniques) > 0 {
+		event.Threat = &schema.Threat{
+			Framework:      attack.Framework,
+			TechniqueIDs:   attack.IDs(techniques),
+			TechniqueNames: attack.Names(techniques),
+			TacticNames:    attack.Tactics(techniques),
+		}
+	}
 
-	// Copy all k8s.* fields from original log
-	copyK8sFields(attrs, originalAttrs, metadata)
+	// Image signature and attestation verifications are supply chain findings
+	if result.isImageVerification() {
+		setSupplyChain(event, &result, scopeName, rule)
+	}
 
-	// Set the log body/content to the security event message
-	logRecord.Body().SetStr(result.Message)
+	return event
 }
 
 // mapSeverityToUppercase maps finding severity to uppercase format
@@ -476,22 +756,6 @@ func mapSeverityToUppercase(severity string) string {
 	}
 }
 
-// calculateRiskScoreFromSeverity calculates the risk score based on finding severity
-func calculateRiskScoreFromSeverity(severity string) float64 {
-	switch severity {
-	case "critical":
-		return 10.0
-	case "high":
-		return 8.9
-	case "medium":
-		return 6.9
-	case "low":
-		return 3.9
-	default:
-		return 0.0
-	}
-}
-
 // mapResultToComplianceStatus maps result.result to compliance.status
 // Returns COMPLIANT for pass, NON_COMPLIANT for all other cases (fail, error, skip, unknown)
 func mapResultToComplianceStatus(result string) string {
@@ -619,75 +883,63 @@ func splitPodName(podName string) []string {
 	return parts
 }
 
-// copyK8sFields copies all k8s.* fields from the original attributes
+// k8sFields collects the k8s.* fields of the original attributes and of the report metadata
+// The values are raw attribute values, as held by schema.Target.Kubernetes
 //
 //nolint:gocyclo // Complex field copying with multiple conditional branches for K8s attribute mapping
-func copyK8sFields(targetAttrs pcommon.Map, originalAttrs pcommon.Map, metadata map[string]interface{}) {
+func k8sFields(originalAttrs pcommon.Map, metadata map[string]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+
 	// Copy k8s.* fields from original attributes
 	originalAttrs.Range(func(key string, value pcommon.Value) bool {
 		if len(key) > 4 && key[:4] == "k8s." {
-			copyValue(targetAttrs, key, value)
+			switch value.Type() {
+			case pcommon.ValueTypeStr, pcommon.ValueTypeInt, pcommon.ValueTypeDouble, pcommon.ValueTypeBool,
+				pcommon.ValueTypeSlice, pcommon.ValueTypeMap:
+				fields[key] = value.AsRaw()
+			}
 		}
 		return true
 	})
 
 	// Also add k8s fields from metadata if available
 	if scopeName, ok := metadata["scope.name"]; ok {
-		targetAttrs.PutStr("k8s.pod.name", fmt.Sprintf("%v", scopeName))
+		fields["k8s.pod.name"] = fmt.Sprintf("%v", scopeName)
 	}
 	if scopeNamespace, ok := metadata["scope.namespace"]; ok {
-		targetAttrs.PutStr("k8s.namespace.name", fmt.Sprintf("%v", scopeNamespace))
+		fields["k8s.namespace.name"] = fmt.Sprintf("%v", scopeNamespace)
 	}
 	if scopeKind, ok := metadata["scope.kind"]; ok {
 		kindStr := fmt.Sprintf("%v", scopeKind)
-		targetAttrs.PutStr("k8s.resource.kind", kindStr)
+		fields["k8s.resource.kind"] = kindStr
 		if kindStr == k8sKindPod {
-			targetAttrs.PutStr("k8s.pod.name", getString(metadata, "scope.name"))
+			fields["k8s.pod.name"] = getString(metadata, "scope.name")
 		}
 	}
 	if scopeUID, ok := metadata["scope.uid"]; ok {
-		targetAttrs.PutStr("k8s.resource.uid", fmt.Sprintf("%v", scopeUID))
+		fields["k8s.resource.uid"] = fmt.Sprintf("%v", scopeUID)
 	}
 
 	// Add workload fields
 	if workloadName, ok := metadata["workload.name"]; ok && workloadName != "" {
 		workloadKind := getString(metadata, "workload.kind")
 		if workloadKind == k8sKindDeployment {
-			targetAttrs.PutStr("k8s.deployment.name", fmt.Sprintf("%v", workloadName))
+			fields["k8s.deployment.name"] = fmt.Sprintf("%v", workloadName)
 		} else if workloadKind == "StatefulSet" {
-			targetAttrs.PutStr("k8s.statefulset.name", fmt.Sprintf("%v", workloadName))
+			fields["k8s.statefulset.name"] = fmt.Sprintf("%v", workloadName)
 		} else if workloadKind == "DaemonSet" {
-			targetAttrs.PutStr("k8s.daemonset.name", fmt.Sprintf("%v", workloadName))
+			fields["k8s.daemonset.name"] = fmt.Sprintf("%v", workloadName)
 		}
-		targetAttrs.PutStr("k8s.workload.name", fmt.Sprintf("%v", workloadName))
-		targetAttrs.PutStr("k8s.workload.kind", workloadKind)
+		fields["k8s.workload.name"] = fmt.Sprintf("%v", workloadName)
+		fields["k8s.workload.kind"] = workloadKind
 	}
 	if workloadNamespace, ok := metadata["workload.namespace"]; ok && workloadNamespace != "" {
-		targetAttrs.PutStr("k8s.workload.namespace", fmt.Sprintf("%v", workloadNamespace))
+		fields["k8s.workload.namespace"] = fmt.Sprintf("%v", workloadNamespace)
 	}
 	if workloadUID, ok := metadata["workload.uid"]; ok && workloadUID != "" {
-		targetAttrs.PutStr("k8s.workload.uid", fmt.Sprintf("%v", workloadUID))
-	}
-}
-
-// copyValue copies a pcommon.Value to the target map
-func copyValue(target pcommon.Map, key string, value pcommon.Value) {
-	switch value.Type() {
-	case pcommon.ValueTypeStr:
-		target.PutStr(key, value.Str())
-	case pcommon.ValueTypeInt:
-		target.PutInt(key, value.Int())
-	case pcommon.ValueTypeDouble:
-		target.PutDouble(key, value.Double())
-	case pcommon.ValueTypeBool:
-		target.PutBool(key, value.Bool())
-	case pcommon.ValueTypeSlice:
-		slice := target.PutEmptySlice(key)
-		value.Slice().CopyTo(slice)
-	case pcommon.ValueTypeMap:
-		m := target.PutEmptyMap(key)
-		value.Map().CopyTo(m)
+		fields["k8s.workload.uid"] = fmt.Sprintf("%v", workloadUID)
 	}
+	return fields
 }
 
 // getString safely gets a string value from metadata
@@ -2,6 +2,12 @@ package openreports
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -9,9 +15,31 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/attack"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/cef"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/semconv"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/vulnintel"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
 )
 
+// processLogRecord runs ProcessLogRecord into a fresh slice and returns the security events created
+func processLogRecord(
+	processor *Processor, logRecord *plog.LogRecord, resource pcommon.Resource, scopeLogs plog.ScopeLogs,
+) ([]plog.LogRecord, processing.Outcome, error) {
+	dst := plog.NewLogRecordSlice()
+	outcome, err := processor.ProcessLogRecord(context.Background(), logRecord, resource, scopeLogs, dst)
+	var records []plog.LogRecord
+	for i := 0; i < dst.Len(); i++ {
+		records = append(records, dst.At(i))
+	}
+	return records, outcome, err
+}
+
 func TestProcessLogRecord_NotOpenReportsLog(t *testing.T) {
 	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
 	require.NoError(t, err)
@@ -24,7 +52,7 @@ func TestProcessLogRecord_NotOpenReportsLog(t *testing.T) {
 	resource := pcommon.NewResource()
 	scopeLogs := plog.NewScopeLogs()
 
-	records, err := processor.ProcessLogRecord(context.Background(), &logRecord, resource, scopeLogs)
+	records, _, err := processLogRecord(processor, &logRecord, resource, scopeLogs)
 	assert.NoError(t, err)
 	assert.Nil(t, records, "Should return nil for non-OpenReports logs")
 }
@@ -42,7 +70,7 @@ func TestProcessLogRecord_OpenReportsLog_NoResults(t *testing.T) {
 	resource := pcommon.NewResource()
 	scopeLogs := plog.NewScopeLogs()
 
-	records, err := processor.ProcessLogRecord(context.Background(), &logRecord, resource, scopeLogs)
+	records, _, err := processLogRecord(processor, &logRecord, resource, scopeLogs)
 	assert.NoError(t, err)
 	assert.Nil(t, records, "Should return nil when no results")
 }
@@ -61,7 +89,7 @@ func TestProcessLogRecord_OpenReportsLog_EmptyResults(t *testing.T) {
 	resource := pcommon.NewResource()
 	scopeLogs := plog.NewScopeLogs()
 
-	records, err := processor.ProcessLogRecord(context.Background(), &logRecord, resource, scopeLogs)
+	records, _, err := processLogRecord(processor, &logRecord, resource, scopeLogs)
 	assert.NoError(t, err)
 	assert.Nil(t, records, "Should return nil when results are empty")
 	_ = resultsSlice // Suppress unused warning
@@ -99,7 +127,7 @@ func TestProcessLogRecord_OpenReportsLog_SingleResult(t *testing.T) {
 	resource := pcommon.NewResource()
 	scopeLogs := plog.NewScopeLogs()
 
-	records, err := processor.ProcessLogRecord(context.Background(), &logRecord, resource, scopeLogs)
+	records, _, err := processLogRecord(processor, &logRecord, resource, scopeLogs)
 	assert.NoError(t, err)
 	require.NotNil(t, records)
 	assert.Len(t, records, 1, "Should create one security event")
@@ -169,7 +197,7 @@ func TestProcessLogRecord_OpenReportsLog_MultipleResults(t *testing.T) {
 	resource := pcommon.NewResource()
 	scopeLogs := plog.NewScopeLogs()
 
-	records, err := processor.ProcessLogRecord(context.Background(), &logRecord, resource, scopeLogs)
+	records, _, err := processLogRecord(processor, &logRecord, resource, scopeLogs)
 	assert.NoError(t, err)
 	require.NotNil(t, records)
 	assert.Len(t, records, 3, "Should create three security events")
@@ -241,7 +269,7 @@ func TestProcessLogRecord_StatusFilter_OnlyFailures(t *testing.T) {
 	resource := pcommon.NewResource()
 	scopeLogs := plog.NewScopeLogs()
 
-	records, err := processor.ProcessLogRecord(context.Background(), &logRecord, resource, scopeLogs)
+	records, _, err := processLogRecord(processor, &logRecord, resource, scopeLogs)
 	assert.NoError(t, err)
 	require.NotNil(t, records)
 	assert.Len(t, records, 1, "Should only create security event for fail status")
@@ -281,7 +309,7 @@ func TestProcessLogRecord_StatusFilter_MultipleStatuses(t *testing.T) {
 	resource := pcommon.NewResource()
 	scopeLogs := plog.NewScopeLogs()
 
-	records, err := processor.ProcessLogRecord(context.Background(), &logRecord, resource, scopeLogs)
+	records, _, err := processLogRecord(processor, &logRecord, resource, scopeLogs)
 	assert.NoError(t, err)
 	require.NotNil(t, records)
 	assert.Len(t, records, 2, "Should create security events for fail and error only")
@@ -314,7 +342,7 @@ func TestProcessLogRecord_StatusFilter_Empty(t *testing.T) {
 	resource := pcommon.NewResource()
 	scopeLogs := plog.NewScopeLogs()
 
-	records, err := processor.ProcessLogRecord(context.Background(), &logRecord, resource, scopeLogs)
+	records, _, err := processLogRecord(processor, &logRecord, resource, scopeLogs)
 	assert.NoError(t, err)
 	require.NotNil(t, records)
 	assert.Len(t, records, 2, "Empty filter should process all statuses")
@@ -348,7 +376,7 @@ func TestTransformToSecurityEvent_FieldMapping(t *testing.T) {
 		"scope.uid":       "pod-uid-123",
 	}
 
-	processor.transformToSecurityEvent(&logRecord, result, metadata, originalAttrs)
+	require.NoError(t, processor.transformToSecurityEvent(&logRecord, result, metadata, k8sFields(originalAttrs, metadata)))
 
 	attrs := logRecord.Attributes()
 
@@ -358,6 +386,9 @@ func TestTransformToSecurityEvent_FieldMapping(t *testing.T) {
 	assert.Equal(t, "COMPLIANCE", attrs.AsRaw()["event.category"])
 	assert.Equal(t, "NON_COMPLIANT", attrs.AsRaw()["compliance.status"]) // fail -> NON_COMPLIANT
 	assert.Contains(t, attrs.AsRaw()["event.description"], "Policy violation")
+	// The policy evaluation and its result are only kept in the model, as in the original layout
+	assert.NotContains(t, attrs.AsRaw(), "action.type")
+	assert.NotContains(t, attrs.AsRaw(), "result.status")
 
 	// Verify finding fields
 	assert.Equal(t, result.Message, attrs.AsRaw()["finding.description"])
@@ -384,6 +415,52 @@ func TestTransformToSecurityEvent_FieldMapping(t *testing.T) {
 	assert.Equal(t, "test-pod-123", attrs.AsRaw()["k8s.pod.name"])
 }
 
+func TestBuildSecurityEvent(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	result := Result{
+		Timestamp: Timestamp{Seconds: 1758264662},
+		Message:   "validation error: CPU and memory limits are required",
+		Policy:    "require-requests-limits",
+		Result:    "fail",
+		Rule:      "validate-resources",
+		Severity:  "high",
+	}
+	metadata := map[string]interface{}{
+		"scope.name": "app-1",
+		"scope.kind": "Pod",
+		"scope.uid":  "pod-uid-1",
+	}
+	k8s := map[string]interface{}{"k8s.pod.name": "app-1"}
+
+	event := processor.buildSecurityEvent(result, metadata, k8s)
+
+	assert.Equal(t, schema.Version, event.SchemaVersion)
+	assert.Equal(t, "COMPLIANCE_FINDING", event.Event.Type)
+	assert.Equal(t, "Policy violation on app-1 for rule validate-resources", event.Event.Description)
+	assert.Equal(t, result.Message, event.Message)
+	assert.Equal(t, schema.Target{
+		ID:           "pod-uid-1",
+		Resource:     "app-1",
+		ResourceType: "Pod",
+		EntityType:   "K8S_POD",
+		Kubernetes:   k8s,
+	}, event.Target)
+	assert.Equal(t, "fail", event.Result.Status)
+	assert.Equal(t, 8.9, event.RiskScore)
+	assert.Equal(t, "HIGH", event.Finding.Severity)
+	assert.Equal(t, "require-requests-limits - validate-resources", event.Finding.Title)
+	assert.Equal(t, time.Unix(1758264662, 0).Format(time.RFC3339Nano), event.Timestamp)
+	assert.Equal(t, schema.Compliance{
+		Control:     "validate-resources",
+		Requirement: "require-requests-limits",
+		Status:      "NON_COMPLIANT",
+	}, event.Compliance)
+	assert.Nil(t, event.Vulnerability)
+	assert.Nil(t, event.Threat)
+}
+
 func TestFindingSeverity(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -407,7 +484,7 @@ func TestFindingSeverity(t *testing.T) {
 			logRecord := plog.NewLogRecord()
 			metadata := map[string]interface{}{"scope.name": "test"}
 
-			processor.transformToSecurityEvent(&logRecord, result, metadata, pcommon.NewMap())
+			require.NoError(t, processor.transformToSecurityEvent(&logRecord, result, metadata, k8sFields(pcommon.NewMap(), metadata)))
 			severity := logRecord.Attributes().AsRaw()["finding.severity"]
 			if tt.severity == "" {
 				// If severity is empty, the field should not be set
@@ -419,27 +496,6 @@ func TestFindingSeverity(t *testing.T) {
 	}
 }
 
-func TestCalculateRiskScoreFromSeverity(t *testing.T) {
-	tests := []struct {
-		severity string
-		expected float64
-	}{
-		{"critical", 10.0},
-		{"high", 8.9},
-		{"medium", 6.9},
-		{"low", 3.9},
-		{"unknown", 0.0},
-		{"", 0.0},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.severity, func(t *testing.T) {
-			score := calculateRiskScoreFromSeverity(tt.severity)
-			assert.Equal(t, tt.expected, score)
-		})
-	}
-}
-
 func TestMapResultToComplianceStatus(t *testing.T) {
 	tests := []struct {
 		result   string
@@ -574,7 +630,7 @@ func TestProcessLogRecord_InvalidJSON(t *testing.T) {
 	resource := pcommon.NewResource()
 	scopeLogs := plog.NewScopeLogs()
 
-	records, err := processor.ProcessLogRecord(context.Background(), &logRecord, resource, scopeLogs)
+	records, _, err := processLogRecord(processor, &logRecord, resource, scopeLogs)
 	assert.NoError(t, err)
 	require.NotNil(t, records)
 	assert.Len(t, records, 1, "Should only process valid JSON results")
@@ -610,7 +666,7 @@ func TestProcessLogRecord_WithSeverityAndCategory(t *testing.T) {
 	resource := pcommon.NewResource()
 	scopeLogs := plog.NewScopeLogs()
 
-	records, err := processor.ProcessLogRecord(context.Background(), &logRecord, resource, scopeLogs)
+	records, _, err := processLogRecord(processor, &logRecord, resource, scopeLogs)
 	assert.NoError(t, err)
 	require.NotNil(t, records)
 	assert.Len(t, records, 1)
@@ -651,7 +707,7 @@ func TestProcessLogRecord_TimestampMapping(t *testing.T) {
 	resource := pcommon.NewResource()
 	scopeLogs := plog.NewScopeLogs()
 
-	records, err := processor.ProcessLogRecord(context.Background(), &logRecord, resource, scopeLogs)
+	records, _, err := processLogRecord(processor, &logRecord, resource, scopeLogs)
 	assert.NoError(t, err)
 	require.NotNil(t, records)
 	assert.Len(t, records, 1)
@@ -666,3 +722,648 @@ func TestProcessLogRecord_TimestampMapping(t *testing.T) {
 	assert.NotEmpty(t, createdTime)
 	assert.Contains(t, createdTime.(string), "2025-09-19") // Approximate date check
 }
+
+func TestProcessLogRecord_ComplianceCatalog(t *testing.T) {
+	catalog, err := compliance.ParseCatalog([]byte(`
+mappings:
+  - policy: disallow-privileged-containers
+    controls:
+      - framework: CIS
+        id: "5.2.2"
+      - framework: NIST 800-53
+        id: AC-6
+  - check_id: KSV017
+    controls:
+      - framework: PCI-DSS
+        id: "2.2.5"
+`))
+	require.NoError(t, err)
+
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithComplianceCatalog(catalog))
+	require.NoError(t, err)
+
+	logRecord := plog.NewLogRecord()
+	attrs := logRecord.Attributes()
+	attrs.PutStr("kind", "Report")
+	attrs.PutStr("apiVersion", "openreports.io/v1alpha1")
+	attrs.PutStr("scope.name", "test-pod")
+	attrs.PutStr("scope.kind", "Pod")
+
+	resultsSlice := attrs.PutEmptySlice("results")
+	resultsSlice.AppendEmpty().SetStr(`{
+		"source": "kyverno",
+		"message": "Privileged mode is disallowed",
+		"policy": "disallow-privileged-containers",
+		"result": "fail",
+		"rule": "privileged-containers",
+		"category": "Pod Security Standards (Baseline)"
+	}`)
+	resultsSlice.AppendEmpty().SetStr(`{
+		"source": "Trivy",
+		"message": "Container should not be privileged",
+		"policy": "Privileged",
+		"result": "fail",
+		"rule": "Privileged",
+		"properties": {"id": "KSV017"}
+	}`)
+	resultsSlice.AppendEmpty().SetStr(`{
+		"source": "kyverno",
+		"message": "Unmapped policy",
+		"policy": "unmapped-policy",
+		"result": "fail",
+		"rule": "unmapped-rule"
+	}`)
+
+	records, _, err := processLogRecord(processor, &logRecord, pcommon.NewResource(), plog.NewScopeLogs())
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	kyverno := records[0].Attributes().AsRaw()
+	assert.Equal(t, []any{"CIS", "NIST 800-53"}, kyverno["compliance.standards"])
+	assert.Equal(t, []any{"CIS 5.2.2", "NIST 800-53 AC-6"}, kyverno["compliance.requirements"])
+	assert.Equal(t, "privileged-containers", kyverno["compliance.control"])
+
+	trivy := records[1].Attributes().AsRaw()
+	assert.Equal(t, []any{"PCI-DSS"}, trivy["compliance.standards"])
+	assert.Equal(t, []any{"PCI-DSS 2.2.5"}, trivy["compliance.requirements"])
+
+	// Unmapped findings keep the policy name as requirement
+	unmapped := records[2].Attributes().AsRaw()
+	assert.Equal(t, "unmapped-policy", unmapped["compliance.requirements"])
+	assert.NotContains(t, unmapped, "compliance.standards")
+}
+
+func TestProcessLogRecord_TechniqueMapping(t *testing.T) {
+	mapping, err := attack.NewMapping(&attack.Config{Enabled: true})
+	require.NoError(t, err)
+
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithTechniqueMapping(mapping))
+	require.NoError(t, err)
+
+	logRecord := plog.NewLogRecord()
+	attrs := logRecord.Attributes()
+	attrs.PutStr("kind", "Report")
+	attrs.PutStr("apiVersion", "openreports.io/v1alpha1")
+	attrs.PutStr("scope.name", "test-pod")
+	attrs.PutStr("scope.kind", "Pod")
+
+	resultsSlice := attrs.PutEmptySlice("results")
+	resultsSlice.AppendEmpty().SetStr(`{
+		"source": "kyverno",
+		"message": "Use of host path volumes is disallowed",
+		"policy": "disallow-host-path",
+		"result": "fail",
+		"rule": "host-path"
+	}`)
+	resultsSlice.AppendEmpty().SetStr(`{
+		"source": "kyverno",
+		"message": "Unmapped policy",
+		"policy": "unmapped-policy",
+		"result": "fail",
+		"rule": "unmapped-rule"
+	}`)
+
+	records, _, err := processLogRecord(processor, &logRecord, pcommon.NewResource(), plog.NewScopeLogs())
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	mapped := records[0].Attributes().AsRaw()
+	assert.Equal(t, "MITRE ATT&CK", mapped["threat.framework"])
+	assert.Equal(t, []any{"T1611"}, mapped["threat.technique.id"])
+	assert.Equal(t, []any{"Escape to Host"}, mapped["threat.technique.name"])
+	assert.Equal(t, []any{"Privilege Escalation"}, mapped["threat.tactic.name"])
+
+	unmapped := records[1].Attributes().AsRaw()
+	assert.NotContains(t, unmapped, "threat.framework")
+	assert.NotContains(t, unmapped, "threat.technique.id")
+}
+
+func TestProcessLogRecord_VulnerabilityIntel(t *testing.T) {
+	store, err := vulnintel.NewStore(zaptest.NewLogger(t), &vulnintel.Config{
+		EPSSFile:        filepath.Join("..", "vulnintel", "testdata", "epss.csv"),
+		KEVFile:         filepath.Join("..", "vulnintel", "testdata", "kev.json"),
+		AdjustRiskScore: true,
+	})
+	require.NoError(t, err)
+
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithVulnerabilityIntel(store))
+	require.NoError(t, err)
+
+	logRecord := plog.NewLogRecord()
+	attrs := logRecord.Attributes()
+	attrs.PutStr("kind", "Report")
+	attrs.PutStr("apiVersion", "openreports.io/v1alpha1")
+	attrs.PutStr("scope.name", "test-pod")
+	attrs.PutStr("scope.kind", "Pod")
+
+	resultsSlice := attrs.PutEmptySlice("results")
+	resultsSlice.AppendEmpty().SetStr(`{
+		"source": "Trivy",
+		"message": "log4j-core: Remote code injection in Log4j",
+		"policy": "CVE-2021-44228",
+		"result": "fail",
+		"rule": "org.apache.logging.log4j:log4j-core",
+		"severity": "medium"
+	}`)
+	resultsSlice.AppendEmpty().SetStr(`{
+		"source": "Trivy",
+		"message": "low risk vulnerability",
+		"policy": "Vulnerability",
+		"result": "fail",
+		"rule": "libfoo",
+		"severity": "low",
+		"properties": {"vulnerabilityID": "CVE-2024-0001"}
+	}`)
+	resultsSlice.AppendEmpty().SetStr(`{
+		"source": "kyverno",
+		"message": "not a vulnerability",
+		"policy": "disallow-host-path",
+		"result": "fail",
+		"rule": "host-path",
+		"severity": "medium"
+	}`)
+
+	records, _, err := processLogRecord(processor, &logRecord, pcommon.NewResource(), plog.NewScopeLogs())
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	kev := records[0].Attributes().AsRaw()
+	assert.Equal(t, "CVE-2021-44228", kev["vulnerability.id"])
+	assert.InDelta(t, 0.94358, kev["vulnerability.epss.score"], 1e-9)
+	assert.InDelta(t, 0.99982, kev["vulnerability.epss.percentile"], 1e-9)
+	assert.Equal(t, true, kev["vulnerability.kev"])
+	assert.InDelta(t, 9.0, kev["dt.security.risk.score"], 1e-9, "KEV findings score at least 9.0")
+
+	epssOnly := records[1].Attributes().AsRaw()
+	assert.Equal(t, "CVE-2024-0001", epssOnly["vulnerability.id"])
+	assert.NotContains(t, epssOnly, "vulnerability.kev")
+	assert.InDelta(t, 3.9+2*0.00043, epssOnly["dt.security.risk.score"], 1e-9)
+
+	policy := records[2].Attributes().AsRaw()
+	assert.NotContains(t, policy, "vulnerability.id")
+	assert.InDelta(t, 6.9, policy["dt.security.risk.score"], 1e-9)
+}
+
+func TestProcessLogRecord_Outcome(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true, StatusFilter: []string{"fail"}})
+	require.NoError(t, err)
+
+	logRecord := plog.NewLogRecord()
+	attrs := logRecord.Attributes()
+	attrs.PutStr("kind", "Report")
+	attrs.PutStr("apiVersion", "openreports.io/v1alpha1")
+	resultsSlice := attrs.PutEmptySlice("results")
+	resultsSlice.AppendEmpty().SetStr(`{"policy": "p1", "rule": "r1", "result": "fail"}`)
+	resultsSlice.AppendEmpty().SetStr(`{"policy": "p1", "rule": "r2", "result": "pass"}`)
+	resultsSlice.AppendEmpty().SetStr(`{"policy": "p1", "rule": "r3", "result": "skip"}`)
+	resultsSlice.AppendEmpty().SetStr(`{invalid json}`)
+
+	records, outcome, err := processLogRecord(processor, &logRecord, pcommon.NewResource(), plog.NewScopeLogs())
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, processing.Outcome{
+		Results:   4,
+		Parsed:    3,
+		Malformed: 1,
+		Filtered:  map[string]int{FilterReasonStatus: 2},
+		Created:   1,
+	}, outcome)
+	assert.Equal(t, 2, outcome.FilteredTotal())
+}
+
+func TestProcessLogRecord_OutcomeSkipReason(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	newReport := func() plog.LogRecord {
+		logRecord := plog.NewLogRecord()
+		logRecord.Attributes().PutStr("kind", "Report")
+		logRecord.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+		return logRecord
+	}
+
+	notReport := plog.NewLogRecord()
+	notReport.Attributes().PutStr("kind", "Pod")
+
+	noResults := newReport()
+
+	emptyResults := newReport()
+	emptyResults.Attributes().PutEmptySlice("results")
+
+	invalidResults := newReport()
+	invalidResults.Attributes().PutInt("results", 42)
+
+	tests := []struct {
+		name      string
+		logRecord plog.LogRecord
+		reason    string
+	}{
+		{name: "not a report", logRecord: notReport, reason: SkipReasonNotReport},
+		{name: "no results", logRecord: noResults, reason: SkipReasonNoResults},
+		{name: "empty results", logRecord: emptyResults, reason: SkipReasonEmptyResults},
+		{name: "invalid results type", logRecord: invalidResults, reason: SkipReasonInvalidResults},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, outcome, err := processLogRecord(processor, &tt.logRecord, pcommon.NewResource(), plog.NewScopeLogs())
+			require.NoError(t, err)
+			assert.Nil(t, records)
+			assert.Equal(t, tt.reason, outcome.SkipReason)
+		})
+	}
+}
+
+func TestProcessLogRecord_AllResultsMalformed(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := plog.NewLogRecord()
+	logRecord.Attributes().PutStr("kind", "Report")
+	logRecord.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	resultsSlice := logRecord.Attributes().PutEmptySlice("results")
+	resultsSlice.AppendEmpty().SetStr(`{invalid json}`)
+	resultsSlice.AppendEmpty().SetStr(`not json either`)
+
+	// The report passes through unchanged, its results counted as malformed
+	records, outcome, err := processLogRecord(processor, &logRecord, pcommon.NewResource(), plog.NewScopeLogs())
+	require.NoError(t, err)
+	assert.Nil(t, records)
+	assert.Equal(t, processing.Outcome{Results: 2, Malformed: 2, SkipReason: SkipReasonMalformedResults}, outcome)
+}
+
+func TestProcessLogRecord_AppendsToDestination(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := plog.NewLogRecord()
+	logRecord.Attributes().PutStr("kind", "Report")
+	logRecord.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	results := logRecord.Attributes().PutEmptySlice("results")
+	results.AppendEmpty().SetStr(`{"policy": "p1", "rule": "r1", "result": "fail"}`)
+	results.AppendEmpty().SetStr(`{"policy": "p1", "rule": "r2", "result": "fail"}`)
+
+	dst := plog.NewLogRecordSlice()
+	dst.AppendEmpty().Attributes().PutStr("kind", "Pod")
+
+	outcome, err := processor.ProcessLogRecord(context.Background(), &logRecord, pcommon.NewResource(), plog.NewScopeLogs(), dst)
+	require.NoError(t, err)
+	assert.Equal(t, 2, outcome.Created)
+	require.Equal(t, 3, dst.Len())
+
+	// Existing records are kept and the security events are appended after them
+	kind, _ := dst.At(0).Attributes().Get("kind")
+	assert.Equal(t, "Pod", kind.Str())
+	for i, rule := range []string{"r1", "r2"} {
+		ruleVal, exists := dst.At(i + 1).Attributes().Get("finding.title")
+		require.True(t, exists)
+		assert.Contains(t, ruleVal.Str(), rule)
+	}
+
+	// The original report is left untouched
+	_, exists := logRecord.Attributes().Get("results")
+	assert.True(t, exists)
+}
+
+// newLargeReport returns a report with n results cycling through fail, pass and malformed results
+func newLargeReport(n int) plog.LogRecord {
+	logRecord := plog.NewLogRecord()
+	logRecord.Attributes().PutStr("kind", "Report")
+	logRecord.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	logRecord.Attributes().PutStr("scope.name", "app-7d9f8b6c5d-x2k4p")
+	logRecord.Attributes().PutStr("scope.kind", "Pod")
+	results := logRecord.Attributes().PutEmptySlice("results")
+	for i := 0; i < n; i++ {
+		switch i % 3 {
+		case 0:
+			results.AppendEmpty().SetStr(fmt.Sprintf(`{"policy": "p", "rule": "rule-%d", "result": "fail", "severity": "high"}`, i))
+		case 1:
+			results.AppendEmpty().SetStr(fmt.Sprintf(`{"policy": "p", "rule": "rule-%d", "result": "pass"}`, i))
+		default:
+			results.AppendEmpty().SetStr("not json")
+		}
+	}
+	return logRecord
+}
+
+func TestProcessLogRecord_WorkersPreserveOrder(t *testing.T) {
+	const numResults = 1000
+
+	serial, err := NewProcessor(zaptest.NewLogger(t, zaptest.Level(zap.InfoLevel)), &Config{Enabled: true, StatusFilter: []string{"fail"}})
+	require.NoError(t, err)
+	parallel, err := NewProcessor(zaptest.NewLogger(t, zaptest.Level(zap.InfoLevel)), &Config{Enabled: true, StatusFilter: []string{"fail"}, Workers: 8})
+	require.NoError(t, err)
+
+	serialReport := newLargeReport(numResults)
+	serialRecords, serialOutcome, err := processLogRecord(serial, &serialReport, pcommon.NewResource(), plog.NewScopeLogs())
+	require.NoError(t, err)
+
+	parallelReport := newLargeReport(numResults)
+	parallelRecords, parallelOutcome, err := processLogRecord(parallel, &parallelReport, pcommon.NewResource(), plog.NewScopeLogs())
+	require.NoError(t, err)
+
+	assert.Equal(t, serialOutcome, parallelOutcome)
+	assert.Equal(t, 334, parallelOutcome.Created)
+	assert.Equal(t, 333, parallelOutcome.Malformed)
+	assert.Equal(t, 333, parallelOutcome.Filtered[FilterReasonStatus])
+
+	require.Len(t, parallelRecords, len(serialRecords))
+	for i := range parallelRecords {
+		want, _ := serialRecords[i].Attributes().Get("finding.title")
+		got, _ := parallelRecords[i].Attributes().Get("finding.title")
+		assert.Equal(t, want.Str(), got.Str(), "record %d", i)
+		assert.Contains(t, got.Str(), fmt.Sprintf("rule-%d", i*3))
+	}
+}
+
+func TestProcessLogRecord_WorkersConcurrentReports(t *testing.T) {
+	// Several reports processed at once share the processor, its enrichment data and the worker pool
+	catalog, err := compliance.LoadCatalog(filepath.Join("..", "compliance", "testdata", "catalog.yaml"))
+	require.NoError(t, err)
+	mapping, err := attack.NewMapping(&attack.Config{Enabled: true})
+	require.NoError(t, err)
+	processor, err := NewProcessor(zaptest.NewLogger(t, zaptest.Level(zap.InfoLevel)), &Config{Enabled: true, Workers: 4},
+		WithComplianceCatalog(catalog), WithTechniqueMapping(mapping))
+	require.NoError(t, err)
+
+	const reports = 8
+	outcomes := make([]processing.Outcome, reports)
+	errs := make([]error, reports)
+	var wg sync.WaitGroup
+	for r := 0; r < reports; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report := newLargeReport(300)
+			_, outcomes[r], errs[r] = processLogRecord(processor, &report, pcommon.NewResource(), plog.NewScopeLogs())
+		}()
+	}
+	wg.Wait()
+
+	for r := 0; r < reports; r++ {
+		require.NoError(t, errs[r])
+		assert.Equal(t, 200, outcomes[r].Created)
+		assert.Equal(t, 100, outcomes[r].Malformed)
+	}
+}
+
+func TestObjectKey(t *testing.T) {
+	withUID := plog.NewLogRecord()
+	withUID.Attributes().PutStr("scope.uid", "uid-1")
+	withUID.Attributes().PutStr("scope.name", "app")
+	assert.Equal(t, "uid-1", ObjectKey(&withUID))
+
+	withoutUID := plog.NewLogRecord()
+	withoutUID.Attributes().PutStr("scope.namespace", "default")
+	withoutUID.Attributes().PutStr("scope.kind", "Pod")
+	withoutUID.Attributes().PutStr("scope.name", "app")
+	assert.Equal(t, "default/Pod/app/", ObjectKey(&withoutUID))
+}
+
+func TestResourceAttributes(t *testing.T) {
+	report := plog.NewLogRecord()
+	report.Attributes().PutStr("scope.kind", "Pod")
+	report.Attributes().PutStr("scope.name", "app-7d9f8b6c5d-x2k4p")
+	report.Attributes().PutStr("scope.namespace", "default")
+	report.Attributes().PutStr("scope.uid", "uid-1")
+	report.Attributes().PutStr("k8s.cluster.name", "prod")
+
+	target := pcommon.NewMap()
+	ResourceAttributes(&report, target)
+
+	assert.Equal(t, map[string]any{
+		"k8s.cluster.name":       "prod",
+		"k8s.pod.name":           "app-7d9f8b6c5d-x2k4p",
+		"k8s.namespace.name":     "default",
+		"k8s.resource.kind":      "Pod",
+		"k8s.resource.uid":       "uid-1",
+		"k8s.deployment.name":    "app",
+		"k8s.workload.name":      "app",
+		"k8s.workload.kind":      "Deployment",
+		"k8s.workload.namespace": "default",
+	}, target.AsRaw())
+}
+
+func TestObjectK8sFields(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.PutStr("k8s.cluster.name", "prod")
+	attrs.PutStr("metadata.name", "ignored")
+
+	// Pods are resolved as the scope of a report, with the workload derived from their name
+	assert.Equal(t, map[string]interface{}{
+		"k8s.cluster.name":       "prod",
+		"k8s.pod.name":           "app-7d9f8b6c5d-x2k4p",
+		"k8s.namespace.name":     "default",
+		"k8s.resource.kind":      "Pod",
+		"k8s.deployment.name":    "app",
+		"k8s.workload.name":      "app",
+		"k8s.workload.kind":      "Deployment",
+		"k8s.workload.namespace": "default",
+	}, ObjectK8sFields(attrs, "Pod", "default", "app-7d9f8b6c5d-x2k4p", ""))
+
+	assert.Equal(t, map[string]interface{}{
+		"k8s.namespace.name":     "shop",
+		"k8s.resource.kind":      "StatefulSet",
+		"k8s.statefulset.name":   "db",
+		"k8s.workload.name":      "db",
+		"k8s.workload.kind":      "StatefulSet",
+		"k8s.workload.namespace": "shop",
+	}, ObjectK8sFields(pcommon.NewMap(), "StatefulSet", "shop", "db", ""))
+
+	assert.Equal(t, map[string]interface{}{
+		"k8s.namespace.name": "shop",
+		"k8s.resource.kind":  "Namespace",
+	}, ObjectK8sFields(pcommon.NewMap(), "Namespace", "", "shop", ""))
+
+	// Objects are named by the attribute of their kind, or else by k8s.resource.name
+	assert.Equal(t, map[string]interface{}{
+		"k8s.node.name":     "cp-1",
+		"k8s.resource.kind": "Node",
+		"k8s.resource.uid":  "0c1d2e3f",
+	}, ObjectK8sFields(pcommon.NewMap(), "Node", "", "cp-1", "0c1d2e3f"))
+
+	assert.Equal(t, map[string]interface{}{
+		"k8s.namespace.name": "shop",
+		"k8s.resource.kind":  "Service",
+		"k8s.resource.name":  "checkout",
+	}, ObjectK8sFields(pcommon.NewMap(), "Service", "shop", "checkout", ""))
+
+	assert.Equal(t, map[string]interface{}{
+		"k8s.resource.kind": "ClusterRole",
+		"k8s.resource.name": "admin",
+	}, ObjectK8sFields(pcommon.NewMap(), "ClusterRole", "", "admin", ""))
+}
+
+func TestPodK8sFields(t *testing.T) {
+	// The workload reported by the source wins over the one derived from the pod name
+	assert.Equal(t, map[string]interface{}{
+		"k8s.pod.name":           "kube-proxy-x7k2p",
+		"k8s.namespace.name":     "kube-system",
+		"k8s.resource.kind":      "Pod",
+		"k8s.resource.uid":       "4f1c2d3e",
+		"k8s.daemonset.name":     "kube-proxy",
+		"k8s.workload.name":      "kube-proxy",
+		"k8s.workload.kind":      "DaemonSet",
+		"k8s.workload.namespace": "kube-system",
+	}, PodK8sFields(pcommon.NewMap(), "kube-system", "kube-proxy-x7k2p", "4f1c2d3e", "kube-proxy", "DaemonSet"))
+
+	assert.Equal(t,
+		ObjectK8sFields(pcommon.NewMap(), "Pod", "default", "app-7d9f8b6c5d-x2k4p", ""),
+		PodK8sFields(pcommon.NewMap(), "default", "app-7d9f8b6c5d-x2k4p", "", "app", ""))
+}
+
+func TestProcessLogRecord_K8sFieldsOnResource(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithK8sFieldsOnResource(true))
+	require.NoError(t, err)
+
+	report := newLargeReport(1)
+	records, _, err := processLogRecord(processor, &report, pcommon.NewResource(), plog.NewScopeLogs())
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	records[0].Attributes().Range(func(key string, _ pcommon.Value) bool {
+		assert.NotContains(t, key, "k8s.", "unexpected attribute %s", key)
+		return true
+	})
+	_, exists := records[0].Attributes().Get("object.type")
+	assert.True(t, exists)
+}
+
+func TestProcessLogRecord_AttributeNames(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithOutput(processing.Output{AttributeNames: semconv.ModeBoth}))
+	require.NoError(t, err)
+
+	report := newLargeReport(1)
+	records, _, err := processLogRecord(processor, &report, pcommon.NewResource(), plog.NewScopeLogs())
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	attrs := records[0].Attributes().AsRaw()
+	assert.Equal(t, "app", attrs["k8s.deployment.name"])
+	assert.Equal(t, "Deployment", attrs["k8s.workload.kind"], "legacy names are kept in both mode")
+	assert.Equal(t, "Compliance finding event", attrs["event.name"])
+	assert.Equal(t, "security.compliance_finding", records[0].EventName())
+}
+
+func TestProcessLogRecord_BodyFormat(t *testing.T) {
+	tests := []struct {
+		format   string
+		wantType pcommon.ValueType
+	}{
+		{format: "", wantType: pcommon.ValueTypeStr},
+		{format: schema.BodyFormatMessage, wantType: pcommon.ValueTypeStr},
+		{format: schema.BodyFormatMap, wantType: pcommon.ValueTypeMap},
+		{format: schema.BodyFormatJSON, wantType: pcommon.ValueTypeStr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithOutput(processing.Output{BodyFormat: tt.format}))
+			require.NoError(t, err)
+
+			report := newLargeReport(0)
+			report.Attributes().PutEmptySlice("results").AppendEmpty().SetStr(
+				`{"policy": "p", "rule": "r", "result": "fail", "message": "result 0"}`)
+			records, _, err := processLogRecord(processor, &report, pcommon.NewResource(), plog.NewScopeLogs())
+			require.NoError(t, err)
+			require.Len(t, records, 1)
+
+			body := records[0].Body()
+			require.Equal(t, tt.wantType, body.Type())
+
+			// The attributes do not depend on the body format
+			eventID, exists := records[0].Attributes().Get("event.id")
+			require.True(t, exists)
+
+			var fields map[string]interface{}
+			switch tt.format {
+			case schema.BodyFormatMap:
+				fields = body.Map().AsRaw()
+			case schema.BodyFormatJSON:
+				require.NoError(t, json.Unmarshal([]byte(body.Str()), &fields))
+			default:
+				assert.Equal(t, "result 0", body.Str())
+				return
+			}
+			assert.Equal(t, schema.Version, fields["schema_version"])
+			assert.Equal(t, eventID.Str(), fields["event"].(map[string]interface{})["id"])
+			assert.Equal(t, "result 0", fields["message"])
+			target := fields["target"].(map[string]interface{})
+			assert.Equal(t, "Pod", target["resource_type"])
+			assert.Equal(t, "app-7d9f8b6c5d-x2k4p", target["kubernetes"].(map[string]interface{})["k8s.pod.name"])
+		})
+	}
+}
+
+func TestProcessLogRecord_TransformError(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithOutput(processing.Output{BodyFormat: schema.BodyFormatJSON}))
+	require.NoError(t, err)
+
+	// JSON has no representation for NaN, so the security events cannot be serialized
+	report := newLargeReport(3)
+	report.Attributes().PutDouble("k8s.cpu.ratio", math.NaN())
+
+	dst := plog.NewLogRecordSlice()
+	dst.AppendEmpty().Attributes().PutStr("kind", "Pod")
+
+	outcome, err := processor.ProcessLogRecord(context.Background(), &report, pcommon.NewResource(), plog.NewScopeLogs(), dst)
+	var stageErr *processing.StageError
+	require.ErrorAs(t, err, &stageErr)
+	assert.Equal(t, processing.StageTransform, stageErr.Stage)
+	assert.Zero(t, outcome.Created)
+
+	// No security event of the report is left behind
+	require.Equal(t, 1, dst.Len())
+	kind, _ := dst.At(0).Attributes().Get("kind")
+	assert.Equal(t, "Pod", kind.Str())
+}
+
+func TestProcessLogRecord_SIEMEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		siem     cef.Config
+		wantBody string
+		wantAttr string
+	}{
+		{
+			name:     "cef in body",
+			siem:     cef.Config{Format: cef.FormatCEF},
+			wantBody: "CEF:0|OpenTelemetry|Security Event Processor||p:rule-0|p - rule-0|9|",
+		},
+		{
+			name:     "leef in attribute",
+			siem:     cef.Config{Format: cef.FormatLEEF, Target: cef.TargetAttribute},
+			wantAttr: "LEEF:2.0|OpenTelemetry|Security Event Processor||p:rule-0|x09|",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithOutput(processing.Output{SIEM: tt.siem}))
+			require.NoError(t, err)
+
+			report := newLargeReport(1)
+			records, _, err := processLogRecord(processor, &report, pcommon.NewResource(), plog.NewScopeLogs())
+			require.NoError(t, err)
+			require.Len(t, records, 1)
+
+			attr, exists := records[0].Attributes().Get(cef.AttrEncoded)
+			if tt.wantAttr != "" {
+				require.True(t, exists)
+				assert.True(t, strings.HasPrefix(attr.Str(), tt.wantAttr), attr.Str())
+				assert.Contains(t, attr.Str(), "resource=Pod/app-7d9f8b6c5d-x2k4p")
+			} else {
+				assert.False(t, exists)
+			}
+			if tt.wantBody != "" {
+				assert.True(t, strings.HasPrefix(records[0].Body().Str(), tt.wantBody), records[0].Body().Str())
+			} else {
+				assert.Empty(t, records[0].Body().Str(), "the body keeps the finding message")
+			}
+
+			// The attributes are written as usual
+			_, exists = records[0].Attributes().Get("finding.id")
+			assert.True(t, exists)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// Package processing contains types shared by the security event sub-processors.
+package processing
+
+import "fmt"
+
+// Processing stages reported by StageError
+const (
+	// StageParse is the stage decoding the source log record
+	StageParse = "parse"
+	// StageTransform is the stage building the security events
+	StageTransform = "transform"
+	// StageValidate is the stage checking the security events against the output schema
+	StageValidate = "validate"
+)
+
+// StageError is returned when a log record cannot be turned into security events
+// It records the stage that failed so failed records can be routed and diagnosed
+type StageError struct {
+	// Stage is the processing stage that failed
+	Stage string
+	// Err is the underlying error
+	Err error
+}
+
+// NewStageError creates a StageError for the given stage
+func NewStageError(stage string, err error) *StageError {
+	return &StageError{Stage: stage, Err: err}
+}
+
+// Error implements the error interface
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s stage failed: %v", e.Stage, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,21 @@
+package processing
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageError(t *testing.T) {
+	cause := errors.New("unexpected type")
+	err := fmt.Errorf("report: %w", NewStageError(StageParse, cause))
+
+	var stageErr *StageError
+	require.ErrorAs(t, err, &stageErr)
+	assert.Equal(t, StageParse, stageErr.Stage)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "report: parse stage failed: unexpected type", err.Error())
+}
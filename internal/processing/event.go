@@ -0,0 +1,104 @@
+package processing
+
+import (
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// EventVersion is the version of the event semantics of the security events
+const EventVersion = "1.309"
+
+// Finding severities
+const (
+	SeverityCritical = "CRITICAL"
+	SeverityHigh     = "HIGH"
+	SeverityMedium   = "MEDIUM"
+	SeverityLow      = "LOW"
+	// SeverityInformational marks findings kept for the record, without risk
+	SeverityInformational = "INFORMATIONAL"
+)
+
+// Risk scores of the finding severities, shared by all sub-processors
+const (
+	RiskScoreCritical = 10.0
+	RiskScoreHigh     = 8.9
+	RiskScoreMedium   = 6.9
+	RiskScoreLow      = 3.9
+)
+
+// RiskScore returns the risk score of a finding severity, in any case, 0 if unrated
+func RiskScore(severity string) float64 {
+	switch strings.ToUpper(severity) {
+	case SeverityCritical:
+		return RiskScoreCritical
+	case SeverityHigh:
+		return RiskScoreHigh
+	case SeverityMedium:
+		return RiskScoreMedium
+	case SeverityLow:
+		return RiskScoreLow
+	default:
+		return 0
+	}
+}
+
+// SeverityFromScore maps a 0-10 score to a finding severity, with the qualitative severity rating scale of CVSS v3:
+// CRITICAL from 9, HIGH from 7, MEDIUM from 4 and LOW below
+func SeverityFromScore(score float64) string {
+	switch {
+	case score >= 9:
+		return SeverityCritical
+	case score >= 7:
+		return SeverityHigh
+	case score >= 4:
+		return SeverityMedium
+	default:
+		return SeverityLow
+	}
+}
+
+// CopyRecordFields copies the timestamps, severity, trace context and flags of a source log record to the log
+// record of one of its security events
+func CopyRecordFields(src, dst plog.LogRecord) {
+	dst.SetTimestamp(src.Timestamp())
+	dst.SetObservedTimestamp(src.ObservedTimestamp())
+	dst.SetSeverityNumber(src.SeverityNumber())
+	dst.SetSeverityText(src.SeverityText())
+	dst.SetTraceID(src.TraceID())
+	dst.SetSpanID(src.SpanID())
+	dst.SetFlags(src.Flags())
+}
+
+// NewEventRecord appends to dst the log record of a security event of src, see CopyRecordFields
+// A non-zero timestamp, the time of the event in the source, replaces the timestamp of src
+func NewEventRecord(src plog.LogRecord, dst plog.LogRecordSlice, timestamp time.Time) plog.LogRecord {
+	record := dst.AppendEmpty()
+	CopyRecordFields(src, record)
+	if !timestamp.IsZero() {
+		record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	}
+	return record
+}
+
+// Rollback removes the log records appended to dst from index first, when the security events of a source log
+// record cannot all be written
+func Rollback(dst plog.LogRecordSlice, first int) {
+	n := 0
+	dst.RemoveIf(func(plog.LogRecord) bool {
+		n++
+		return n > first
+	})
+}
+
+// FirstNonEmpty returns the first non-empty value
+func FirstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package processing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestRiskScore(t *testing.T) {
+	tests := []struct {
+		severity string
+		expected float64
+	}{
+		{"critical", 10.0},
+		{"high", 8.9},
+		{"medium", 6.9},
+		{"low", 3.9},
+		{"HIGH", 8.9},
+		{"unknown", 0.0},
+		{"", 0.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.severity, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RiskScore(tt.severity))
+		})
+	}
+}
+
+func TestSeverityFromScore(t *testing.T) {
+	assert.Equal(t, SeverityCritical, SeverityFromScore(10))
+	assert.Equal(t, SeverityCritical, SeverityFromScore(9))
+	assert.Equal(t, SeverityHigh, SeverityFromScore(8.9))
+	assert.Equal(t, SeverityHigh, SeverityFromScore(7))
+	assert.Equal(t, SeverityMedium, SeverityFromScore(6.9))
+	assert.Equal(t, SeverityMedium, SeverityFromScore(4))
+	assert.Equal(t, SeverityLow, SeverityFromScore(3.9))
+	assert.Equal(t, SeverityLow, SeverityFromScore(0))
+}
+
+func TestNewEventRecord(t *testing.T) {
+	src := plog.NewLogRecord()
+	src.SetTimestamp(pcommon.Timestamp(1))
+	src.SetObservedTimestamp(pcommon.Timestamp(2))
+	src.SetSeverityNumber(plog.SeverityNumberWarn)
+	src.SetSeverityText("WARN")
+	src.SetTraceID(pcommon.TraceID{1})
+	src.SetSpanID(pcommon.SpanID{2})
+	src.SetFlags(plog.DefaultLogRecordFlags.WithIsSampled(true))
+	src.Attributes().PutStr("kind", "Report")
+	src.Body().SetStr("report")
+
+	dst := plog.NewLogRecordSlice()
+	record := NewEventRecord(src, dst, time.Time{})
+	require.Equal(t, 1, dst.Len())
+	assert.Equal(t, src.Timestamp(), record.Timestamp())
+	assert.Equal(t, src.ObservedTimestamp(), record.ObservedTimestamp())
+	assert.Equal(t, src.SeverityNumber(), record.SeverityNumber())
+	assert.Equal(t, src.SeverityText(), record.SeverityText())
+	assert.Equal(t, src.TraceID(), record.TraceID())
+	assert.Equal(t, src.SpanID(), record.SpanID())
+	assert.Equal(t, src.Flags(), record.Flags())
+	assert.Zero(t, record.Attributes().Len(), "the attributes are the security event's")
+	assert.Equal(t, pcommon.ValueTypeEmpty, record.Body().Type())
+
+	eventTime := time.Date(2025, 10, 18, 12, 0, 0, 0, time.UTC)
+	record = NewEventRecord(src, dst, eventTime)
+	require.Equal(t, 2, dst.Len())
+	assert.Equal(t, eventTime, record.Timestamp().AsTime(), "the time of the event replaces the source timestamp")
+	assert.Equal(t, src.ObservedTimestamp(), record.ObservedTimestamp())
+}
+
+func TestRollback(t *testing.T) {
+	dst := plog.NewLogRecordSlice()
+	dst.AppendEmpty().Body().SetStr("kept")
+	first := dst.Len()
+	dst.AppendEmpty().Body().SetStr("event 1")
+	dst.AppendEmpty().Body().SetStr("event 2")
+
+	Rollback(dst, first)
+	require.Equal(t, 1, dst.Len())
+	assert.Equal(t, "kept", dst.At(0).Body().Str())
+
+	Rollback(dst, first)
+	assert.Equal(t, 1, dst.Len(), "nothing to remove")
+}
+
+func TestFirstNonEmpty(t *testing.T) {
+	assert.Equal(t, "b", FirstNonEmpty("", "b", "c"))
+	assert.Empty(t, FirstNonEmpty("", ""))
+	assert.Empty(t, FirstNonEmpty())
+}
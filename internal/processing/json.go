@@ -0,0 +1,107 @@
+package processing
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// JSON value kinds, told by the first byte of a value
+const (
+	JSONObject byte = '{'
+	JSONArray  byte = '['
+	JSONString byte = '"'
+)
+
+// JSONValue is a value at the top level of a JSON object, as found by a quick check of a log record body
+type JSONValue struct {
+	// Kind is the first byte of the value: JSONObject, JSONArray, JSONString, or another byte for a scalar
+	Kind byte
+
+	// Str is the value of a string, "" if it is cut by a syntax error
+	Str string
+
+	// Offset is the offset of the value in the scanned document, to look into an object
+	Offset int
+}
+
+// TopLevelValues returns the values of keys at the top level of the JSON object s
+// Documents mentioning none of the keys are ruled out without being scanned; otherwise the object is scanned until
+// all keys are found or up to the first syntax error, so a truncated document is still told by its first keys,
+// and then fails to decode
+func TopLevelValues(s string, keys ...string) map[string]JSONValue {
+	values := make(map[string]JSONValue, len(keys))
+	if !slices.ContainsFunc(keys, func(key string) bool { return strings.Contains(s, `"`+key+`"`) }) {
+		return values
+	}
+
+	dec := json.NewDecoder(strings.NewReader(s))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return values
+	}
+	for dec.More() && len(values) < len(keys) {
+		t, err := dec.Token()
+		key, ok := t.(string)
+		if err != nil || !ok {
+			break
+		}
+		offset := int(dec.InputOffset())
+		for offset < len(s) && (s[offset] == ':' || s[offset] == ' ' || s[offset] == '\t' || s[offset] == '\n' || s[offset] == '\r') {
+			offset++
+		}
+		if offset >= len(s) {
+			break
+		}
+
+		if !slices.Contains(keys, key) {
+			if err := dec.Decode(&skipValue{}); err != nil {
+				break
+			}
+			continue
+		}
+		value := JSONValue{Kind: s[offset], Offset: offset}
+		if value.Kind == JSONString {
+			err = dec.Decode(&value.Str)
+		} else {
+			err = dec.Decode(&skipValue{})
+		}
+		values[key] = value
+		if err != nil {
+			break
+		}
+	}
+	return values
+}
+
+// MapValues returns the values of keys of a map body parsed from a JSON object, as TopLevelValues does
+func MapValues(m pcommon.Map, keys ...string) map[string]JSONValue {
+	values := make(map[string]JSONValue, len(keys))
+	for _, key := range keys {
+		v, exists := m.Get(key)
+		if !exists {
+			continue
+		}
+		switch v.Type() {
+		case pcommon.ValueTypeMap:
+			values[key] = JSONValue{Kind: JSONObject}
+		case pcommon.ValueTypeSlice:
+			values[key] = JSONValue{Kind: JSONArray}
+		case pcommon.ValueTypeStr:
+			values[key] = JSONValue{Kind: JSONString, Str: v.Str()}
+		default:
+			// Other scalars only need to be told apart from the kinds above
+			values[key] = JSONValue{}
+		}
+	}
+	return values
+}
+
+// skipValue skips a JSON value without copying it
+type skipValue struct{}
+
+// UnmarshalJSON discards the value
+func (skipValue) UnmarshalJSON([]byte) error {
+	return nil
+}
@@ -0,0 +1,95 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestTopLevelValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		kinds map[string]byte
+		strs  map[string]string
+	}{
+		{
+			name:  "object, array and string",
+			s:     `{"version": "2.1.0", "runs": [{"tool": {}}], "meta": {"runs": 1}}`,
+			kinds: map[string]byte{"version": JSONString, "runs": JSONArray, "meta": JSONObject},
+			strs:  map[string]string{"version": "2.1.0"},
+		},
+		{
+			name:  "scalars",
+			s:     `{"msg":"uploaded scan","runs":3,"schema":"2.1.0"}`,
+			kinds: map[string]byte{"runs": '3'},
+		},
+		{
+			name:  "nested keys are not top-level",
+			s:     `{"msg": {"runs": []}, "data": [{"version": "2.1.0"}]}`,
+			kinds: map[string]byte{},
+		},
+		{
+			name:  "keys in strings are not keys",
+			s:     `{"msg": "\"runs\": [] and \"version\": \"2.1.0\""}`,
+			kinds: map[string]byte{},
+		},
+		{
+			name:  "truncated after the first keys",
+			s:     `{"version": "2.1.0", "runs": [{"tool": `,
+			kinds: map[string]byte{"version": JSONString, "runs": JSONArray},
+			strs:  map[string]string{"version": "2.1.0"},
+		},
+		{
+			name:  "not an object",
+			s:     `["runs", "version"]`,
+			kinds: map[string]byte{},
+		},
+		{
+			name:  "not JSON",
+			s:     `level=info msg="runs" version=2.1.0`,
+			kinds: map[string]byte{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := TopLevelValues(tt.s, "version", "runs", "meta")
+			kinds := make(map[string]byte, len(values))
+			for key, v := range values {
+				kinds[key] = v.Kind
+				if want, ok := tt.strs[key]; ok {
+					assert.Equal(t, want, v.Str, key)
+				}
+			}
+			assert.Equal(t, tt.kinds, kinds)
+		})
+	}
+}
+
+func TestTopLevelValues_Offset(t *testing.T) {
+	s := `{"kind": "Audit", "object": {"apiVersion": "constraints.gatekeeper.sh/v1beta1"}}`
+	values := TopLevelValues(s, "object")
+	require.Contains(t, values, "object")
+	require.Equal(t, JSONObject, values["object"].Kind)
+
+	nested := TopLevelValues(s[values["object"].Offset:], "apiVersion")
+	assert.Equal(t, "constraints.gatekeeper.sh/v1beta1", nested["apiVersion"].Str)
+}
+
+func TestMapValues(t *testing.T) {
+	m := pcommon.NewMap()
+	m.PutStr("version", "2.1.0")
+	m.PutEmptySlice("runs")
+	m.PutEmptyMap("meta")
+	m.PutInt("count", 3)
+
+	values := MapValues(m, "version", "runs", "meta", "count", "missing")
+	assert.Equal(t, JSONValue{Kind: JSONString, Str: "2.1.0"}, values["version"])
+	assert.Equal(t, JSONArray, values["runs"].Kind)
+	assert.Equal(t, JSONObject, values["meta"].Kind)
+	assert.Contains(t, values, "count")
+	assert.NotContains(t, []byte{JSONObject, JSONArray, JSONString}, values["count"].Kind)
+	assert.NotContains(t, values, "missing")
+}
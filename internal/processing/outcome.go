@@ -0,0 +1,40 @@
+package processing
+
+// Outcome summarizes how the records of a source log record were handled by a sub-processor
+// A source log record (e.g. a report) holds one or more records (e.g. results), each turned into a security event
+type Outcome struct {
+	// SkipReason is set when the whole log record was skipped before its records were parsed
+	SkipReason string
+
+	// Results is the number of records found in the source log record
+	Results int
+
+	// Parsed is the number of records successfully parsed
+	Parsed int
+
+	// Malformed is the number of records that could not be parsed
+	Malformed int
+
+	// Filtered is the number of parsed records not turned into security events, by reason
+	Filtered map[string]int
+
+	// Created is the number of security events created
+	Created int
+}
+
+// AddFiltered counts a record filtered for the given reason
+func (o *Outcome) AddFiltered(reason string) {
+	if o.Filtered == nil {
+		o.Filtered = make(map[string]int)
+	}
+	o.Filtered[reason]++
+}
+
+// FilteredTotal returns the number of filtered records across all reasons
+func (o *Outcome) FilteredTotal() int {
+	total := 0
+	for _, count := range o.Filtered {
+		total += count
+	}
+	return total
+}
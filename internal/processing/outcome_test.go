@@ -0,0 +1,19 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutcome_Filtered(t *testing.T) {
+	var outcome Outcome
+	assert.Zero(t, outcome.FilteredTotal())
+
+	outcome.AddFiltered("status_filter")
+	outcome.AddFiltered("status_filter")
+	outcome.AddFiltered("severity_filter")
+
+	assert.Equal(t, map[string]int{"status_filter": 2, "severity_filter": 1}, outcome.Filtered)
+	assert.Equal(t, 3, outcome.FilteredTotal())
+}
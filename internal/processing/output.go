@@ -0,0 +1,63 @@
+package processing
+
+import (
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/cef"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/profile"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/semconv"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Output lays out the security events of all sub-processors in their log records
+type Output struct {
+	// Profile is the attribute layout of the security events, dynatrace if empty
+	Profile string
+
+	// AttributeNames is the semconv attribute name mode of the security events
+	AttributeNames string
+
+	// BodyFormat is the schema body format of the security events
+	BodyFormat string
+
+	// SIEM renders the security events in CEF or LEEF when enabled
+	SIEM cef.Config
+
+	// ActionFields writes the action.type and result.status attributes of the dynatrace profile
+	// Set by the sub-processors whose action and result are part of the source event
+	ActionFields bool
+}
+
+// Write writes a security event into a log record: its attributes in the layout of the profile and its body,
+// the CEF or LEEF rendering if enabled, then the action fields and semantic convention attribute names of the
+// dynatrace profile
+// It is safe for concurrent use on distinct log records
+func (o *Output) Write(logRecord plog.LogRecord, event *schema.SecurityEvent) error {
+	profile.PutAttributes(logRecord.Attributes(), event, o.Profile)
+	if err := event.PutBody(logRecord.Body(), o.BodyFormat); err != nil {
+		return err
+	}
+
+	// CEF or LEEF rendering for legacy SIEM ingestion
+	if o.SIEM.Enabled() {
+		encoded := cef.Encode(event, o.SIEM.Format)
+		if o.SIEM.Target == cef.TargetAttribute {
+			logRecord.Attributes().PutStr(cef.AttrEncoded, encoded)
+		} else {
+			logRecord.Body().SetStr(encoded)
+		}
+	}
+
+	// The other profiles have their own action, result and attribute names
+	if o.Profile != "" && o.Profile != profile.Dynatrace {
+		return nil
+	}
+	if o.ActionFields {
+		event.PutActionAttributes(logRecord.Attributes())
+	}
+
+	// Semantic convention attribute names, alongside or instead of the legacy names
+	semconv.Apply(logRecord.Attributes(), o.AttributeNames)
+	semconv.ApplyEventName(logRecord, o.AttributeNames)
+	return nil
+}
@@ -0,0 +1,109 @@
+package processing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/cef"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/profile"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/semconv"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// newTestEvent returns a security event on a pod
+func newTestEvent() *schema.SecurityEvent {
+	return &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event:         schema.Event{ID: "event-1", Version: "1.309", Type: "COMPLIANCE_FINDING"},
+		Message:       "resource limits are required",
+		Target: schema.Target{
+			ResourceType: "Pod",
+			Kubernetes:   map[string]interface{}{"k8s.workload.kind": "Deployment", "k8s.workload.name": "web"},
+		},
+		Finding: schema.Finding{ID: "finding-1", Title: "pod-security - require-limits"},
+	}
+}
+
+func TestOutput_Write(t *testing.T) {
+	logRecord := plog.NewLogRecord()
+	output := Output{}
+	require.NoError(t, output.Write(logRecord, newTestEvent()))
+
+	attrs := logRecord.Attributes().AsRaw()
+	assert.Equal(t, "event-1", attrs["event.id"])
+	assert.Equal(t, "web", attrs["k8s.workload.name"])
+	assert.Equal(t, "resource limits are required", logRecord.Body().Str())
+	assert.NotContains(t, attrs, cef.AttrEncoded)
+	assert.NotContains(t, attrs, schema.AttrActionType)
+}
+
+func TestOutput_WriteActionFields(t *testing.T) {
+	event := newTestEvent()
+	event.Action.Type = "blocked"
+	event.Result.Status = "failure"
+
+	logRecord := plog.NewLogRecord()
+	output := Output{ActionFields: true}
+	require.NoError(t, output.Write(logRecord, event))
+	assert.Equal(t, "blocked", logRecord.Attributes().AsRaw()[schema.AttrActionType])
+	assert.Equal(t, "failure", logRecord.Attributes().AsRaw()[schema.AttrResultStatus])
+
+	// The other profiles map the action and result to their own fields
+	logRecord = plog.NewLogRecord()
+	output.Profile = profile.SplunkCIM
+	require.NoError(t, output.Write(logRecord, event))
+	assert.NotContains(t, logRecord.Attributes().AsRaw(), schema.AttrActionType)
+	assert.Equal(t, "blocked", logRecord.Attributes().AsRaw()["action"])
+}
+
+func TestOutput_WriteSIEMAndSemconv(t *testing.T) {
+	logRecord := plog.NewLogRecord()
+	output := Output{
+		AttributeNames: semconv.ModeSemconv,
+		SIEM:           cef.Config{Format: cef.FormatCEF, Target: cef.TargetAttribute},
+	}
+	require.NoError(t, output.Write(logRecord, newTestEvent()))
+
+	attrs := logRecord.Attributes().AsRaw()
+	assert.Equal(t, "web", attrs["k8s.deployment.name"])
+	assert.NotContains(t, attrs, "k8s.workload.name")
+	assert.Equal(t, "security.compliance_finding", logRecord.EventName())
+	assert.Contains(t, attrs[cef.AttrEncoded], "CEF:0|OpenTelemetry|Security Event Processor||")
+	assert.Equal(t, "resource limits are required", logRecord.Body().Str())
+
+	// The body target replaces the message
+	logRecord = plog.NewLogRecord()
+	output.SIEM.Target = cef.TargetBody
+	require.NoError(t, output.Write(logRecord, newTestEvent()))
+	assert.Contains(t, logRecord.Body().Str(), "CEF:0|")
+}
+
+func TestOutput_WriteProfile(t *testing.T) {
+	logRecord := plog.NewLogRecord()
+	// Semantic convention names only apply to the dynatrace profile
+	output := Output{Profile: profile.SplunkCIM, AttributeNames: semconv.ModeSemconv, BodyFormat: schema.BodyFormatMap}
+	require.NoError(t, output.Write(logRecord, newTestEvent()))
+
+	attrs := logRecord.Attributes().AsRaw()
+	assert.Equal(t, "finding-1", attrs["id"])
+	assert.Equal(t, "pod-security - require-limits", attrs["signature"])
+	assert.Equal(t, "informational", attrs["severity"])
+	assert.Equal(t, "web", attrs["k8s.workload.name"])
+	assert.NotContains(t, attrs, "event.id")
+	assert.Empty(t, logRecord.EventName())
+	assert.Equal(t, "event-1", logRecord.Body().Map().AsRaw()["event"].(map[string]interface{})["id"])
+}
+
+func TestOutput_WriteError(t *testing.T) {
+	event := newTestEvent()
+	event.Target.Kubernetes["k8s.ratio"] = math.NaN()
+	output := Output{BodyFormat: schema.BodyFormatJSON}
+
+	err := output.Write(plog.NewLogRecord(), event)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to serialize security event")
+}
@@ -0,0 +1,36 @@
+package processing
+
+import "sync"
+
+// MinItemsPerWorker is the smallest number of items handed to a worker goroutine
+// Smaller batches are processed serially, as the goroutine overhead would outweigh the gain
+const MinItemsPerWorker = 16
+
+// ForEach calls fn once for every index in [0, n) using up to workers goroutines
+// Each worker handles a contiguous range of indices, so fn must only write state owned by its index
+// With workers <= 1 or fewer than 2*MinItemsPerWorker items, fn is called serially on the calling goroutine
+func ForEach(workers, n int, fn func(i int)) {
+	if maxWorkers := n / MinItemsPerWorker; workers > maxWorkers {
+		workers = maxWorkers
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	chunk := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += chunk {
+		end := min(start+chunk, n)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
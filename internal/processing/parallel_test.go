@@ -0,0 +1,46 @@
+package processing
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEach(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		n       int
+	}{
+		{name: "no items", workers: 4, n: 0},
+		{name: "serial", workers: 1, n: 100},
+		{name: "zero workers", workers: 0, n: 100},
+		{name: "too few items to split", workers: 8, n: MinItemsPerWorker},
+		{name: "parallel", workers: 4, n: 1000},
+		{name: "uneven chunks", workers: 3, n: 101},
+		{name: "more workers than chunks", workers: 64, n: 5 * MinItemsPerWorker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := make([]int32, tt.n)
+			ForEach(tt.workers, tt.n, func(i int) {
+				atomic.AddInt32(&calls[i], 1)
+			})
+			for i, count := range calls {
+				assert.Equal(t, int32(1), count, "index %d", i)
+			}
+		})
+	}
+}
+
+func TestForEach_PreservesIndexOrder(t *testing.T) {
+	out := make([]int, 500)
+	ForEach(8, len(out), func(i int) {
+		out[i] = i * i
+	})
+	for i, v := range out {
+		assert.Equal(t, i*i, v)
+	}
+}
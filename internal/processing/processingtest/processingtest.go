@@ -0,0 +1,84 @@
+// Package processingtest provides the test fixtures shared by the sub-processors.
+package processingtest
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+)
+
+// Timestamps of the log records returned by NewLogRecord
+var (
+	Timestamp         = pcommon.NewTimestampFromTime(time.Unix(1760788800, 0))
+	ObservedTimestamp = pcommon.NewTimestampFromTime(time.Unix(1760788900, 0))
+)
+
+// ProcessFunc is the ProcessLogRecord method of a sub-processor
+type ProcessFunc func(ctx context.Context, logRecord *plog.LogRecord, resource pcommon.Resource,
+	scopeLogs plog.ScopeLogs, dst plog.LogRecordSlice) (processing.Outcome, error)
+
+// NewLogRecord returns a log record whose string body is the content of a file of the testdata directory
+func NewLogRecord(t testing.TB, name string) plog.LogRecord {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return NewStringLogRecord(string(data))
+}
+
+// NewStringLogRecord returns a log record whose string body is s
+func NewStringLogRecord(s string) plog.LogRecord {
+	logRecord := plog.NewLogRecord()
+	logRecord.Body().SetStr(s)
+	logRecord.SetTimestamp(Timestamp)
+	logRecord.SetObservedTimestamp(ObservedTimestamp)
+	return logRecord
+}
+
+// NewMapLogRecord returns a log record whose map body is the JSON object of a file of the testdata directory,
+// as parsed by a receiver, optionally wrapped by wrap
+func NewMapLogRecord(t testing.TB, name string, wrap func(object map[string]interface{}) map[string]interface{}) plog.LogRecord {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+
+	var object map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &object))
+	if wrap != nil {
+		object = wrap(object)
+	}
+
+	logRecord := NewStringLogRecord("")
+	require.NoError(t, logRecord.Body().SetEmptyMap().FromRaw(object))
+	return logRecord
+}
+
+// Process runs process on a log record into a fresh slice, with an empty resource and scope
+func Process(t testing.TB, process ProcessFunc, logRecord *plog.LogRecord) (plog.LogRecordSlice, processing.Outcome, error) {
+	t.Helper()
+	return ProcessResource(t, process, logRecord, pcommon.NewResource())
+}
+
+// ProcessResource runs process on a log record of a resource into a fresh slice, with an empty scope
+func ProcessResource(t testing.TB, process ProcessFunc, logRecord *plog.LogRecord, resource pcommon.Resource) (plog.LogRecordSlice, processing.Outcome, error) {
+	t.Helper()
+	dst := plog.NewLogRecordSlice()
+	outcome, err := process(context.Background(), logRecord, resource, plog.NewScopeLogs(), dst)
+	return dst, outcome, err
+}
+
+// RequireStageError requires err to be a *processing.StageError of the given stage
+func RequireStageError(t testing.TB, err error, stage string) {
+	t.Helper()
+	var stageErr *processing.StageError
+	require.ErrorAs(t, err, &stageErr)
+	require.Equal(t, stage, stageErr.Stage)
+}
@@ -0,0 +1,221 @@
+package profile
+
+import (
+	"math"
+	"strings"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// ASIM field names used outside the mapping tables
+const (
+	asimSeverity      = "EventSeverity"
+	asimResultDetails = "EventResultDetails"
+)
+
+// ASIM schemas
+const (
+	asimSchemaAuditEvent = "AuditEvent"
+	asimSchemaAlert      = "Alert"
+	asimSchemaVersion    = "0.1"
+)
+
+// asimFields returns the mapping table of a security event: compliance findings, the evaluation of a
+// resource against a policy, map to the Audit Event schema, other findings to the Alert schema
+func asimFields(e *schema.SecurityEvent) []field {
+	if e.Compliance.Status != "" && e.Vulnerability == nil {
+		return asimAuditEventFields
+	}
+	return asimAlertFields
+}
+
+// asimCommonFields maps the fields shared by the Audit Event and Alert schemas
+var asimCommonFields = []field{
+	{name: "EventSchemaVersion", value: func(*schema.SecurityEvent) interface{} { return asimSchemaVersion }},
+	{name: "EventVendor", value: func(e *schema.SecurityEvent) interface{} { return e.Source.Vendor }},             // Source.Vendor
+	{name: "EventProduct", value: func(e *schema.SecurityEvent) interface{} { return e.Source.Application }},       // Source.Application
+	{name: "EventProductVersion", value: func(e *schema.SecurityEvent) interface{} { return e.Source.Version }},    // Source.Version
+	{name: "EventOriginalUid", value: func(e *schema.SecurityEvent) interface{} { return e.Event.ID }},             // Event.ID
+	{name: "EventOriginalType", value: func(e *schema.SecurityEvent) interface{} { return e.Event.Type }},          // Event.Type
+	{name: "EventMessage", value: func(e *schema.SecurityEvent) interface{} { return e.Message }},                  // Message
+	{name: "EventStartTime", value: func(e *schema.SecurityEvent) interface{} { return e.Timestamp }},              // Timestamp
+	{name: "EventEndTime", value: func(e *schema.SecurityEvent) interface{} { return e.Timestamp }},                // Timestamp
+	{name: "EventResult", value: func(e *schema.SecurityEvent) interface{} { return asimResult(e.Result.Status) }}, // Result.Status: Success, Failure or NA
+	{name: asimResultDetails, value: func(e *schema.SecurityEvent) interface{} { return e.Compliance.Status }},     // Compliance.Status
+	{name: "EventOriginalResultDetails", value: func(e *schema.SecurityEvent) interface{} { return e.Result.Status }},
+	{name: asimSeverity, value: func(e *schema.SecurityEvent) interface{} { return asimSeverityOf(e) }},                        // Finding.Severity
+	{name: "EventOriginalSeverity", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.Severity }},            // Finding.Severity
+	{name: "RuleName", value: func(e *schema.SecurityEvent) interface{} { return signatureID(e) }},                             // Compliance.Control, or Finding.Type
+	{name: "ThreatRiskLevel", value: func(e *schema.SecurityEvent) interface{} { return asimRiskLevel(e.RiskScore) }},          // RiskScore, 0 to 100
+	{name: "ActorUsername", value: func(e *schema.SecurityEvent) interface{} { return e.Source.User }},                         // Source.User
+	{name: "SrcIpAddr", value: func(e *schema.SecurityEvent) interface{} { return e.Source.IPAddress }},                        // Source.IPAddress
+	{name: "TargetResourceId", value: func(e *schema.SecurityEvent) interface{} { return e.Target.ID }},                        // Target.ID
+	{name: "TargetFilePath", value: func(e *schema.SecurityEvent) interface{} { return filePath(e) }},                          // Finding.Location.FilePath
+	{name: "TargetIpAddr", value: func(e *schema.SecurityEvent) interface{} { return e.Target.IPAddress }},                     // Target.IPAddress
+	{name: "AdditionalFields", value: func(e *schema.SecurityEvent) interface{} { return asimAdditionalFields(e) }},            // Compliance, vulnerability, supply chain, process, container and URL fields
+	{name: "AttackTechniques", value: func(e *schema.SecurityEvent) interface{} { return strings.Join(techniqueIDs(e), ",") }}, // Threat.TechniqueIDs
+}
+
+// asimAuditEventFields maps compliance findings to the ASIM Audit Event schema
+var asimAuditEventFields = append([]field{
+	{name: "EventSchema", value: func(*schema.SecurityEvent) interface{} { return asimSchemaAuditEvent }},
+	{name: "EventType", value: func(*schema.SecurityEvent) interface{} { return "Other" }},
+	{name: "Operation", value: func(e *schema.SecurityEvent) interface{} { return e.Action.Type }},          // Action.Type
+	{name: "Object", value: func(e *schema.SecurityEvent) interface{} { return e.Target.Resource }},         // Target.Resource
+	{name: "ObjectType", value: func(e *schema.SecurityEvent) interface{} { return e.Target.ResourceType }}, // Target.ResourceType
+}, asimCommonFields...)
+
+// asimAlertFields maps vulnerability and detection findings to the ASIM Alert schema
+var asimAlertFields = append([]field{
+	{name: "EventSchema", value: func(*schema.SecurityEvent) interface{} { return asimSchemaAlert }},
+	{name: "EventType", value: func(*schema.SecurityEvent) interface{} { return "Alert" }},
+	{name: "AlertId", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.ID }},                   // Finding.ID
+	{name: "AlertName", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.Title }},              // Finding.Title
+	{name: "AlertDescription", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.Description }}, // Finding.Description
+	{name: "DetectionMethod", value: func(e *schema.SecurityEvent) interface{} { return asimDetectionMethod(e) }},
+	{name: "ThreatId", value: func(e *schema.SecurityEvent) interface{} { return vulnerabilityID(e) }},    // Vulnerability.ID
+	{name: "DvcAction", value: func(e *schema.SecurityEvent) interface{} { return e.Action.Type }},        // Action.Type
+	{name: "TargetHostname", value: func(e *schema.SecurityEvent) interface{} { return asimHostname(e) }}, // Target.Resource of host targets
+}, asimCommonFields...)
+
+// asimResult maps the result of an event to Success, Failure or NA
+func asimResult(status string) string {
+	switch strings.ToLower(status) {
+	case "pass", "success", "succeeded", "allowed":
+		return "Success"
+	case "fail", "failure", "failed", "error", "denied", "blocked":
+		return "Failure"
+	default:
+		return "NA"
+	}
+}
+
+// asimSeverityOf returns the ASIM severity of a finding: High, Medium, Low or Informational
+// ASIM has no critical severity; critical findings are High
+func asimSeverityOf(e *schema.SecurityEvent) string {
+	switch strings.ToUpper(e.Finding.Severity) {
+	case "CRITICAL", "HIGH":
+		return "High"
+	case "MEDIUM":
+		return "Medium"
+	case "LOW":
+		return "Low"
+	default:
+		return "Informational"
+	}
+}
+
+// asimRiskLevel scales a risk score from 0-10 to the 0-100 ASIM risk level
+func asimRiskLevel(riskScore float64) int64 {
+	return int64(math.Round(min(max(riskScore, 0), 10) * 10))
+}
+
+// asimHostname returns the name of a host target, or ""
+func asimHostname(e *schema.SecurityEvent) string {
+	if e.Target.ResourceType != "host" {
+		return ""
+	}
+	return e.Target.Resource
+}
+
+// asimDetectionMethod returns the detection method of a finding: vulnerability scans or the event category
+func asimDetectionMethod(e *schema.SecurityEvent) string {
+	if e.Vulnerability != nil {
+		return "Vulnerability"
+	}
+	return e.Event.Category
+}
+
+// asimAdditionalFields returns the fields without ASIM equivalent, or nil if none is set
+//
+//nolint:gocyclo // One branch per optional field
+func asimAdditionalFields(e *schema.SecurityEvent) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if standards := standards(e); len(standards) > 0 {
+		fields["ComplianceStandards"] = stringsToRaw(standards)
+	}
+	if requirements := requirements(e); len(requirements) > 0 {
+		fields["ComplianceRequirements"] = stringsToRaw(requirements)
+	}
+	if e.Compliance.Control != "" {
+		fields["ComplianceControl"] = e.Compliance.Control
+	}
+	if e.Finding.URL != "" {
+		fields["FindingUrl"] = e.Finding.URL
+	}
+	if e.Vulnerability != nil {
+		if e.Vulnerability.EPSS != nil {
+			fields["EpssScore"] = e.Vulnerability.EPSS.Score
+		}
+		if e.Vulnerability.KEV {
+			fields["CisaKev"] = true
+		}
+		if e.Vulnerability.CVSS != nil {
+			fields["CvssScore"] = e.Vulnerability.CVSS.BaseScore
+			if e.Vulnerability.CVSS.Vector != "" {
+				fields["CvssVector"] = e.Vulnerability.CVSS.Vector
+			}
+		}
+		if pkg := e.Vulnerability.Package; pkg != nil {
+			fields["PackageName"] = pkg.Name
+			if pkg.Version != "" {
+				fields["PackageVersion"] = pkg.Version
+			}
+			if pkg.PURL != "" {
+				fields["PackagePurl"] = pkg.PURL
+			}
+		}
+		if e.Vulnerability.FixState != "" {
+			fields["FixState"] = e.Vulnerability.FixState
+		}
+		if e.Vulnerability.VEXState != "" {
+			fields["VexState"] = e.Vulnerability.VEXState
+		}
+	}
+	if sc := e.SupplyChain; sc != nil {
+		putNonEmpty(fields, "ImageName", sc.Image)
+		putNonEmpty(fields, "ImageDigest", sc.Digest)
+		putNonEmpty(fields, "Signer", sc.Signer)
+		putNonEmpty(fields, "SignerIssuer", sc.Issuer)
+		putNonEmpty(fields, "AttestationType", sc.AttestationType)
+	}
+	if p := e.Process; p != nil {
+		if p.PID != 0 {
+			fields["ProcessId"] = p.PID
+		}
+		putNonEmpty(fields, "ProcessPath", p.Binary)
+		putNonEmpty(fields, "ProcessCommandLine", p.CommandLine())
+		if parent := p.Parent; parent != nil {
+			if parent.PID != 0 {
+				fields["ParentProcessId"] = parent.PID
+			}
+			putNonEmpty(fields, "ParentProcessPath", parent.Binary)
+			putNonEmpty(fields, "ParentProcessCommandLine", parent.CommandLine())
+		}
+	}
+	if c := e.Container; c != nil {
+		putNonEmpty(fields, "ContainerId", c.ID)
+		putNonEmpty(fields, "ContainerName", c.Name)
+		putNonEmpty(fields, "ContainerImage", c.Image)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// putNonEmpty sets a field to a value, unless the value is empty
+func putNonEmpty(fields map[string]interface{}, name, value string) {
+	if value != "" {
+		fields[name] = value
+	}
+}
+
+// stringsToRaw converts strings to raw slice values
+func stringsToRaw(values []string) []interface{} {
+	raw := make([]interface{}, len(values))
+	for i, value := range values {
+		raw[i] = value
+	}
+	return raw
+}
@@ -0,0 +1,266 @@
+// Package profile lays out security events as the attributes expected by a SIEM or observability backend.
+//
+// The dynatrace profile is the flat attribute layout written by the schema package. The other profiles
+// are produced from the same schema.SecurityEvent through mapping tables, one field per attribute.
+package profile
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Output profiles
+const (
+	// Dynatrace is the flat security event attributes ingested by Dynatrace
+	Dynatrace = "dynatrace"
+	// SplunkCIM is the Splunk Common Information Model, Vulnerabilities and Alerts data models
+	SplunkCIM = "splunk_cim"
+	// GoogleUDM is the Google Security Operations Unified Data Model
+	GoogleUDM = "google_udm"
+	// ASIM is the Microsoft Sentinel Advanced Security Information Model, Audit Event and Alert schemas
+	ASIM = "asim"
+)
+
+// Valid reports whether name is a known output profile
+func Valid(name string) bool {
+	switch name {
+	case Dynatrace, SplunkCIM, GoogleUDM, ASIM:
+		return true
+	default:
+		return false
+	}
+}
+
+// SeverityAttribute returns the attribute holding the severity of the security events of a profile
+func SeverityAttribute(name string) string {
+	return Attribute(name, schema.AttrFindingSeverity)
+}
+
+// ComplianceStatusAttribute returns the attribute holding the compliance status of the security events of a profile
+func ComplianceStatusAttribute(name string) string {
+	return Attribute(name, schema.AttrComplianceStatus)
+}
+
+// attributes maps dynatrace attributes to the attributes carrying the same security event field in the
+// other profiles, for the consumers reading the events back
+var attributes = map[string]map[string]string{
+	SplunkCIM: {
+		schema.AttrEventType:        cimTag,
+		schema.AttrFindingSeverity:  cimSeverity,
+		schema.AttrFindingType:      "signature_id",
+		schema.AttrComplianceStatus: cimComplianceStatus,
+		schema.AttrObjectID:         "dest",
+		schema.AttrObjectType:       "dest_type",
+	},
+	GoogleUDM: {
+		schema.AttrEventType:        "metadata.product_event_type",
+		schema.AttrFindingSeverity:  udmSeverity,
+		schema.AttrFindingType:      "security_result.category_details",
+		schema.AttrComplianceStatus: udmComplianceStatus,
+		schema.AttrObjectID:         "target.resource.product_object_id",
+		schema.AttrObjectType:       "target.resource.resource_subtype",
+	},
+	ASIM: {
+		schema.AttrEventType:        "EventOriginalType",
+		schema.AttrFindingSeverity:  asimSeverity,
+		schema.AttrFindingType:      "RuleName",
+		schema.AttrComplianceStatus: asimResultDetails,
+		schema.AttrObjectID:         "TargetResourceId",
+		schema.AttrObjectType:       "ObjectType",
+	},
+}
+
+// Attribute returns the attribute of a profile carrying the same field as the dynatrace attribute attr
+// Attributes without an equivalent, such as the Kubernetes fields written by every profile, are returned as is
+// The splunk_cim profile has no object UID: object.id maps to the dest name
+func Attribute(name, attr string) string {
+	if mapped, ok := attributes[name][attr]; ok {
+		return mapped
+	}
+	return attr
+}
+
+// field maps a security event field to an attribute of a profile
+type field struct {
+	// name is the attribute name in the profile
+	name string
+
+	// value returns the attribute value: a string, int64, float64, bool, []string or map[string]interface{}
+	// Empty strings, false, empty slices and empty maps are not written
+	value func(e *schema.SecurityEvent) interface{}
+}
+
+// PutAttributes writes the attributes of a security event in the layout of a profile, followed by the
+// Kubernetes fields of its target
+// An empty or unknown profile is the dynatrace profile
+func PutAttributes(attrs pcommon.Map, event *schema.SecurityEvent, name string) {
+	var fields []field
+	switch name {
+	case SplunkCIM:
+		fields = splunkCIMFields(event)
+	case GoogleUDM:
+		fields = googleUDMFields
+	case ASIM:
+		fields = asimFields(event)
+	default:
+		event.PutAttributes(attrs)
+		return
+	}
+
+	for i := range fields {
+		putValue(attrs, fields[i].name, fields[i].value(event))
+	}
+	schema.PutFields(attrs, event.Target.Kubernetes)
+}
+
+// putValue writes a field value, skipping empty values
+func putValue(attrs pcommon.Map, key string, value interface{}) {
+	switch value := value.(type) {
+	case string:
+		if value != "" {
+			attrs.PutStr(key, value)
+		}
+	case int64:
+		attrs.PutInt(key, value)
+	case float64:
+		attrs.PutDouble(key, value)
+	case bool:
+		if value {
+			attrs.PutBool(key, true)
+		}
+	case []string:
+		if len(value) > 0 {
+			slice := attrs.PutEmptySlice(key)
+			slice.EnsureCapacity(len(value))
+			for _, item := range value {
+				slice.AppendEmpty().SetStr(item)
+			}
+		}
+	case map[string]interface{}:
+		if len(value) > 0 {
+			// FromRaw only fails on unsupported value types, which the mapping tables do not produce
+			_ = attrs.PutEmptyMap(key).FromRaw(value)
+		}
+	}
+}
+
+// vulnerabilityID returns the identifier of the vulnerability of a finding, or ""
+func vulnerabilityID(e *schema.SecurityEvent) string {
+	if e.Vulnerability == nil {
+		return ""
+	}
+	return e.Vulnerability.ID
+}
+
+// cvss returns the CVSS rating of a vulnerability finding, empty if unknown
+func cvss(e *schema.SecurityEvent) schema.CVSS {
+	if e.Vulnerability == nil || e.Vulnerability.CVSS == nil {
+		return schema.CVSS{}
+	}
+	return *e.Vulnerability.CVSS
+}
+
+// cvssScore returns the CVSS base score of a vulnerability finding, or nil if unknown
+// A score of 0.0 is a valid rating, so unknown scores are not written as 0
+func cvssScore(e *schema.SecurityEvent) interface{} {
+	if e.Vulnerability == nil || e.Vulnerability.CVSS == nil {
+		return nil
+	}
+	return e.Vulnerability.CVSS.BaseScore
+}
+
+// vulnerablePackage returns the package affected by a vulnerability finding, empty if unknown
+func vulnerablePackage(e *schema.SecurityEvent) schema.Package {
+	if e.Vulnerability == nil || e.Vulnerability.Package == nil {
+		return schema.Package{}
+	}
+	return *e.Vulnerability.Package
+}
+
+// supplyChain returns the image verification fields of a supply chain finding, empty if unknown
+func supplyChain(e *schema.SecurityEvent) schema.SupplyChain {
+	if e.SupplyChain == nil {
+		return schema.SupplyChain{}
+	}
+	return *e.SupplyChain
+}
+
+// process returns the process of a runtime detection, empty if unknown
+func process(e *schema.SecurityEvent) schema.Process {
+	if e.Process == nil {
+		return schema.Process{}
+	}
+	return *e.Process
+}
+
+// parentProcess returns the parent of the process of a runtime detection, empty if unknown
+func parentProcess(e *schema.SecurityEvent) schema.Process {
+	if e.Process == nil || e.Process.Parent == nil {
+		return schema.Process{}
+	}
+	return *e.Process.Parent
+}
+
+// pid returns the PID of a process, or nil if unknown
+func pid(p schema.Process) interface{} {
+	if p.PID == 0 {
+		return nil
+	}
+	return p.PID
+}
+
+// commandLine returns the command line of a process, or ""
+func commandLine(p schema.Process) string {
+	return p.CommandLine()
+}
+
+// container returns the container of a runtime detection, empty if unknown
+func container(e *schema.SecurityEvent) schema.Container {
+	if e.Container == nil {
+		return schema.Container{}
+	}
+	return *e.Container
+}
+
+// techniqueIDs returns the MITRE ATT&CK technique identifiers of a finding, or nil
+func techniqueIDs(e *schema.SecurityEvent) []string {
+	if e.Threat == nil {
+		return nil
+	}
+	return e.Threat.TechniqueIDs
+}
+
+// filePath returns the file of a finding in a file, or ""
+func filePath(e *schema.SecurityEvent) string {
+	if e.Finding.Location == nil {
+		return ""
+	}
+	return e.Finding.Location.FilePath
+}
+
+// signatureID identifies the check behind a finding: its rule, or its policy without rule
+func signatureID(e *schema.SecurityEvent) string {
+	if e.Compliance.Control != "" {
+		return e.Compliance.Control
+	}
+	return e.Finding.Type
+}
+
+// standards returns the frameworks of the compliance check
+func standards(e *schema.SecurityEvent) []string {
+	return e.Compliance.StandardList()
+}
+
+// requirements returns the requirements or framework controls of the compliance check
+func requirements(e *schema.SecurityEvent) []string {
+	return e.Compliance.RequirementList()
+}
+
+// ipAddresses returns an IP address as a repeated field, or nil
+func ipAddresses(ip string) []string {
+	if ip == "" {
+		return nil
+	}
+	return []string{ip}
+}
@@ -0,0 +1,382 @@
+package profile
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// newComplianceEvent returns a Kyverno compliance finding on a pod, mapped to framework controls
+func newComplianceEvent() *schema.SecurityEvent {
+	return &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event: schema.Event{
+			ID:          "6f1c2d1e-0000-4000-8000-000000000001",
+			Version:     "1.309",
+			Category:    "COMPLIANCE",
+			Name:        "Compliance finding event",
+			Type:        "COMPLIANCE_FINDING",
+			Description: "Policy violation on app-7d9f8b6c5d-x2k4p for rule validate-resources",
+		},
+		Timestamp: "2025-09-19T06:51:02Z",
+		Message:   "validation error: CPU and memory resource requests and limits are required",
+		Source:    schema.Source{Application: "Kyverno", Vendor: "Nirmata"},
+		Action:    schema.Action{Type: "policy_evaluation"},
+		Result:    schema.Result{Status: "fail"},
+		Target: schema.Target{
+			ID:           "pod-uid-1",
+			Resource:     "app-7d9f8b6c5d-x2k4p",
+			ResourceType: "Pod",
+			EntityType:   "K8S_POD",
+			Kubernetes: map[string]interface{}{
+				"k8s.namespace.name": "production",
+				"k8s.pod.name":       "app-7d9f8b6c5d-x2k4p",
+			},
+		},
+		RiskScore: 8.9,
+		Finding: schema.Finding{
+			ID:          "0c9a7e52-0000-4000-8000-000000000002",
+			Title:       "require-requests-limits - validate-resources",
+			Description: "validation error: CPU and memory resource requests and limits are required",
+			Severity:    "HIGH",
+			Type:        "require-requests-limits",
+		},
+		Compliance: schema.Compliance{
+			Control:      "validate-resources",
+			Requirement:  "require-requests-limits",
+			Requirements: []string{"CIS-5.2.1", "NIST-CM-6"},
+			Standards:    []string{"CIS Kubernetes Benchmark", "NIST 800-53"},
+			Status:       "NON_COMPLIANT",
+		},
+	}
+}
+
+// newVulnerabilityEvent returns a Trivy vulnerability finding with exploitation intelligence and
+// ATT&CK techniques
+func newVulnerabilityEvent() *schema.SecurityEvent {
+	event := newComplianceEvent()
+	event.Source = schema.Source{Application: "Trivy", Vendor: "Aqua Security"}
+	event.Message = "openssl 3.0.2 is vulnerable, upgrade to 3.0.7"
+	event.RiskScore = 10
+	event.Finding.Title = "vulnerability - CVE-2022-3602"
+	event.Finding.Type = "vulnerability"
+	event.Finding.Severity = "CRITICAL"
+	event.Finding.Description = event.Message
+	event.Finding.URL = "https://avd.aquasec.com/nvd/cve-2022-3602"
+	event.Compliance = schema.Compliance{
+		Control:     "CVE-2022-3602",
+		Requirement: "vulnerability",
+		Status:      "NON_COMPLIANT",
+	}
+	event.Vulnerability = &schema.Vulnerability{
+		ID:            "CVE-2022-3602",
+		EPSS:          &schema.EPSS{Score: 0.97, Percentile: 0.99},
+		KEV:           true,
+		Package:       &schema.Package{Name: "openssl", Version: "3.0.2", PURL: "pkg:deb/ubuntu/openssl@3.0.2", Type: "deb"},
+		CVSS:          &schema.CVSS{Version: "3.1", Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", BaseScore: 7.5},
+		FixState:      "fixed",
+		FixedVersions: []string{"3.0.7"},
+	}
+	event.Threat = &schema.Threat{
+		Framework:      "MITRE ATT&CK",
+		TechniqueIDs:   []string{"T1190"},
+		TechniqueNames: []string{"Exploit Public-Facing Application"},
+		TacticNames:    []string{"Initial Access"},
+	}
+	return event
+}
+
+// newSupplyChainEvent returns a Kyverno image verification failure on a pod
+func newSupplyChainEvent() *schema.SecurityEvent {
+	event := newComplianceEvent()
+	event.Event.Category = "SUPPLY_CHAIN"
+	event.Event.Name = "Supply chain finding event"
+	event.Event.Type = "SUPPLY_CHAIN_FINDING"
+	event.Event.Description = "Image verification failed for ghcr.io/org/app:1.0 on app-7d9f8b6c5d-x2k4p for rule check-signature"
+	event.Message = "failed to verify image ghcr.io/org/app:1.0: .attestors[0].entries[0].keyless: subject mismatch"
+	event.Finding.Title = "verify-image-signatures - check-signature"
+	event.Finding.Type = "verify-image-signatures"
+	event.Finding.Description = event.Message
+	event.Compliance = schema.Compliance{
+		Control:     "check-signature",
+		Requirement: "verify-image-signatures",
+		Status:      "NON_COMPLIANT",
+	}
+	event.SupplyChain = &schema.SupplyChain{
+		Image:           "ghcr.io/org/app:1.0",
+		Digest:          "sha256:5b0c1f2e2d8c4c7e9a510d7f1c2b3a415b0c1f2e2d8c4c7e9a510d7f1c2b3a41",
+		Signer:          "https://github.com/org/app/.github/workflows/release.yml@refs/tags/v1.0",
+		Issuer:          "https://token.actions.githubusercontent.com",
+		AttestationType: "https://slsa.dev/provenance/v1",
+	}
+	return event
+}
+
+// newRuntimeEvent returns a Tetragon policy match that killed a process reading a sensitive file in a pod
+func newRuntimeEvent() *schema.SecurityEvent {
+	return &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event: schema.Event{
+			ID:          "6f1c2d1e-0000-4000-8000-000000000005",
+			Version:     "1.309",
+			Category:    "DETECTION",
+			Name:        "Detection finding event",
+			Type:        "DETECTION_FINDING",
+			Description: "security_file_permission by /usr/bin/cat on default/xwing-6d9bd5c8b9-7kq2p for policy file-monitoring",
+		},
+		Timestamp: "2025-10-18T12:00:01.71332758Z",
+		Message:   "Sensitive file read",
+		Source:    schema.Source{Application: "Tetragon", Vendor: "Cilium"},
+		Action:    schema.Action{Type: "blocked"},
+		Target: schema.Target{
+			ID:           "0f3b5c39-5b6e-4d4c-9f0e-6a3c1f7f0d21",
+			Resource:     "xwing-6d9bd5c8b9-7kq2p",
+			ResourceType: "Pod",
+			EntityType:   "K8S_POD",
+			Kubernetes: map[string]interface{}{
+				"k8s.namespace.name": "default",
+				"k8s.pod.name":       "xwing-6d9bd5c8b9-7kq2p",
+				"k8s.container.name": "spaceship",
+				"k8s.node.name":      "kind-control-plane",
+			},
+		},
+		Finding: schema.Finding{
+			ID:          "0c9a7e52-0000-4000-8000-000000000006",
+			Title:       "file-monitoring - security_file_permission",
+			Description: "Sensitive file read",
+			Type:        "file-monitoring",
+		},
+		Process: &schema.Process{
+			PID:       52699,
+			Binary:    "/usr/bin/cat",
+			Arguments: "/etc/shadow",
+			Parent:    &schema.Process{PID: 52654, Binary: "/bin/bash", Arguments: `-c "cat /etc/shadow"`},
+		},
+		Container: &schema.Container{
+			ID:    "551e161c47d8ff0eb665438a7bcd5b4e3ef5a297282b40a92b7c77d6bd168eb3",
+			Name:  "spaceship",
+			Image: "docker.io/tgraf/netperf:latest",
+		},
+		Threat: &schema.Threat{
+			Framework:      "MITRE ATT&CK",
+			TechniqueIDs:   []string{"T1003.008"},
+			TechniqueNames: []string{"/etc/passwd and /etc/shadow"},
+			TacticNames:    []string{"Credential Access"},
+		},
+	}
+}
+
+// newDetectionEvent returns a firewall detection finding parsed from a CEF record
+func newDetectionEvent() *schema.SecurityEvent {
+	return &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event: schema.Event{
+			ID:          "6f1c2d1e-0000-4000-8000-000000000003",
+			Version:     "1.309",
+			Category:    "DETECTION",
+			Name:        "Detection finding event",
+			Type:        "DETECTION_FINDING",
+			Description: "Port scan detected from alice to db01",
+		},
+		Timestamp: "2025-10-18T12:00:00Z",
+		Message:   "Port scan from 10.0.0.5",
+		Source: schema.Source{
+			User:        "alice",
+			IPAddress:   "10.0.0.5",
+			Application: "PAN-OS",
+			Vendor:      "Palo Alto Networks",
+		},
+		Target: schema.Target{
+			ID:           "db01",
+			Resource:     "db01",
+			ResourceType: "host",
+			IPAddress:    "192.168.1.20",
+		},
+		Action:    schema.Action{Type: "blocked"},
+		Result:    schema.Result{Status: "failure"},
+		RiskScore: 8,
+		Finding: schema.Finding{
+			ID:          "threat-42",
+			Title:       "Port scan detected",
+			Description: "Port scan from 10.0.0.5",
+			Severity:    "HIGH",
+			Type:        "THREAT",
+		},
+	}
+}
+
+// newCodeEvent returns a static analysis finding parsed from a SARIF result
+func newCodeEvent() *schema.SecurityEvent {
+	return &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event: schema.Event{
+			ID:          "6f1c2d1e-0000-4000-8000-000000000004",
+			Version:     "1.309",
+			Category:    "DETECTION",
+			Name:        "Detection finding event",
+			Type:        "DETECTION_FINDING",
+			Description: "Database query built from user-controlled sources in src/app/handler.go:42",
+		},
+		Timestamp: "2025-10-18T12:00:00Z",
+		Message:   "This query depends on a user-provided value.",
+		Source:    schema.Source{Application: "CodeQL", Vendor: "GitHub", Version: "2.19.0"},
+		Target: schema.Target{
+			ID:           "src/app/handler.go",
+			Resource:     "src/app/handler.go",
+			ResourceType: "file",
+		},
+		RiskScore: 8.8,
+		Finding: schema.Finding{
+			ID:          "3d3bb4a0-0000-5000-8000-000000000005",
+			Title:       "Database query built from user-controlled sources",
+			Description: "This query depends on a user-provided value.",
+			Severity:    "HIGH",
+			Type:        "go/sql-injection",
+			URL:         "https://codeql.github.com/codeql-query-help/go/go-sql-injection/",
+			Location:    &schema.Location{FilePath: "src/app/handler.go", Line: 42, Column: 7},
+		},
+	}
+}
+
+func TestPutAttributes_Golden(t *testing.T) {
+	events := map[string]*schema.SecurityEvent{
+		"compliance":    newComplianceEvent(),
+		"vulnerability": newVulnerabilityEvent(),
+		"detection":     newDetectionEvent(),
+		"code":          newCodeEvent(),
+		"supply_chain":  newSupplyChainEvent(),
+		"runtime":       newRuntimeEvent(),
+	}
+
+	for _, name := range []string{Dynatrace, SplunkCIM, GoogleUDM, ASIM} {
+		for eventName, event := range events {
+			t.Run(name+"/"+eventName, func(t *testing.T) {
+				attrs := pcommon.NewMap()
+				PutAttributes(attrs, event, name)
+				got, err := json.MarshalIndent(attrs.AsRaw(), "", "  ")
+				require.NoError(t, err)
+
+				golden := filepath.Join("testdata", name, eventName+".json")
+				if *update {
+					require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0o750))
+					require.NoError(t, os.WriteFile(golden, append(got, '\n'), 0o600))
+				}
+				want, err := os.ReadFile(golden)
+				require.NoError(t, err)
+				assert.Equal(t, string(want), string(got)+"\n")
+			})
+		}
+	}
+}
+
+func TestPutAttributes_DefaultProfile(t *testing.T) {
+	event := newComplianceEvent()
+	want := pcommon.NewMap()
+	event.PutAttributes(want)
+
+	for _, name := range []string{"", "unknown"} {
+		attrs := pcommon.NewMap()
+		PutAttributes(attrs, event, name)
+		assert.Equal(t, want.AsRaw(), attrs.AsRaw(), name)
+	}
+}
+
+func TestPutAttributes_SeverityAttribute(t *testing.T) {
+	event := newComplianceEvent()
+	event.Finding.Severity = ""
+
+	tests := []struct {
+		profile string
+		want    interface{}
+	}{
+		{profile: Dynatrace, want: nil},
+		{profile: SplunkCIM, want: "informational"},
+		{profile: GoogleUDM, want: "INFORMATIONAL"},
+		{profile: ASIM, want: "Informational"},
+	}
+	for _, tt := range tests {
+		attrs := pcommon.NewMap()
+		PutAttributes(attrs, event, tt.profile)
+		assert.Equal(t, tt.want, attrs.AsRaw()[SeverityAttribute(tt.profile)], tt.profile)
+	}
+}
+
+func TestPutAttributes_ComplianceStatusAttribute(t *testing.T) {
+	for _, name := range []string{Dynatrace, SplunkCIM, GoogleUDM, ASIM} {
+		attrs := pcommon.NewMap()
+		PutAttributes(attrs, newComplianceEvent(), name)
+		assert.Equal(t, "NON_COMPLIANT", attrs.AsRaw()[ComplianceStatusAttribute(name)], name)
+	}
+}
+
+func TestPutAttributes_Attribute(t *testing.T) {
+	keys := []string{
+		schema.AttrEventType, schema.AttrFindingSeverity, schema.AttrFindingType,
+		schema.AttrComplianceStatus, schema.AttrObjectID, schema.AttrObjectType,
+	}
+	for _, name := range []string{Dynatrace, SplunkCIM, GoogleUDM, ASIM} {
+		attrs := pcommon.NewMap()
+		PutAttributes(attrs, newComplianceEvent(), name)
+		for _, key := range keys {
+			assert.Contains(t, attrs.AsRaw(), Attribute(name, key), "%s: %s", name, key)
+		}
+	}
+	assert.Equal(t, "k8s.namespace.name", Attribute(ASIM, "k8s.namespace.name"), "unmapped attributes are returned as is")
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid(Dynatrace))
+	assert.True(t, Valid(SplunkCIM))
+	assert.True(t, Valid(GoogleUDM))
+	assert.True(t, Valid(ASIM))
+	assert.False(t, Valid(""))
+	assert.False(t, Valid("splunk"))
+}
+
+func TestUDMAction(t *testing.T) {
+	assert.Equal(t, "BLOCK", udmAction("Denied"))
+	assert.Equal(t, "ALLOW", udmAction("permit"))
+	assert.Empty(t, udmAction("policy_evaluation"))
+	assert.Empty(t, udmAction(""))
+}
+
+func TestASIMFields(t *testing.T) {
+	// Compliance findings are audit events; vulnerability and detection findings are alerts
+	assert.Equal(t, "AuditEvent", asimValue(t, newComplianceEvent(), "EventSchema"))
+	assert.Equal(t, "Alert", asimValue(t, newVulnerabilityEvent(), "EventSchema"))
+	assert.Equal(t, "Alert", asimValue(t, newDetectionEvent(), "EventSchema"))
+
+	assert.Equal(t, "db01", asimValue(t, newDetectionEvent(), "TargetHostname"))
+	assert.Nil(t, asimValue(t, newVulnerabilityEvent(), "TargetHostname"))
+}
+
+// asimValue returns the raw value of an attribute of an event in the asim profile, or nil
+func asimValue(t *testing.T, event *schema.SecurityEvent, key string) interface{} {
+	t.Helper()
+	attrs := pcommon.NewMap()
+	PutAttributes(attrs, event, ASIM)
+	return attrs.AsRaw()[key]
+}
+
+func TestASIMResult(t *testing.T) {
+	assert.Equal(t, "Success", asimResult("pass"))
+	assert.Equal(t, "Failure", asimResult("Blocked"))
+	assert.Equal(t, "NA", asimResult("skip"))
+	assert.Equal(t, "NA", asimResult(""))
+}
+
+func TestASIMRiskLevel(t *testing.T) {
+	assert.Equal(t, int64(89), asimRiskLevel(8.9))
+	assert.Equal(t, int64(100), asimRiskLevel(12))
+	assert.Equal(t, int64(0), asimRiskLevel(-1))
+}
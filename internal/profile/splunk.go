@@ -0,0 +1,97 @@
+package profile
+
+import (
+	"strings"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Splunk CIM field names used outside the mapping tables
+const (
+	cimSeverity         = "severity"
+	cimComplianceStatus = "compliance_status"
+	cimTag              = "tag"
+)
+
+// splunkCIMFields returns the mapping table of a security event: vulnerability findings map to the
+// Vulnerabilities data model, other events to the Alerts data model
+func splunkCIMFields(e *schema.SecurityEvent) []field {
+	if e.Vulnerability != nil {
+		return cimVulnerabilityFields
+	}
+	return cimAlertFields
+}
+
+// cimVulnerabilityFields maps vulnerability findings to the CIM Vulnerabilities data model
+var cimVulnerabilityFields = []field{
+	{name: "category", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.Type }},            // Finding.Type
+	{name: "cve", value: func(e *schema.SecurityEvent) interface{} { return vulnerabilityID(e) }},             // Vulnerability.ID
+	{name: "cvss", value: func(e *schema.SecurityEvent) interface{} { return cvssScore(e) }},                  // Vulnerability.CVSS.BaseScore
+	{name: "dest", value: func(e *schema.SecurityEvent) interface{} { return cimDest(e) }},                    // Target.Resource, or Target.IPAddress
+	{name: "dest_type", value: func(e *schema.SecurityEvent) interface{} { return e.Target.ResourceType }},    // Target.ResourceType
+	{name: "id", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.ID }},                    // Finding.ID
+	{name: "risk_score", value: func(e *schema.SecurityEvent) interface{} { return e.RiskScore }},             // RiskScore
+	{name: cimSeverity, value: func(e *schema.SecurityEvent) interface{} { return cimSeverityOf(e) }},         // Finding.Severity, lower-cased
+	{name: "signature", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.Title }},          // Finding.Title
+	{name: "signature_id", value: func(e *schema.SecurityEvent) interface{} { return signatureID(e) }},        // Compliance.Control, or Finding.Type
+	{name: "url", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.URL }},                  // Finding.URL
+	{name: "vendor_product", value: func(e *schema.SecurityEvent) interface{} { return cimVendorProduct(e) }}, // Source.Vendor Source.Application
+	{name: "xref", value: func(e *schema.SecurityEvent) interface{} { return techniqueIDs(e) }},               // Threat.TechniqueIDs
+	{name: cimTag, value: func(*schema.SecurityEvent) interface{} { return []string{"vulnerability", "report"} }},
+}
+
+// cimAlertFields maps compliance and detection findings to the CIM Alerts data model
+var cimAlertFields = []field{
+	{name: "action", value: func(e *schema.SecurityEvent) interface{} { return e.Action.Type }},                    // Action.Type
+	{name: "app", value: func(e *schema.SecurityEvent) interface{} { return e.Source.Application }},                // Source.Application
+	{name: "body", value: func(e *schema.SecurityEvent) interface{} { return e.Message }},                          // Message
+	{name: "description", value: func(e *schema.SecurityEvent) interface{} { return e.Event.Description }},         // Event.Description
+	{name: "dest", value: func(e *schema.SecurityEvent) interface{} { return cimDest(e) }},                         // Target.Resource, or Target.IPAddress
+	{name: "dest_type", value: func(e *schema.SecurityEvent) interface{} { return e.Target.ResourceType }},         // Target.ResourceType
+	{name: "file_path", value: func(e *schema.SecurityEvent) interface{} { return filePath(e) }},                   // Finding.Location.FilePath
+	{name: "id", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.ID }},                         // Finding.ID
+	{name: "mitre_technique_id", value: func(e *schema.SecurityEvent) interface{} { return techniqueIDs(e) }},      // Threat.TechniqueIDs
+	{name: "result", value: func(e *schema.SecurityEvent) interface{} { return e.Result.Status }},                  // Result.Status
+	{name: "risk_score", value: func(e *schema.SecurityEvent) interface{} { return e.RiskScore }},                  // RiskScore
+	{name: cimSeverity, value: func(e *schema.SecurityEvent) interface{} { return cimSeverityOf(e) }},              // Finding.Severity, lower-cased
+	{name: "signature", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.Title }},               // Finding.Title
+	{name: "signature_id", value: func(e *schema.SecurityEvent) interface{} { return signatureID(e) }},             // Compliance.Control, or Finding.Type
+	{name: "src", value: func(e *schema.SecurityEvent) interface{} { return e.Source.IPAddress }},                  // Source.IPAddress
+	{name: "subject", value: func(e *schema.SecurityEvent) interface{} { return e.Event.Name }},                    // Event.Name
+	{name: "type", value: func(*schema.SecurityEvent) interface{} { return "alert" }},                              // Always "alert"
+	{name: "user", value: func(e *schema.SecurityEvent) interface{} { return e.Source.User }},                      // Source.User
+	{name: "vendor_product", value: func(e *schema.SecurityEvent) interface{} { return cimVendorProduct(e) }},      // Source.Vendor Source.Application
+	{name: cimComplianceStatus, value: func(e *schema.SecurityEvent) interface{} { return e.Compliance.Status }},   // Compliance.Status
+	{name: "compliance_standards", value: func(e *schema.SecurityEvent) interface{} { return standards(e) }},       // Compliance.Standards, or Compliance.Standard
+	{name: "compliance_requirements", value: func(e *schema.SecurityEvent) interface{} { return requirements(e) }}, // Compliance.Requirements, or Compliance.Requirement
+	{name: "process", value: func(e *schema.SecurityEvent) interface{} { return commandLine(process(e)) }},         // Process.Binary Process.Arguments
+	{name: "process_path", value: func(e *schema.SecurityEvent) interface{} { return process(e).Binary }},          // Process.Binary
+	{name: "process_id", value: func(e *schema.SecurityEvent) interface{} { return pid(process(e)) }},              // Process.PID
+	{name: "parent_process", value: func(e *schema.SecurityEvent) interface{} { return commandLine(parentProcess(e)) }},
+	{name: "parent_process_path", value: func(e *schema.SecurityEvent) interface{} { return parentProcess(e).Binary }},
+	{name: "parent_process_id", value: func(e *schema.SecurityEvent) interface{} { return pid(parentProcess(e)) }},
+	{name: cimTag, value: func(*schema.SecurityEvent) interface{} { return []string{"alert"} }},
+}
+
+// cimDest returns the name of the target, or its IP address
+func cimDest(e *schema.SecurityEvent) string {
+	if e.Target.Resource != "" {
+		return e.Target.Resource
+	}
+	return e.Target.IPAddress
+}
+
+// cimSeverityOf returns the CIM severity of a finding: critical, high, medium, low or informational
+func cimSeverityOf(e *schema.SecurityEvent) string {
+	switch severity := strings.ToLower(e.Finding.Severity); severity {
+	case "critical", "high", "medium", "low":
+		return severity
+	default:
+		return "informational"
+	}
+}
+
+// cimVendorProduct returns the vendor and product reporting the event, e.g. "Aqua Security Trivy"
+func cimVendorProduct(e *schema.SecurityEvent) string {
+	return strings.TrimSpace(e.Source.Vendor + " " + e.Source.Application)
+}
@@ -0,0 +1,124 @@
+package profile
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Google UDM field names used outside the mapping table
+const (
+	udmSeverity         = "security_result.severity"
+	udmComplianceStatus = "security_result.detection_fields.compliance_status"
+)
+
+// UDM event types
+const (
+	udmEventScanVulnHost      = "SCAN_VULN_HOST"
+	udmEventScanUncategorized = "SCAN_UNCATEGORIZED"
+	udmEventGeneric           = "GENERIC_EVENT"
+)
+
+// googleUDMFields maps security events to the UDM event model, as flattened field paths
+var googleUDMFields = []field{
+	// Event metadata
+	{name: "metadata.event_type", value: func(e *schema.SecurityEvent) interface{} { return udmEventType(e) }},          // Vulnerability, Compliance.Status, else generic
+	{name: "metadata.product_event_type", value: func(e *schema.SecurityEvent) interface{} { return e.Event.Type }},     // Event.Type
+	{name: "metadata.product_log_id", value: func(e *schema.SecurityEvent) interface{} { return e.Event.ID }},           // Event.ID
+	{name: "metadata.product_name", value: func(e *schema.SecurityEvent) interface{} { return e.Source.Application }},   // Source.Application
+	{name: "metadata.product_version", value: func(e *schema.SecurityEvent) interface{} { return e.Source.Version }},    // Source.Version
+	{name: "metadata.vendor_name", value: func(e *schema.SecurityEvent) interface{} { return e.Source.Vendor }},         // Source.Vendor
+	{name: "metadata.description", value: func(e *schema.SecurityEvent) interface{} { return e.Event.Description }},     // Event.Description
+	{name: "metadata.event_timestamp", value: func(e *schema.SecurityEvent) interface{} { return e.Timestamp }},         // Timestamp
+	{name: "principal.user.userid", value: func(e *schema.SecurityEvent) interface{} { return e.Source.User }},          // Source.User
+	{name: "principal.ip", value: func(e *schema.SecurityEvent) interface{} { return ipAddresses(e.Source.IPAddress) }}, // Source.IPAddress
+	// Target of the finding
+	{name: "target.resource.product_object_id", value: func(e *schema.SecurityEvent) interface{} { return e.Target.ID }}, // Target.ID
+	{name: "target.resource.name", value: func(e *schema.SecurityEvent) interface{} { return e.Target.Resource }},        // Target.Resource
+	{name: "target.resource.resource_subtype", value: func(e *schema.SecurityEvent) interface{} { return e.Target.ResourceType }},
+	{name: "target.file.full_path", value: func(e *schema.SecurityEvent) interface{} { return filePath(e) }},         // Finding.Location.FilePath
+	{name: "target.ip", value: func(e *schema.SecurityEvent) interface{} { return ipAddresses(e.Target.IPAddress) }}, // Target.IPAddress
+	// Security result of the finding
+	{name: "security_result.rule_id", value: func(e *schema.SecurityEvent) interface{} { return signatureID(e) }},                  // Compliance.Control, or Finding.Type
+	{name: "security_result.rule_name", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.Title }},               // Finding.Title
+	{name: "security_result.summary", value: func(e *schema.SecurityEvent) interface{} { return e.Message }},                       // Message
+	{name: "security_result.description", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.Description }},       // Finding.Description
+	{name: "security_result.category_details", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.Type }},         // Finding.Type
+	{name: udmSeverity, value: func(e *schema.SecurityEvent) interface{} { return udmSeverityOf(e) }},                              // Finding.Severity
+	{name: "security_result.risk_score", value: func(e *schema.SecurityEvent) interface{} { return e.RiskScore }},                  // RiskScore
+	{name: "security_result.action", value: func(e *schema.SecurityEvent) interface{} { return udmAction(e.Action.Type) }},         // Action.Type: ALLOW or BLOCK
+	{name: "security_result.action_details", value: func(e *schema.SecurityEvent) interface{} { return e.Action.Type }},            // Action.Type
+	{name: "security_result.detection_fields.result", value: func(e *schema.SecurityEvent) interface{} { return e.Result.Status }}, // Result.Status
+	{name: "security_result.url_back_to_product", value: func(e *schema.SecurityEvent) interface{} { return e.Finding.URL }},       // Finding.URL
+	{name: "security_result.attack_details.techniques.id", value: func(e *schema.SecurityEvent) interface{} { return techniqueIDs(e) }},
+	// Compliance fields, as detection fields
+	{name: udmComplianceStatus, value: func(e *schema.SecurityEvent) interface{} { return e.Compliance.Status }},
+	{name: "security_result.detection_fields.compliance_standards", value: func(e *schema.SecurityEvent) interface{} { return standards(e) }},
+	// Vulnerability fields
+	{name: "extensions.vulns.vulnerabilities.cve_id", value: func(e *schema.SecurityEvent) interface{} { return vulnerabilityID(e) }},
+	{name: "extensions.vulns.vulnerabilities.cisa_kev", value: func(e *schema.SecurityEvent) interface{} { return e.Vulnerability != nil && e.Vulnerability.KEV }},
+	{name: "extensions.vulns.vulnerabilities.cvss_base_score", value: func(e *schema.SecurityEvent) interface{} { return cvssScore(e) }},
+	{name: "extensions.vulns.vulnerabilities.cvss_vector", value: func(e *schema.SecurityEvent) interface{} { return cvss(e).Vector }},
+	{name: "extensions.vulns.vulnerabilities.cvss_version", value: func(e *schema.SecurityEvent) interface{} { return cvss(e).Version }},
+	{name: "target.asset.software.name", value: func(e *schema.SecurityEvent) interface{} { return vulnerablePackage(e).Name }},
+	{name: "target.asset.software.version", value: func(e *schema.SecurityEvent) interface{} { return vulnerablePackage(e).Version }},
+	// Supply chain fields of image verifications, as detection fields
+	{name: "security_result.detection_fields.image", value: func(e *schema.SecurityEvent) interface{} { return supplyChain(e).Image }},
+	{name: "security_result.detection_fields.image_digest", value: func(e *schema.SecurityEvent) interface{} { return supplyChain(e).Digest }},
+	{name: "security_result.detection_fields.signer", value: func(e *schema.SecurityEvent) interface{} { return supplyChain(e).Signer }},
+	{name: "security_result.detection_fields.signer_issuer", value: func(e *schema.SecurityEvent) interface{} { return supplyChain(e).Issuer }},
+	{name: "security_result.detection_fields.attestation_type", value: func(e *schema.SecurityEvent) interface{} { return supplyChain(e).AttestationType }},
+	// Process tree and container of runtime detections
+	{name: "principal.process.pid", value: func(e *schema.SecurityEvent) interface{} { return udmPID(process(e)) }},
+	{name: "principal.process.file.full_path", value: func(e *schema.SecurityEvent) interface{} { return process(e).Binary }},
+	{name: "principal.process.command_line", value: func(e *schema.SecurityEvent) interface{} { return commandLine(process(e)) }},
+	{name: "principal.process.parent_process.pid", value: func(e *schema.SecurityEvent) interface{} { return udmPID(parentProcess(e)) }},
+	{name: "principal.process.parent_process.file.full_path", value: func(e *schema.SecurityEvent) interface{} { return parentProcess(e).Binary }},
+	{name: "principal.process.parent_process.command_line", value: func(e *schema.SecurityEvent) interface{} { return commandLine(parentProcess(e)) }},
+	{name: "security_result.detection_fields.container_id", value: func(e *schema.SecurityEvent) interface{} { return container(e).ID }},
+	{name: "security_result.detection_fields.container_name", value: func(e *schema.SecurityEvent) interface{} { return container(e).Name }},
+	{name: "security_result.detection_fields.container_image", value: func(e *schema.SecurityEvent) interface{} { return container(e).Image }},
+}
+
+// udmEventType classifies a security event: a vulnerability scan, a compliance scan, or a generic event
+func udmEventType(e *schema.SecurityEvent) string {
+	switch {
+	case e.Vulnerability != nil:
+		return udmEventScanVulnHost
+	case e.Compliance.Status != "":
+		return udmEventScanUncategorized
+	default:
+		return udmEventGeneric
+	}
+}
+
+// udmSeverityOf returns the UDM severity of a finding: CRITICAL, HIGH, MEDIUM, LOW or INFORMATIONAL
+func udmSeverityOf(e *schema.SecurityEvent) string {
+	switch severity := strings.ToUpper(e.Finding.Severity); severity {
+	case "CRITICAL", "HIGH", "MEDIUM", "LOW":
+		return severity
+	default:
+		return "INFORMATIONAL"
+	}
+}
+
+// udmPID returns the PID of a process as a UDM string, or "" if unknown
+func udmPID(p schema.Process) string {
+	if p.PID == 0 {
+		return ""
+	}
+	return strconv.FormatInt(p.PID, 10)
+}
+
+// udmAction maps the action of an event to ALLOW or BLOCK, or "" if the action is neither
+func udmAction(action string) string {
+	switch strings.ToLower(action) {
+	case "allow", "allowed", "accept", "accepted", "permit", "permitted", "pass":
+		return "ALLOW"
+	case "block", "blocked", "deny", "denied", "drop", "dropped", "reject", "rejected":
+		return "BLOCK"
+	default:
+		return ""
+	}
+}
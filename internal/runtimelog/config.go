@@ -0,0 +1,16 @@
+package runtimelog
+
+// Config defines the configuration for the runtime event processor
+type Config struct {
+	// Enabled indicates whether the runtime event processor is enabled
+	// Log records whose body holds a Cilium Tetragon or Aqua Tracee JSON event, as a JSON string or a parsed map,
+	// are turned into runtime detections; events matched by no policy (e.g. Tetragon process_exec events)
+	// are filtered out, and their log records pass through unchanged
+	Enabled bool `mapstructure:"enabled"`
+
+	// PolicyFilter is an array of policy names to process: Tetragon TracingPolicy names or Tracee policy names
+	// Only events matched by a policy in this list will be transformed into security events; the log records
+	// of the other events pass through unchanged
+	// If empty or not specified, the events of all policies will be processed
+	PolicyFilter []string `mapstructure:"policy_filter"`
+}
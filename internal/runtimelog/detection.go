@@ -0,0 +1,76 @@
+package runtimelog
+
+import (
+	"strings"
+	"time"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// detection is a runtime event of Tetragon or Tracee, normalised for the security event
+type detection struct {
+	// eventType is the event type of the sensor, e.g. process_kprobe or anti_debugging
+	eventType string
+	// hook is the kernel function, tracepoint or signature that matched, e.g. security_file_permission
+	hook string
+	// policies are the names of the policies that matched the event, empty if none did
+	policies []string
+
+	// tags are the tags of the policy, e.g. the Tetragon TracingPolicy tags
+	tags []string
+
+	// message is the message of the policy or signature, if any
+	message string
+	// enforced is set when the sensor stopped the operation or the process, e.g. a Tetragon sigkill action
+	enforced bool
+
+	process   *schema.Process
+	container *schema.Container
+
+	// Kubernetes context of the process, empty for host processes
+	namespace string
+	pod       string
+	podUID    string
+	node      string
+	// workload and workloadKind own the pod as reported by the sensor, derived from the pod name if empty
+	workload     string
+	workloadKind string
+
+	// severity and technique are set for the Tracee signatures that rate and classify their detections
+	severity  string
+	technique *technique
+
+	timestamp time.Time
+	metadata  map[string]interface{}
+}
+
+// technique is a MITRE ATT&CK technique reported by the sensor
+type technique struct {
+	id     string
+	name   string
+	tactic string
+}
+
+// containerID strips the runtime prefix of a container ID, e.g. "containerd://"
+func containerID(id string) string {
+	if _, after, ok := strings.Cut(id, "://"); ok {
+		return after
+	}
+	return id
+}
+
+// tacticName turns an ATT&CK tactic short name into its name, e.g. defense-evasion into Defense Evasion
+func tacticName(shortName string) string {
+	words := strings.FieldsFunc(shortName, func(r rune) bool { return r == '-' || r == '_' || r == ' ' })
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// putString sets a metadata field, unless the value is empty
+func putString(metadata map[string]interface{}, key, value string) {
+	if value != "" {
+		metadata[key] = value
+	}
+}
@@ -0,0 +1,354 @@
+// Package runtimelog turns the eBPF runtime events of Cilium Tetragon and Aqua Tracee into runtime detections,
+// with the process tree and the container and pod context of the process that matched a policy.
+package runtimelog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/attack"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/openreports"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Runtime event formats
+const (
+	FormatTetragon = "tetragon"
+	FormatTracee   = "tracee"
+)
+
+// Reasons for events that are not turned into security events
+const (
+	// FilterReasonNoPolicy marks events matched by no policy, e.g. Tetragon process executions
+	FilterReasonNoPolicy = "no_policy"
+	// FilterReasonPolicy marks events excluded by the policy_filter configuration
+	FilterReasonPolicy = "policy_filter"
+)
+
+// Reasons for log records that produce no security events
+const (
+	// SkipReasonNotRuntime marks log records whose body holds no Tetragon or Tracee event
+	SkipReasonNotRuntime = "not_runtime_event"
+)
+
+// Security event classification of the runtime detections
+const (
+	eventCategory = "DETECTION"
+	eventName     = "Detection finding event"
+	eventType     = "DETECTION_FINDING"
+
+	actionBlocked  = "blocked"
+	actionDetected = "detected"
+	kindPod        = "Pod"
+	targetHost     = "host"
+)
+
+// nodeNameAttribute is the resource attribute naming the node the sensor runs on
+const nodeNameAttribute = "k8s.node.name"
+
+// sources are the products of the security events, by format
+var sources = map[string]schema.Source{
+	FormatTetragon: {Application: "Tetragon", Vendor: "Cilium"},
+	FormatTracee:   {Application: "Tracee", Vendor: "Aqua Security"},
+}
+
+// Processor handles transformation of Tetragon and Tracee events into security events
+type Processor struct {
+	logger     *zap.Logger
+	config     *Config
+	techniques *attack.Mapping
+
+	// output lays out the security events in their log records
+	output processing.Output
+}
+
+// Option configures optional dependencies of the Processor
+type Option func(*Processor)
+
+// WithTechniqueMapping tags detections with the MITRE ATT&CK techniques mapped to their policy,
+// instead of the technique reported by a Tracee signature
+func WithTechniqueMapping(mapping *attack.Mapping) Option {
+	return func(p *Processor) {
+		p.techniques = mapping
+	}
+}
+
+// WithOutput sets the layout of the security events in their log records: the profile, attribute names,
+// body format and SIEM encoding
+func WithOutput(output processing.Output) Option {
+	return func(p *Processor) {
+		p.output = output
+	}
+}
+
+// NewProcessor creates a new runtime event processor
+func NewProcessor(logger *zap.Logger, config *Config, opts ...Option) (*Processor, error) {
+	p := &Processor{
+		logger: logger,
+		config: config,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	// The action and result of the security events are part of the source events
+	p.output.ActionFields = true
+	return p, nil
+}
+
+// Format performs a quick check to determine the format of the runtime event in the body of a log record,
+// as a JSON string or a map parsed from it: FormatTetragon, FormatTracee, or "" if none
+// The format is told by the top-level keys of the event, so a JSON log merely mentioning them is not an event:
+// a Tetragon event type holding an object, or Tracee eventName and processName strings
+func Format(logRecord *plog.LogRecord) string {
+	var values map[string]processing.JSONValue
+	body := logRecord.Body()
+	switch body.Type() {
+	case pcommon.ValueTypeStr:
+		values = processing.TopLevelValues(body.Str(), formatKeys...)
+	case pcommon.ValueTypeMap:
+		values = processing.MapValues(body.Map(), formatKeys...)
+	default:
+		return ""
+	}
+	for _, t := range tetragonEventTypes {
+		if values[t].Kind == processing.JSONObject {
+			return FormatTetragon
+		}
+	}
+	if values["eventName"].Kind == processing.JSONString && values["processName"].Kind == processing.JSONString {
+		return FormatTracee
+	}
+	return ""
+}
+
+// formatKeys are the top-level keys telling the format of a runtime event
+var formatKeys = append([]string{"eventName", "processName"}, tetragonEventTypes...)
+
+// ProcessLogRecord transforms a log record whose body holds a Tetragon or Tracee event into a runtime detection
+// The security event is appended to dst; nothing is appended if the body holds no event, or if the event
+// is matched by no allowed policy
+// The returned outcome counts the event as a single result
+// A *processing.StageError is returned if the event cannot be decoded or transformed;
+// nothing is appended to dst in that case
+func (p *Processor) ProcessLogRecord(
+	_ context.Context, logRecord *plog.LogRecord, resource pcommon.Resource, _ plog.ScopeLogs, dst plog.LogRecordSlice,
+) (processing.Outcome, error) {
+	var outcome processing.Outcome
+	format := Format(logRecord)
+	if format == "" {
+		outcome.SkipReason = SkipReasonNotRuntime
+		return outcome, nil
+	}
+
+	outcome.Results = 1
+	d, err := parseBody(logRecord.Body(), format)
+	if err != nil {
+		outcome.Malformed = 1
+		return outcome, processing.NewStageError(processing.StageParse, err)
+	}
+	outcome.Parsed = 1
+
+	if len(d.policies) == 0 {
+		outcome.AddFiltered(FilterReasonNoPolicy)
+		return outcome, nil
+	}
+	policy, ok := p.allowedPolicy(d.policies)
+	if !ok {
+		outcome.AddFiltered(FilterReasonPolicy)
+		return outcome, nil
+	}
+	if d.node == "" {
+		if value, exists := resource.Attributes().Get(nodeNameAttribute); exists {
+			d.node = value.AsString()
+		}
+	}
+
+	if p.logger.Core().Enabled(zapcore.DebugLevel) {
+		p.logger.Debug("Runtime event identified - processing",
+			zap.String("format", format),
+			zap.String("event_type", d.eventType),
+			zap.String("policy", policy),
+			zap.String("binary", d.process.Binary),
+			zap.String("trace_id", logRecord.TraceID().String()))
+	}
+
+	first := dst.Len()
+	// The record timestamp is the time the sensor observed the event
+	newRecord := processing.NewEventRecord(*logRecord, dst, d.timestamp)
+
+	if err := p.output.Write(newRecord, p.buildSecurityEvent(logRecord.Attributes(), d, format, policy)); err != nil {
+		// Remove the partially written security event
+		processing.Rollback(dst, first)
+		return outcome, processing.NewStageError(processing.StageTransform, err)
+	}
+	outcome.Created = 1
+	return outcome, nil
+}
+
+// parseBody decodes the event of a string or map body
+func parseBody(body pcommon.Value, format string) (*detection, error) {
+	var data []byte
+	if body.Type() == pcommon.ValueTypeStr {
+		data = []byte(body.Str())
+	} else {
+		var err error
+		if data, err = json.Marshal(body.Map().AsRaw()); err != nil {
+			return nil, fmt.Errorf("invalid %s event: %w", format, err)
+		}
+	}
+	if format == FormatTracee {
+		return parseTracee(data)
+	}
+	return parseTetragon(data)
+}
+
+// buildSecurityEvent builds the runtime detection of an event matched by a policy
+//
+// The policy is the finding type and the hook (the kernel function, tracepoint or Tracee signature) completes
+// the title; the pod of the process is the target, or the node for host processes
+func (p *Processor) buildSecurityEvent(attrs pcommon.Map, d *detection, format, policy string) *schema.SecurityEvent {
+	title := policy
+	if d.hook != "" {
+		title = policy + " - " + d.hook
+	}
+	description := eventDescription(d, policy)
+	message := d.message
+	if message == "" {
+		message = description
+	}
+
+	event := &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event: schema.Event{
+			ID:          uuid.New().String(),
+			Version:     processing.EventVersion,
+			Category:    eventCategory,
+			Name:        eventName,
+			Type:        eventType,
+			Description: description,
+		},
+		Message: message,
+		Source:  sources[format],
+		Target:  target(attrs, d),
+		Action: schema.Action{
+			Type:        actionDetected,
+			Description: description,
+		},
+		Finding: schema.Finding{
+			ID:          uuid.New().String(),
+			Title:       title,
+			Description: message,
+			Type:        policy,
+		},
+		Process:   d.process,
+		Container: d.container,
+		Metadata:  d.metadata,
+	}
+	if d.enforced {
+		event.Action.Type = actionBlocked
+	}
+	event.RiskScore, event.Finding.Severity = processing.RiskScore(d.severity), d.severity
+	if !d.timestamp.IsZero() {
+		event.Timestamp = d.timestamp.Format(time.RFC3339Nano)
+	}
+	event.Metadata["event_type"] = d.eventType
+	putString(event.Metadata, "node_name", d.node)
+
+	// MITRE ATT&CK technique tagging, or the technique of a Tracee signature
+	if techniques := p.techniques.Lookup(policy, d.hook, d.tags...); len(techniques) > 0 {
+		event.Threat = &schema.Threat{
+			Framework:      attack.Framework,
+			TechniqueIDs:   attack.IDs(techniques),
+			TechniqueNames: attack.Names(techniques),
+			TacticNames:    attack.Tactics(techniques),
+		}
+	} else if t := d.technique; t != nil {
+		event.Threat = &schema.Threat{
+			Framework:      attack.Framework,
+			TechniqueIDs:   []string{t.id},
+			TechniqueNames: []string{t.name},
+		}
+		if t.tactic != "" {
+			event.Threat.TacticNames = []string{t.tactic}
+		}
+	}
+	return event
+}
+
+// target returns the pod of the process, with the k8s.* fields of an OpenReports pod scope and the workload reported
+// by the sensor, or else the node
+func target(attrs pcommon.Map, d *detection) schema.Target {
+	if d.pod == "" {
+		if d.node == "" {
+			return schema.Target{}
+		}
+		return schema.Target{
+			ID:           d.node,
+			Resource:     d.node,
+			ResourceType: targetHost,
+			Kubernetes:   map[string]interface{}{nodeNameAttribute: d.node},
+		}
+	}
+
+	t := schema.Target{
+		ID:           d.podUID,
+		Resource:     d.pod,
+		ResourceType: kindPod,
+		EntityType:   "K8S_POD",
+		Kubernetes:   openreports.PodK8sFields(attrs, d.namespace, d.pod, d.podUID, d.workload, d.workloadKind),
+	}
+	if d.container != nil && d.container.Name != "" {
+		t.Kubernetes["k8s.container.name"] = d.container.Name
+	}
+	if d.node != "" {
+		t.Kubernetes[nodeNameAttribute] = d.node
+	}
+	return t
+}
+
+// eventDescription describes a detection, e.g.
+// "security_file_permission by /usr/bin/cat on default/xwing-6d9bd5c8b9-7kq2p for policy file-monitoring"
+func eventDescription(d *detection, policy string) string {
+	var sb strings.Builder
+	sb.WriteString(processing.FirstNonEmpty(d.hook, d.eventType))
+	if d.process.Binary != "" {
+		sb.WriteString(" by ")
+		sb.WriteString(d.process.Binary)
+	}
+	switch {
+	case d.pod != "" && d.namespace != "":
+		sb.WriteString(" on " + d.namespace + "/" + d.pod)
+	case d.pod != "":
+		sb.WriteString(" on " + d.pod)
+	case d.node != "":
+		sb.WriteString(" on " + d.node)
+	}
+	sb.WriteString(" for policy ")
+	sb.WriteString(policy)
+	return sb.String()
+}
+
+// allowedPolicy returns the first policy of an event in the allowed filter list
+func (p *Processor) allowedPolicy(policies []string) (string, bool) {
+	if len(p.config.PolicyFilter) == 0 {
+		return policies[0], true
+	}
+	for _, policy := range policies {
+		for _, allowed := range p.config.PolicyFilter {
+			if policy == allowed {
+				return policy, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,289 @@
+package runtimelog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/attack"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing/processingtest"
+)
+
+// newLogRecord returns a log record whose body is the event of a test file, collected from prod-eu-1
+func newLogRecord(t *testing.T, name string) plog.LogRecord {
+	t.Helper()
+	logRecord := processingtest.NewLogRecord(t, name)
+	logRecord.Attributes().PutStr("k8s.cluster.name", "prod-eu-1")
+	return logRecord
+}
+
+func TestFormat(t *testing.T) {
+	for name, want := range map[string]string{
+		"tetragon_kprobe.json":  FormatTetragon,
+		"tetragon_exec.json":    FormatTetragon,
+		"tracee_signature.json": FormatTracee,
+	} {
+		logRecord := newLogRecord(t, name)
+		assert.Equal(t, want, Format(&logRecord), name)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(logRecord.Body().Str()), &body))
+		require.NoError(t, logRecord.Body().SetEmptyMap().FromRaw(body))
+		assert.Equal(t, want, Format(&logRecord), name+" as a map")
+	}
+
+	logRecord := plog.NewLogRecord()
+	logRecord.Body().SetStr(`{"eventName": "login"}`)
+	assert.Empty(t, Format(&logRecord))
+	logRecord.Body().SetStr("process_kprobe on security_file_permission")
+	assert.Empty(t, Format(&logRecord))
+
+	// The keys must be at the top level of the event
+	logRecord.Body().SetStr(`{"msg": "audit", "event": {"process_exec": {}}, "type": "process_exit"}`)
+	assert.Empty(t, Format(&logRecord))
+	logRecord.Body().SetStr(`{"level": "info", "message": "\"eventName\" and \"processName\" are required"}`)
+	assert.Empty(t, Format(&logRecord))
+	logRecord.Body().SetStr(`{"eventName": "openat", "args": {"processName": "cat"}}`)
+	assert.Empty(t, Format(&logRecord))
+
+	// The keys must hold an event
+	logRecord.Body().SetStr(`{"msg": "exec traced", "process_exec": "started"}`)
+	assert.Empty(t, Format(&logRecord))
+	logRecord.Body().SetStr(`{"eventName": 3, "processName": "cat"}`)
+	assert.Empty(t, Format(&logRecord))
+
+	// A truncated event is told by its first keys, and fails to decode
+	logRecord.Body().SetStr(`{"node_name": "worker-1", "process_kprobe": {"process": {"binary": "/usr/bin/ca`)
+	assert.Equal(t, FormatTetragon, Format(&logRecord))
+}
+
+func TestProcessLogRecord_Tetragon(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := newLogRecord(t, "tetragon_kprobe.json")
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{Results: 1, Parsed: 1, Created: 1}, outcome)
+	require.Equal(t, 1, events.Len())
+
+	event := events.At(0)
+	attrs := event.Attributes().AsRaw()
+	assert.Equal(t, "DETECTION_FINDING", attrs["event.type"])
+	assert.Equal(t, "DETECTION", attrs["event.category"])
+	assert.Equal(t, "Detection finding event", attrs["event.name"])
+	assert.Equal(t, "security_file_permission by /usr/bin/cat on default/xwing-6d9bd5c8b9-7kq2p for policy file-monitoring-filtered",
+		attrs["event.description"])
+	assert.Equal(t, "Tetragon", attrs["product.name"])
+	assert.Equal(t, "Cilium", attrs["product.vendor"])
+	assert.Equal(t, "blocked", attrs["action.type"])
+	assert.Equal(t, "file-monitoring-filtered", attrs["finding.type"])
+	assert.Equal(t, "file-monitoring-filtered - security_file_permission", attrs["finding.title"])
+	assert.Equal(t, "Sensitive file read", attrs["finding.description"])
+	assert.NotContains(t, attrs, "finding.severity", "Tetragon does not rate its events")
+	assert.Equal(t, "2025-10-18T12:00:01.71332758Z", attrs["finding.time.created"])
+	assert.Equal(t, time.Date(2025, 10, 18, 12, 0, 1, 713327580, time.UTC), event.Timestamp().AsTime())
+	assert.Equal(t, logRecord.ObservedTimestamp(), event.ObservedTimestamp())
+	assert.Equal(t, "Sensitive file read", event.Body().Str())
+
+	// Process tree
+	assert.Equal(t, int64(52699), attrs["process.pid"])
+	assert.Equal(t, "/usr/bin/cat", attrs["process.executable.path"])
+	assert.Equal(t, "/usr/bin/cat /etc/shadow", attrs["process.command_line"])
+	assert.Equal(t, int64(52654), attrs["process.parent_pid"])
+	assert.Equal(t, "/bin/bash", attrs["process.parent.executable.path"])
+	assert.Equal(t, `/bin/bash -c "cat /etc/shadow"`, attrs["process.parent.command_line"])
+
+	// Container and pod context
+	assert.Equal(t, "551e161c47d8ff0eb665438a7bcd5b4e3ef5a297282b40a92b7c77d6bd168eb3", attrs["container.id"])
+	assert.Equal(t, "docker.io/tgraf/netperf:latest", attrs["container.image.name"])
+	assert.Equal(t, "Pod", attrs["object.type"])
+	assert.Equal(t, "K8S_POD", attrs["smartscape.type"])
+	assert.Equal(t, "prod-eu-1", attrs["k8s.cluster.name"])
+	assert.Equal(t, "default", attrs["k8s.namespace.name"])
+	assert.Equal(t, "xwing-6d9bd5c8b9-7kq2p", attrs["k8s.pod.name"])
+	assert.Equal(t, "xwing", attrs["k8s.deployment.name"])
+	assert.Equal(t, "spaceship", attrs["k8s.container.name"])
+	assert.Equal(t, "kind-control-plane", attrs["k8s.node.name"])
+}
+
+func TestProcessLogRecord_TetragonWorkload(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		pod  string
+		want map[string]interface{}
+	}{
+		{
+			// The pod name alone would make kube-proxy-x7k2p a pod of Deployment kube
+			name: "reported workload",
+			pod:  `"name": "kube-proxy-x7k2p", "workload": "kube-proxy", "workload_kind": "DaemonSet"`,
+			want: map[string]interface{}{"k8s.daemonset.name": "kube-proxy", "k8s.workload.kind": "DaemonSet"},
+		},
+		{
+			name: "derived workload",
+			pod:  `"name": "xwing-6d9bd5c8b9-7kq2p"`,
+			want: map[string]interface{}{"k8s.deployment.name": "xwing", "k8s.workload.kind": "Deployment"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logRecord := processingtest.NewStringLogRecord(`{"process_kprobe": {"process": {"pid": 812, "binary": "/usr/bin/cat",
+				"pod": {"namespace": "kube-system", ` + tt.pod + `}}, "function_name": "security_file_permission", "policy_name": "file-monitoring"}}`)
+			events, _, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+			require.NoError(t, err)
+			require.Equal(t, 1, events.Len())
+
+			attrs := events.At(0).Attributes().AsRaw()
+			for key, want := range tt.want {
+				assert.Equal(t, want, attrs[key], key)
+			}
+		})
+	}
+}
+
+func TestProcessLogRecord_Tracee(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithOutput(processing.Output{BodyFormat: "map"}))
+	require.NoError(t, err)
+
+	logRecord := newLogRecord(t, "tracee_signature.json")
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, 1, outcome.Created)
+
+	attrs := events.At(0).Attributes().AsRaw()
+	assert.Equal(t, "Tracee", attrs["product.name"])
+	assert.Equal(t, "Aqua Security", attrs["product.vendor"])
+	assert.Equal(t, "detected", attrs["action.type"])
+	assert.Equal(t, "container-threats - Anti-Debugging detected", attrs["finding.title"])
+	assert.Equal(t, "LOW", attrs["finding.severity"])
+	assert.Equal(t, 3.9, attrs["dt.security.risk.score"])
+	assert.Equal(t, "0f3b5c39-5b6e-4d4c-9f0e-6a3c1f7f0d21", attrs["object.id"])
+	assert.Equal(t, []interface{}{"T1622"}, attrs["threat.technique.id"])
+	assert.Equal(t, []interface{}{"Defense Evasion"}, attrs["threat.tactic.name"])
+	assert.Equal(t, "/usr/bin/strace", attrs["process.executable.path"])
+	assert.Equal(t, int64(52654), attrs["process.parent_pid"])
+	assert.NotContains(t, attrs, "process.parent.executable.path", "Tracee only identifies the parent by its PID")
+
+	body := events.At(0).Body().Map().AsRaw()
+	assert.Equal(t, "TRC-102", body["metadata"].(map[string]interface{})["signature_id"])
+	assert.Equal(t, "/usr/bin/strace", body["process"].(map[string]interface{})["binary"])
+}
+
+func TestProcessLogRecord_HostProcess(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewStringLogRecord(`{"process_kprobe": {"process": {"pid": 812, "binary": "/usr/sbin/insmod", "arguments": "rootkit.ko"},
+		"function_name": "do_init_module", "policy_name": "kernel-modules"}}`)
+	resource := pcommon.NewResource()
+	resource.Attributes().PutStr("k8s.node.name", "worker-2")
+	dst, _, err := processingtest.ProcessResource(t, processor.ProcessLogRecord, &logRecord, resource)
+	require.NoError(t, err)
+	require.Equal(t, 1, dst.Len())
+
+	// Host processes target the node of the sensor
+	attrs := dst.At(0).Attributes().AsRaw()
+	assert.Equal(t, "do_init_module by /usr/sbin/insmod on worker-2 for policy kernel-modules", attrs["event.description"])
+	assert.Equal(t, "do_init_module by /usr/sbin/insmod on worker-2 for policy kernel-modules", dst.At(0).Body().Str())
+	assert.Equal(t, "host", attrs["object.type"])
+	assert.Equal(t, "worker-2", attrs["object.id"])
+	assert.Equal(t, "worker-2", attrs["k8s.node.name"])
+	assert.NotContains(t, attrs, "container.id")
+}
+
+func TestProcessLogRecord_PolicyFilter(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true, PolicyFilter: []string{"default"}})
+	require.NoError(t, err)
+
+	// Tetragon process executions are matched by no policy
+	logRecord := newLogRecord(t, "tetragon_exec.json")
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{Results: 1, Parsed: 1, Filtered: map[string]int{FilterReasonNoPolicy: 1}}, outcome)
+	assert.Zero(t, events.Len())
+
+	logRecord = newLogRecord(t, "tracee_signature.json")
+	events, outcome, err = processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{Results: 1, Parsed: 1, Filtered: map[string]int{FilterReasonPolicy: 1}}, outcome)
+	assert.Zero(t, events.Len())
+
+	// The first allowed policy of a Tracee event is its finding type
+	logRecord.Body().SetStr(`{"eventName": "sched_process_exec", "processName": "curl", "matchedPolicies": ["exec-monitor", "default"]}`)
+	events, outcome, err = processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, 1, outcome.Created)
+	assert.Equal(t, "default", events.At(0).Attributes().AsRaw()["finding.type"])
+}
+
+func TestProcessLogRecord_Techniques(t *testing.T) {
+	techniques, err := attack.ParseMapping([]byte(`
+techniques:
+  T1003:
+    name: OS Credential Dumping
+    tactics: [Credential Access]
+mappings:
+  - policy: file-monitoring-filtered
+    techniques: [T1003]
+  - policy: container-threats
+    techniques: [T1003]
+`))
+	require.NoError(t, err)
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithTechniqueMapping(techniques))
+	require.NoError(t, err)
+
+	// The mapped techniques take precedence over the technique of a Tracee signature
+	for _, name := range []string{"tetragon_kprobe.json", "tracee_signature.json"} {
+		logRecord := newLogRecord(t, name)
+		events, _, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+		require.NoError(t, err)
+		attrs := events.At(0).Attributes().AsRaw()
+		assert.Equal(t, []interface{}{"T1003"}, attrs["threat.technique.id"], name)
+		assert.Equal(t, []interface{}{"Credential Access"}, attrs["threat.tactic.name"], name)
+	}
+}
+
+func TestProcessLogRecord_Skipped(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		stage   string
+		outcome processing.Outcome
+	}{
+		{name: "plain line", body: "plain log line", outcome: processing.Outcome{SkipReason: SkipReasonNotRuntime}},
+		{name: "other JSON", body: `{"eventName": "login"}`, outcome: processing.Outcome{SkipReason: SkipReasonNotRuntime}},
+		{
+			// A log record holds a single event, counted as malformed rather than skipped
+			name:    "kprobe without process",
+			body:    `{"process_kprobe": {"function_name": "security_file_permission"}}`,
+			stage:   processing.StageParse,
+			outcome: processing.Outcome{Results: 1, Malformed: 1},
+		},
+	}
+
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logRecord := processingtest.NewStringLogRecord(tt.body)
+			events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+			if tt.stage != "" {
+				processingtest.RequireStageError(t, err, tt.stage)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.outcome, outcome)
+			assert.Zero(t, events.Len())
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package runtimelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// tetragonEventTypes are the event types of the Tetragon JSON export, each the single key holding the event
+// Process executions and exits are matched by no policy; the hook events carry the name of their TracingPolicy
+var tetragonEventTypes = []string{
+	"process_kprobe",
+	"process_tracepoint",
+	"process_uprobe",
+	"process_lsm",
+	"process_exec",
+	"process_exit",
+}
+
+// tetragonEnforcingActions are the actions of a TracingPolicy that stop the operation or the process
+var tetragonEnforcingActions = map[string]bool{
+	"sigkill":        true,
+	"override":       true,
+	"signal":         true,
+	"notifyenforcer": true,
+}
+
+// tetragonEvent is the body of a Tetragon event, the same for every event type
+type tetragonEvent struct {
+	Process *tetragonProcess `json:"process"`
+	Parent  *tetragonProcess `json:"parent"`
+
+	// Hook of kprobe, uprobe and LSM events
+	FunctionName string `json:"function_name"`
+	// Hook of tracepoint events
+	Subsys string `json:"subsys"`
+	Event  string `json:"event"`
+
+	PolicyName string        `json:"policy_name"`
+	Action     string        `json:"action"`
+	Message    string        `json:"message"`
+	Tags       []string      `json:"tags"`
+	Args       []interface{} `json:"args"`
+}
+
+// tetragonProcess is a process of a Tetragon event
+type tetragonProcess struct {
+	ExecID    string       `json:"exec_id"`
+	PID       int64        `json:"pid"`
+	UID       *int64       `json:"uid"`
+	Binary    string       `json:"binary"`
+	Arguments string       `json:"arguments"`
+	Pod       *tetragonPod `json:"pod"`
+}
+
+// tetragonPod is the pod of a containerised process
+type tetragonPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Container *struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Image struct {
+			Name string `json:"name"`
+		} `json:"image"`
+	} `json:"container"`
+	Workload     string `json:"workload"`
+	WorkloadKind string `json:"workload_kind"`
+}
+
+// parseTetragon decodes a Tetragon JSON event
+func parseTetragon(data []byte) (*detection, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("invalid Tetragon event: %w", err)
+	}
+
+	var eventType string
+	for _, t := range tetragonEventTypes {
+		if _, ok := fields[t]; ok {
+			eventType = t
+			break
+		}
+	}
+	if eventType == "" {
+		return nil, fmt.Errorf("invalid Tetragon event: no event type among %s", strings.Join(tetragonEventTypes, ", "))
+	}
+
+	var event tetragonEvent
+	if err := json.Unmarshal(fields[eventType], &event); err != nil {
+		return nil, fmt.Errorf("invalid Tetragon %s event: %w", eventType, err)
+	}
+	if event.Process == nil {
+		return nil, fmt.Errorf("invalid Tetragon %s event: no process", eventType)
+	}
+	var node, timestamp string
+	_ = json.Unmarshal(fields["node_name"], &node)
+	_ = json.Unmarshal(fields["time"], &timestamp)
+
+	d := &detection{
+		eventType: eventType,
+		hook:      event.hook(),
+		tags:      event.Tags,
+		message:   event.Message,
+		enforced:  tetragonEnforcingActions[tetragonAction(event.Action)],
+		process:   event.Process.process(),
+		node:      node,
+		metadata:  map[string]interface{}{},
+	}
+	if event.PolicyName != "" {
+		d.policies = []string{event.PolicyName}
+	}
+	if event.Parent != nil {
+		d.process.Parent = event.Parent.process()
+	}
+	if pod := event.Process.Pod; pod != nil {
+		d.namespace, d.pod = pod.Namespace, pod.Name
+		d.workload, d.workloadKind = pod.Workload, pod.WorkloadKind
+		if c := pod.Container; c != nil {
+			d.container = &schema.Container{ID: containerID(c.ID), Name: c.Name, Image: c.Image.Name}
+		}
+		putString(d.metadata, "workload", pod.Workload)
+		putString(d.metadata, "workload_kind", pod.WorkloadKind)
+	}
+	if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+		d.timestamp = t.UTC()
+	}
+
+	putString(d.metadata, "exec_id", event.Process.ExecID)
+	putString(d.metadata, "action", event.Action)
+	if event.Process.UID != nil {
+		d.metadata["uid"] = *event.Process.UID
+	}
+	if len(event.Tags) > 0 {
+		tags := make([]interface{}, len(event.Tags))
+		for i, tag := range event.Tags {
+			tags[i] = tag
+		}
+		d.metadata["tags"] = tags
+	}
+	if len(event.Args) > 0 {
+		// The arguments of the hook, as the raw Tetragon values (e.g. {"file_arg": {"path": "/etc/shadow"}})
+		d.metadata["args"] = event.Args
+	}
+	return d, nil
+}
+
+// hook returns the kernel function or the tracepoint of a hook event, e.g. security_file_permission
+// or syscalls/sys_enter_ptrace, or "" for process executions and exits
+func (e *tetragonEvent) hook() string {
+	if e.FunctionName != "" {
+		return e.FunctionName
+	}
+	if e.Subsys != "" && e.Event != "" {
+		return e.Subsys + "/" + e.Event
+	}
+	return e.Event
+}
+
+// process returns the process fields of a Tetragon process
+func (p *tetragonProcess) process() *schema.Process {
+	return &schema.Process{
+		PID:       p.PID,
+		Binary:    p.Binary,
+		Arguments: p.Arguments,
+	}
+}
+
+// tetragonAction lower-cases a TracingPolicy action without its prefix, e.g. KPROBE_ACTION_SIGKILL into sigkill
+func tetragonAction(action string) string {
+	if i := strings.LastIndex(action, "ACTION_"); i >= 0 {
+		action = action[i+len("ACTION_"):]
+	}
+	return strings.ToLower(action)
+}
@@ -0,0 +1,92 @@
+package runtimelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+func TestParseTetragon_Kprobe(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "tetragon_kprobe.json"))
+	require.NoError(t, err)
+
+	d, err := parseTetragon(data)
+	require.NoError(t, err)
+	assert.Equal(t, "process_kprobe", d.eventType)
+	assert.Equal(t, "security_file_permission", d.hook)
+	assert.Equal(t, []string{"file-monitoring-filtered"}, d.policies)
+	assert.Equal(t, []string{"observability.filesystem"}, d.tags)
+	assert.Equal(t, "Sensitive file read", d.message)
+	assert.True(t, d.enforced, "sigkill stops the process")
+	assert.Equal(t, &schema.Process{
+		PID:       52699,
+		Binary:    "/usr/bin/cat",
+		Arguments: "/etc/shadow",
+		Parent:    &schema.Process{PID: 52654, Binary: "/bin/bash", Arguments: `-c "cat /etc/shadow"`},
+	}, d.process)
+	assert.Equal(t, &schema.Container{
+		ID:    "551e161c47d8ff0eb665438a7bcd5b4e3ef5a297282b40a92b7c77d6bd168eb3",
+		Name:  "spaceship",
+		Image: "docker.io/tgraf/netperf:latest",
+	}, d.container)
+	assert.Equal(t, "default", d.namespace)
+	assert.Equal(t, "xwing-6d9bd5c8b9-7kq2p", d.pod)
+	assert.Equal(t, "kind-control-plane", d.node)
+	assert.Equal(t, time.Date(2025, 10, 18, 12, 0, 1, 713327580, time.UTC), d.timestamp)
+	assert.Equal(t, "KPROBE_ACTION_SIGKILL", d.metadata["action"])
+	assert.Equal(t, "Deployment", d.metadata["workload_kind"])
+	assert.Equal(t, int64(0), d.metadata["uid"])
+	assert.Len(t, d.metadata["args"], 2)
+}
+
+func TestParseTetragon_Exec(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "tetragon_exec.json"))
+	require.NoError(t, err)
+
+	d, err := parseTetragon(data)
+	require.NoError(t, err)
+	assert.Equal(t, "process_exec", d.eventType)
+	assert.Empty(t, d.hook)
+	assert.Empty(t, d.policies, "process executions are matched by no policy")
+	assert.False(t, d.enforced)
+	assert.Equal(t, "/usr/bin/curl", d.process.Binary)
+	assert.Equal(t, "/bin/bash", d.process.Parent.Binary)
+}
+
+func TestParseTetragon_Tracepoint(t *testing.T) {
+	d, err := parseTetragon([]byte(`{"process_tracepoint": {
+		"process": {"pid": 1234, "binary": "/usr/bin/strace"},
+		"subsys": "syscalls", "event": "sys_enter_ptrace",
+		"policy_name": "ptrace-monitoring", "action": "KPROBE_ACTION_POST"
+	}, "time": "2025-10-18T12:00:00Z"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "syscalls/sys_enter_ptrace", d.hook)
+	assert.Equal(t, []string{"ptrace-monitoring"}, d.policies)
+	assert.False(t, d.enforced, "post only reports the event")
+	assert.Nil(t, d.process.Parent)
+	assert.Nil(t, d.container)
+}
+
+func TestParseTetragon_Invalid(t *testing.T) {
+	for _, body := range []string{
+		`{"process_kprobe": `,
+		`{"node_name": "kind-control-plane"}`,
+		`{"process_kprobe": {"function_name": "security_file_permission"}}`,
+		`{"process_kprobe": {"process": "cat"}}`,
+	} {
+		_, err := parseTetragon([]byte(body))
+		assert.Error(t, err, body)
+	}
+}
+
+func TestTetragonAction(t *testing.T) {
+	assert.Equal(t, "sigkill", tetragonAction("KPROBE_ACTION_SIGKILL"))
+	assert.Equal(t, "notifyenforcer", tetragonAction("KPROBE_ACTION_NOTIFYENFORCER"))
+	assert.Equal(t, "", tetragonAction(""))
+}
@@ -0,0 +1,199 @@
+package runtimelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Tracee signature severities, from 0 (informative) to 3 (high)
+var traceeSeverities = []string{"", processing.SeverityLow, processing.SeverityMedium, processing.SeverityHigh}
+
+// traceeEvent is a Tracee JSON event: a traced event or the detection of a signature
+type traceeEvent struct {
+	Timestamp           int64  `json:"timestamp"`
+	ProcessID           int64  `json:"processId"`
+	ParentProcessID     int64  `json:"parentProcessId"`
+	HostProcessID       int64  `json:"hostProcessId"`
+	HostParentProcessID int64  `json:"hostParentProcessId"`
+	UserID              *int64 `json:"userId"`
+	ProcessName         string `json:"processName"`
+	Executable          struct {
+		Path string `json:"path"`
+	} `json:"executable"`
+	Container struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Image       string `json:"image"`
+		ImageDigest string `json:"imageDigest"`
+	} `json:"container"`
+	Kubernetes struct {
+		PodName      string `json:"podName"`
+		PodNamespace string `json:"podNamespace"`
+		PodUID       string `json:"podUID"`
+	} `json:"kubernetes"`
+
+	// Flattened container and pod fields of Tracee versions before 0.20
+	ContainerID    string `json:"containerId"`
+	ContainerName  string `json:"containerName"`
+	ContainerImage string `json:"containerImage"`
+	PodName        string `json:"podName"`
+	PodNamespace   string `json:"podNamespace"`
+	PodUID         string `json:"podUID"`
+
+	EventID         string          `json:"eventId"`
+	EventName       string          `json:"eventName"`
+	MatchedPolicies []string        `json:"matchedPolicies"`
+	Syscall         string          `json:"syscall"`
+	Args            []traceeArg     `json:"args"`
+	Metadata        *traceeMetadata `json:"metadata"`
+}
+
+// traceeArg is an argument of a Tracee event
+type traceeArg struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// traceeMetadata describes the signature of a detection
+type traceeMetadata struct {
+	Description string                 `json:"Description"`
+	Properties  map[string]interface{} `json:"Properties"`
+}
+
+// parseTracee decodes a Tracee JSON event
+func parseTracee(data []byte) (*detection, error) {
+	var event traceeEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("invalid Tracee event: %w", err)
+	}
+	if event.EventName == "" {
+		return nil, fmt.Errorf("invalid Tracee event: no eventName")
+	}
+
+	d := &detection{
+		eventType: event.EventName,
+		hook:      event.EventName,
+		policies:  event.MatchedPolicies,
+		process:   event.process(),
+		namespace: processing.FirstNonEmpty(event.Kubernetes.PodNamespace, event.PodNamespace),
+		pod:       processing.FirstNonEmpty(event.Kubernetes.PodName, event.PodName),
+		podUID:    processing.FirstNonEmpty(event.Kubernetes.PodUID, event.PodUID),
+		metadata:  map[string]interface{}{},
+	}
+	if id := processing.FirstNonEmpty(event.Container.ID, event.ContainerID); id != "" {
+		d.container = &schema.Container{
+			ID:    containerID(id),
+			Name:  processing.FirstNonEmpty(event.Container.Name, event.ContainerName),
+			Image: processing.FirstNonEmpty(event.Container.Image, event.ContainerImage),
+		}
+	}
+	if event.Timestamp > 0 {
+		d.timestamp = time.Unix(0, event.Timestamp).UTC()
+	}
+
+	putString(d.metadata, "event_id", event.EventID)
+	putString(d.metadata, "syscall", event.Syscall)
+	putString(d.metadata, "process_name", event.ProcessName)
+	putString(d.metadata, "image_digest", event.Container.ImageDigest)
+	if event.UserID != nil {
+		d.metadata["uid"] = *event.UserID
+	}
+	if len(event.MatchedPolicies) > 1 {
+		policies := make([]interface{}, len(event.MatchedPolicies))
+		for i, policy := range event.MatchedPolicies {
+			policies[i] = policy
+		}
+		d.metadata["matched_policies"] = policies
+	}
+	if len(event.Args) > 0 {
+		args := make(map[string]interface{}, len(event.Args))
+		for _, arg := range event.Args {
+			args[arg.Name] = arg.Value
+		}
+		d.metadata["args"] = args
+	}
+
+	if m := event.Metadata; m != nil {
+		// Signature detections name, describe and rate the detection
+		d.message = m.Description
+		if name, ok := m.Properties["signatureName"].(string); ok && name != "" {
+			d.hook = name
+		}
+		if severity, ok := m.Properties["Severity"].(float64); ok && severity >= 0 {
+			d.severity = processing.SeverityCritical
+			if int(severity) < len(traceeSeverities) {
+				d.severity = traceeSeverities[int(severity)]
+			}
+		}
+		if id, ok := m.Properties["external_id"].(string); ok && strings.HasPrefix(id, "T") {
+			d.technique = &technique{id: id}
+			d.technique.name, _ = m.Properties["Technique"].(string)
+			if category, ok := m.Properties["Category"].(string); ok {
+				d.technique.tactic = tacticName(category)
+			}
+		}
+		if id, ok := m.Properties["signatureID"].(string); ok {
+			putString(d.metadata, "signature_id", id)
+		}
+	}
+	return d, nil
+}
+
+// process returns the process of an event, with the host PIDs when known, and its parent
+// Tracee only identifies the parent process by its PID
+func (e *traceeEvent) process() *schema.Process {
+	process := &schema.Process{
+		PID:    e.ProcessID,
+		Binary: processing.FirstNonEmpty(e.Executable.Path, e.stringArg("pathname"), e.ProcessName),
+	}
+	if e.HostProcessID != 0 {
+		process.PID = e.HostProcessID
+	}
+	if argv := e.stringsArg("argv"); len(argv) > 1 {
+		process.Arguments = strings.Join(argv[1:], " ")
+	}
+
+	parentPID := e.ParentProcessID
+	if e.HostParentProcessID != 0 {
+		parentPID = e.HostParentProcessID
+	}
+	if parentPID != 0 {
+		process.Parent = &schema.Process{PID: parentPID}
+	}
+	return process
+}
+
+// stringArg returns the value of a string argument, or ""
+func (e *traceeEvent) stringArg(name string) string {
+	for _, arg := range e.Args {
+		if arg.Name == name {
+			s, _ := arg.Value.(string)
+			return s
+		}
+	}
+	return ""
+}
+
+// stringsArg returns the values of a string array argument, e.g. the argv of a process execution, or nil
+func (e *traceeEvent) stringsArg(name string) []string {
+	for _, arg := range e.Args {
+		if arg.Name != name {
+			continue
+		}
+		values, _ := arg.Value.([]interface{})
+		strs := make([]string, 0, len(values))
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		return strs
+	}
+	return nil
+}
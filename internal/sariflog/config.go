@@ -0,0 +1,9 @@
+package sariflog
+
+// Config defines the configuration for the SARIF processor
+type Config struct {
+	// Enabled indicates whether the SARIF processor is enabled
+	// Log records whose body holds a SARIF 2.1.0 log, as a JSON string or a parsed map, are expanded
+	// into one security event per result
+	Enabled bool `mapstructure:"enabled"`
+}
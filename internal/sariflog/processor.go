@@ -0,0 +1,390 @@
+// Package sariflog expands the SARIF logs of static analysis tools (CodeQL, Semgrep, Checkov, ...) into
+// security events, one per result.
+package sariflog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Kind is the report kind of SARIF logs in the telemetry
+const Kind = "sarif"
+
+// Reasons for results that are not turned into security events
+const (
+	// FilterReasonKind marks results that are not failures: pass, notApplicable or informational
+	FilterReasonKind = "result_kind"
+	// FilterReasonSuppressed marks results with an accepted suppression
+	FilterReasonSuppressed = "suppressed"
+)
+
+// Reasons for log records that produce no security events
+const (
+	// SkipReasonNotSARIF marks log records whose body holds no SARIF log
+	SkipReasonNotSARIF = "not_sarif"
+	// SkipReasonInvalidLog marks SARIF logs that cannot be decoded,
+	// ProcessLogRecord also returns a parse stage error for them
+	SkipReasonInvalidLog = "invalid_sarif"
+	// SkipReasonNoResults marks SARIF logs without results
+	SkipReasonNoResults = "no_results"
+)
+
+// Security event classification of the SARIF results
+const (
+	eventCategory = "DETECTION"
+	eventName     = "Detection finding event"
+	eventType     = "DETECTION_FINDING"
+	actionType    = "code_scan"
+	targetFile    = "file"
+)
+
+// findingNamespace is the namespace of the finding IDs derived from result fingerprints
+var findingNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://docs.oasis-open.org/sarif/sarif/v2.1.0"))
+
+// Processor handles transformation of SARIF logs into security events
+type Processor struct {
+	logger *zap.Logger
+	config *Config
+
+	// output lays out the security events in their log records
+	output processing.Output
+}
+
+// Option configures optional dependencies of the Processor
+type Option func(*Processor)
+
+// WithOutput sets the layout of the security events in their log records: the profile, attribute names,
+// body format and SIEM encoding
+func WithOutput(output processing.Output) Option {
+	return func(p *Processor) {
+		p.output = output
+	}
+}
+
+// NewProcessor creates a new SARIF processor
+func NewProcessor(logger *zap.Logger, config *Config, opts ...Option) (*Processor, error) {
+	p := &Processor{
+		logger: logger,
+		config: config,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	// The action and result of the security events are part of the source events
+	p.output.ActionFields = true
+	return p, nil
+}
+
+// IsSARIF performs a quick check to determine if the body of a log record holds a SARIF 2.1.0 log,
+// as a JSON string or a map parsed from it
+// A SARIF log is told by its top-level version and runs array, not by mentions of them elsewhere in the body
+func IsSARIF(logRecord *plog.LogRecord) bool {
+	var values map[string]processing.JSONValue
+	body := logRecord.Body()
+	switch body.Type() {
+	case pcommon.ValueTypeStr:
+		values = processing.TopLevelValues(body.Str(), "version", "runs")
+	case pcommon.ValueTypeMap:
+		values = processing.MapValues(body.Map(), "version", "runs")
+	default:
+		return false
+	}
+	version, runs := values["version"], values["runs"]
+	return version.Kind == processing.JSONString && version.Str == sarifVersion && runs.Kind == processing.JSONArray
+}
+
+// ProcessLogRecord expands a log record whose body holds a SARIF log into one security event per result
+// The security events are appended to dst; nothing is appended if the body holds no SARIF log
+// Results that are not failures or that are suppressed are filtered out
+// A *processing.StageError is returned if the log cannot be decoded or its security events cannot be written;
+// nothing is appended to dst in that case
+func (p *Processor) ProcessLogRecord(
+	_ context.Context, logRecord *plog.LogRecord, _ pcommon.Resource, _ plog.ScopeLogs, dst plog.LogRecordSlice,
+) (processing.Outcome, error) {
+	var outcome processing.Outcome
+	if !IsSARIF(logRecord) {
+		outcome.SkipReason = SkipReasonNotSARIF
+		return outcome, nil
+	}
+
+	log, err := parseBody(logRecord.Body())
+	if err != nil {
+		outcome.SkipReason = SkipReasonInvalidLog
+		return outcome, processing.NewStageError(processing.StageParse, err)
+	}
+
+	debug := p.logger.Core().Enabled(zapcore.DebugLevel)
+	first := dst.Len()
+	for i := range log.Runs {
+		run := &log.Runs[i]
+		timestamp := run.endTime()
+		if debug {
+			p.logger.Debug("SARIF run identified - processing",
+				zap.String("tool", run.Tool.Driver.Name),
+				zap.Int("results", len(run.Results)),
+				zap.String("trace_id", logRecord.TraceID().String()))
+		}
+
+		for j := range run.Results {
+			result := &run.Results[j]
+			outcome.Results++
+			outcome.Parsed++
+			switch kind := result.kind(); {
+			case kind != "fail" && kind != "review" && kind != "open":
+				outcome.AddFiltered(FilterReasonKind)
+				continue
+			case result.suppressed():
+				outcome.AddFiltered(FilterReasonSuppressed)
+				continue
+			}
+
+			// The record timestamp is the time the analysis completed
+			newRecord := processing.NewEventRecord(*logRecord, dst, timestamp)
+
+			if err := p.output.Write(newRecord, buildSecurityEvent(run, result, timestamp)); err != nil {
+				// Remove the security events of the log, including the partially written one
+				processing.Rollback(dst, first)
+				outcome.Created = 0
+				return outcome, processing.NewStageError(processing.StageTransform, err)
+			}
+			outcome.Created++
+		}
+	}
+	if outcome.Results == 0 {
+		outcome.SkipReason = SkipReasonNoResults
+	}
+	return outcome, nil
+}
+
+// parseBody decodes the SARIF log of a string or map body
+func parseBody(body pcommon.Value) (*Log, error) {
+	if body.Type() == pcommon.ValueTypeStr {
+		return Parse([]byte(body.Str()))
+	}
+	data, err := json.Marshal(body.Map().AsRaw())
+	if err != nil {
+		return nil, fmt.Errorf("invalid SARIF log: %w", err)
+	}
+	return Parse(data)
+}
+
+// endTime returns the time the run completed, zero if unknown
+func (r *Run) endTime() time.Time {
+	for _, invocation := range r.Invocations {
+		if t, err := time.Parse(time.RFC3339Nano, invocation.EndTimeUTC); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// buildSecurityEvent builds the security event of a SARIF result
+//
+// The tool identifies the product, the rule the finding, and the first physical location the file and line
+// of the finding
+func buildSecurityEvent(run *Run, result *Result, timestamp time.Time) *schema.SecurityEvent {
+	driver := &run.Tool.Driver
+	rule := run.rule(result)
+	ruleID := result.ruleID()
+	if ruleID == "" && rule != nil {
+		ruleID = rule.ID
+	}
+
+	title := ruleID
+	if rule != nil {
+		title = processing.FirstNonEmpty(messageText(rule.ShortDescription), rule.Name, ruleID)
+	}
+	message := result.Message.Text
+	if message == "" && rule != nil {
+		message = messageText(rule.FullDescription)
+	}
+	if message == "" {
+		message = title
+	}
+
+	vendor := driver.Organization
+	if vendor == "" {
+		vendor = driver.Name
+	}
+
+	event := &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event: schema.Event{
+			ID:       uuid.New().String(),
+			Version:  processing.EventVersion,
+			Category: eventCategory,
+			Name:     eventName,
+			Type:     eventType,
+		},
+		Message: message,
+		Source: schema.Source{
+			Application: driver.Name,
+			Vendor:      vendor,
+			Version:     processing.FirstNonEmpty(driver.SemanticVersion, driver.Version),
+		},
+		Action: schema.Action{
+			Type: actionType,
+		},
+		Result: schema.Result{
+			Status: result.kind(),
+		},
+		Finding: schema.Finding{
+			ID:          findingID(driver.Name, ruleID, result),
+			Title:       title,
+			Description: message,
+			Type:        ruleID,
+		},
+		Metadata: map[string]interface{}{
+			"format": Kind,
+			"level":  result.level(rule),
+		},
+	}
+	if rule != nil {
+		event.Finding.URL = rule.HelpURI
+	}
+	if !timestamp.IsZero() {
+		event.Timestamp = timestamp.Format(time.RFC3339Nano)
+	}
+
+	// Target: the file of the first physical location
+	if location := result.location(); location != nil {
+		event.Finding.Location = &schema.Location{FilePath: location.ArtifactLocation.URI}
+		if location.Region != nil {
+			event.Finding.Location.Line = location.Region.StartLine
+			event.Finding.Location.Column = location.Region.StartColumn
+		}
+		event.Target = schema.Target{
+			ID:           location.ArtifactLocation.URI,
+			Resource:     location.ArtifactLocation.URI,
+			ResourceType: targetFile,
+		}
+	}
+	event.Event.Description = description(title, event.Finding.Location)
+
+	event.RiskScore, event.Finding.Severity = severity(result, rule)
+
+	// Metadata: fingerprints, rule tags and the analyzed revision
+	if len(result.Fingerprints) > 0 {
+		event.Metadata["fingerprints"] = stringMap(result.Fingerprints)
+	}
+	if len(result.PartialFingerprints) > 0 {
+		event.Metadata["partial_fingerprints"] = stringMap(result.PartialFingerprints)
+	}
+	if rule != nil && len(rule.Properties.Tags) > 0 {
+		tags := make([]interface{}, len(rule.Properties.Tags))
+		for i, tag := range rule.Properties.Tags {
+			tags[i] = tag
+		}
+		event.Metadata["tags"] = tags
+	}
+	if len(run.VersionControlProvenance) > 0 {
+		provenance := run.VersionControlProvenance[0]
+		event.Metadata["repository"] = provenance.RepositoryURI
+		event.Metadata["revision"] = provenance.RevisionID
+		if provenance.Branch != "" {
+			event.Metadata["branch"] = provenance.Branch
+		}
+	}
+	return event
+}
+
+// description describes the event: "<title> in <file>:<line>", skipping unknown parts
+func description(title string, location *schema.Location) string {
+	if location == nil {
+		return title
+	}
+	if location.Line > 0 {
+		return title + " in " + location.FilePath + ":" + strconv.Itoa(location.Line)
+	}
+	return title + " in " + location.FilePath
+}
+
+// findingID returns a stable finding ID derived from the tool, the rule and the fingerprints of a result,
+// so the same finding keeps its ID across runs
+// Results without fingerprints use their GUID, or a random ID
+func findingID(tool, ruleID string, result *Result) string {
+	fingerprints := result.Fingerprints
+	if len(fingerprints) == 0 {
+		fingerprints = result.PartialFingerprints
+	}
+	if len(fingerprints) == 0 {
+		if result.GUID != "" {
+			return result.GUID
+		}
+		return uuid.New().String()
+	}
+
+	keys := make([]string, 0, len(fingerprints))
+	for key := range fingerprints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(tool)
+	sb.WriteByte(0)
+	sb.WriteString(ruleID)
+	for _, key := range keys {
+		sb.WriteByte(0)
+		sb.WriteString(key)
+		sb.WriteByte('=')
+		sb.WriteString(fingerprints[key])
+	}
+	return uuid.NewSHA1(findingNamespace, []byte(sb.String())).String()
+}
+
+// severity maps a result to a risk score and a finding severity
+// The security-severity property of the result or its rule, from 0.0 to 10.0, takes precedence over the level;
+// levels use the risk scores of the OpenReports severities: error is high, warning medium and note low
+func severity(result *Result, rule *Rule) (float64, string) {
+	score, ok := result.Properties.securitySeverity()
+	if !ok && rule != nil {
+		score, ok = rule.Properties.securitySeverity()
+	}
+	if ok {
+		score = min(max(score, 0), 10)
+		return score, processing.SeverityFromScore(score)
+	}
+
+	switch result.level(rule) {
+	case "error":
+		return processing.RiskScoreHigh, processing.SeverityHigh
+	case "warning":
+		return processing.RiskScoreMedium, processing.SeverityMedium
+	case "note":
+		return processing.RiskScoreLow, processing.SeverityLow
+	default:
+		return 0, ""
+	}
+}
+
+// messageText returns the text of an optional message
+func messageText(message *Message) string {
+	if message == nil {
+		return ""
+	}
+	return message.Text
+}
+
+// stringMap returns a string map as raw values
+func stringMap(values map[string]string) map[string]interface{} {
+	raw := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		raw[key] = value
+	}
+	return raw
+}
@@ -0,0 +1,259 @@
+package sariflog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing/processingtest"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/profile"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+func TestProcessLogRecord_CodeQL(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewLogRecord(t, "codeql.sarif")
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{
+		Results:  3,
+		Parsed:   3,
+		Filtered: map[string]int{FilterReasonSuppressed: 1, FilterReasonKind: 1},
+		Created:  1,
+	}, outcome)
+	require.Equal(t, 1, events.Len())
+
+	event := events.At(0)
+	attrs := event.Attributes().AsRaw()
+	assert.Equal(t, "DETECTION_FINDING", attrs["event.type"])
+	assert.Equal(t, "Database query built from user-controlled sources in src/app/handler.go:42", attrs["event.description"])
+	assert.Equal(t, "CodeQL", attrs["product.name"])
+	assert.Equal(t, "GitHub", attrs["product.vendor"])
+	assert.Equal(t, "2.19.0", attrs["product.version"])
+	assert.Equal(t, "code_scan", attrs["action.type"])
+	assert.Equal(t, "fail", attrs["result.status"])
+	assert.Equal(t, "src/app/handler.go", attrs["object.id"])
+	assert.Equal(t, "file", attrs["object.type"])
+	assert.Equal(t, "src/app/handler.go", attrs["code.file.path"])
+	assert.Equal(t, int64(42), attrs["code.line.number"])
+	assert.Equal(t, int64(7), attrs["code.column.number"])
+	assert.Equal(t, 8.8, attrs["dt.security.risk.score"])
+	assert.Equal(t, "HIGH", attrs["finding.severity"])
+	assert.Equal(t, "Database query built from user-controlled sources", attrs["finding.title"])
+	assert.Equal(t, "go/sql-injection", attrs["finding.type"])
+	assert.Equal(t, "https://codeql.github.com/codeql-query-help/go/go-sql-injection/", attrs["finding.url"])
+	assert.Equal(t, "2025-10-18T12:00:00Z", attrs["finding.time.created"])
+	assert.NotContains(t, attrs, "compliance.status")
+
+	assert.Equal(t, "This query depends on a user-provided value.", event.Body().Str())
+	assert.Equal(t, time.Date(2025, 10, 18, 12, 0, 0, 0, time.UTC), event.Timestamp().AsTime())
+	assert.Equal(t, logRecord.ObservedTimestamp(), event.ObservedTimestamp())
+
+	// The finding ID is derived from the fingerprints, so it is stable across runs
+	again, _, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, attrs["finding.id"], again.At(0).Attributes().AsRaw()["finding.id"])
+	assert.NotEqual(t, attrs["event.id"], again.At(0).Attributes().AsRaw()["event.id"])
+}
+
+func TestProcessLogRecord_Semgrep(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithOutput(processing.Output{BodyFormat: schema.BodyFormatJSON}))
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewLogRecord(t, "semgrep.sarif")
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{Results: 1, Parsed: 1, Created: 1}, outcome)
+	require.Equal(t, 1, events.Len())
+	event := events.At(0)
+
+	var body schema.SecurityEvent
+	require.NoError(t, json.Unmarshal([]byte(event.Body().Str()), &body))
+	// Without organization, the tool name is the vendor
+	assert.Equal(t, schema.Source{Application: "Semgrep OSS", Vendor: "Semgrep OSS", Version: "1.90.0"}, body.Source)
+	// Without security-severity, the severity is the rule level
+	assert.Equal(t, "MEDIUM", body.Finding.Severity)
+	assert.Equal(t, 6.9, body.RiskScore)
+	assert.Equal(t, &schema.Location{FilePath: "scripts/config.py", Line: 12, Column: 5}, body.Finding.Location)
+	assert.Equal(t, "Detected the use of eval().", body.Finding.Description)
+	assert.Equal(t, Kind, body.Metadata["format"])
+	assert.Equal(t, "warning", body.Metadata["level"])
+	assert.Equal(t, map[string]interface{}{"matchBasedId/v1": "3b0f2e1d9c8a"}, body.Metadata["fingerprints"])
+	// Without invocation time, the record keeps the time of the log
+	assert.Empty(t, body.Timestamp)
+	assert.Equal(t, logRecord.Timestamp(), event.Timestamp())
+}
+
+func TestProcessLogRecord_MapBody(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithOutput(processing.Output{Profile: profile.SplunkCIM}))
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewMapLogRecord(t, "semgrep.sarif", nil)
+	events, _, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	require.Equal(t, 1, events.Len())
+
+	attrs := events.At(0).Attributes().AsRaw()
+	assert.Equal(t, "scripts/config.py", attrs["file_path"])
+	assert.Equal(t, "medium", attrs["severity"])
+}
+
+func TestProcessLogRecord_Rules(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	// Two runs: rules are resolved by index, by reference or by ID within the run of the result, and
+	// results take the default level of their rule
+	logRecord := processingtest.NewStringLogRecord(`{"version": "2.1.0", "runs": [
+		{"tool": {"driver": {"name": "Checkov", "rules": [
+			{"id": "CKV_K8S_20", "shortDescription": {"text": "Containers should not run with allowPrivilegeEscalation"},
+			 "defaultConfiguration": {"level": "error"}},
+			{"id": "CKV_K8S_40", "name": "RunAsHighUID", "defaultConfiguration": {"level": "note"}}]}},
+		 "results": [
+			{"ruleIndex": 0, "message": {"text": "privilege escalation"},
+			 "locations": [{"physicalLocation": {"artifactLocation": {"uri": "deploy/app.yaml"}}}]},
+			{"rule": {"index": 1}, "kind": "review", "message": {"text": "low UID"},
+			 "suppressions": [{"kind": "external", "status": "rejected"}]},
+			{"ruleId": "CKV_K8S_40", "kind": "pass", "message": {"text": "high UID"}}]},
+		{"tool": {"driver": {"name": "Semgrep OSS"}},
+		 "results": [{"ruleId": "python.eval", "level": "none", "message": {"text": "eval"},
+			"suppressions": [{"kind": "inSource"}]}]}]}`)
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{
+		Results:  4,
+		Parsed:   4,
+		Filtered: map[string]int{FilterReasonKind: 1, FilterReasonSuppressed: 1},
+		Created:  2,
+	}, outcome)
+	require.Equal(t, 2, events.Len())
+
+	escalation := events.At(0).Attributes().AsRaw()
+	assert.Equal(t, "Containers should not run with allowPrivilegeEscalation", escalation["finding.title"])
+	assert.Equal(t, "HIGH", escalation["finding.severity"])
+	assert.Equal(t, "deploy/app.yaml", escalation["object.id"])
+
+	// A rejected suppression does not suppress the result; without location the result has no target
+	uid := events.At(1).Attributes().AsRaw()
+	assert.Equal(t, "RunAsHighUID", uid["finding.title"])
+	assert.Equal(t, "CKV_K8S_40", uid["finding.type"])
+	assert.Equal(t, "LOW", uid["finding.severity"])
+	assert.NotContains(t, uid, "object.id")
+}
+
+func TestProcessLogRecord_Skipped(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantReason string
+		wantStage  string
+	}{
+		{name: "plain log line", body: "plain log line", wantReason: SkipReasonNotSARIF},
+		{name: "no version", body: `{"runs": []}`, wantReason: SkipReasonNotSARIF},
+		{name: "SARIF 2.0.0", body: `{"version": "2.0.0", "runs": []}`, wantReason: SkipReasonNotSARIF},
+		{
+			name:       "mentions runs and version",
+			body:       `{"msg":"uploaded scan","runs":3,"schema":"2.1.0"}`,
+			wantReason: SkipReasonNotSARIF,
+		},
+		{
+			name:       "nested runs and version",
+			body:       `{"msg": "uploaded scan", "sarif": {"version": "2.1.0", "runs": []}}`,
+			wantReason: SkipReasonNotSARIF,
+		},
+		{
+			name:       "run without results",
+			body:       `{"version": "2.1.0", "runs": [{"tool": {"driver": {"name": "Checkov"}}, "results": []}]}`,
+			wantReason: SkipReasonNoResults,
+		},
+		{
+			name:       "invalid results",
+			body:       `{"version": "2.1.0", "runs": [{"results": "none"}]}`,
+			wantReason: SkipReasonInvalidLog,
+			wantStage:  processing.StageParse,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logRecord := processingtest.NewStringLogRecord(tt.body)
+			events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+			if tt.wantStage != "" {
+				processingtest.RequireStageError(t, err, tt.wantStage)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantReason, outcome.SkipReason)
+			assert.Zero(t, events.Len())
+		})
+	}
+}
+
+func TestIsSARIF(t *testing.T) {
+	sarifRecord := processingtest.NewLogRecord(t, "semgrep.sarif")
+	assert.True(t, IsSARIF(&sarifRecord))
+
+	mapRecord := plog.NewLogRecord()
+	mapRecord.Body().SetEmptyMap().PutStr("version", "2.1.0")
+	assert.False(t, IsSARIF(&mapRecord))
+	mapRecord.Body().Map().PutEmptySlice("runs")
+	assert.True(t, IsSARIF(&mapRecord))
+
+	otherRecord := plog.NewLogRecord()
+	otherRecord.Body().SetStr(`{"version": "2.0.0", "runs": []}`)
+	assert.False(t, IsSARIF(&otherRecord))
+	otherRecord.Body().SetStr(`{"msg":"uploaded scan","runs":3,"schema":"2.1.0"}`)
+	assert.False(t, IsSARIF(&otherRecord))
+	mapRecord.Body().Map().PutInt("runs", 3)
+	assert.False(t, IsSARIF(&mapRecord))
+	emptyRecord := plog.NewLogRecord()
+	assert.False(t, IsSARIF(&emptyRecord))
+}
+
+func TestFindingID(t *testing.T) {
+	result := &Result{Fingerprints: map[string]string{"a/v1": "1", "b/v1": "2"}}
+	id := findingID("CodeQL", "go/sql-injection", result)
+	assert.Equal(t, id, findingID("CodeQL", "go/sql-injection", result))
+	assert.NotEqual(t, id, findingID("CodeQL", "go/log-injection", result))
+	assert.NotEqual(t, id, findingID("Semgrep", "go/sql-injection", result))
+
+	// Partial fingerprints are used without fingerprints, then the GUID
+	partial := &Result{PartialFingerprints: map[string]string{"a/v1": "1", "b/v1": "2"}}
+	assert.Equal(t, id, findingID("CodeQL", "go/sql-injection", partial))
+	assert.Equal(t, "guid-1", findingID("CodeQL", "go/sql-injection", &Result{GUID: "guid-1"}))
+	assert.NotEmpty(t, findingID("CodeQL", "go/sql-injection", &Result{}))
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		name         string
+		result       Result
+		rule         *Rule
+		wantScore    float64
+		wantSeverity string
+	}{
+		{name: "result security-severity", result: Result{Properties: Properties{SecuritySeverity: json.RawMessage(`9.1`)}}, wantScore: 9.1, wantSeverity: "CRITICAL"},
+		{name: "rule security-severity", rule: &Rule{Properties: Properties{SecuritySeverity: json.RawMessage(`"5.0"`)}}, wantScore: 5, wantSeverity: "MEDIUM"},
+		{name: "out of range", result: Result{Properties: Properties{SecuritySeverity: json.RawMessage(`12`)}}, wantScore: 10, wantSeverity: "CRITICAL"},
+		{name: "low", result: Result{Properties: Properties{SecuritySeverity: json.RawMessage(`"2"`)}}, wantScore: 2, wantSeverity: "LOW"},
+		{name: "error", result: Result{Level: "error"}, wantScore: 8.9, wantSeverity: "HIGH"},
+		{name: "note", result: Result{Level: "note"}, wantScore: 3.9, wantSeverity: "LOW"},
+		{name: "none", result: Result{Level: "none"}, wantScore: 0, wantSeverity: ""},
+		{name: "default", wantScore: 6.9, wantSeverity: "MEDIUM"},
+	}
+	for _, tt := range tests {
+		score, severity := severity(&tt.result, tt.rule)
+		assert.Equal(t, tt.wantScore, score, tt.name)
+		assert.Equal(t, tt.wantSeverity, severity, tt.name)
+	}
+}
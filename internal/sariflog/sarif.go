@@ -0,0 +1,237 @@
+package sariflog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// sarifVersion is the only SARIF version supported
+const sarifVersion = "2.1.0"
+
+// Log is a SARIF log: the results of one or more runs of static analysis tools
+// Only the fields mapped to security events are decoded
+type Log struct {
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run is a single invocation of an analysis tool
+type Run struct {
+	Tool                     Tool                    `json:"tool"`
+	Results                  []Result                `json:"results"`
+	Invocations              []Invocation            `json:"invocations"`
+	VersionControlProvenance []VersionControlDetails `json:"versionControlProvenance"`
+}
+
+// Tool describes the analysis tool of a run
+type Tool struct {
+	Driver ToolComponent `json:"driver"`
+}
+
+// ToolComponent is the driver of a tool: its identity and rules
+type ToolComponent struct {
+	Name            string `json:"name"`
+	Organization    string `json:"organization"`
+	Version         string `json:"version"`
+	SemanticVersion string `json:"semanticVersion"`
+	Rules           []Rule `json:"rules"`
+}
+
+// Rule describes an analysis rule
+type Rule struct {
+	ID                   string         `json:"id"`
+	Name                 string         `json:"name"`
+	ShortDescription     *Message       `json:"shortDescription"`
+	FullDescription      *Message       `json:"fullDescription"`
+	HelpURI              string         `json:"helpUri"`
+	DefaultConfiguration *Configuration `json:"defaultConfiguration"`
+	Properties           Properties     `json:"properties"`
+}
+
+// Configuration is the default configuration of a rule
+type Configuration struct {
+	Level string `json:"level"`
+}
+
+// Properties holds the conventional properties of rules and results
+type Properties struct {
+	// Tags categorize the rule, e.g. "security" or "external/cwe/cwe-089"
+	Tags []string `json:"tags"`
+
+	// SecuritySeverity is the CVSS-like severity from 0.0 to 10.0 set by GitHub code scanning tools,
+	// as a string or a number
+	SecuritySeverity json.RawMessage `json:"security-severity"`
+}
+
+// Result is a single finding of a run
+type Result struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           *int              `json:"ruleIndex"`
+	Rule                *RuleReference    `json:"rule"`
+	Kind                string            `json:"kind"`
+	Level               string            `json:"level"`
+	Message             Message           `json:"message"`
+	Locations           []Location        `json:"locations"`
+	GUID                string            `json:"guid"`
+	Fingerprints        map[string]string `json:"fingerprints"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Suppressions        []Suppression     `json:"suppressions"`
+	Properties          Properties        `json:"properties"`
+}
+
+// RuleReference refers to a rule of the driver
+type RuleReference struct {
+	ID    string `json:"id"`
+	Index *int   `json:"index"`
+}
+
+// Message is a plain text message
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Location is the location of a result
+type Location struct {
+	PhysicalLocation *PhysicalLocation `json:"physicalLocation"`
+}
+
+// PhysicalLocation is a region of a file
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region"`
+}
+
+// ArtifactLocation identifies a file
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Region is a position in a file
+type Region struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+// Invocation describes when a tool ran
+type Invocation struct {
+	EndTimeUTC string `json:"endTimeUtc"`
+}
+
+// VersionControlDetails identifies the revision analyzed by a run
+type VersionControlDetails struct {
+	RepositoryURI string `json:"repositoryUri"`
+	RevisionID    string `json:"revisionId"`
+	Branch        string `json:"branch"`
+}
+
+// Suppression records that a result was suppressed, e.g. by an in-source annotation
+type Suppression struct {
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+}
+
+// Parse decodes a SARIF 2.1.0 log
+func Parse(data []byte) (*Log, error) {
+	var log Log
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("invalid SARIF log: %w", err)
+	}
+	if log.Version != sarifVersion {
+		return nil, fmt.Errorf("unsupported SARIF version %q", log.Version)
+	}
+	if log.Runs == nil {
+		return nil, errors.New("invalid SARIF log: missing runs")
+	}
+	return &log, nil
+}
+
+// rule returns the rule of a result, looked up by index then by ID, or nil if the driver does not describe it
+func (r *Run) rule(result *Result) *Rule {
+	rules := r.Tool.Driver.Rules
+	index := result.RuleIndex
+	if index == nil && result.Rule != nil {
+		index = result.Rule.Index
+	}
+	if index != nil && *index >= 0 && *index < len(rules) {
+		return &rules[*index]
+	}
+	id := result.ruleID()
+	if id == "" {
+		return nil
+	}
+	for i := range rules {
+		if rules[i].ID == id {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// ruleID returns the ID of the rule of a result, or ""
+func (r *Result) ruleID() string {
+	if r.RuleID != "" {
+		return r.RuleID
+	}
+	if r.Rule != nil {
+		return r.Rule.ID
+	}
+	return ""
+}
+
+// level returns the level of a result: its own, the default of its rule, or warning
+func (r *Result) level(rule *Rule) string {
+	if r.Level != "" {
+		return r.Level
+	}
+	if rule != nil && rule.DefaultConfiguration != nil && rule.DefaultConfiguration.Level != "" {
+		return rule.DefaultConfiguration.Level
+	}
+	return "warning"
+}
+
+// kind returns the kind of a result, fail if unset
+func (r *Result) kind() string {
+	if r.Kind != "" {
+		return r.Kind
+	}
+	return "fail"
+}
+
+// suppressed reports whether a result has an accepted suppression
+// A suppression without status is accepted
+func (r *Result) suppressed() bool {
+	for _, suppression := range r.Suppressions {
+		if suppression.Status == "" || suppression.Status == "accepted" {
+			return true
+		}
+	}
+	return false
+}
+
+// location returns the first physical location of a result, or nil
+func (r *Result) location() *PhysicalLocation {
+	for _, location := range r.Locations {
+		if location.PhysicalLocation != nil && location.PhysicalLocation.ArtifactLocation.URI != "" {
+			return location.PhysicalLocation
+		}
+	}
+	return nil
+}
+
+// securitySeverity parses a security-severity property, a number as a string or a JSON number
+// ok is false if the property is absent or not a number
+func (p *Properties) securitySeverity() (float64, bool) {
+	if len(p.SecuritySeverity) == 0 {
+		return 0, false
+	}
+	raw := strings.Trim(string(p.SecuritySeverity), `"`)
+	score, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || math.IsNaN(score) {
+		return 0, false
+	}
+	return score, true
+}
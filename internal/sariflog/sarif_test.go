@@ -0,0 +1,72 @@
+package sariflog
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	log, err := Parse([]byte(`{"version": "2.1.0", "runs": [{"tool": {"driver": {"name": "Checkov"}}}]}`))
+	require.NoError(t, err)
+	require.Len(t, log.Runs, 1)
+	assert.Equal(t, "Checkov", log.Runs[0].Tool.Driver.Name)
+
+	_, err = Parse([]byte(`not json`))
+	assert.ErrorContains(t, err, "invalid SARIF log")
+	_, err = Parse([]byte(`{"version": "2.0.0", "runs": []}`))
+	assert.ErrorContains(t, err, `unsupported SARIF version "2.0.0"`)
+	_, err = Parse([]byte(`{"version": "2.1.0"}`))
+	assert.ErrorContains(t, err, "missing runs")
+}
+
+func TestRun_Rule(t *testing.T) {
+	zero, outOfRange := 0, 5
+	run := &Run{Tool: Tool{Driver: ToolComponent{Rules: []Rule{{ID: "R1"}, {ID: "R2"}}}}}
+
+	assert.Equal(t, "R1", run.rule(&Result{RuleIndex: &zero, RuleID: "R2"}).ID)
+	assert.Equal(t, "R1", run.rule(&Result{Rule: &RuleReference{Index: &zero}}).ID)
+	assert.Equal(t, "R2", run.rule(&Result{RuleIndex: &outOfRange, RuleID: "R2"}).ID)
+	assert.Equal(t, "R2", run.rule(&Result{Rule: &RuleReference{ID: "R2"}}).ID)
+	assert.Nil(t, run.rule(&Result{RuleID: "R3"}))
+	assert.Nil(t, run.rule(&Result{}))
+}
+
+func TestResult_Level(t *testing.T) {
+	rule := &Rule{DefaultConfiguration: &Configuration{Level: "error"}}
+	assert.Equal(t, "note", (&Result{Level: "note"}).level(rule))
+	assert.Equal(t, "error", (&Result{}).level(rule))
+	assert.Equal(t, "warning", (&Result{}).level(nil))
+}
+
+func TestResult_Suppressed(t *testing.T) {
+	assert.False(t, (&Result{}).suppressed())
+	assert.True(t, (&Result{Suppressions: []Suppression{{Kind: "inSource"}}}).suppressed())
+	assert.True(t, (&Result{Suppressions: []Suppression{{Kind: "external", Status: "accepted"}}}).suppressed())
+	assert.False(t, (&Result{Suppressions: []Suppression{{Kind: "external", Status: "underReview"}}}).suppressed())
+}
+
+func TestResult_Location(t *testing.T) {
+	result := &Result{Locations: []Location{
+		{},
+		{PhysicalLocation: &PhysicalLocation{}},
+		{PhysicalLocation: &PhysicalLocation{ArtifactLocation: ArtifactLocation{URI: "main.tf"}}},
+	}}
+	require.NotNil(t, result.location())
+	assert.Equal(t, "main.tf", result.location().ArtifactLocation.URI)
+	assert.Nil(t, (&Result{}).location())
+}
+
+func TestProperties_SecuritySeverity(t *testing.T) {
+	for raw, want := range map[string]float64{`"7.5"`: 7.5, `9`: 9, `" 4.0 "`: 4} {
+		score, ok := (&Properties{SecuritySeverity: json.RawMessage(raw)}).securitySeverity()
+		assert.True(t, ok, raw)
+		assert.Equal(t, want, score, raw)
+	}
+	for _, raw := range []string{``, `"high"`, `null`, `"NaN"`} {
+		_, ok := (&Properties{SecuritySeverity: json.RawMessage(raw)}).securitySeverity()
+		assert.False(t, ok, raw)
+	}
+}
@@ -0,0 +1,150 @@
+// Package semconv maps the legacy security event attribute names to the OpenTelemetry semantic conventions.
+package semconv
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Attribute name modes
+const (
+	// ModeLegacy emits the legacy attribute names only
+	ModeLegacy = "legacy"
+	// ModeSemconv emits the semantic convention names instead of the legacy names they replace
+	ModeSemconv = "semconv"
+	// ModeBoth emits the semantic convention names alongside the legacy names
+	ModeBoth = "both"
+)
+
+// Legacy attribute names replaced in semconv mode
+const (
+	attrResourceKind      = "k8s.resource.kind"
+	attrResourceUID       = "k8s.resource.uid"
+	attrWorkloadName      = "k8s.workload.name"
+	attrWorkloadKind      = "k8s.workload.kind"
+	attrWorkloadUID       = "k8s.workload.uid"
+	attrWorkloadNamespace = "k8s.workload.namespace"
+	attrNamespaceName     = "k8s.namespace.name"
+	attrPodName           = "k8s.pod.name"
+	attrEventName         = "event.name"
+	attrEventType         = "event.type"
+	attrVulnerabilityID   = "vulnerability.id"
+	attrFindingSeverity   = "finding.severity"
+	attrFindingDesc       = "finding.description"
+)
+
+// eventNamePrefix namespaces the event names set on the log records, e.g. security.compliance_finding
+const eventNamePrefix = "security."
+
+// kindPrefixes maps Kubernetes kinds to the k8s.<kind> prefix of their semantic convention attributes
+var kindPrefixes = map[string]string{
+	"Pod":         "k8s.pod",
+	"Deployment":  "k8s.deployment",
+	"StatefulSet": "k8s.statefulset",
+	"DaemonSet":   "k8s.daemonset",
+	"ReplicaSet":  "k8s.replicaset",
+	"Job":         "k8s.job",
+	"CronJob":     "k8s.cronjob",
+	"Namespace":   "k8s.namespace",
+	"Node":        "k8s.node",
+}
+
+// NameAttribute returns the semantic convention attribute naming the objects of a Kubernetes kind,
+// e.g. k8s.deployment.name, or false for kinds without one
+func NameAttribute(kind string) (string, bool) {
+	prefix, ok := kindPrefixes[kind]
+	if !ok {
+		return "", false
+	}
+	return prefix + ".name", true
+}
+
+// ValidMode reports whether mode is a known attribute name mode
+func ValidMode(mode string) bool {
+	return mode == ModeLegacy || mode == ModeSemconv || mode == ModeBoth
+}
+
+// Apply adds the semantic convention names to the attributes of a security event or of its resource
+// In semconv mode, legacy names are removed once their value is carried by a semantic convention name;
+// legacy names without an equivalent (e.g. the kind of an unknown resource type) are kept
+func Apply(attrs pcommon.Map, mode string) {
+	if mode != ModeSemconv && mode != ModeBoth {
+		return
+	}
+	replaceLegacy := mode == ModeSemconv
+
+	// Scoped object: k8s.resource.uid of a Pod becomes k8s.pod.uid
+	// The legacy fields carry the name of any scoped object as k8s.pod.name: it becomes k8s.deployment.name
+	// for a Deployment
+	if prefix, ok := kindPrefix(attrs, attrResourceKind); ok {
+		if uid, exists := attrs.Get(attrResourceUID); exists {
+			attrs.PutStr(prefix+".uid", uid.AsString())
+		}
+		if name, exists := attrs.Get(attrPodName); exists && prefix != kindPrefixes["Pod"] {
+			attrs.PutStr(prefix+".name", name.AsString())
+			if replaceLegacy {
+				attrs.Remove(attrPodName)
+			}
+		}
+		if replaceLegacy {
+			attrs.Remove(attrResourceKind)
+			attrs.Remove(attrResourceUID)
+		}
+	}
+
+	// Owning workload: k8s.workload.* of a Deployment become k8s.deployment.*
+	if prefix, ok := kindPrefix(attrs, attrWorkloadKind); ok {
+		if name, exists := attrs.Get(attrWorkloadName); exists {
+			attrs.PutStr(prefix+".name", name.AsString())
+		}
+		if uid, exists := attrs.Get(attrWorkloadUID); exists {
+			attrs.PutStr(prefix+".uid", uid.AsString())
+		}
+		if replaceLegacy {
+			attrs.Remove(attrWorkloadKind)
+			attrs.Remove(attrWorkloadName)
+			attrs.Remove(attrWorkloadUID)
+		}
+	}
+	if _, exists := attrs.Get(attrNamespaceName); exists && replaceLegacy {
+		attrs.Remove(attrWorkloadNamespace)
+	}
+
+	// Vulnerability findings also describe the vulnerability itself
+	if _, exists := attrs.Get(attrVulnerabilityID); exists {
+		if severity, exists := attrs.Get(attrFindingSeverity); exists {
+			attrs.PutStr("vulnerability.severity", severity.AsString())
+		}
+		if description, exists := attrs.Get(attrFindingDesc); exists && description.AsString() != "" {
+			attrs.PutStr("vulnerability.description", description.AsString())
+		}
+	}
+}
+
+// ApplyEventName sets the event name of a security event log record from its event.type,
+// e.g. security.compliance_finding for COMPLIANCE_FINDING
+// In semconv mode, the legacy event.name attribute holding a display name is removed
+func ApplyEventName(logRecord plog.LogRecord, mode string) {
+	if mode != ModeSemconv && mode != ModeBoth {
+		return
+	}
+	attrs := logRecord.Attributes()
+	if eventType, exists := attrs.Get(attrEventType); exists && eventType.AsString() != "" {
+		logRecord.SetEventName(eventNamePrefix + strings.ToLower(eventType.AsString()))
+	}
+	if mode == ModeSemconv {
+		attrs.Remove(attrEventName)
+	}
+}
+
+// kindPrefix returns the semantic convention prefix of the Kubernetes kind held by the key attribute
+func kindPrefix(attrs pcommon.Map, key string) (string, bool) {
+	kind, exists := attrs.Get(key)
+	if !exists {
+		return "", false
+	}
+	prefix, ok := kindPrefixes[kind.AsString()]
+	return prefix, ok
+}
@@ -0,0 +1,195 @@
+package semconv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// newEventAttributes returns the legacy attributes of a security event on a pod owned by a deployment
+func newEventAttributes() pcommon.Map {
+	attrs := pcommon.NewMap()
+	attrs.PutStr("k8s.pod.name", "app-7d9f8b6c5d-x2k4p")
+	attrs.PutStr("k8s.namespace.name", "default")
+	attrs.PutStr("k8s.resource.kind", "Pod")
+	attrs.PutStr("k8s.resource.uid", "pod-uid")
+	attrs.PutStr("k8s.deployment.name", "app")
+	attrs.PutStr("k8s.workload.name", "app")
+	attrs.PutStr("k8s.workload.kind", "Deployment")
+	attrs.PutStr("k8s.workload.namespace", "default")
+	attrs.PutStr("k8s.workload.uid", "deployment-uid")
+	attrs.PutStr("finding.severity", "HIGH")
+	attrs.PutStr("finding.description", "Log4Shell")
+	return attrs
+}
+
+func TestApply(t *testing.T) {
+	semconvAttrs := map[string]any{
+		"k8s.pod.name":        "app-7d9f8b6c5d-x2k4p",
+		"k8s.pod.uid":         "pod-uid",
+		"k8s.namespace.name":  "default",
+		"k8s.deployment.name": "app",
+		"k8s.deployment.uid":  "deployment-uid",
+		"finding.severity":    "HIGH",
+		"finding.description": "Log4Shell",
+	}
+	legacyOnly := map[string]any{
+		"k8s.resource.kind":      "Pod",
+		"k8s.resource.uid":       "pod-uid",
+		"k8s.workload.name":      "app",
+		"k8s.workload.kind":      "Deployment",
+		"k8s.workload.namespace": "default",
+		"k8s.workload.uid":       "deployment-uid",
+	}
+
+	tests := []struct {
+		name string
+		mode string
+		want map[string]any
+	}{
+		{
+			name: "legacy",
+			mode: ModeLegacy,
+			want: newEventAttributes().AsRaw(),
+		},
+		{
+			name: "empty mode is legacy",
+			mode: "",
+			want: newEventAttributes().AsRaw(),
+		},
+		{
+			name: "semconv",
+			mode: ModeSemconv,
+			want: semconvAttrs,
+		},
+		{
+			name: "both",
+			mode: ModeBoth,
+			want: func() map[string]any {
+				both := map[string]any{}
+				for k, v := range semconvAttrs {
+					both[k] = v
+				}
+				for k, v := range legacyOnly {
+					both[k] = v
+				}
+				return both
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := newEventAttributes()
+			Apply(attrs, tt.mode)
+			assert.Equal(t, tt.want, attrs.AsRaw())
+		})
+	}
+}
+
+func TestApply_DeploymentScope(t *testing.T) {
+	newAttrs := func() pcommon.Map {
+		attrs := pcommon.NewMap()
+		attrs.PutStr("k8s.pod.name", "app")
+		attrs.PutStr("k8s.namespace.name", "default")
+		attrs.PutStr("k8s.resource.kind", "Deployment")
+		attrs.PutStr("k8s.resource.uid", "deployment-uid")
+		return attrs
+	}
+
+	attrs := newAttrs()
+	Apply(attrs, ModeSemconv)
+	assert.Equal(t, map[string]any{
+		"k8s.namespace.name":  "default",
+		"k8s.deployment.name": "app",
+		"k8s.deployment.uid":  "deployment-uid",
+	}, attrs.AsRaw(), "the scope name is the deployment name, not a pod name")
+
+	attrs = newAttrs()
+	Apply(attrs, ModeBoth)
+	assert.Equal(t, map[string]any{
+		"k8s.pod.name":        "app",
+		"k8s.namespace.name":  "default",
+		"k8s.resource.kind":   "Deployment",
+		"k8s.resource.uid":    "deployment-uid",
+		"k8s.deployment.name": "app",
+		"k8s.deployment.uid":  "deployment-uid",
+	}, attrs.AsRaw())
+}
+
+func TestApply_UnknownKindKeepsLegacyNames(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.PutStr("k8s.resource.kind", "ConfigMap")
+	attrs.PutStr("k8s.resource.uid", "cm-uid")
+
+	Apply(attrs, ModeSemconv)
+	assert.Equal(t, map[string]any{
+		"k8s.resource.kind": "ConfigMap",
+		"k8s.resource.uid":  "cm-uid",
+	}, attrs.AsRaw())
+}
+
+func TestApply_Vulnerability(t *testing.T) {
+	attrs := newEventAttributes()
+	attrs.PutStr("vulnerability.id", "CVE-2021-44228")
+
+	Apply(attrs, ModeSemconv)
+	severity, _ := attrs.Get("vulnerability.severity")
+	assert.Equal(t, "HIGH", severity.Str())
+	description, _ := attrs.Get("vulnerability.description")
+	assert.Equal(t, "Log4Shell", description.Str())
+
+	// Findings without a vulnerability do not get vulnerability attributes
+	attrs = newEventAttributes()
+	Apply(attrs, ModeSemconv)
+	_, exists := attrs.Get("vulnerability.severity")
+	assert.False(t, exists)
+}
+
+func TestApplyEventName(t *testing.T) {
+	tests := []struct {
+		mode          string
+		wantEventName string
+		wantAttribute bool
+	}{
+		{mode: ModeLegacy, wantEventName: "", wantAttribute: true},
+		{mode: ModeSemconv, wantEventName: "security.compliance_finding", wantAttribute: false},
+		{mode: ModeBoth, wantEventName: "security.compliance_finding", wantAttribute: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			logRecord := plog.NewLogRecord()
+			logRecord.Attributes().PutStr("event.name", "Compliance finding event")
+			logRecord.Attributes().PutStr("event.type", "COMPLIANCE_FINDING")
+
+			ApplyEventName(logRecord, tt.mode)
+			assert.Equal(t, tt.wantEventName, logRecord.EventName())
+			_, exists := logRecord.Attributes().Get("event.name")
+			assert.Equal(t, tt.wantAttribute, exists)
+		})
+	}
+}
+
+func TestValidMode(t *testing.T) {
+	assert.True(t, ValidMode(ModeLegacy))
+	assert.True(t, ValidMode(ModeSemconv))
+	assert.True(t, ValidMode(ModeBoth))
+	assert.False(t, ValidMode(""))
+	assert.False(t, ValidMode("ecs"))
+}
+
+func TestNameAttribute(t *testing.T) {
+	attr, ok := NameAttribute("Deployment")
+	assert.True(t, ok)
+	assert.Equal(t, "k8s.deployment.name", attr)
+
+	attr, ok = NameAttribute("Node")
+	assert.True(t, ok)
+	assert.Equal(t, "k8s.node.name", attr)
+
+	_, ok = NameAttribute("Service")
+	assert.False(t, ok)
+}
@@ -0,0 +1,42 @@
+package validation
+
+import "fmt"
+
+// Actions taken on security events that fail validation
+const (
+	// ActionCount counts invalid security events and passes them through unchanged
+	ActionCount = "count"
+	// ActionDeadLetter counts invalid security events and annotates them with the violated constraints
+	ActionDeadLetter = "dead_letter"
+)
+
+// Config defines the configuration for the JSON Schema validation of the security events
+type Config struct {
+	// Enabled turns on the validation of every security event produced by the processor
+	Enabled bool `mapstructure:"enabled"`
+
+	// SchemaFile is the path to a JSON Schema overriding the schema embedded for the output profile
+	// The schema is applied to the attributes of the security events, as a JSON object
+	SchemaFile string `mapstructure:"schema_file"`
+
+	// Action is taken on security events that fail validation
+	// Valid values: "count", "dead_letter"
+	// If empty, invalid security events are only counted
+	Action string `mapstructure:"action"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	switch cfg.Action {
+	case "", ActionCount, ActionDeadLetter:
+	default:
+		return fmt.Errorf("invalid validation action: %s. Valid values are: %s, %s", cfg.Action, ActionCount, ActionDeadLetter)
+	}
+	if !cfg.Enabled || cfg.SchemaFile == "" {
+		return nil
+	}
+	if _, err := LoadSchema(cfg.SchemaFile); err != nil {
+		return fmt.Errorf("invalid validation schema_file: %w", err)
+	}
+	return nil
+}
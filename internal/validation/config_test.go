@@ -0,0 +1,57 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	validSchema := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(validSchema, []byte(`{"type": "object"}`), 0o600))
+	invalidSchema := filepath.Join(t.TempDir(), "invalid.json")
+	require.NoError(t, os.WriteFile(invalidSchema, []byte(`{"type": 42}`), 0o600))
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{name: "disabled", config: Config{}},
+		{name: "embedded schema", config: Config{Enabled: true}},
+		{name: "count", config: Config{Enabled: true, Action: ActionCount}},
+		{name: "dead letter", config: Config{Enabled: true, Action: ActionDeadLetter}},
+		{name: "schema file", config: Config{Enabled: true, SchemaFile: validSchema}},
+		{name: "schema file of disabled validation is not loaded", config: Config{SchemaFile: "/nonexistent/schema.json"}},
+		{
+			name:    "invalid action",
+			config:  Config{Enabled: true, Action: "drop"},
+			wantErr: "invalid validation action: drop",
+		},
+		{
+			name:    "missing schema file",
+			config:  Config{Enabled: true, SchemaFile: "/nonexistent/schema.json"},
+			wantErr: "invalid validation schema_file",
+		},
+		{
+			name:    "invalid schema file",
+			config:  Config{Enabled: true, SchemaFile: invalidSchema},
+			wantErr: "failed to compile schema",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
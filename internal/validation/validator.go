@@ -0,0 +1,103 @@
+// Package validation checks the security events produced by the processor against a JSON Schema.
+package validation
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// maxViolations is the maximum number of violated constraints reported for an invalid security event
+const maxViolations = 5
+
+// schemaURL is the location the schema is registered under in the compiler
+const schemaURL = "securityevent.schema.json"
+
+//go:embed schemas/*.json
+var embeddedSchemas embed.FS
+
+// Validator validates the attributes of security events against a JSON Schema
+// It is safe for concurrent use
+type Validator struct {
+	schema *jsonschema.Schema
+}
+
+// NewValidator creates a validator using the schema file of the configuration, or the schema
+// embedded for the output profile
+func NewValidator(cfg *Config, profile string) (*Validator, error) {
+	if cfg.SchemaFile != "" {
+		return LoadSchema(cfg.SchemaFile)
+	}
+	return EmbeddedSchema(profile)
+}
+
+// EmbeddedSchema creates a validator using the schema embedded for an output profile
+func EmbeddedSchema(profile string) (*Validator, error) {
+	data, err := embeddedSchemas.ReadFile("schemas/" + profile + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("no schema embedded for output profile %q", profile)
+	}
+	return ParseSchema(data)
+}
+
+// LoadSchema creates a validator using the JSON Schema file at path
+func LoadSchema(path string) (*Validator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+	return ParseSchema(data)
+}
+
+// ParseSchema creates a validator using a JSON Schema document
+func ParseSchema(data []byte) (*Validator, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(schemaURL, doc); err != nil {
+		return nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+	schema, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema: %w", err)
+	}
+	return &Validator{schema: schema}, nil
+}
+
+// Validate checks the attributes of a security event against the schema
+// The returned error lists the violated constraints as "<keyword location> at '<instance location>': <message>",
+// e.g. "/properties/finding.severity/enum at '/finding.severity': value must be one of ..."
+func (v *Validator) Validate(attrs pcommon.Map) error {
+	err := v.schema.Validate(attrs.AsRaw())
+	if err == nil {
+		return nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	var violations []string
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error == nil || len(unit.Errors) > 0 {
+			continue
+		}
+		violations = append(violations,
+			fmt.Sprintf("%s at '%s': %s", unit.KeywordLocation, unit.InstanceLocation, unit.Error.String()))
+	}
+	if len(violations) == 0 {
+		return err
+	}
+	if len(violations) > maxViolations {
+		violations = append(violations[:maxViolations], fmt.Sprintf("and %d more", len(violations)-maxViolations))
+	}
+	return errors.New(strings.Join(violations, "; "))
+}
@@ -0,0 +1,221 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/profile"
+)
+
+// newValidEvent returns the attributes of a security event valid against the embedded schema
+func newValidEvent() pcommon.Map {
+	attrs := pcommon.NewMap()
+	attrs.PutStr("event.id", "event-1")
+	attrs.PutStr("event.version", "1.309")
+	attrs.PutStr("event.category", "COMPLIANCE")
+	attrs.PutStr("event.name", "Compliance finding event")
+	attrs.PutStr("event.type", "COMPLIANCE_FINDING")
+	attrs.PutStr("event.description", "Policy violation on app-1 for rule require-limits")
+	attrs.PutStr("product.name", "")
+	attrs.PutStr("product.vendor", "")
+	attrs.PutDouble("dt.security.risk.score", 8.9)
+	attrs.PutStr("finding.id", "finding-1")
+	attrs.PutStr("finding.title", "pod-security - require-limits")
+	attrs.PutStr("finding.description", "resource limits are required")
+	attrs.PutStr("finding.severity", "HIGH")
+	attrs.PutStr("finding.url", "")
+	attrs.PutStr("compliance.requirements", "pod-security")
+	attrs.PutStr("compliance.status", "NON_COMPLIANT")
+	attrs.PutInt("k8s.node.count", 3)
+	return attrs
+}
+
+func TestEmbeddedSchema(t *testing.T) {
+	validator, err := EmbeddedSchema(profile.Dynatrace)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		modify  func(attrs pcommon.Map)
+		wantErr []string
+	}{
+		{
+			name:   "valid",
+			modify: func(pcommon.Map) {},
+		},
+		{
+			name: "valid with framework controls",
+			modify: func(attrs pcommon.Map) {
+				attrs.PutEmptySlice("compliance.requirements").AppendEmpty().SetStr("CIS 5.2.2")
+				attrs.PutEmptySlice("compliance.standards").AppendEmpty().SetStr("CIS")
+			},
+		},
+		{
+			name: "informational severity",
+			modify: func(attrs pcommon.Map) {
+				attrs.PutStr("finding.severity", "INFORMATIONAL")
+			},
+		},
+		{
+			name: "unmapped compliance requirement",
+			modify: func(attrs pcommon.Map) {
+				attrs.PutStr("compliance.requirements", "pod-security")
+			},
+		},
+		{
+			name: "compliance requirement as a number",
+			modify: func(attrs pcommon.Map) {
+				attrs.PutInt("compliance.requirements", 42)
+			},
+			wantErr: []string{"/properties/compliance.requirements/$ref/oneOf at '/compliance.requirements'"},
+		},
+		{
+			name: "missing mandatory field",
+			modify: func(attrs pcommon.Map) {
+				attrs.Remove("event.id")
+			},
+			wantErr: []string{"/required at '': missing property 'event.id'"},
+		},
+		{
+			name: "missing compliance status",
+			modify: func(attrs pcommon.Map) {
+				attrs.Remove("compliance.status")
+			},
+			wantErr: []string{"/then/required at '': missing property 'compliance.status'"},
+		},
+		{
+			name: "detection finding without compliance status",
+			modify: func(attrs pcommon.Map) {
+				attrs.PutStr("event.type", "DETECTION_FINDING")
+				attrs.Remove("compliance.status")
+			},
+		},
+		{
+			name: "risk score out of range",
+			modify: func(attrs pcommon.Map) {
+				attrs.PutDouble("dt.security.risk.score", 12)
+			},
+			wantErr: []string{"/properties/dt.security.risk.score/maximum at '/dt.security.risk.score'"},
+		},
+		{
+			name: "several violations",
+			modify: func(attrs pcommon.Map) {
+				attrs.PutStr("finding.severity", "SEVERE")
+				attrs.PutStr("compliance.status", "UNKNOWN")
+			},
+			wantErr: []string{
+				"/properties/finding.severity/enum at '/finding.severity'",
+				"/properties/compliance.status/enum at '/compliance.status'",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := newValidEvent()
+			tt.modify(attrs)
+
+			err := validator.Validate(attrs)
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, want := range tt.wantErr {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedSchema_UnknownProfile(t *testing.T) {
+	_, err := EmbeddedSchema("unknown")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no schema embedded for output profile "unknown"`)
+}
+
+func TestEmbeddedSchema_Profiles(t *testing.T) {
+	// The golden attributes of every output profile are valid against the schema embedded for it
+	for _, name := range []string{profile.Dynatrace, profile.SplunkCIM, profile.GoogleUDM, profile.ASIM} {
+		validator, err := EmbeddedSchema(name)
+		require.NoError(t, err, name)
+
+		goldens, err := filepath.Glob(filepath.Join("..", "profile", "testdata", name, "*.json"))
+		require.NoError(t, err)
+		require.NotEmpty(t, goldens, name)
+		for _, golden := range goldens {
+			data, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			var raw map[string]interface{}
+			require.NoError(t, json.Unmarshal(data, &raw))
+			attrs := pcommon.NewMap()
+			require.NoError(t, attrs.FromRaw(raw))
+
+			assert.NoError(t, validator.Validate(attrs), golden)
+		}
+	}
+}
+
+func TestEmbeddedSchema_SplunkCIM(t *testing.T) {
+	validator, err := EmbeddedSchema(profile.SplunkCIM)
+	require.NoError(t, err)
+
+	attrs := pcommon.NewMap()
+	attrs.PutStr("dest", "app-1")
+	attrs.PutStr("id", "finding-1")
+	attrs.PutStr("severity", "HIGH")
+	attrs.PutStr("signature", "CVE-2022-3602")
+	attrs.PutStr("signature_id", "CVE-2022-3602")
+	attrs.PutStr("vendor_product", "Aqua Security Trivy")
+	attrs.PutEmptySlice("tag").AppendEmpty().SetStr("vulnerability")
+
+	err = validator.Validate(attrs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "/properties/severity/enum at '/severity'")
+	assert.Contains(t, err.Error(), "/then/required at '': missing properties 'cve', 'category'")
+}
+
+func TestNewValidator_SchemaFileOverridesProfile(t *testing.T) {
+	schemaFile := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(schemaFile, []byte(`{"type": "object", "required": ["tenant.id"]}`), 0o600))
+
+	validator, err := NewValidator(&Config{Enabled: true, SchemaFile: schemaFile}, profile.Dynatrace)
+	require.NoError(t, err)
+
+	err = validator.Validate(newValidEvent())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing property 'tenant.id'")
+}
+
+func TestValidate_LimitsViolations(t *testing.T) {
+	properties := make([]string, 0, maxViolations+2)
+	for i := 0; i < maxViolations+2; i++ {
+		properties = append(properties, fmt.Sprintf(`"field.%d": {"type": "string"}`, i))
+	}
+	validator, err := ParseSchema([]byte(`{"properties": {` + strings.Join(properties, ",") + `}}`))
+	require.NoError(t, err)
+
+	attrs := pcommon.NewMap()
+	for i := 0; i < maxViolations+2; i++ {
+		attrs.PutInt(fmt.Sprintf("field.%d", i), int64(i))
+	}
+
+	err = validator.Validate(attrs)
+	require.Error(t, err)
+	assert.Len(t, strings.Split(err.Error(), "; "), maxViolations+1)
+	assert.True(t, strings.HasSuffix(err.Error(), "and 2 more"))
+}
+
+func TestParseSchema_Invalid(t *testing.T) {
+	_, err := ParseSchema([]byte(`{"type":`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse schema")
+}
@@ -0,0 +1,70 @@
+package vulnintel
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config defines the configuration for offline vulnerability intelligence enrichment
+type Config struct {
+	// EPSSFile is the path to the EPSS scores CSV (optionally gzip compressed, ending in .gz)
+	// as published at https://epss.cyentia.com/epss_scores-current.csv.gz
+	EPSSFile string `mapstructure:"epss_file"`
+
+	// KEVFile is the path to the CISA Known Exploited Vulnerabilities catalog JSON
+	// as published at https://www.cisa.gov/known-exploited-vulnerabilities-catalog
+	KEVFile string `mapstructure:"kev_file"`
+
+	// RefreshInterval is how often the files are checked for changes and reloaded
+	// If zero, the files are only loaded at startup
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+
+	// AdjustRiskScore factors the EPSS score and KEV membership into dt.security.risk.score
+	AdjustRiskScore bool `mapstructure:"adjust_risk_score"`
+}
+
+// Enabled reports whether at least one data file is configured
+func (cfg *Config) Enabled() bool {
+	return cfg.EPSSFile != "" || cfg.KEVFile != ""
+}
+
+// Validate checks if the configuration is valid
+// The data files are only checked for readability; they are parsed once, when the processor loads them
+func (cfg *Config) Validate() error {
+	if cfg.RefreshInterval < 0 {
+		return fmt.Errorf("vulnerability refresh_interval must not be negative")
+	}
+	if cfg.AdjustRiskScore && !cfg.Enabled() {
+		return fmt.Errorf("vulnerability adjust_risk_score requires epss_file or kev_file")
+	}
+	if cfg.EPSSFile != "" {
+		if err := checkReadable(cfg.EPSSFile); err != nil {
+			return fmt.Errorf("invalid vulnerability epss_file: %w", err)
+		}
+	}
+	if cfg.KEVFile != "" {
+		if err := checkReadable(cfg.KEVFile); err != nil {
+			return fmt.Errorf("invalid vulnerability kev_file: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkReadable checks that a path is a regular file that can be opened for reading
+func checkReadable(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package vulnintel
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EPSS holds the Exploit Prediction Scoring System data of a CVE
+type EPSS struct {
+	// Score is the probability of exploitation in the next 30 days (0-1)
+	Score float64
+	// Percentile is the rank of the score among all scored CVEs (0-1)
+	Percentile float64
+}
+
+// kevCatalog is the subset of the CISA KEV catalog JSON used for enrichment
+type kevCatalog struct {
+	Vulnerabilities []struct {
+		CVEID string `json:"cveID"`
+	} `json:"vulnerabilities"`
+}
+
+// loadEPSS reads an EPSS CSV file
+// The file starts with an optional "#model_version:...,score_date:..." comment line followed by
+// a "cve,epss,percentile" header
+func loadEPSS(path string) (map[string]EPSS, error) {
+	file, err := os.Open(path) //nolint:gosec // path comes from the collector configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to open EPSS file: %w", err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress EPSS file: %w", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	csvReader := csv.NewReader(reader)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = -1
+
+	scores := make(map[string]EPSS)
+	line := 0
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read EPSS file: %w", err)
+		}
+		line++
+		if len(record) < 3 {
+			return nil, fmt.Errorf("EPSS file line %d: expected cve,epss,percentile columns", line)
+		}
+		if record[0] == "cve" {
+			continue // header
+		}
+
+		score, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("EPSS file line %d: invalid epss score: %w", line, err)
+		}
+		percentile, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("EPSS file line %d: invalid percentile: %w", line, err)
+		}
+		scores[strings.ToUpper(record[0])] = EPSS{Score: score, Percentile: percentile}
+	}
+
+	return scores, nil
+}
+
+// loadKEV reads a CISA Known Exploited Vulnerabilities catalog JSON file
+func loadKEV(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from the collector configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read KEV file: %w", err)
+	}
+
+	var catalog kevCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse KEV file: %w", err)
+	}
+
+	kev := make(map[string]bool, len(catalog.Vulnerabilities))
+	for _, vulnerability := range catalog.Vulnerabilities {
+		if vulnerability.CVEID != "" {
+			kev[strings.ToUpper(vulnerability.CVEID)] = true
+		}
+	}
+	return kev, nil
+}
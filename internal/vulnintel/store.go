@@ -0,0 +1,197 @@
+// Package vulnintel enriches vulnerability findings with exploitation intelligence
+// (EPSS scores and CISA Known Exploited Vulnerabilities) loaded from local files.
+package vulnintel
+
+import (
+	"context"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// cveIDPattern matches CVE identifiers
+var cveIDPattern = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,}\b`)
+
+// Intel is the exploitation intelligence known about a CVE
+type Intel struct {
+	// EPSS is the EPSS data, only meaningful if HasEPSS is true
+	EPSS    EPSS
+	HasEPSS bool
+	// KEV is true if the CVE is in the CISA Known Exploited Vulnerabilities catalog
+	KEV bool
+}
+
+// dataset is an immutable snapshot of the loaded files
+type dataset struct {
+	epss map[string]EPSS
+	kev  map[string]bool
+}
+
+// Store holds the vulnerability intelligence and reloads it when the files change
+// A nil *Store is valid and knows nothing
+type Store struct {
+	logger *zap.Logger
+	config *Config
+
+	data atomic.Pointer[dataset]
+
+	// modification times of the loaded files, only accessed by the refresh goroutine
+	epssModTime time.Time
+	kevModTime  time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewStore loads the configured files
+func NewStore(logger *zap.Logger, config *Config) (*Store, error) {
+	s := &Store{
+		logger: logger,
+		config: config,
+	}
+	s.data.Store(&dataset{})
+
+	if _, err := s.refresh(true); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Start starts watching the files for changes if a refresh interval is configured
+func (s *Store) Start(_ context.Context) error {
+	if s == nil || s.config.RefreshInterval <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.config.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if reloaded, err := s.refresh(false); err != nil {
+					s.logger.Warn("Failed to reload vulnerability intelligence - keeping previous data", zap.Error(err))
+				} else if reloaded {
+					s.logger.Info("Vulnerability intelligence reloaded", zap.Int("epss_entries", len(s.data.Load().epss)),
+						zap.Int("kev_entries", len(s.data.Load().kev)))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops watching the files
+func (s *Store) Shutdown(_ context.Context) error {
+	if s == nil || s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+// refresh reloads the files whose modification time changed, or all files if force is set
+// The current snapshot is only replaced if every changed file loads successfully
+func (s *Store) refresh(force bool) (bool, error) {
+	current := s.data.Load()
+	next := &dataset{epss: current.epss, kev: current.kev}
+	epssModTime, kevModTime := s.epssModTime, s.kevModTime
+	reloaded := false
+
+	if s.config.EPSSFile != "" {
+		modTime, changed, err := fileChanged(s.config.EPSSFile, s.epssModTime, force)
+		if err != nil {
+			return false, err
+		}
+		if changed {
+			if next.epss, err = loadEPSS(s.config.EPSSFile); err != nil {
+				return false, err
+			}
+			epssModTime = modTime
+			reloaded = true
+		}
+	}
+
+	if s.config.KEVFile != "" {
+		modTime, changed, err := fileChanged(s.config.KEVFile, s.kevModTime, force)
+		if err != nil {
+			return false, err
+		}
+		if changed {
+			if next.kev, err = loadKEV(s.config.KEVFile); err != nil {
+				return false, err
+			}
+			kevModTime = modTime
+			reloaded = true
+		}
+	}
+
+	if reloaded {
+		s.data.Store(next)
+		s.epssModTime, s.kevModTime = epssModTime, kevModTime
+	}
+	return reloaded, nil
+}
+
+// fileChanged reports whether the file modification time differs from the last loaded one
+func fileChanged(path string, lastModTime time.Time, force bool) (time.Time, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return info.ModTime(), force || !info.ModTime().Equal(lastModTime), nil
+}
+
+// Lookup returns the intelligence known about a CVE
+func (s *Store) Lookup(cveID string) (Intel, bool) {
+	if s == nil || cveID == "" {
+		return Intel{}, false
+	}
+
+	data := s.data.Load()
+	cveID = strings.ToUpper(cveID)
+	epss, hasEPSS := data.epss[cveID]
+	kev := data.kev[cveID]
+	return Intel{EPSS: epss, HasEPSS: hasEPSS, KEV: kev}, hasEPSS || kev
+}
+
+// AdjustRiskScores reports whether risk scores should factor in the intelligence
+func (s *Store) AdjustRiskScores() bool {
+	return s != nil && s.config.AdjustRiskScore
+}
+
+// AdjustRiskScore raises a severity based risk score (0-10) with exploitation intelligence:
+// the score grows by up to 2 points proportionally to the EPSS probability, and CVEs in the
+// KEV catalog score at least 9.0. The result is capped at 10.
+func AdjustRiskScore(score float64, intel Intel) float64 {
+	if intel.HasEPSS {
+		score += 2 * intel.EPSS.Score
+	}
+	if intel.KEV {
+		score = math.Max(score, 9.0)
+	}
+	return math.Min(score, 10.0)
+}
+
+// ExtractCVEID returns the first CVE identifier found in the candidates, upper-cased
+func ExtractCVEID(candidates ...string) string {
+	for _, candidate := range candidates {
+		if match := cveIDPattern.FindString(candidate); match != "" {
+			return strings.ToUpper(match)
+		}
+	}
+	return ""
+}
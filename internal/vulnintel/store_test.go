@@ -0,0 +1,193 @@
+package vulnintel
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNewStore_Lookup(t *testing.T) {
+	store, err := NewStore(zaptest.NewLogger(t), &Config{
+		EPSSFile: filepath.Join("testdata", "epss.csv"),
+		KEVFile:  filepath.Join("testdata", "kev.json"),
+	})
+	require.NoError(t, err)
+
+	intel, ok := store.Lookup("CVE-2021-44228")
+	require.True(t, ok)
+	assert.True(t, intel.HasEPSS)
+	assert.InDelta(t, 0.94358, intel.EPSS.Score, 1e-9)
+	assert.InDelta(t, 0.99982, intel.EPSS.Percentile, 1e-9)
+	assert.True(t, intel.KEV)
+
+	intel, ok = store.Lookup("cve-2024-0001")
+	require.True(t, ok, "lookup is case insensitive")
+	assert.True(t, intel.HasEPSS)
+	assert.False(t, intel.KEV)
+
+	_, ok = store.Lookup("CVE-1999-0001")
+	assert.False(t, ok)
+}
+
+func TestNewStore_GzipEPSS(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "epss.csv"))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err = writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	path := filepath.Join(t.TempDir(), "epss_scores-current.csv.gz")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	store, err := NewStore(zaptest.NewLogger(t), &Config{EPSSFile: path})
+	require.NoError(t, err)
+
+	intel, ok := store.Lookup("CVE-2023-44487")
+	require.True(t, ok)
+	assert.InDelta(t, 0.81234, intel.EPSS.Score, 1e-9)
+}
+
+func TestNewStore_InvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	badEPSS := filepath.Join(dir, "epss.csv")
+	require.NoError(t, os.WriteFile(badEPSS, []byte("cve,epss,percentile\nCVE-2021-44228,abc,0.1\n"), 0o600))
+	badKEV := filepath.Join(dir, "kev.json")
+	require.NoError(t, os.WriteFile(badKEV, []byte("{"), 0o600))
+
+	_, err := NewStore(zaptest.NewLogger(t), &Config{EPSSFile: badEPSS})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid epss score")
+
+	_, err = NewStore(zaptest.NewLogger(t), &Config{KEVFile: badKEV})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse KEV file")
+
+	_, err = NewStore(zaptest.NewLogger(t), &Config{KEVFile: filepath.Join(dir, "missing.json")})
+	require.Error(t, err)
+}
+
+func TestStore_RefreshOnFileChange(t *testing.T) {
+	kevFile := filepath.Join(t.TempDir(), "kev.json")
+	require.NoError(t, os.WriteFile(kevFile, []byte(`{"vulnerabilities": []}`), 0o600))
+
+	store, err := NewStore(zaptest.NewLogger(t), &Config{KEVFile: kevFile, RefreshInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	require.NoError(t, store.Start(context.Background()))
+	defer func() { require.NoError(t, store.Shutdown(context.Background())) }()
+
+	_, ok := store.Lookup("CVE-2021-44228")
+	require.False(t, ok)
+
+	require.NoError(t, os.WriteFile(kevFile, []byte(`{"vulnerabilities": [{"cveID": "CVE-2021-44228"}]}`), 0o600))
+	require.NoError(t, os.Chtimes(kevFile, time.Now(), time.Now().Add(time.Minute)))
+
+	assert.Eventually(t, func() bool {
+		intel, ok := store.Lookup("CVE-2021-44228")
+		return ok && intel.KEV
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStore_RefreshKeepsDataOnError(t *testing.T) {
+	kevFile := filepath.Join(t.TempDir(), "kev.json")
+	require.NoError(t, os.WriteFile(kevFile, []byte(`{"vulnerabilities": [{"cveID": "CVE-2021-44228"}]}`), 0o600))
+
+	store, err := NewStore(zaptest.NewLogger(t), &Config{KEVFile: kevFile})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(kevFile, []byte("not json"), 0o600))
+	require.NoError(t, os.Chtimes(kevFile, time.Now(), time.Now().Add(time.Minute)))
+
+	reloaded, err := store.refresh(false)
+	require.Error(t, err)
+	assert.False(t, reloaded)
+
+	_, ok := store.Lookup("CVE-2021-44228")
+	assert.True(t, ok, "previous data is kept when reload fails")
+}
+
+func TestStore_NilStore(t *testing.T) {
+	var store *Store
+	_, ok := store.Lookup("CVE-2021-44228")
+	assert.False(t, ok)
+	assert.False(t, store.AdjustRiskScores())
+	assert.NoError(t, store.Start(context.Background()))
+	assert.NoError(t, store.Shutdown(context.Background()))
+}
+
+func TestAdjustRiskScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		score    float64
+		intel    Intel
+		expected float64
+	}{
+		{name: "no intelligence", score: 6.9, intel: Intel{}, expected: 6.9},
+		{name: "epss raises score", score: 6.9, intel: Intel{HasEPSS: true, EPSS: EPSS{Score: 0.5}}, expected: 7.9},
+		{name: "kev floor", score: 3.9, intel: Intel{KEV: true}, expected: 9.0},
+		{name: "kev keeps higher score", score: 10.0, intel: Intel{KEV: true}, expected: 10.0},
+		{name: "capped at 10", score: 8.9, intel: Intel{HasEPSS: true, EPSS: EPSS{Score: 0.9}, KEV: true}, expected: 10.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, AdjustRiskScore(tt.score, tt.intel), 1e-9)
+		})
+	}
+}
+
+func TestExtractCVEID(t *testing.T) {
+	assert.Equal(t, "CVE-2021-44228", ExtractCVEID("", "cve-2021-44228"))
+	assert.Equal(t, "CVE-2023-44487", ExtractCVEID("policy", "CVE-2023-44487 in golang.org/x/net"))
+	assert.Equal(t, "", ExtractCVEID("disallow-privileged-containers", "GHSA-xxxx-yyyy-zzzz"))
+}
+
+func TestConfig_ValidateDoesNotParse(t *testing.T) {
+	// Malformed content is reported when the store loads the file, not by the configuration validation
+	kevFile := filepath.Join(t.TempDir(), "kev.json")
+	require.NoError(t, os.WriteFile(kevFile, []byte("{"), 0o600))
+
+	config := &Config{KEVFile: kevFile}
+	require.NoError(t, config.Validate())
+	_, err := NewStore(zaptest.NewLogger(t), config)
+	assert.Error(t, err)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&Config{}).Validate())
+	assert.NoError(t, (&Config{
+		EPSSFile:        filepath.Join("testdata", "epss.csv"),
+		KEVFile:         filepath.Join("testdata", "kev.json"),
+		RefreshInterval: time.Hour,
+		AdjustRiskScore: true,
+	}).Validate())
+
+	tests := []struct {
+		name   string
+		config Config
+		errMsg string
+	}{
+		{name: "negative interval", config: Config{RefreshInterval: -time.Second}, errMsg: "must not be negative"},
+		{name: "adjust without files", config: Config{AdjustRiskScore: true}, errMsg: "requires epss_file or kev_file"},
+		{name: "missing epss file", config: Config{EPSSFile: "/nonexistent/epss.csv"}, errMsg: "invalid vulnerability epss_file"},
+		{name: "missing kev file", config: Config{KEVFile: "/nonexistent/kev.json"}, errMsg: "invalid vulnerability kev_file"},
+		{name: "directory", config: Config{EPSSFile: "testdata"}, errMsg: "not a regular file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
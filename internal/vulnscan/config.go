@@ -0,0 +1,34 @@
+package vulnscan
+
+import "fmt"
+
+// VEX actions for findings whose VEX analysis states they are not exploitable
+const (
+	// VEXSuppress drops the findings (default)
+	VEXSuppress = "suppress"
+	// VEXAnnotate keeps the findings with their VEX state, at informational severity
+	VEXAnnotate = "annotate"
+)
+
+// Config defines the configuration for the vulnerability scan processor
+type Config struct {
+	// Enabled indicates whether the vulnerability scan processor is enabled
+	// Log records whose body holds a Grype JSON report or a CycloneDX BOM with vulnerabilities, as a JSON
+	// string or a parsed map, are expanded into one security event per vulnerable package
+	Enabled bool `mapstructure:"enabled"`
+
+	// VEX is the action for findings whose CycloneDX VEX analysis state is not_affected, false_positive,
+	// resolved or resolved_with_pedigree
+	// Valid values: "suppress" (default), "annotate"
+	VEX string `mapstructure:"vex"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	switch cfg.VEX {
+	case "", VEXSuppress, VEXAnnotate:
+		return nil
+	default:
+		return fmt.Errorf("invalid vulnscan vex: %s. Valid values are: suppress, annotate", cfg.VEX)
+	}
+}
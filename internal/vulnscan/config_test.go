@@ -0,0 +1,17 @@
+package vulnscan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	for _, vex := range []string{"", VEXSuppress, VEXAnnotate} {
+		cfg := &Config{Enabled: true, VEX: vex}
+		assert.NoError(t, cfg.Validate(), vex)
+	}
+
+	cfg := &Config{Enabled: true, VEX: "drop"}
+	assert.EqualError(t, cfg.Validate(), "invalid vulnscan vex: drop. Valid values are: suppress, annotate")
+}
@@ -0,0 +1,272 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// cycloneDXFormat is the bomFormat of CycloneDX BOMs
+const cycloneDXFormat = "CycloneDX"
+
+// cdxBOM is a CycloneDX JSON BOM, or a VEX document (a BOM holding only vulnerabilities)
+// Only the fields mapped to security events are decoded
+type cdxBOM struct {
+	BOMFormat       string             `json:"bomFormat"`
+	SpecVersion     string             `json:"specVersion"`
+	SerialNumber    string             `json:"serialNumber"`
+	Metadata        cdxMetadata        `json:"metadata"`
+	Components      []cdxComponent     `json:"components"`
+	Vulnerabilities []cdxVulnerability `json:"vulnerabilities"`
+}
+
+// cdxMetadata describes the BOM: when and by which tool it was produced, and its subject
+type cdxMetadata struct {
+	Timestamp string `json:"timestamp"`
+	// Tools is an array of tools up to CycloneDX 1.4, an object holding components from 1.5
+	Tools     json.RawMessage `json:"tools"`
+	Component *cdxComponent   `json:"component"`
+}
+
+// cdxTool is a tool that produced the BOM, as a legacy tool or a component
+type cdxTool struct {
+	Vendor    string `json:"vendor"`
+	Group     string `json:"group"`
+	Publisher string `json:"publisher"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+}
+
+// cdxComponent is a software component, possibly nesting other components
+type cdxComponent struct {
+	BOMRef     string         `json:"bom-ref"`
+	Type       string         `json:"type"`
+	Name       string         `json:"name"`
+	Version    string         `json:"version"`
+	PURL       string         `json:"purl"`
+	Components []cdxComponent `json:"components"`
+}
+
+// cdxVulnerability is a vulnerability affecting components of the BOM
+type cdxVulnerability struct {
+	ID         string     `json:"id"`
+	Source     *cdxSource `json:"source"`
+	References []struct {
+		ID string `json:"id"`
+	} `json:"references"`
+	Ratings        []cdxRating `json:"ratings"`
+	CWEs           []int       `json:"cwes"`
+	Description    string      `json:"description"`
+	Detail         string      `json:"detail"`
+	Recommendation string      `json:"recommendation"`
+	Advisories     []struct {
+		URL string `json:"url"`
+	} `json:"advisories"`
+	Analysis *cdxAnalysis `json:"analysis"`
+	Affects  []struct {
+		Ref string `json:"ref"`
+	} `json:"affects"`
+}
+
+// cdxSource is the source of a vulnerability or rating
+type cdxSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// cdxRating is a severity rating of a vulnerability
+type cdxRating struct {
+	Score    *float64 `json:"score"`
+	Severity string   `json:"severity"`
+	Method   string   `json:"method"`
+	Vector   string   `json:"vector"`
+}
+
+// cdxAnalysis is the VEX analysis of a vulnerability
+type cdxAnalysis struct {
+	State         string   `json:"state"`
+	Justification string   `json:"justification"`
+	Response      []string `json:"response"`
+	Detail        string   `json:"detail"`
+}
+
+// cvssVersions maps the CVSS rating methods to CVSS versions
+var cvssVersions = map[string]string{
+	"CVSSv2":  "2.0",
+	"CVSSv3":  "3.0",
+	"CVSSv31": "3.1",
+	"CVSSv4":  "4.0",
+}
+
+// parseCycloneDX decodes a CycloneDX JSON BOM
+func parseCycloneDX(data []byte) (*scan, error) {
+	var bom cdxBOM
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, fmt.Errorf("invalid CycloneDX BOM: %w", err)
+	}
+	if bom.BOMFormat != cycloneDXFormat {
+		return nil, fmt.Errorf("invalid CycloneDX BOM: unexpected bomFormat %q", bom.BOMFormat)
+	}
+
+	s := &scan{
+		tool:      bom.Metadata.tool(),
+		timestamp: parseTime(bom.Metadata.Timestamp),
+		metadata:  map[string]interface{}{},
+	}
+	if component := bom.Metadata.Component; component != nil {
+		s.target = schema.Target{
+			ID:           processing.FirstNonEmpty(component.PURL, component.BOMRef, component.Name),
+			Resource:     component.Name,
+			ResourceType: component.Type,
+		}
+		if component.Type == "container" {
+			s.target.ResourceType = targetContainerImage
+		}
+	}
+	if bom.SerialNumber != "" {
+		s.metadata["serial_number"] = bom.SerialNumber
+	}
+	if bom.SpecVersion != "" {
+		s.metadata["spec_version"] = bom.SpecVersion
+	}
+
+	components := make(map[string]*cdxComponent)
+	indexComponents(components, bom.Components)
+	for i := range bom.Vulnerabilities {
+		s.findings = append(s.findings, bom.Vulnerabilities[i].findings(components)...)
+	}
+	return s, nil
+}
+
+// tool returns the first tool that produced the BOM
+func (m *cdxMetadata) tool() schema.Source {
+	var tools []cdxTool
+	if err := json.Unmarshal(m.Tools, &tools); err != nil {
+		var object struct {
+			Components []cdxTool `json:"components"`
+		}
+		if err := json.Unmarshal(m.Tools, &object); err != nil {
+			return schema.Source{}
+		}
+		tools = object.Components
+	}
+	if len(tools) == 0 {
+		return schema.Source{}
+	}
+	tool := tools[0]
+	return schema.Source{
+		Application: tool.Name,
+		Vendor:      processing.FirstNonEmpty(tool.Vendor, tool.Publisher, tool.Group, tool.Name),
+		Version:     tool.Version,
+	}
+}
+
+// indexComponents indexes components and their nested components by BOM reference
+func indexComponents(index map[string]*cdxComponent, components []cdxComponent) {
+	for i := range components {
+		if components[i].BOMRef != "" {
+			index[components[i].BOMRef] = &components[i]
+		}
+		indexComponents(index, components[i].Components)
+	}
+}
+
+// findings returns one finding per component affected by a vulnerability, or a single finding without
+// package if the vulnerability names no affected component
+func (v *cdxVulnerability) findings(components map[string]*cdxComponent) []finding {
+	base := finding{
+		id:          v.ID,
+		description: processing.FirstNonEmpty(v.Description, v.Detail),
+		metadata:    map[string]interface{}{},
+	}
+	for _, reference := range v.References {
+		if reference.ID != "" && reference.ID != v.ID {
+			base.aliases = append(base.aliases, reference.ID)
+		}
+	}
+	base.cvss, base.severity = cdxRatings(v.Ratings)
+	if len(v.Advisories) > 0 {
+		base.url = v.Advisories[0].URL
+	}
+	if base.url == "" && v.Source != nil {
+		base.url = v.Source.URL
+	}
+	if v.Recommendation != "" {
+		base.metadata["recommendation"] = v.Recommendation
+	}
+	if len(v.CWEs) > 0 {
+		cwes := make([]interface{}, len(v.CWEs))
+		for i, cwe := range v.CWEs {
+			cwes[i] = fmt.Sprintf("CWE-%d", cwe)
+		}
+		base.metadata["cwes"] = cwes
+	}
+	if v.Analysis != nil {
+		base.vexState = v.Analysis.State
+		base.fixState = cdxFixState(v.Analysis.Response)
+		if v.Analysis.Justification != "" {
+			base.metadata["vex_justification"] = v.Analysis.Justification
+		}
+		if v.Analysis.Detail != "" {
+			base.metadata["vex_detail"] = v.Analysis.Detail
+		}
+	}
+
+	if len(v.Affects) == 0 {
+		return []finding{base}
+	}
+	findings := make([]finding, 0, len(v.Affects))
+	for _, affect := range v.Affects {
+		f := base
+		if component, ok := components[affect.Ref]; ok {
+			f.pkg = &schema.Package{Name: component.Name, Version: component.Version, PURL: component.PURL}
+			if purl := packageFromPURL(component.PURL); purl != nil {
+				f.pkg.Type = purl.Type
+			}
+		} else {
+			// VEX documents refer to components of another BOM, usually by package URL
+			f.pkg = packageFromPURL(affect.Ref)
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// cdxRatings returns the highest version CVSS rating with a score, or nil, and the severity of that rating
+// Without CVSS rating, the severity is the one of the first rating with a severity; a CVSS rating without
+// severity returns none, so the severity is derived from its score
+func cdxRatings(ratings []cdxRating) (*schema.CVSS, string) {
+	var best *schema.CVSS
+	var bestSeverity, firstSeverity string
+	for _, rating := range ratings {
+		version, isCVSS := cvssVersions[rating.Method]
+		if isCVSS && rating.Score != nil && (best == nil || version > best.Version) {
+			best = &schema.CVSS{Version: version, Vector: rating.Vector, BaseScore: *rating.Score}
+			bestSeverity = rating.Severity
+		}
+		if firstSeverity == "" {
+			firstSeverity = rating.Severity
+		}
+	}
+	if best == nil {
+		return nil, firstSeverity
+	}
+	return best, bestSeverity
+}
+
+// cdxFixState derives a fix state from the responses of a VEX analysis, "" if they do not tell
+func cdxFixState(responses []string) string {
+	for _, response := range responses {
+		switch response {
+		case "update", "rollback":
+			return fixStateFixed
+		case "will_not_fix":
+			return fixStateWontFix
+		case "can_not_fix":
+			return fixStateNotFixed
+		}
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package vulnscan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+func TestParseCycloneDX_VEXDocument(t *testing.T) {
+	// A CycloneDX 1.4 VEX document refers to the components of another BOM by package URL
+	report, err := parseCycloneDX([]byte(`{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.4",
+		"metadata": {"tools": [{"vendor": "Aqua Security", "name": "trivy", "version": "0.56.0"}]},
+		"vulnerabilities": [{
+			"id": "CVE-2023-45288",
+			"analysis": {"state": "in_triage", "response": ["will_not_fix"]},
+			"affects": [{"ref": "pkg:golang/golang.org/x/net@v0.15.0"}, {"ref": "urn:cdx:unknown"}]
+		}, {
+			"id": "CVE-2024-0001"
+		}]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, schema.Source{Application: "trivy", Vendor: "Aqua Security", Version: "0.56.0"}, report.tool)
+	assert.Equal(t, schema.Target{}, report.target)
+
+	require.Len(t, report.findings, 3)
+	assert.Equal(t, &schema.Package{
+		Name:    "net",
+		Version: "v0.15.0",
+		PURL:    "pkg:golang/golang.org/x/net@v0.15.0",
+		Type:    "golang",
+	}, report.findings[0].pkg)
+	assert.Equal(t, "in_triage", report.findings[0].vexState)
+	assert.Equal(t, "wont_fix", report.findings[0].fixState)
+	assert.Nil(t, report.findings[1].pkg)
+	assert.Equal(t, "CVE-2024-0001", report.findings[2].id)
+	assert.Nil(t, report.findings[2].pkg)
+}
+
+func TestParseCycloneDX_Invalid(t *testing.T) {
+	_, err := parseCycloneDX([]byte(`[]`))
+	assert.ErrorContains(t, err, "invalid CycloneDX BOM")
+	_, err = parseCycloneDX([]byte(`{"bomFormat": "SPDX"}`))
+	assert.ErrorContains(t, err, `unexpected bomFormat "SPDX"`)
+}
+
+func TestCDXRatings(t *testing.T) {
+	score := func(v float64) *float64 { return &v }
+
+	cvss, severity := cdxRatings([]cdxRating{
+		{Severity: "medium", Method: "other"},
+		{Score: score(5.0), Severity: "medium", Method: "CVSSv2"},
+		{Score: score(9.8), Severity: "critical", Method: "CVSSv31", Vector: "CVSS:3.1/AV:N"},
+		{Score: score(8.1), Severity: "high", Method: "CVSSv3"},
+	})
+	assert.Equal(t, &schema.CVSS{Version: "3.1", Vector: "CVSS:3.1/AV:N", BaseScore: 9.8}, cvss)
+	assert.Equal(t, "critical", severity)
+
+	// The severity is the one of the chosen rating, not of a lower version one
+	cvss, severity = cdxRatings([]cdxRating{
+		{Score: score(5.0), Severity: "medium", Method: "CVSSv2"},
+		{Score: score(9.3), Method: "CVSSv4", Vector: "CVSS:4.0/AV:N"},
+		{Score: score(9.8), Severity: "critical", Method: "CVSSv31"},
+	})
+	assert.Equal(t, &schema.CVSS{Version: "4.0", Vector: "CVSS:4.0/AV:N", BaseScore: 9.3}, cvss)
+	assert.Empty(t, severity, "derived from the score of the rating")
+
+	cvss, severity = cdxRatings([]cdxRating{{Method: "other"}, {Severity: "high", Method: "OWASP"}})
+	assert.Nil(t, cvss)
+	assert.Equal(t, "high", severity)
+}
+
+func TestCDXFixState(t *testing.T) {
+	assert.Equal(t, "fixed", cdxFixState([]string{"workaround_available", "update"}))
+	assert.Equal(t, "wont_fix", cdxFixState([]string{"will_not_fix"}))
+	assert.Equal(t, "not_fixed", cdxFixState([]string{"can_not_fix"}))
+	assert.Empty(t, cdxFixState(nil))
+}
@@ -0,0 +1,237 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// grypeVendor is the vendor of Grype, which its reports do not name
+const grypeVendor = "Anchore"
+
+// grypeReport is a Grype JSON report (grype -o json)
+// Only the fields mapped to security events are decoded
+type grypeReport struct {
+	Matches    []grypeMatch    `json:"matches"`
+	Source     *grypeSource    `json:"source"`
+	Distro     grypeDistro     `json:"distro"`
+	Descriptor grypeDescriptor `json:"descriptor"`
+}
+
+// grypeMatch is a vulnerability matched to a package
+type grypeMatch struct {
+	Vulnerability          grypeVulnerability   `json:"vulnerability"`
+	RelatedVulnerabilities []grypeVulnerability `json:"relatedVulnerabilities"`
+	Artifact               grypeArtifact        `json:"artifact"`
+}
+
+// grypeVulnerability describes a vulnerability from one data source
+type grypeVulnerability struct {
+	ID          string      `json:"id"`
+	DataSource  string      `json:"dataSource"`
+	Namespace   string      `json:"namespace"`
+	Severity    string      `json:"severity"`
+	URLs        []string    `json:"urls"`
+	Description string      `json:"description"`
+	CVSS        []grypeCVSS `json:"cvss"`
+	Fix         grypeFix    `json:"fix"`
+}
+
+// grypeCVSS is a CVSS rating of a vulnerability
+type grypeCVSS struct {
+	Version string `json:"version"`
+	Vector  string `json:"vector"`
+	Metrics struct {
+		BaseScore *float64 `json:"baseScore"`
+	} `json:"metrics"`
+}
+
+// grypeFix lists the versions fixing a vulnerability
+type grypeFix struct {
+	Versions []string `json:"versions"`
+	State    string   `json:"state"`
+}
+
+// grypeArtifact is the vulnerable package
+type grypeArtifact struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Type      string `json:"type"`
+	PURL      string `json:"purl"`
+	Locations []struct {
+		Path string `json:"path"`
+	} `json:"locations"`
+}
+
+// grypeSource is the scanned image, directory or file
+type grypeSource struct {
+	Type string `json:"type"`
+	// Target is an image description for images, a path otherwise
+	Target json.RawMessage `json:"target"`
+}
+
+// grypeImage describes a scanned image
+type grypeImage struct {
+	UserInput      string   `json:"userInput"`
+	ImageID        string   `json:"imageID"`
+	ManifestDigest string   `json:"manifestDigest"`
+	RepoDigests    []string `json:"repoDigests"`
+}
+
+// grypeDistro is the Linux distribution of the scanned image
+type grypeDistro struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// grypeDescriptor describes the Grype run
+type grypeDescriptor struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Timestamp string `json:"timestamp"`
+}
+
+// parseGrype decodes a Grype JSON report
+func parseGrype(data []byte) (*scan, error) {
+	var report grypeReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("invalid Grype report: %w", err)
+	}
+	if report.Matches == nil {
+		return nil, errors.New("invalid Grype report: missing matches")
+	}
+
+	name := report.Descriptor.Name
+	if name == "" {
+		name = FormatGrype
+	}
+	s := &scan{
+		tool: schema.Source{
+			Application: strings.ToUpper(name[:1]) + name[1:],
+			Vendor:      grypeVendor,
+			Version:     report.Descriptor.Version,
+		},
+		timestamp: parseTime(report.Descriptor.Timestamp),
+		findings:  make([]finding, 0, len(report.Matches)),
+		metadata:  map[string]interface{}{},
+	}
+	if report.Source != nil {
+		s.target = report.Source.target()
+	}
+	if report.Distro.Name != "" {
+		s.metadata["distro"] = strings.TrimSpace(report.Distro.Name + " " + report.Distro.Version)
+	}
+
+	for i := range report.Matches {
+		s.findings = append(s.findings, report.Matches[i].finding())
+	}
+	return s, nil
+}
+
+// target returns the scanned image, directory or file
+func (s *grypeSource) target() schema.Target {
+	var path string
+	if err := json.Unmarshal(s.Target, &path); err == nil {
+		return schema.Target{ID: path, Resource: path, ResourceType: s.Type}
+	}
+
+	var image grypeImage
+	if err := json.Unmarshal(s.Target, &image); err != nil {
+		return schema.Target{ResourceType: s.Type}
+	}
+	target := schema.Target{
+		ID:           image.ManifestDigest,
+		Resource:     image.UserInput,
+		ResourceType: targetContainerImage,
+	}
+	if target.ID == "" {
+		target.ID = image.ImageID
+	}
+	return target
+}
+
+// finding returns the finding of a match
+// The related vulnerabilities, e.g. the NVD record of a distribution advisory, complete the description
+// and CVSS rating
+func (m *grypeMatch) finding() finding {
+	vuln := &m.Vulnerability
+	f := finding{
+		id:            vuln.ID,
+		description:   vuln.Description,
+		severity:      vuln.Severity,
+		url:           vuln.DataSource,
+		cvss:          grypeRating(vuln.CVSS),
+		fixState:      grypeFixState(vuln.Fix.State),
+		fixedVersions: vuln.Fix.Versions,
+		metadata:      map[string]interface{}{},
+	}
+	if f.url == "" && len(vuln.URLs) > 0 {
+		f.url = vuln.URLs[0]
+	}
+	for i := range m.RelatedVulnerabilities {
+		related := &m.RelatedVulnerabilities[i]
+		if related.ID != vuln.ID {
+			f.aliases = append(f.aliases, related.ID)
+		}
+		if f.description == "" {
+			f.description = related.Description
+		}
+		if f.cvss == nil {
+			f.cvss = grypeRating(related.CVSS)
+		}
+	}
+
+	artifact := &m.Artifact
+	if artifact.Name != "" {
+		f.pkg = &schema.Package{
+			Name:    artifact.Name,
+			Version: artifact.Version,
+			PURL:    artifact.PURL,
+			Type:    artifact.Type,
+		}
+	}
+	if len(artifact.Locations) > 0 {
+		locations := make([]interface{}, 0, len(artifact.Locations))
+		for _, location := range artifact.Locations {
+			locations = append(locations, location.Path)
+		}
+		f.metadata["locations"] = locations
+	}
+	if vuln.Namespace != "" {
+		f.metadata["namespace"] = vuln.Namespace
+	}
+	return f
+}
+
+// grypeRating returns the highest version CVSS rating with a base score, or nil
+func grypeRating(ratings []grypeCVSS) *schema.CVSS {
+	var best *schema.CVSS
+	for _, rating := range ratings {
+		if rating.Metrics.BaseScore == nil {
+			continue
+		}
+		if best == nil || rating.Version > best.Version {
+			best = &schema.CVSS{Version: rating.Version, Vector: rating.Vector, BaseScore: *rating.Metrics.BaseScore}
+		}
+	}
+	return best
+}
+
+// grypeFixState maps a Grype fix state (fixed, not-fixed, wont-fix, unknown) to a fix state
+func grypeFixState(state string) string {
+	switch strings.ToLower(state) {
+	case "fixed":
+		return fixStateFixed
+	case "not-fixed":
+		return fixStateNotFixed
+	case "wont-fix":
+		return fixStateWontFix
+	case "":
+		return ""
+	default:
+		return fixStateUnknown
+	}
+}
@@ -0,0 +1,52 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+func TestParseGrype_DirectorySource(t *testing.T) {
+	report, err := parseGrype([]byte(`{
+		"matches": [{"vulnerability": {"id": "GHSA-xxxx", "severity": "Low"}, "artifact": {"name": "lodash", "version": "4.17.20"}}],
+		"source": {"type": "directory", "target": "/src/app"},
+		"descriptor": {"name": "grype", "version": "0.74.0"}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, schema.Target{ID: "/src/app", Resource: "/src/app", ResourceType: "directory"}, report.target)
+	assert.True(t, report.timestamp.IsZero())
+	require.Len(t, report.findings, 1)
+	assert.Equal(t, &schema.Package{Name: "lodash", Version: "4.17.20"}, report.findings[0].pkg)
+	assert.Empty(t, report.findings[0].fixState)
+}
+
+func TestParseGrype_Invalid(t *testing.T) {
+	_, err := parseGrype([]byte(`not json`))
+	assert.ErrorContains(t, err, "invalid Grype report")
+	_, err = parseGrype([]byte(`{"descriptor": {"name": "grype"}}`))
+	assert.ErrorContains(t, err, "missing matches")
+}
+
+func TestGrypeRating(t *testing.T) {
+	var ratings []grypeCVSS
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"version": "2.0", "vector": "AV:N/AC:L/Au:N/C:P/I:P/A:P", "metrics": {"baseScore": 7.5}},
+		{"version": "3.1", "vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "metrics": {"baseScore": 9.8}},
+		{"version": "4.0", "vector": "CVSS:4.0/AV:N"}
+	]`), &ratings))
+
+	assert.Equal(t, &schema.CVSS{Version: "3.1", Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", BaseScore: 9.8}, grypeRating(ratings))
+	assert.Nil(t, grypeRating(nil))
+}
+
+func TestGrypeFixState(t *testing.T) {
+	assert.Equal(t, "fixed", grypeFixState("fixed"))
+	assert.Equal(t, "not_fixed", grypeFixState("not-fixed"))
+	assert.Equal(t, "wont_fix", grypeFixState("wont-fix"))
+	assert.Equal(t, "unknown", grypeFixState("unknown"))
+	assert.Empty(t, grypeFixState(""))
+}
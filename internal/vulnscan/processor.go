@@ -0,0 +1,336 @@
+// Package vulnscan expands the vulnerability reports of image and SBOM scanners (Grype JSON, CycloneDX) into
+// security events, one per vulnerable package.
+package vulnscan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/vulnintel"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Reasons for findings that are not turned into security events
+const (
+	// FilterReasonVEX marks findings suppressed by their VEX analysis state
+	FilterReasonVEX = "vex"
+)
+
+// Reasons for log records that produce no security events
+const (
+	// SkipReasonNotScan marks log records whose body holds no vulnerability report
+	SkipReasonNotScan = "not_vulnerability_report"
+	// SkipReasonInvalidReport marks reports that cannot be decoded,
+	// ProcessLogRecord also returns a parse stage error for them
+	SkipReasonInvalidReport = "invalid_report"
+	// SkipReasonNoVulnerabilities marks reports without vulnerabilities, e.g. SBOMs
+	SkipReasonNoVulnerabilities = "no_vulnerabilities"
+)
+
+// Security event classification of the findings
+const (
+	eventCategory = "VULNERABILITY_MANAGEMENT"
+	eventName     = "Vulnerability finding event"
+	eventType     = "VULNERABILITY_FINDING"
+	actionType    = "vulnerability_scan"
+	findingType   = "vulnerability"
+
+	// resultAffected is the result status of findings without VEX analysis
+	resultAffected = "affected"
+)
+
+// suppressedVEXStates are the VEX analysis states of findings that are not exploitable
+var suppressedVEXStates = map[string]bool{
+	"not_affected":           true,
+	"false_positive":         true,
+	"resolved":               true,
+	"resolved_with_pedigree": true,
+}
+
+// findingNamespace is the namespace of the finding IDs derived from the target, vulnerability and package
+var findingNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/henrikrexed/securitylogeventprocessor/vulnscan"))
+
+// Processor handles transformation of vulnerability reports into security events
+type Processor struct {
+	logger    *zap.Logger
+	config    *Config
+	vulnIntel *vulnintel.Store
+
+	// output lays out the security events in their log records
+	output processing.Output
+}
+
+// Option configures optional dependencies of the Processor
+type Option func(*Processor)
+
+// WithVulnerabilityIntel enriches vulnerability findings with EPSS scores and CISA KEV membership
+func WithVulnerabilityIntel(store *vulnintel.Store) Option {
+	return func(p *Processor) {
+		p.vulnIntel = store
+	}
+}
+
+// WithOutput sets the layout of the security events in their log records: the profile, attribute names,
+// body format and SIEM encoding
+func WithOutput(output processing.Output) Option {
+	return func(p *Processor) {
+		p.output = output
+	}
+}
+
+// NewProcessor creates a new vulnerability scan processor
+func NewProcessor(logger *zap.Logger, config *Config, opts ...Option) (*Processor, error) {
+	p := &Processor{
+		logger: logger,
+		config: config,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	// The action and result of the security events are part of the source events
+	p.output.ActionFields = true
+	return p, nil
+}
+
+// Format performs a quick check to determine the format of the vulnerability report in the body of a
+// log record, as a JSON string or a map parsed from it: FormatGrype, FormatCycloneDX, or "" if none
+// A report is told by its top-level keys: a CycloneDX bomFormat, or Grype matches and descriptor
+func Format(logRecord *plog.LogRecord) string {
+	var values map[string]processing.JSONValue
+	body := logRecord.Body()
+	switch body.Type() {
+	case pcommon.ValueTypeStr:
+		values = processing.TopLevelValues(body.Str(), "bomFormat", "matches", "descriptor")
+	case pcommon.ValueTypeMap:
+		values = processing.MapValues(body.Map(), "bomFormat", "matches", "descriptor")
+	default:
+		return ""
+	}
+	switch {
+	case values["bomFormat"].Kind == processing.JSONString && values["bomFormat"].Str == cycloneDXFormat:
+		return FormatCycloneDX
+	case values["matches"].Kind == processing.JSONArray && values["descriptor"].Kind == processing.JSONObject:
+		return FormatGrype
+	default:
+		return ""
+	}
+}
+
+// ProcessLogRecord expands a log record whose body holds a vulnerability report into one security event
+// per vulnerable package
+// The security events are appended to dst; nothing is appended if the body holds no report
+// Findings whose VEX analysis states they are not exploitable are filtered out, unless the vex action is annotate
+// A *processing.StageError is returned if the report cannot be decoded or its security events cannot be written;
+// nothing is appended to dst in that case
+func (p *Processor) ProcessLogRecord(
+	_ context.Context, logRecord *plog.LogRecord, _ pcommon.Resource, _ plog.ScopeLogs, dst plog.LogRecordSlice,
+) (processing.Outcome, error) {
+	var outcome processing.Outcome
+	format := Format(logRecord)
+	if format == "" {
+		outcome.SkipReason = SkipReasonNotScan
+		return outcome, nil
+	}
+
+	report, err := parseBody(logRecord.Body(), format)
+	if err != nil {
+		outcome.SkipReason = SkipReasonInvalidReport
+		return outcome, processing.NewStageError(processing.StageParse, err)
+	}
+	if len(report.findings) == 0 {
+		outcome.SkipReason = SkipReasonNoVulnerabilities
+		return outcome, nil
+	}
+	if p.logger.Core().Enabled(zapcore.DebugLevel) {
+		p.logger.Debug("Vulnerability report identified - processing",
+			zap.String("format", format),
+			zap.String("target", report.target.Resource),
+			zap.Int("findings", len(report.findings)),
+			zap.String("trace_id", logRecord.TraceID().String()))
+	}
+
+	first := dst.Len()
+	for i := range report.findings {
+		f := &report.findings[i]
+		outcome.Results++
+		outcome.Parsed++
+		if suppressedVEXStates[f.vexState] && p.config.VEX != VEXAnnotate {
+			outcome.AddFiltered(FilterReasonVEX)
+			continue
+		}
+
+		// The record timestamp is the time of the scan
+		newRecord := processing.NewEventRecord(*logRecord, dst, report.timestamp)
+
+		if err := p.output.Write(newRecord, p.buildSecurityEvent(report, f, format)); err != nil {
+			// Remove the security events of the report, including the partially written one
+			processing.Rollback(dst, first)
+			outcome.Created = 0
+			return outcome, processing.NewStageError(processing.StageTransform, err)
+		}
+		outcome.Created++
+	}
+	return outcome, nil
+}
+
+// parseBody decodes the report of a string or map body
+func parseBody(body pcommon.Value, format string) (*scan, error) {
+	var data []byte
+	if body.Type() == pcommon.ValueTypeStr {
+		data = []byte(body.Str())
+	} else {
+		var err error
+		if data, err = json.Marshal(body.Map().AsRaw()); err != nil {
+			return nil, fmt.Errorf("invalid %s report: %w", format, err)
+		}
+	}
+	if format == FormatCycloneDX {
+		return parseCycloneDX(data)
+	}
+	return parseGrype(data)
+}
+
+// buildSecurityEvent builds the security event of a finding
+//
+// The scanner identifies the product and the scanned image the target; the CVE ID, when known, identifies
+// the vulnerability and the exploitation intelligence
+func (p *Processor) buildSecurityEvent(report *scan, f *finding, format string) *schema.SecurityEvent {
+	vulnerability := &schema.Vulnerability{
+		ID:            f.id,
+		Package:       f.pkg,
+		CVSS:          f.cvss,
+		FixState:      f.fixState,
+		FixedVersions: f.fixedVersions,
+		VEXState:      f.vexState,
+	}
+	if cveID := vulnintel.ExtractCVEID(append([]string{f.id}, f.aliases...)...); cveID != "" {
+		vulnerability.ID = cveID
+	}
+
+	title := vulnerability.ID
+	if f.pkg != nil {
+		title = strings.TrimSpace(vulnerability.ID + " in " + f.pkg.Name + " " + f.pkg.Version)
+	}
+	message := f.description
+	if message == "" {
+		message = title
+	}
+
+	event := &schema.SecurityEvent{
+		SchemaVersion: schema.Version,
+		Event: schema.Event{
+			ID:          uuid.New().String(),
+			Version:     processing.EventVersion,
+			Category:    eventCategory,
+			Name:        eventName,
+			Type:        eventType,
+			Description: title,
+		},
+		Message: message,
+		Source:  report.tool,
+		Target:  report.target,
+		Action: schema.Action{
+			Type: actionType,
+		},
+		Result: schema.Result{
+			Status: resultAffected,
+		},
+		Finding: schema.Finding{
+			ID:          findingID(&report.target, vulnerability.ID, f.pkg),
+			Title:       title,
+			Description: message,
+			Type:        findingType,
+			URL:         f.url,
+		},
+		Vulnerability: vulnerability,
+		Metadata:      map[string]interface{}{"format": format},
+	}
+	if report.target.Resource != "" {
+		event.Event.Description += " of " + report.target.Resource
+	}
+	if f.vexState != "" {
+		event.Result.Status = f.vexState
+	}
+	if !report.timestamp.IsZero() {
+		event.Timestamp = report.timestamp.Format(time.RFC3339Nano)
+	}
+
+	// Findings that are not exploitable are kept for the record, at informational severity and without risk
+	if suppressedVEXStates[f.vexState] {
+		event.Finding.Severity = processing.SeverityInformational
+	} else {
+		event.RiskScore, event.Finding.Severity = severity(f)
+		if intel, ok := p.vulnIntel.Lookup(vulnerability.ID); ok {
+			if intel.HasEPSS {
+				vulnerability.EPSS = &schema.EPSS{Score: intel.EPSS.Score, Percentile: intel.EPSS.Percentile}
+			}
+			vulnerability.KEV = intel.KEV
+			if p.vulnIntel.AdjustRiskScores() {
+				event.RiskScore = vulnintel.AdjustRiskScore(event.RiskScore, intel)
+			}
+		}
+	}
+
+	for key, value := range report.metadata {
+		event.Metadata[key] = value
+	}
+	for key, value := range f.metadata {
+		event.Metadata[key] = value
+	}
+	if len(f.aliases) > 0 {
+		aliases := make([]interface{}, len(f.aliases))
+		for i, alias := range f.aliases {
+			aliases[i] = alias
+		}
+		event.Metadata["aliases"] = aliases
+	}
+	return event
+}
+
+// findingID returns a stable finding ID derived from the target, the vulnerability and the package,
+// so the same finding keeps its ID across scans
+func findingID(target *schema.Target, vulnerabilityID string, pkg *schema.Package) string {
+	key := []string{processing.FirstNonEmpty(target.Resource, target.ID), vulnerabilityID}
+	if pkg != nil {
+		key = append(key, processing.FirstNonEmpty(pkg.PURL, pkg.Name+"@"+pkg.Version))
+	}
+	return uuid.NewSHA1(findingNamespace, []byte(strings.Join(key, "\x00"))).String()
+}
+
+// severity maps a finding to a risk score and a finding severity
+// The reported severity takes precedence; the risk score is the CVSS base score, or derived from the severity
+// with the risk scores of the OpenReports severities
+func severity(f *finding) (float64, string) {
+	var score float64
+	var name string
+	switch strings.ToLower(f.severity) {
+	case "critical":
+		name = processing.SeverityCritical
+	case "high":
+		name = processing.SeverityHigh
+	case "medium", "moderate":
+		name = processing.SeverityMedium
+	case "low", "negligible":
+		name = processing.SeverityLow
+	}
+	score = processing.RiskScore(name)
+	if f.cvss == nil {
+		return score, name
+	}
+
+	score = min(max(f.cvss.BaseScore, 0), 10)
+	if name == "" && score > 0 {
+		name = processing.SeverityFromScore(score)
+	}
+	return score, name
+}
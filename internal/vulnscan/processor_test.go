@@ -0,0 +1,317 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing/processingtest"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/vulnintel"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+func TestProcessLogRecord_Grype(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewLogRecord(t, "grype.json")
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{Results: 3, Parsed: 3, Created: 3}, outcome)
+	require.Equal(t, 3, events.Len())
+
+	event := events.At(0)
+	attrs := event.Attributes().AsRaw()
+	assert.Equal(t, "VULNERABILITY_FINDING", attrs["event.type"])
+	assert.Equal(t, "VULNERABILITY_MANAGEMENT", attrs["event.category"])
+	assert.Equal(t, "CVE-2022-3602 in openssl 3.0.2-0ubuntu1.6 of registry.example.com/shop/app:1.4.2", attrs["event.description"])
+	assert.Equal(t, "Grype", attrs["product.name"])
+	assert.Equal(t, "Anchore", attrs["product.vendor"])
+	assert.Equal(t, "0.74.0", attrs["product.version"])
+	assert.Equal(t, "sha256:9b4c1d2e3f40", attrs["object.id"])
+	assert.Equal(t, "container_image", attrs["object.type"])
+	assert.Equal(t, "vulnerability_scan", attrs["action.type"])
+	assert.Equal(t, "affected", attrs["result.status"])
+	assert.Equal(t, "CVE-2022-3602", attrs["vulnerability.id"])
+	assert.Equal(t, "openssl", attrs["software_component.name"])
+	assert.Equal(t, "3.0.2-0ubuntu1.6", attrs["software_component.version"])
+	assert.Equal(t, "pkg:deb/ubuntu/openssl@3.0.2-0ubuntu1.6?arch=amd64&distro=ubuntu-22.04", attrs["software_component.purl"])
+	assert.Equal(t, "deb", attrs["software_component.type"])
+	// The CVSS rating comes from the related NVD record, highest version first
+	assert.Equal(t, "3.1", attrs["vulnerability.cvss.version"])
+	assert.Equal(t, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", attrs["vulnerability.cvss.vector"])
+	assert.Equal(t, 7.5, attrs["vulnerability.cvss.base_score"])
+	assert.Equal(t, "fixed", attrs["vulnerability.fix.state"])
+	assert.Equal(t, []interface{}{"3.0.2-0ubuntu1.7"}, attrs["vulnerability.fix.versions"])
+	assert.NotContains(t, attrs, "vulnerability.vex.state")
+	assert.Equal(t, 7.5, attrs["dt.security.risk.score"])
+	assert.Equal(t, "HIGH", attrs["finding.severity"])
+	assert.Equal(t, "vulnerability", attrs["finding.type"])
+	assert.Equal(t, "https://ubuntu.com/security/CVE-2022-3602", attrs["finding.url"])
+	assert.Equal(t, "2025-10-18T12:00:00.123456789Z", attrs["finding.time.created"])
+
+	assert.Equal(t, "A buffer overrun can be triggered in X.509 certificate verification.", event.Body().Str())
+	assert.Equal(t, time.Date(2025, 10, 18, 12, 0, 0, 123456789, time.UTC), event.Timestamp().AsTime())
+	assert.Equal(t, logRecord.ObservedTimestamp(), event.ObservedTimestamp())
+
+	// GitHub advisories are identified by their CVE alias
+	ghsa := events.At(1).Attributes().AsRaw()
+	assert.Equal(t, "CVE-2023-44487", ghsa["vulnerability.id"])
+	assert.Equal(t, "CVE-2023-44487 in golang.org/x/net v0.15.0", ghsa["finding.title"])
+	assert.Equal(t, "MEDIUM", ghsa["finding.severity"])
+	assert.Equal(t, 5.3, ghsa["dt.security.risk.score"])
+
+	// Negligible vulnerabilities without CVSS rating are low
+	negligible := events.At(2).Attributes().AsRaw()
+	assert.Equal(t, "LOW", negligible["finding.severity"])
+	assert.Equal(t, 3.9, negligible["dt.security.risk.score"])
+	assert.Equal(t, "wont_fix", negligible["vulnerability.fix.state"])
+	assert.NotContains(t, negligible, "vulnerability.fix.versions")
+	assert.NotContains(t, negligible, "vulnerability.cvss.base_score")
+
+	// The finding ID is derived from the target, vulnerability and package, so it is stable across scans
+	again, _, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, attrs["finding.id"], again.At(0).Attributes().AsRaw()["finding.id"])
+	assert.NotEqual(t, attrs["finding.id"], ghsa["finding.id"])
+}
+
+func TestProcessLogRecord_CycloneDX(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithOutput(processing.Output{BodyFormat: schema.BodyFormatJSON}))
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewLogRecord(t, "cyclonedx.json")
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	// The not_affected finding is suppressed
+	assert.Equal(t, processing.Outcome{
+		Results:  2,
+		Parsed:   2,
+		Filtered: map[string]int{FilterReasonVEX: 1},
+		Created:  1,
+	}, outcome)
+	require.Equal(t, 1, events.Len())
+
+	var body schema.SecurityEvent
+	require.NoError(t, json.Unmarshal([]byte(events.At(0).Body().Str()), &body))
+	assert.Equal(t, schema.Source{Application: "grype", Vendor: "anchore", Version: "0.74.0"}, body.Source)
+	assert.Equal(t, schema.Target{
+		ID:           "image-app",
+		Resource:     "registry.example.com/shop/app:1.4.2",
+		ResourceType: "container_image",
+	}, body.Target)
+	assert.Equal(t, &schema.Vulnerability{
+		ID: "CVE-2022-3602",
+		Package: &schema.Package{
+			Name:    "openssl",
+			Version: "3.0.2-0ubuntu1.6",
+			PURL:    "pkg:deb/ubuntu/openssl@3.0.2-0ubuntu1.6?arch=amd64",
+			Type:    "deb",
+		},
+		CVSS:     &schema.CVSS{Version: "3.1", Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", BaseScore: 7.5},
+		FixState: "fixed",
+		VEXState: "exploitable",
+	}, body.Vulnerability)
+	assert.Equal(t, "exploitable", body.Result.Status)
+	assert.Equal(t, "HIGH", body.Finding.Severity)
+	assert.Equal(t, "https://www.openssl.org/news/secadv/20221101.txt", body.Finding.URL)
+	assert.Equal(t, FormatCycloneDX, body.Metadata["format"])
+	assert.Equal(t, []interface{}{"CWE-120"}, body.Metadata["cwes"])
+	assert.Equal(t, "Upgrade openssl to 3.0.2-0ubuntu1.7", body.Metadata["recommendation"])
+	assert.Equal(t, "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79", body.Metadata["serial_number"])
+	assert.Equal(t, "2025-10-18T12:00:00Z", body.Timestamp)
+}
+
+func TestProcessLogRecord_VEXAnnotate(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true, VEX: VEXAnnotate})
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewLogRecord(t, "cyclonedx.json")
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{Results: 2, Parsed: 2, Created: 2}, outcome)
+	require.Equal(t, 2, events.Len())
+
+	// The not_affected finding is kept with its VEX state, at informational severity
+	attrs := events.At(1).Attributes().AsRaw()
+	assert.Equal(t, "CVE-2023-45288", attrs["vulnerability.id"])
+	assert.Equal(t, "not_affected", attrs["vulnerability.vex.state"])
+	assert.Equal(t, "not_affected", attrs["result.status"])
+	assert.Equal(t, "github.com/sirupsen/logrus", attrs["software_component.name"])
+	assert.Equal(t, 0.0, attrs["dt.security.risk.score"])
+	assert.Equal(t, processing.SeverityInformational, attrs["finding.severity"])
+}
+
+func TestProcessLogRecord_VulnerabilityIntel(t *testing.T) {
+	store, err := vulnintel.NewStore(zaptest.NewLogger(t), &vulnintel.Config{
+		EPSSFile:        filepath.Join("..", "vulnintel", "testdata", "epss.csv"),
+		KEVFile:         filepath.Join("..", "vulnintel", "testdata", "kev.json"),
+		AdjustRiskScore: true,
+	})
+	require.NoError(t, err)
+
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true}, WithVulnerabilityIntel(store))
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewLogRecord(t, "grype.json")
+	events, _, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	require.Equal(t, 3, events.Len())
+
+	// The GitHub advisory is enriched through its CVE alias, which is a known exploited vulnerability
+	attrs := events.At(1).Attributes().AsRaw()
+	assert.Equal(t, 0.81234, attrs["vulnerability.epss.score"])
+	assert.Equal(t, true, attrs["vulnerability.kev"])
+	assert.Equal(t, 9.0, attrs["dt.security.risk.score"])
+
+	assert.NotContains(t, events.At(0).Attributes().AsRaw(), "vulnerability.kev")
+}
+
+func TestProcessLogRecord_MapBody(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	logRecord := processingtest.NewMapLogRecord(t, "grype.json", nil)
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, 3, outcome.Created)
+	assert.Equal(t, "CVE-2022-3602", events.At(0).Attributes().AsRaw()["vulnerability.id"])
+}
+
+func TestProcessLogRecord_VEXDocument(t *testing.T) {
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+
+	// A standalone VEX document refers to the components of another BOM by package URL, and reports
+	// the analysis of each vulnerability
+	logRecord := processingtest.NewStringLogRecord(`{"bomFormat": "CycloneDX", "specVersion": "1.5",
+		"vulnerabilities": [
+			{"id": "CVE-2024-24790", "ratings": [{"method": "CVSSv31", "score": 9.8}],
+				"analysis": {"state": "exploitable", "response": ["can_not_fix"]},
+				"affects": [{"ref": "pkg:golang/stdlib@1.22.3"}, {"ref": "pkg:golang/stdlib@1.21.10"}]},
+			{"id": "CVE-2023-44487", "ratings": [{"severity": "high"}],
+				"analysis": {"state": "resolved", "response": ["update"]},
+				"affects": [{"ref": "pkg:golang/golang.org/x/net@0.17.0"}]}
+		]}`)
+	events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+	require.NoError(t, err)
+	assert.Equal(t, processing.Outcome{
+		Results:  3,
+		Parsed:   3,
+		Filtered: map[string]int{FilterReasonVEX: 1},
+		Created:  2,
+	}, outcome)
+	require.Equal(t, 2, events.Len())
+
+	// One finding per affected package, with the fix state of the analysis response
+	for i, version := range []string{"1.22.3", "1.21.10"} {
+		attrs := events.At(i).Attributes().AsRaw()
+		assert.Equal(t, "CVE-2024-24790", attrs["vulnerability.id"])
+		assert.Equal(t, "stdlib", attrs["software_component.name"])
+		assert.Equal(t, version, attrs["software_component.version"])
+		assert.Equal(t, "CRITICAL", attrs["finding.severity"])
+		assert.Equal(t, 9.8, attrs["dt.security.risk.score"])
+	}
+	assert.NotEqual(t, events.At(0).Attributes().AsRaw()["finding.id"], events.At(1).Attributes().AsRaw()["finding.id"])
+}
+
+func TestProcessLogRecord_Skipped(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		stage      string
+		skipReason string
+	}{
+		{name: "plain line", body: "plain log line", skipReason: SkipReasonNotScan},
+		{name: "grype without descriptor", body: `{"matches": []}`, skipReason: SkipReasonNotScan},
+		{name: "SARIF", body: `{"version": "2.1.0", "runs": []}`, skipReason: SkipReasonNotScan},
+		{
+			name:       "mentions matches and descriptor",
+			body:       `{"msg":"grype finished","matches":12,"descriptor":"grype"}`,
+			skipReason: SkipReasonNotScan,
+		},
+		{
+			name:       "mentions CycloneDX",
+			body:       `{"msg": "converted to CycloneDX", "format": {"bomFormat": "CycloneDX"}}`,
+			skipReason: SkipReasonNotScan,
+		},
+		{
+			// A Syft SBOM lists components without vulnerabilities
+			name: "SBOM",
+			body: `{"bomFormat": "CycloneDX", "specVersion": "1.5",
+				"components": [{"type": "library", "name": "openssl", "version": "3.0.2"}]}`,
+			skipReason: SkipReasonNoVulnerabilities,
+		},
+		{
+			name:       "invalid matches",
+			body:       `{"matches": [{"vulnerability": "none"}], "descriptor": {"name": "grype"}}`,
+			stage:      processing.StageParse,
+			skipReason: SkipReasonInvalidReport,
+		},
+	}
+
+	processor, err := NewProcessor(zaptest.NewLogger(t), &Config{Enabled: true})
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logRecord := processingtest.NewStringLogRecord(tt.body)
+			events, outcome, err := processingtest.Process(t, processor.ProcessLogRecord, &logRecord)
+			if tt.stage != "" {
+				processingtest.RequireStageError(t, err, tt.stage)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.skipReason, outcome.SkipReason)
+			assert.Zero(t, events.Len())
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	grypeRecord := processingtest.NewLogRecord(t, "grype.json")
+	cdxRecord := processingtest.NewLogRecord(t, "cyclonedx.json")
+	plainRecord := plog.NewLogRecord()
+	plainRecord.Body().SetStr(`{"matches": []}`)
+	mapRecord := plog.NewLogRecord()
+	mapRecord.Body().SetEmptyMap().PutStr("bomFormat", "CycloneDX")
+	emptyRecord := plog.NewLogRecord()
+
+	assert.Equal(t, FormatGrype, Format(&grypeRecord))
+	assert.Equal(t, FormatCycloneDX, Format(&cdxRecord))
+	assert.Equal(t, FormatCycloneDX, Format(&mapRecord))
+	assert.Empty(t, Format(&plainRecord))
+	assert.Empty(t, Format(&emptyRecord))
+
+	plainRecord.Body().SetStr(`{"msg":"grype finished","matches":12,"descriptor":"grype"}`)
+	assert.Empty(t, Format(&plainRecord))
+	mapRecord.Body().Map().PutStr("bomFormat", "SPDX")
+	assert.Empty(t, Format(&mapRecord))
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		name         string
+		finding      finding
+		wantScore    float64
+		wantSeverity string
+	}{
+		{name: "severity", finding: finding{severity: "Critical"}, wantScore: 10, wantSeverity: "CRITICAL"},
+		{name: "moderate", finding: finding{severity: "moderate"}, wantScore: 6.9, wantSeverity: "MEDIUM"},
+		{name: "cvss score", finding: finding{severity: "High", cvss: &schema.CVSS{BaseScore: 7.5}}, wantScore: 7.5, wantSeverity: "HIGH"},
+		{name: "cvss only", finding: finding{cvss: &schema.CVSS{BaseScore: 9.8}}, wantScore: 9.8, wantSeverity: "CRITICAL"},
+		{name: "cvss zero", finding: finding{cvss: &schema.CVSS{}}, wantScore: 0, wantSeverity: ""},
+		{name: "unknown", finding: finding{severity: "Unknown"}, wantScore: 0, wantSeverity: ""},
+	}
+	for _, tt := range tests {
+		score, severity := severity(&tt.finding)
+		assert.Equal(t, tt.wantScore, score, tt.name)
+		assert.Equal(t, tt.wantSeverity, severity, tt.name)
+	}
+}
@@ -0,0 +1,103 @@
+package vulnscan
+
+import (
+	"strings"
+	"time"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// Report formats, also the report kinds of the telemetry
+const (
+	FormatGrype     = "grype"
+	FormatCycloneDX = "cyclonedx"
+)
+
+// Fix states of the affected packages
+const (
+	fixStateFixed    = "fixed"
+	fixStateNotFixed = "not_fixed"
+	fixStateWontFix  = "wont_fix"
+	fixStateUnknown  = "unknown"
+)
+
+// Target types
+const targetContainerImage = "container_image"
+
+// scan is a vulnerability report decoded from one of the report formats
+type scan struct {
+	// tool is the scanner that produced the report
+	tool schema.Source
+
+	// target is the scanned image, directory or application
+	target schema.Target
+
+	// timestamp is the time of the scan, zero if unknown
+	timestamp time.Time
+
+	// findings are the vulnerable packages of the report
+	findings []finding
+
+	// metadata is shared by the security events of the report
+	metadata map[string]interface{}
+}
+
+// finding is a vulnerability of a package, in a format-neutral form
+type finding struct {
+	// id is the identifier of the vulnerability as reported (e.g., a CVE or GHSA ID)
+	id string
+
+	// aliases are the other identifiers of the vulnerability
+	aliases []string
+
+	description string
+	severity    string
+	url         string
+
+	// cvss is the highest version CVSS rating of the vulnerability, nil if unrated
+	cvss *schema.CVSS
+
+	// pkg is the affected package, nil if the report does not name it
+	pkg *schema.Package
+
+	fixState      string
+	fixedVersions []string
+
+	// vexState is the VEX analysis state, "" without analysis
+	vexState string
+
+	// metadata holds the format specific fields of the finding
+	metadata map[string]interface{}
+}
+
+// parseTime parses an RFC 3339 timestamp, zero if absent or invalid
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
+
+// packageFromPURL returns the package identified by a package URL, or nil if s is not a package URL
+// e.g. pkg:deb/ubuntu/openssl@3.0.2?arch=amd64 is the deb package openssl, version 3.0.2
+func packageFromPURL(s string) *schema.Package {
+	rest, ok := strings.CutPrefix(s, "pkg:")
+	if !ok {
+		return nil
+	}
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+	pkgType, path, ok := strings.Cut(rest, "/")
+	if !ok {
+		return nil
+	}
+	pkg := &schema.Package{PURL: s, Type: pkgType}
+	path, pkg.Version, _ = strings.Cut(path, "@")
+	pkg.Name = path[strings.LastIndex(path, "/")+1:]
+	if pkg.Name == "" {
+		return nil
+	}
+	return pkg
+}
@@ -0,0 +1,29 @@
+package vulnscan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+func TestPackageFromPURL(t *testing.T) {
+	assert.Equal(t, &schema.Package{
+		Name:    "openssl",
+		Version: "3.0.2",
+		PURL:    "pkg:deb/ubuntu/openssl@3.0.2?arch=amd64",
+		Type:    "deb",
+	}, packageFromPURL("pkg:deb/ubuntu/openssl@3.0.2?arch=amd64"))
+	assert.Equal(t, &schema.Package{Name: "lodash", PURL: "pkg:npm/lodash", Type: "npm"}, packageFromPURL("pkg:npm/lodash"))
+	assert.Nil(t, packageFromPURL("urn:uuid:1234"))
+	assert.Nil(t, packageFromPURL("pkg:npm"))
+	assert.Nil(t, packageFromPURL("pkg:npm/"))
+}
+
+func TestParseTime(t *testing.T) {
+	assert.Equal(t, time.Date(2025, 10, 18, 10, 0, 0, 0, time.UTC), parseTime("2025-10-18T12:00:00+02:00"))
+	assert.True(t, parseTime("").IsZero())
+	assert.True(t, parseTime("yesterday").IsZero())
+}
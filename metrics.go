@@ -0,0 +1,206 @@
+package securityevent
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+)
+
+// processorMetrics holds the metrics for the processor
+type processorMetrics struct {
+	incomingLogs     metric.Int64Counter
+	outgoingLogs     metric.Int64Counter
+	droppedLogs      metric.Int64Counter
+	processingErrors metric.Int64Counter
+	resultsPerReport metric.Int64Histogram
+	batchDuration    metric.Float64Histogram
+}
+
+const (
+	metricPrefix = "processor_securityevent_"
+
+	metricIncomingLogs     = metricPrefix + "incoming_logs_total"
+	metricOutgoingLogs     = metricPrefix + "outgoing_logs_total"
+	metricDroppedLogs      = metricPrefix + "dropped_logs_total"
+	metricProcessingErrors = metricPrefix + "processing_errors_total"
+	metricResultsPerReport = metricPrefix + "results_per_report"
+	metricBatchDuration    = metricPrefix + "batch_processing_duration"
+)
+
+// Metric attribute keys
+const (
+	attrProcessor        = "processor"
+	attrReportKind       = "report_kind"
+	attrComplianceStatus = "compliance_status"
+	attrSeverity         = "severity"
+	attrReason           = "reason"
+	attrErrorType        = "error_type"
+)
+
+// Metric attribute values
+const (
+	processorNone        = "none"
+	processorOpenReports = "openreports"
+	processorCEF         = "cef"
+	processorSARIF       = "sarif"
+	processorVulnScan    = "vulnscan"
+	processorBenchmark   = "benchmark"
+	processorGatekeeper  = "gatekeeper"
+	processorRuntime     = "runtime"
+
+	reasonNotMatched      = "not_matched"
+	reasonNotExpanded     = "not_expanded"
+	reasonProcessingError = "processing_error"
+	reasonMalformedResult = "malformed_result"
+	reasonDeadLetter      = "dead_letter"
+)
+
+// createProcessorMetrics creates the metrics for the processor
+func createProcessorMetrics(meter metric.Meter) (*processorMetrics, error) {
+	incomingLogs, err := meter.Int64Counter(
+		metricIncomingLogs,
+		metric.WithDescription("Total number of incoming logs processed"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	outgoingLogs, err := meter.Int64Counter(
+		metricOutgoingLogs,
+		metric.WithDescription("Total number of outgoing logs produced"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	droppedLogs, err := meter.Int64Counter(
+		metricDroppedLogs,
+		metric.WithDescription("Total number of logs dropped during processing"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	processingErrors, err := meter.Int64Counter(
+		metricProcessingErrors,
+		metric.WithDescription("Total number of processing errors"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsPerReport, err := meter.Int64Histogram(
+		metricResultsPerReport,
+		metric.WithDescription("Number of security events produced per expanded report"),
+		metric.WithUnit("{result}"),
+		metric.WithExplicitBucketBoundaries(0, 1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	batchDuration, err := meter.Float64Histogram(
+		metricBatchDuration,
+		metric.WithDescription("Duration of the processing of a logs batch"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &processorMetrics{
+		incomingLogs:     incomingLogs,
+		outgoingLogs:     outgoingLogs,
+		droppedLogs:      droppedLogs,
+		processingErrors: processingErrors,
+		resultsPerReport: resultsPerReport,
+		batchDuration:    batchDuration,
+	}, nil
+}
+
+// telemetryKey is the attribute set of a counter measurement
+// Empty fields are not recorded as attributes
+type telemetryKey struct {
+	processor        string
+	reportKind       string
+	complianceStatus string
+	severity         string
+	reason           string
+}
+
+// attributes returns the measurement option carrying the non-empty attributes of the key
+// The reason is recorded under reasonAttr ("reason" or "error_type")
+func (k telemetryKey) attributes(reasonAttr string) metric.MeasurementOption {
+	attrs := make([]attribute.KeyValue, 0, 5)
+	attrs = append(attrs, attribute.String(attrProcessor, k.processor))
+	if k.reportKind != "" {
+		attrs = append(attrs, attribute.String(attrReportKind, k.reportKind))
+	}
+	if k.complianceStatus != "" {
+		attrs = append(attrs, attribute.String(attrComplianceStatus, k.complianceStatus))
+	}
+	if k.severity != "" {
+		attrs = append(attrs, attribute.String(attrSeverity, k.severity))
+	}
+	if k.reason != "" {
+		attrs = append(attrs, attribute.String(reasonAttr, k.reason))
+	}
+	return metric.WithAttributeSet(attribute.NewSet(attrs...))
+}
+
+// batchTelemetry accumulates the counter measurements of a batch so each attribute set is recorded once
+type batchTelemetry struct {
+	incoming map[telemetryKey]int64
+	outgoing map[telemetryKey]int64
+	dropped  map[telemetryKey]int64
+	errors   map[telemetryKey]int64
+}
+
+func newBatchTelemetry() *batchTelemetry {
+	return &batchTelemetry{
+		incoming: make(map[telemetryKey]int64),
+		outgoing: make(map[telemetryKey]int64),
+		dropped:  make(map[telemetryKey]int64),
+		errors:   make(map[telemetryKey]int64),
+	}
+}
+
+// record adds the accumulated measurements to the counters
+func (t *batchTelemetry) record(ctx context.Context, metrics *processorMetrics) {
+	for key, count := range t.incoming {
+		metrics.incomingLogs.Add(ctx, count, key.attributes(attrReason))
+	}
+	for key, count := range t.outgoing {
+		metrics.outgoingLogs.Add(ctx, count, key.attributes(attrReason))
+	}
+	for key, count := range t.dropped {
+		metrics.droppedLogs.Add(ctx, count, key.attributes(attrReason))
+	}
+	for key, count := range t.errors {
+		metrics.processingErrors.Add(ctx, count, key.attributes(attrErrorType))
+	}
+}
+
+// addOutcome accounts for the results of a report that did not become security events:
+// malformed results are dropped processing errors, filtered results are dropped with their filter reason
+func (t *batchTelemetry) addOutcome(reportKey telemetryKey, outcome *processing.Outcome) {
+	if outcome.Malformed > 0 {
+		key := reportKey
+		key.reason = reasonMalformedResult
+		t.dropped[key] += int64(outcome.Malformed)
+		t.errors[key] += int64(outcome.Malformed)
+	}
+	for reason, count := range outcome.Filtered {
+		key := reportKey
+		key.reason = reason
+		t.dropped[key] += int64(count)
+	}
+}
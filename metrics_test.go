@@ -0,0 +1,167 @@
+package securityevent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/ceflog"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/openreports"
+)
+
+// sumByAttributes returns the int64 sum data points of a metric keyed by their attribute set
+func sumByAttributes(t *testing.T, tel *componenttest.Telemetry, name string) map[attribute.Distinct]int64 {
+	m, err := tel.GetMetric(name)
+	require.NoError(t, err)
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	require.True(t, ok, "metric %s should be an int64 sum", name)
+
+	values := make(map[attribute.Distinct]int64)
+	for _, dp := range sum.DataPoints {
+		values[dp.Attributes.Equivalent()] = dp.Value
+	}
+	return values
+}
+
+func attrSet(kvs ...attribute.KeyValue) attribute.Distinct {
+	set := attribute.NewSet(kvs...)
+	return set.Equivalent()
+}
+
+func TestProcessLogs_DimensionalTelemetry(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true},
+		},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, tel.NewTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+
+	report := records.AppendEmpty()
+	report.Attributes().PutStr("kind", "Report")
+	report.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	report.Attributes().PutStr("scope.kind", "Pod")
+	results := report.Attributes().PutEmptySlice("results")
+	results.AppendEmpty().SetStr(`{"policy": "p1", "rule": "r1", "result": "fail", "severity": "high"}`)
+	results.AppendEmpty().SetStr(`{"policy": "p1", "rule": "r2", "result": "fail", "severity": "high"}`)
+	results.AppendEmpty().SetStr(`{"policy": "p2", "rule": "r1", "result": "pass", "severity": "low"}`)
+
+	emptyReport := records.AppendEmpty()
+	emptyReport.Attributes().PutStr("kind", "Report")
+	emptyReport.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	emptyReport.Attributes().PutStr("scope.kind", "Pod")
+
+	records.AppendEmpty().Body().SetStr("regular log")
+
+	_, err = processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	openReports := attribute.String(attrProcessor, processorOpenReports)
+	kind := attribute.String(attrReportKind, "Pod")
+	none := attribute.String(attrProcessor, processorNone)
+
+	assert.Equal(t, map[attribute.Distinct]int64{
+		attrSet(openReports, kind): 2,
+		attrSet(none):              1,
+	}, sumByAttributes(t, tel, metricIncomingLogs))
+
+	assert.Equal(t, map[attribute.Distinct]int64{
+		attrSet(openReports, kind, attribute.String(attrComplianceStatus, "NON_COMPLIANT"), attribute.String(attrSeverity, "HIGH")): 2,
+		attrSet(openReports, kind, attribute.String(attrComplianceStatus, "COMPLIANT"), attribute.String(attrSeverity, "LOW")):      1,
+		attrSet(openReports, kind, attribute.String(attrReason, reasonNotExpanded)):                                                 1,
+		attrSet(none, attribute.String(attrReason, reasonNotMatched)):                                                               1,
+	}, sumByAttributes(t, tel, metricOutgoingLogs))
+
+	fanOut, err := tel.GetMetric(metricResultsPerReport)
+	require.NoError(t, err)
+	histogram, ok := fanOut.Data.(metricdata.Histogram[int64])
+	require.True(t, ok)
+	require.Len(t, histogram.DataPoints, 1)
+	assert.Equal(t, uint64(2), histogram.DataPoints[0].Count)
+	assert.Equal(t, int64(3), histogram.DataPoints[0].Sum)
+
+	duration, err := tel.GetMetric(metricBatchDuration)
+	require.NoError(t, err)
+	durationHistogram, ok := duration.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, durationHistogram.DataPoints, 1)
+	assert.Equal(t, uint64(1), durationHistogram.DataPoints[0].Count)
+}
+
+func TestBatchTelemetry_Record(t *testing.T) {
+	key := telemetryKey{processor: processorOpenReports, reason: reasonProcessingError}
+
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	metrics, err := createProcessorMetrics(tel.NewTelemetrySettings().MeterProvider.Meter("test"))
+	require.NoError(t, err)
+
+	batch := newBatchTelemetry()
+	batch.dropped[key] += 2
+	batch.record(context.Background(), metrics)
+
+	assert.Equal(t, map[attribute.Distinct]int64{
+		attrSet(attribute.String(attrProcessor, processorOpenReports), attribute.String(attrReason, reasonProcessingError)): 2,
+	}, sumByAttributes(t, tel, metricDroppedLogs), "empty attributes are omitted")
+}
+
+func TestProcessLogs_DroppedAndErrorAccounting(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true, StatusFilter: []string{"fail"}},
+			CEF:         ceflog.Config{Enabled: true},
+		},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, tel.NewTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+
+	report := records.AppendEmpty()
+	report.Attributes().PutStr("kind", "Report")
+	report.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	report.Attributes().PutStr("scope.kind", "Pod")
+	results := report.Attributes().PutEmptySlice("results")
+	results.AppendEmpty().SetStr(`{"policy": "p1", "rule": "r1", "result": "fail", "severity": "high"}`)
+	results.AppendEmpty().SetStr(`{"policy": "p1", "rule": "r2", "result": "pass"}`)
+	results.AppendEmpty().SetStr(`{"policy": "p1", "rule": "r3", "result": "pass"}`)
+	results.AppendEmpty().SetStr(`not json`)
+
+	records.AppendEmpty().Body().SetStr(malformedCEF)
+
+	_, err = processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	openReports := attribute.String(attrProcessor, processorOpenReports)
+	kind := attribute.String(attrReportKind, "Pod")
+	cefLogs := attribute.String(attrProcessor, processorCEF)
+	cefKind := attribute.String(attrReportKind, "cef")
+
+	assert.Equal(t, map[attribute.Distinct]int64{
+		attrSet(openReports, kind, attribute.String(attrReason, openreports.FilterReasonStatus)): 2,
+		attrSet(openReports, kind, attribute.String(attrReason, reasonMalformedResult)):          1,
+		attrSet(cefLogs, cefKind, attribute.String(attrReason, "parse_error")):                   1,
+	}, sumByAttributes(t, tel, metricDroppedLogs))
+
+	assert.Equal(t, map[attribute.Distinct]int64{
+		attrSet(openReports, kind, attribute.String(attrErrorType, reasonMalformedResult)): 1,
+		attrSet(cefLogs, cefKind, attribute.String(attrErrorType, "parse_error")):          1,
+	}, sumByAttributes(t, tel, metricProcessingErrors))
+}
@@ -0,0 +1,51 @@
+package postureconnector
+
+import (
+	"fmt"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/profile"
+)
+
+// Default security event attributes used as metric attributes of the findings metrics
+var defaultDimensions = []string{
+	attrFindingSeverity,
+	attrComplianceStatus,
+	attrFindingType,
+	attrNamespace,
+	attrWorkloadName,
+}
+
+// Config defines the configuration for the security posture connector
+//
+// The compliance score and failing findings gauges are computed per batch and per scoped object (object.id, or
+// namespace and pod name), not per report: the security events carry no report identifier
+type Config struct {
+	// Dimensions lists the security event attributes copied onto the findings metrics
+	// Defaults to finding.severity, compliance.status, finding.type (policy),
+	// k8s.namespace.name and k8s.workload.name
+	Dimensions []string `mapstructure:"dimensions"`
+
+	// Profile is the output profile of the securityevent processor: dynatrace (default), splunk_cim,
+	// google_udm or asim
+	// Dimensions keep the dynatrace attribute names and are read from the attributes of the profile
+	Profile string `mapstructure:"profile"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Profile != "" && !profile.Valid(cfg.Profile) {
+		return fmt.Errorf("invalid profile: %s, must be one of dynatrace, splunk_cim, google_udm, asim", cfg.Profile)
+	}
+
+	seen := make(map[string]bool, len(cfg.Dimensions))
+	for _, dimension := range cfg.Dimensions {
+		if dimension == "" {
+			return fmt.Errorf("dimensions must not contain empty attribute names")
+		}
+		if seen[dimension] {
+			return fmt.Errorf("duplicate dimension: %s", dimension)
+		}
+		seen[dimension] = true
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package postureconnector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		errMsg string
+	}{
+		{name: "empty dimensions", config: Config{}},
+		{name: "custom dimensions", config: Config{Dimensions: []string{"finding.severity", "k8s.cluster.name"}}},
+		{name: "empty dimension", config: Config{Dimensions: []string{""}}, errMsg: "must not contain empty attribute names"},
+		{name: "profile", config: Config{Profile: "asim"}},
+		{name: "invalid profile", config: Config{Profile: "splunk"}, errMsg: "invalid profile: splunk"},
+		{name: "duplicate dimension", config: Config{Dimensions: []string{"finding.severity", "finding.severity"}}, errMsg: "duplicate dimension"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package postureconnector
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/profile"
+)
+
+const (
+	scopeName = "github.com/henrikrexed/securitylogeventprocessor/postureconnector"
+
+	metricFindings        = "security.findings"
+	metricComplianceScore = "security.compliance.score"
+	metricFailingFindings = "security.compliance.failing_findings"
+
+	attrEventType        = "event.type"
+	attrFindingSeverity  = "finding.severity"
+	attrFindingType      = "finding.type"
+	attrComplianceStatus = "compliance.status"
+	attrObjectID         = "object.id"
+	attrObjectType       = "object.type"
+	attrNamespace        = "k8s.namespace.name"
+	attrWorkloadName     = "k8s.workload.name"
+	attrPodName          = "k8s.pod.name"
+
+	complianceCompliant = "COMPLIANT"
+)
+
+// semconvFallbacks lists the semantic convention attributes carrying a legacy attribute value
+// when the processor emits semantic convention names only
+var semconvFallbacks = map[string][]string{
+	attrWorkloadName: {
+		"k8s.deployment.name", "k8s.statefulset.name", "k8s.daemonset.name",
+		"k8s.replicaset.name", "k8s.job.name", "k8s.cronjob.name",
+	},
+}
+
+// reportDimensions are the attributes identifying the scoped object of a report
+var reportDimensions = []string{attrObjectID, attrObjectType, attrNamespace, attrWorkloadName}
+
+// postureConnector turns security events into posture metrics
+type postureConnector struct {
+	component.ShutdownFunc
+
+	logger *zap.Logger
+	config *Config
+	next   consumer.Metrics
+
+	// Attributes of the configured profile read for the event type, compliance status and dimensions
+	eventTypeKey        string
+	complianceStatusKey string
+	dimensionKeys       []string
+	reportKeys          []string
+
+	// lastFlush is the start time of the next findings data points: the previous batch, or the connector start
+	mu        sync.Mutex
+	lastFlush pcommon.Timestamp
+}
+
+// findingCount accumulates the number of findings sharing the same dimension values
+type findingCount struct {
+	values []string
+	count  int64
+}
+
+// reportPosture accumulates the compliance of the findings of one scoped object in a batch
+// Security events carry no report identifier: the findings of several reports of the object in the batch are
+// aggregated, and a report split across batches yields one data point per batch
+type reportPosture struct {
+	values    []string
+	total     int64
+	compliant int64
+}
+
+func newPostureConnector(logger *zap.Logger, config *Config, next consumer.Metrics) *postureConnector {
+	return &postureConnector{
+		logger:              logger,
+		config:              config,
+		next:                next,
+		eventTypeKey:        profile.Attribute(config.Profile, attrEventType),
+		complianceStatusKey: profile.Attribute(config.Profile, attrComplianceStatus),
+		dimensionKeys:       profileAttributes(config.Profile, config.Dimensions),
+		reportKeys:          profileAttributes(config.Profile, reportDimensions),
+		lastFlush:           pcommon.NewTimestampFromTime(time.Now()),
+	}
+}
+
+// profileAttributes returns the attributes of a profile carrying the dimensions
+func profileAttributes(name string, dimensions []string) []string {
+	keys := make([]string, len(dimensions))
+	for i, dimension := range dimensions {
+		keys[i] = profile.Attribute(name, dimension)
+	}
+	return keys
+}
+
+// Start implements component.Component, starting the first interval of the findings counter
+func (c *postureConnector) Start(context.Context, component.Host) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastFlush = pcommon.NewTimestampFromTime(time.Now())
+	return nil
+}
+
+// interval returns the start time of the findings counted in a batch consumed at now, and starts the next one
+func (c *postureConnector) interval(now pcommon.Timestamp) pcommon.Timestamp {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	start := c.lastFlush
+	c.lastFlush = now
+	return start
+}
+
+// Capabilities implements consumer.Logs
+func (c *postureConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+// ConsumeLogs derives posture metrics from the security events of the batch
+func (c *postureConnector) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	metrics := pmetric.NewMetrics()
+	now := pcommon.NewTimestampFromTime(time.Now())
+	start := c.interval(now)
+
+	resourceLogs := ld.ResourceLogs()
+	for i := 0; i < resourceLogs.Len(); i++ {
+		resourceLog := resourceLogs.At(i)
+		resource := resourceLog.Resource()
+
+		var findings []*findingCount
+		findingIndex := make(map[string]*findingCount)
+		var reports []*reportPosture
+		reportIndex := make(map[string]*reportPosture)
+
+		scopeLogs := resourceLog.ScopeLogs()
+		for j := 0; j < scopeLogs.Len(); j++ {
+			logRecords := scopeLogs.At(j).LogRecords()
+			for k := 0; k < logRecords.Len(); k++ {
+				attrs := logRecords.At(k).Attributes()
+				if lookup(attrs, resource, c.eventTypeKey) == "" {
+					// Not a security event
+					continue
+				}
+
+				values := lookupAll(attrs, resource, c.dimensionKeys)
+				key := strings.Join(values, "\x00")
+				finding, ok := findingIndex[key]
+				if !ok {
+					finding = &findingCount{values: values}
+					findingIndex[key] = finding
+					findings = append(findings, finding)
+				}
+				finding.count++
+
+				reportValues := lookupAll(attrs, resource, c.reportKeys)
+				reportKey := reportValues[0]
+				if reportKey == "" {
+					// Fall back to namespace and pod name when the scoped object has no UID
+					reportKey = lookup(attrs, resource, attrNamespace) + "/" + lookup(attrs, resource, attrPodName)
+				}
+				report, ok := reportIndex[reportKey]
+				if !ok {
+					report = &reportPosture{values: reportValues}
+					reportIndex[reportKey] = report
+					reports = append(reports, report)
+				}
+				report.total++
+				if lookup(attrs, resource, c.complianceStatusKey) == complianceCompliant {
+					report.compliant++
+				}
+			}
+		}
+
+		if len(findings) == 0 {
+			continue
+		}
+
+		resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+		resource.CopyTo(resourceMetrics.Resource())
+		scopeMetrics := resourceMetrics.ScopeMetrics().AppendEmpty()
+		scopeMetrics.Scope().SetName(scopeName)
+
+		c.appendFindings(scopeMetrics.Metrics(), findings, start, now)
+		appendReports(scopeMetrics.Metrics(), reports, now)
+	}
+
+	if metrics.ResourceMetrics().Len() == 0 {
+		return nil
+	}
+
+	c.logger.Debug("Derived security posture metrics",
+		zap.Int("data_points", metrics.DataPointCount()))
+
+	return c.next.ConsumeMetrics(ctx, metrics)
+}
+
+// appendFindings adds the findings counter of the interval from start to now
+func (c *postureConnector) appendFindings(metrics pmetric.MetricSlice, findings []*findingCount, start, now pcommon.Timestamp) {
+	metric := metrics.AppendEmpty()
+	metric.SetName(metricFindings)
+	metric.SetDescription("Number of security findings")
+	metric.SetUnit("{finding}")
+	sum := metric.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+
+	dataPoints := sum.DataPoints()
+	dataPoints.EnsureCapacity(len(findings))
+	for _, finding := range findings {
+		dataPoint := dataPoints.AppendEmpty()
+		dataPoint.SetStartTimestamp(start)
+		dataPoint.SetTimestamp(now)
+		dataPoint.SetIntValue(finding.count)
+		putDimensions(dataPoint.Attributes(), c.config.Dimensions, finding.values)
+	}
+}
+
+// appendReports adds the compliance score and failing findings gauges of each scoped object, over all its
+// security events in the batch
+// The values are not carried over from previous batches, see reportPosture
+func appendReports(metrics pmetric.MetricSlice, reports []*reportPosture, now pcommon.Timestamp) {
+	score := metrics.AppendEmpty()
+	score.SetName(metricComplianceScore)
+	score.SetDescription("Percentage of compliant findings of a scoped object in the batch")
+	score.SetUnit("%")
+	scoreDataPoints := score.SetEmptyGauge().DataPoints()
+	scoreDataPoints.EnsureCapacity(len(reports))
+
+	failing := metrics.AppendEmpty()
+	failing.SetName(metricFailingFindings)
+	failing.SetDescription("Number of non-compliant findings of a scoped object in the batch")
+	failing.SetUnit("{finding}")
+	failingDataPoints := failing.SetEmptyGauge().DataPoints()
+	failingDataPoints.EnsureCapacity(len(reports))
+
+	for _, report := range reports {
+		scoreDataPoint := scoreDataPoints.AppendEmpty()
+		scoreDataPoint.SetTimestamp(now)
+		scoreDataPoint.SetDoubleValue(100 * float64(report.compliant) / float64(report.total))
+		putDimensions(scoreDataPoint.Attributes(), reportDimensions, report.values)
+
+		failingDataPoint := failingDataPoints.AppendEmpty()
+		failingDataPoint.SetTimestamp(now)
+		failingDataPoint.SetIntValue(report.total - report.compliant)
+		putDimensions(failingDataPoint.Attributes(), reportDimensions, report.values)
+	}
+}
+
+// putDimensions sets the non-empty dimension values as attributes
+func putDimensions(target pcommon.Map, keys, values []string) {
+	for i, key := range keys {
+		if values[i] != "" {
+			target.PutStr(key, values[i])
+		}
+	}
+}
+
+// lookupAll returns the values of the keys, see lookup
+func lookupAll(attrs pcommon.Map, resource pcommon.Resource, keys []string) []string {
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = lookup(attrs, resource, key)
+	}
+	return values
+}
+
+// lookup returns the value of a log record attribute, falling back to the resource attributes
+// and then to the semantic convention equivalents of the key
+func lookup(attrs pcommon.Map, resource pcommon.Resource, key string) string {
+	if value, ok := lookupKey(attrs, resource, key); ok {
+		return value
+	}
+	for _, fallback := range semconvFallbacks[key] {
+		if value, ok := lookupKey(attrs, resource, fallback); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// lookupKey returns the value of a log record or resource attribute
+func lookupKey(attrs pcommon.Map, resource pcommon.Resource, key string) (string, bool) {
+	if value, ok := attrs.Get(key); ok {
+		return value.AsString(), true
+	}
+	if value, ok := resource.Attributes().Get(key); ok {
+		return value.AsString(), true
+	}
+	return "", false
+}
@@ -0,0 +1,266 @@
+package postureconnector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/profile"
+	"github.com/henrikrexed/securitylogeventprocessor/schema"
+)
+
+// appendEvent adds a security event log record with the given attributes
+func appendEvent(records plog.LogRecordSlice, attrs map[string]string) {
+	record := records.AppendEmpty()
+	record.Attributes().PutStr("event.type", "COMPLIANCE_FINDING")
+	for key, value := range attrs {
+		record.Attributes().PutStr(key, value)
+	}
+}
+
+// findMetric returns the metric with the given name
+func findMetric(t *testing.T, metrics pmetric.MetricSlice, name string) pmetric.Metric {
+	for i := 0; i < metrics.Len(); i++ {
+		if metrics.At(i).Name() == name {
+			return metrics.At(i)
+		}
+	}
+	require.Failf(t, "metric not found", "metric %s", name)
+	return pmetric.Metric{}
+}
+
+func TestConsumeLogs_PostureMetrics(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	connector := newPostureConnector(zaptest.NewLogger(t), &Config{Dimensions: defaultDimensions}, sink)
+
+	logs := plog.NewLogs()
+	resourceLogs := logs.ResourceLogs().AppendEmpty()
+	resourceLogs.Resource().Attributes().PutStr("k8s.cluster.name", "prod")
+	records := resourceLogs.ScopeLogs().AppendEmpty().LogRecords()
+
+	podA := map[string]string{
+		"object.id":          "uid-a",
+		"object.type":        "Pod",
+		"k8s.namespace.name": "shop",
+		"k8s.workload.name":  "cart",
+	}
+	withFinding := func(base map[string]string, policy, status, severity string) map[string]string {
+		attrs := map[string]string{"finding.type": policy, "compliance.status": status, "finding.severity": severity}
+		for key, value := range base {
+			attrs[key] = value
+		}
+		return attrs
+	}
+	appendEvent(records, withFinding(podA, "disallow-host-path", "NON_COMPLIANT", "HIGH"))
+	appendEvent(records, withFinding(podA, "disallow-host-path", "NON_COMPLIANT", "HIGH"))
+	appendEvent(records, withFinding(podA, "require-labels", "COMPLIANT", "LOW"))
+	appendEvent(records, withFinding(podA, "require-limits", "COMPLIANT", "LOW"))
+
+	podB := map[string]string{
+		"object.id":          "uid-b",
+		"object.type":        "Pod",
+		"k8s.namespace.name": "payments",
+	}
+	appendEvent(records, withFinding(podB, "require-labels", "COMPLIANT", "LOW"))
+
+	// Regular logs are ignored
+	records.AppendEmpty().Body().SetStr("plain application log")
+
+	require.NoError(t, connector.ConsumeLogs(context.Background(), logs))
+	require.Len(t, sink.AllMetrics(), 1)
+
+	metrics := sink.AllMetrics()[0]
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+	resourceMetrics := metrics.ResourceMetrics().At(0)
+	assert.Equal(t, map[string]any{"k8s.cluster.name": "prod"}, resourceMetrics.Resource().Attributes().AsRaw())
+	assert.Equal(t, scopeName, resourceMetrics.ScopeMetrics().At(0).Scope().Name())
+	metricSlice := resourceMetrics.ScopeMetrics().At(0).Metrics()
+
+	findings := findMetric(t, metricSlice, metricFindings)
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, findings.Sum().AggregationTemporality())
+	assert.NotZero(t, findings.Sum().DataPoints().At(0).StartTimestamp())
+	require.Equal(t, 4, findings.Sum().DataPoints().Len())
+	first := findings.Sum().DataPoints().At(0)
+	assert.Equal(t, int64(2), first.IntValue())
+	assert.Equal(t, map[string]any{
+		"finding.severity":   "HIGH",
+		"compliance.status":  "NON_COMPLIANT",
+		"finding.type":       "disallow-host-path",
+		"k8s.namespace.name": "shop",
+		"k8s.workload.name":  "cart",
+	}, first.Attributes().AsRaw())
+	last := findings.Sum().DataPoints().At(3)
+	assert.Equal(t, int64(1), last.IntValue())
+	assert.NotContains(t, last.Attributes().AsRaw(), "k8s.workload.name", "empty dimensions are omitted")
+
+	score := findMetric(t, metricSlice, metricComplianceScore)
+	require.Equal(t, 2, score.Gauge().DataPoints().Len())
+	assert.InDelta(t, 50.0, score.Gauge().DataPoints().At(0).DoubleValue(), 1e-9)
+	assert.Equal(t, "uid-a", score.Gauge().DataPoints().At(0).Attributes().AsRaw()["object.id"])
+	assert.InDelta(t, 100.0, score.Gauge().DataPoints().At(1).DoubleValue(), 1e-9)
+
+	failing := findMetric(t, metricSlice, metricFailingFindings)
+	require.Equal(t, 2, failing.Gauge().DataPoints().Len())
+	assert.Equal(t, int64(2), failing.Gauge().DataPoints().At(0).IntValue())
+	assert.Equal(t, int64(0), failing.Gauge().DataPoints().At(1).IntValue())
+}
+
+func TestConsumeLogs_StartTimestamp(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	connector := newPostureConnector(zaptest.NewLogger(t), &Config{Dimensions: defaultDimensions}, sink)
+	require.NoError(t, connector.Start(context.Background(), componenttest.NewNopHost()))
+
+	for i := 0; i < 2; i++ {
+		logs := plog.NewLogs()
+		appendEvent(logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords(), nil)
+		require.NoError(t, connector.ConsumeLogs(context.Background(), logs))
+	}
+	require.Len(t, sink.AllMetrics(), 2)
+
+	dataPoint := func(i int) pmetric.NumberDataPoint {
+		metrics := sink.AllMetrics()[i].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+		return findMetric(t, metrics, metricFindings).Sum().DataPoints().At(0)
+	}
+	first, second := dataPoint(0), dataPoint(1)
+	assert.LessOrEqual(t, first.StartTimestamp(), first.Timestamp(), "the first interval starts with the connector")
+	assert.Equal(t, first.Timestamp(), second.StartTimestamp(), "the next interval starts at the previous batch")
+}
+
+func TestConsumeLogs_ResourceAttributes(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	connector := newPostureConnector(zaptest.NewLogger(t), &Config{Dimensions: []string{"k8s.namespace.name"}}, sink)
+
+	logs := plog.NewLogs()
+	resourceLogs := logs.ResourceLogs().AppendEmpty()
+	resourceLogs.Resource().Attributes().PutStr("k8s.namespace.name", "shop")
+	appendEvent(resourceLogs.ScopeLogs().AppendEmpty().LogRecords(), map[string]string{"compliance.status": "NON_COMPLIANT"})
+
+	require.NoError(t, connector.ConsumeLogs(context.Background(), logs))
+	require.Len(t, sink.AllMetrics(), 1)
+
+	metricSlice := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	findings := findMetric(t, metricSlice, metricFindings)
+	assert.Equal(t, map[string]any{"k8s.namespace.name": "shop"}, findings.Sum().DataPoints().At(0).Attributes().AsRaw(),
+		"dimensions fall back to resource attributes")
+}
+
+func TestConsumeLogs_SemconvWorkloadName(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	connector := newPostureConnector(zaptest.NewLogger(t), &Config{Dimensions: []string{"k8s.workload.name"}}, sink)
+
+	logs := plog.NewLogs()
+	resourceLogs := logs.ResourceLogs().AppendEmpty()
+	resourceLogs.Resource().Attributes().PutStr("k8s.statefulset.name", "db")
+	appendEvent(resourceLogs.ScopeLogs().AppendEmpty().LogRecords(), map[string]string{"compliance.status": "NON_COMPLIANT"})
+
+	require.NoError(t, connector.ConsumeLogs(context.Background(), logs))
+	require.Len(t, sink.AllMetrics(), 1)
+
+	metricSlice := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	findings := findMetric(t, metricSlice, metricFindings)
+	assert.Equal(t, map[string]any{"k8s.workload.name": "db"}, findings.Sum().DataPoints().At(0).Attributes().AsRaw(),
+		"k8s.workload.name falls back to the semantic convention workload names")
+}
+
+func TestConsumeLogs_NoSecurityEvents(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	connector := newPostureConnector(zaptest.NewLogger(t), &Config{Dimensions: defaultDimensions}, sink)
+
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("hello")
+
+	require.NoError(t, connector.ConsumeLogs(context.Background(), logs))
+	assert.Empty(t, sink.AllMetrics(), "no metrics are emitted without security events")
+}
+
+func TestConsumeLogs_Profile(t *testing.T) {
+	event := &schema.SecurityEvent{
+		Event:      schema.Event{Type: "COMPLIANCE_FINDING"},
+		Finding:    schema.Finding{Type: "disallow-host-path", Severity: "HIGH"},
+		Compliance: schema.Compliance{Status: "NON_COMPLIANT"},
+		Target: schema.Target{
+			ID:           "uid-a",
+			ResourceType: "Pod",
+			Resource:     "cart-1",
+			Kubernetes:   map[string]interface{}{"k8s.namespace.name": "shop"},
+		},
+	}
+
+	for _, name := range []string{profile.Dynatrace, profile.SplunkCIM, profile.GoogleUDM, profile.ASIM} {
+		t.Run(name, func(t *testing.T) {
+			sink := new(consumertest.MetricsSink)
+			connector := newPostureConnector(zaptest.NewLogger(t),
+				&Config{Dimensions: []string{"compliance.status", "finding.type", "k8s.namespace.name"}, Profile: name}, sink)
+
+			logs := plog.NewLogs()
+			record := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+			profile.PutAttributes(record.Attributes(), event, name)
+
+			require.NoError(t, connector.ConsumeLogs(context.Background(), logs))
+			require.Len(t, sink.AllMetrics(), 1, "security events of the profile are counted")
+
+			metricSlice := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+			findings := findMetric(t, metricSlice, metricFindings).Sum().DataPoints().At(0)
+			assert.Equal(t, map[string]any{
+				"compliance.status":  "NON_COMPLIANT",
+				"finding.type":       "disallow-host-path",
+				"k8s.namespace.name": "shop",
+			}, findings.Attributes().AsRaw(), "dimensions keep the dynatrace names")
+
+			failing := findMetric(t, metricSlice, metricFailingFindings).Gauge().DataPoints().At(0)
+			assert.Equal(t, int64(1), failing.IntValue())
+			objectType, ok := failing.Attributes().Get("object.type")
+			require.True(t, ok)
+			assert.Equal(t, "Pod", objectType.Str())
+		})
+	}
+}
+
+func TestConsumeLogs_ComplianceScorePerBatch(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	connector := newPostureConnector(zaptest.NewLogger(t), &Config{Dimensions: defaultDimensions}, sink)
+
+	pod := func(status string) map[string]string {
+		return map[string]string{"object.id": "uid-a", "object.type": "Pod", "compliance.status": status}
+	}
+
+	// The first batch holds two reports of the object, the second one the rest of the second report
+	first := plog.NewLogs()
+	records := first.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	appendEvent(records, pod("COMPLIANT"))
+	appendEvent(records, pod("COMPLIANT"))
+	appendEvent(records, pod("COMPLIANT"))
+	appendEvent(records, pod("NON_COMPLIANT"))
+	require.NoError(t, connector.ConsumeLogs(context.Background(), first))
+
+	second := plog.NewLogs()
+	appendEvent(second.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords(), pod("NON_COMPLIANT"))
+	require.NoError(t, connector.ConsumeLogs(context.Background(), second))
+	require.Len(t, sink.AllMetrics(), 2)
+
+	gauges := func(i int) (pmetric.NumberDataPointSlice, pmetric.NumberDataPointSlice) {
+		metrics := sink.AllMetrics()[i].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+		return findMetric(t, metrics, metricComplianceScore).Gauge().DataPoints(),
+			findMetric(t, metrics, metricFailingFindings).Gauge().DataPoints()
+	}
+
+	// The reports of the first batch are aggregated into one data point of the object
+	score, failing := gauges(0)
+	require.Equal(t, 1, score.Len())
+	assert.InDelta(t, 75.0, score.At(0).DoubleValue(), 1e-9)
+	assert.Equal(t, int64(1), failing.At(0).IntValue())
+
+	// The second batch only covers its own findings
+	score, failing = gauges(1)
+	require.Equal(t, 1, score.Len())
+	assert.InDelta(t, 0.0, score.At(0).DoubleValue(), 1e-9)
+	assert.Equal(t, int64(1), failing.At(0).IntValue())
+	assert.Equal(t, "uid-a", score.At(0).Attributes().AsRaw()["object.id"])
+}
@@ -0,0 +1,41 @@
+// Package postureconnector provides a logs-to-metrics connector that derives
+// compliance posture metrics from the security events produced by the
+// securityevent processor.
+package postureconnector
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+)
+
+var (
+	typeStr   = component.MustNewType("securityposture")
+	stability = component.StabilityLevelDevelopment
+)
+
+// NewFactory creates a new security posture connector factory
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		typeStr,
+		createDefaultConfig,
+		connector.WithLogsToMetrics(createLogsToMetrics, stability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		Dimensions: append([]string(nil), defaultDimensions...),
+	}
+}
+
+func createLogsToMetrics(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (connector.Logs, error) {
+	return newPostureConnector(set.Logger, cfg.(*Config), nextConsumer), nil
+}
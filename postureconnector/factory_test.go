@@ -0,0 +1,40 @@
+package postureconnector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/connector/connectortest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+)
+
+func TestNewFactory(t *testing.T) {
+	factory := NewFactory()
+	require.NotNil(t, factory)
+
+	assert.Equal(t, component.MustNewType("securityposture"), factory.Type())
+	assert.Equal(t, component.StabilityLevelDevelopment, factory.LogsToMetricsStability())
+}
+
+func TestCreateDefaultConfig(t *testing.T) {
+	cfg, ok := NewFactory().CreateDefaultConfig().(*Config)
+	require.True(t, ok, "Config should be of type *Config")
+
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, defaultDimensions, cfg.Dimensions)
+}
+
+func TestCreateLogsToMetrics(t *testing.T) {
+	factory := NewFactory()
+	connector, err := factory.CreateLogsToMetrics(context.Background(),
+		connectortest.NewNopSettings(factory.Type()), factory.CreateDefaultConfig(), new(consumertest.MetricsSink))
+	require.NoError(t, err)
+	require.NotNil(t, connector)
+
+	require.NoError(t, connector.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, connector.Shutdown(context.Background()))
+}
@@ -2,14 +2,29 @@ package securityevent
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
+	"github.com/henrikrexed/securitylogeventprocessor/internal/attack"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/benchmark"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/ceflog"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/gatekeeper"
 	"github.com/henrikrexed/securitylogeventprocessor/internal/openreports"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/profile"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/runtimelog"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/sariflog"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/validation"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/vulnintel"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/vulnscan"
 )
 
 // securityEventProcessor processes logs and transforms them into security events
@@ -17,31 +32,29 @@ type securityEventProcessor struct {
 	logger      *zap.Logger
 	config      *Config
 	openReports *openreports.Processor
+	cefLogs     *ceflog.Processor
+	sarifLogs   *sariflog.Processor
+	vulnScans   *vulnscan.Processor
+	benchmarks  *benchmark.Processor
+	gatekeeper  *gatekeeper.Processor
+	runtimeLogs *runtimelog.Processor
+	vulnIntel   *vulnintel.Store
 	metrics     *processorMetrics
-}
-
-// processorMetrics holds the metrics for the processor
-type processorMetrics struct {
-	incomingLogs     metric.Int64Counter
-	outgoingLogs     metric.Int64Counter
-	droppedLogs      metric.Int64Counter
-	processingErrors metric.Int64Counter
-}
+	summary     *reportSummary
 
-const (
-	metricPrefix = "processor_securityevent_"
+	// subProcessors are the enabled sub-processors, in matching order
+	subProcessors []subProcessor
 
-	metricIncomingLogs     = metricPrefix + "incoming_logs_total"
-	metricOutgoingLogs     = metricPrefix + "outgoing_logs_total"
-	metricDroppedLogs      = metricPrefix + "dropped_logs_total"
-	metricProcessingErrors = metricPrefix + "processing_errors_total"
-)
+	// validator checks the security events against the output schema, nil if validation is disabled
+	validator *validation.Validator
+}
 
 // newSecurityEventProcessor creates a new security event processor
 func newSecurityEventProcessor(logger *zap.Logger, config *Config, settings component.TelemetrySettings) (*securityEventProcessor, error) {
 	processor := &securityEventProcessor{
-		logger: logger,
-		config: config,
+		logger:  logger,
+		config:  config,
+		summary: newReportSummary(time.Now()),
 	}
 
 	// Initialize metrics
@@ -52,79 +65,256 @@ func newSecurityEventProcessor(logger *zap.Logger, config *Config, settings comp
 	}
 	processor.metrics = metrics
 
+	// Load the compliance framework catalog if configured
+	var catalog *compliance.Catalog
+	if config.Enrichment.Compliance.CatalogFile != "" {
+		catalog, err = compliance.LoadCatalog(config.Enrichment.Compliance.CatalogFile)
+		if err != nil {
+			return nil, err
+		}
+		processor.logger.Info("Compliance catalog loaded",
+			zap.String("catalog_file", config.Enrichment.Compliance.CatalogFile),
+			zap.Int("mappings", catalog.Len()))
+	}
+
+	// Build the MITRE ATT&CK technique mapping if enabled
+	var techniques *attack.Mapping
+	if config.Enrichment.Attack.Enabled {
+		techniques, err = attack.NewMapping(&config.Enrichment.Attack)
+		if err != nil {
+			return nil, err
+		}
+		processor.logger.Info("MITRE ATT&CK technique mapping loaded",
+			zap.String("mapping_file", config.Enrichment.Attack.MappingFile),
+			zap.Int("mappings", techniques.Len()))
+	}
+
+	// Load the EPSS and KEV vulnerability intelligence if configured
+	if config.Enrichment.Vulnerability.Enabled() {
+		processor.vulnIntel, err = vulnintel.NewStore(logger, &config.Enrichment.Vulnerability)
+		if err != nil {
+			return nil, err
+		}
+		processor.logger.Info("Vulnerability intelligence loaded",
+			zap.String("epss_file", config.Enrichment.Vulnerability.EPSSFile),
+			zap.String("kev_file", config.Enrichment.Vulnerability.KEVFile),
+			zap.Duration("refresh_interval", config.Enrichment.Vulnerability.RefreshInterval))
+	}
+
+	// Load the output schema if validation is enabled
+	if config.Output.Validation.Enabled {
+		processor.validator, err = validation.NewValidator(&config.Output.Validation, config.Output.profile())
+		if err != nil {
+			return nil, err
+		}
+		processor.logger.Info("Security event validation enabled",
+			zap.String("profile", config.Output.profile()),
+			zap.String("schema_file", config.Output.Validation.SchemaFile),
+			zap.String("action", config.Output.Validation.Action))
+	}
+
+	// Layout of the security events of all sub-processors
+	output := config.Output.output()
+
 	// Initialize OpenReports processor if enabled
 	if config.Processors.OpenReports.Enabled {
 		var err error
-		processor.openReports, err = openreports.NewProcessor(logger, &config.Processors.OpenReports)
+		processor.openReports, err = openreports.NewProcessor(logger, &config.Processors.OpenReports,
+			openreports.WithComplianceCatalog(catalog),
+			openreports.WithTechniqueMapping(techniques),
+			openreports.WithVulnerabilityIntel(processor.vulnIntel),
+			openreports.WithK8sFieldsOnResource(config.Output.GroupByResource),
+			openreports.WithOutput(output))
 		if err != nil {
 			return nil, err
 		}
+		processor.subProcessors = append(processor.subProcessors, subProcessor{
+			name:            processorOpenReports,
+			matches:         isOpenReportsLog,
+			kind:            openreports.ScopeKind,
+			groupByResource: true,
+			process:         processor.openReports.ProcessLogRecord,
+		})
 		processor.logger.Info("OpenReports processor enabled")
 	}
 
-	return processor, nil
-}
+	// Initialize CEF and LEEF processor if enabled
+	if config.Processors.CEF.Enabled {
+		processor.cefLogs, err = ceflog.NewProcessor(logger, &config.Processors.CEF,
+			ceflog.WithOutput(output))
+		if err != nil {
+			return nil, err
+		}
+		processor.subProcessors = append(processor.subProcessors, subProcessor{
+			name:    processorCEF,
+			matches: isCEFLog,
+			kind:    ceflog.Format,
+			process: processor.cefLogs.ProcessLogRecord,
+		})
+		processor.logger.Info("CEF processor enabled")
+	}
 
-// createProcessorMetrics creates the metrics for the processor
-func createProcessorMetrics(meter metric.Meter) (*processorMetrics, error) {
-	incomingLogs, err := meter.Int64Counter(
-		metricIncomingLogs,
-		metric.WithDescription("Total number of incoming logs processed"),
-		metric.WithUnit("1"),
-	)
-	if err != nil {
-		return nil, err
+	// Initialize SARIF processor if enabled
+	if config.Processors.SARIF.Enabled {
+		processor.sarifLogs, err = sariflog.NewProcessor(logger, &config.Processors.SARIF,
+			sariflog.WithOutput(output))
+		if err != nil {
+			return nil, err
+		}
+		processor.subProcessors = append(processor.subProcessors, subProcessor{
+			name:    processorSARIF,
+			matches: sariflog.IsSARIF,
+			kind:    sarifKind,
+			process: processor.sarifLogs.ProcessLogRecord,
+		})
+		processor.logger.Info("SARIF processor enabled")
 	}
 
-	outgoingLogs, err := meter.Int64Counter(
-		metricOutgoingLogs,
-		metric.WithDescription("Total number of outgoing logs produced"),
-		metric.WithUnit("1"),
-	)
-	if err != nil {
-		return nil, err
+	// Initialize Grype and CycloneDX vulnerability report processor if enabled
+	if config.Processors.VulnScan.Enabled {
+		processor.vulnScans, err = vulnscan.NewProcessor(logger, &config.Processors.VulnScan,
+			vulnscan.WithVulnerabilityIntel(processor.vulnIntel),
+			vulnscan.WithOutput(output))
+		if err != nil {
+			return nil, err
+		}
+		processor.subProcessors = append(processor.subProcessors, subProcessor{
+			name:    processorVulnScan,
+			matches: isVulnScanLog,
+			kind:    vulnscan.Format,
+			process: processor.vulnScans.ProcessLogRecord,
+		})
+		processor.logger.Info("Vulnerability report processor enabled",
+			zap.String("vex", config.Processors.VulnScan.VEX))
 	}
 
-	droppedLogs, err := meter.Int64Counter(
-		metricDroppedLogs,
-		metric.WithDescription("Total number of logs dropped during processing"),
-		metric.WithUnit("1"),
-	)
-	if err != nil {
-		return nil, err
+	// Initialize kube-bench and Kubescape processor if enabled
+	if config.Processors.Benchmark.Enabled {
+		processor.benchmarks, err = benchmark.NewProcessor(logger, &config.Processors.Benchmark,
+			benchmark.WithComplianceCatalog(catalog),
+			benchmark.WithOutput(output))
+		if err != nil {
+			return nil, err
+		}
+		processor.subProcessors = append(processor.subProcessors, subProcessor{
+			name:    processorBenchmark,
+			matches: isBenchmarkLog,
+			kind:    benchmark.Format,
+			process: processor.benchmarks.ProcessLogRecord,
+		})
+		processor.logger.Info("Benchmark processor enabled",
+			zap.Strings("status_filter", config.Processors.Benchmark.StatusFilter))
 	}
 
-	processingErrors, err := meter.Int64Counter(
-		metricProcessingErrors,
-		metric.WithDescription("Total number of processing errors"),
-		metric.WithUnit("1"),
-	)
-	if err != nil {
-		return nil, err
+	// Initialize OPA Gatekeeper processor if enabled
+	if config.Processors.Gatekeeper.Enabled {
+		processor.gatekeeper, err = gatekeeper.NewProcessor(logger, &config.Processors.Gatekeeper,
+			gatekeeper.WithComplianceCatalog(catalog),
+			gatekeeper.WithTechniqueMapping(techniques),
+			gatekeeper.WithOutput(output))
+		if err != nil {
+			return nil, err
+		}
+		processor.subProcessors = append(processor.subProcessors, subProcessor{
+			name:    processorGatekeeper,
+			matches: gatekeeper.IsConstraint,
+			kind:    gatekeeperKind,
+			process: processor.gatekeeper.ProcessLogRecord,
+		})
+		processor.logger.Info("Gatekeeper processor enabled",
+			zap.Strings("enforcement_action_filter", config.Processors.Gatekeeper.EnforcementActionFilter))
+	}
+
+	// Initialize Tetragon and Tracee runtime event processor if enabled
+	if config.Processors.Runtime.Enabled {
+		processor.runtimeLogs, err = runtimelog.NewProcessor(logger, &config.Processors.Runtime,
+			runtimelog.WithTechniqueMapping(techniques),
+			runtimelog.WithOutput(output))
+		if err != nil {
+			return nil, err
+		}
+		processor.subProcessors = append(processor.subProcessors, subProcessor{
+			name:    processorRuntime,
+			matches: isRuntimeLog,
+			kind:    runtimelog.Format,
+			process: processor.runtimeLogs.ProcessLogRecord,
+		})
+		processor.logger.Info("Runtime event processor enabled",
+			zap.Strings("policy_filter", config.Processors.Runtime.PolicyFilter))
+	}
+
+	return processor, nil
+}
+
+// subProcessor turns the log records of one source format into security events
+type subProcessor struct {
+	// name is the processor attribute of the telemetry and of the dead letter annotations
+	name string
+
+	// matches performs a quick check to determine if a log record is in the source format
+	matches func(logRecord *plog.LogRecord) bool
+
+	// kind returns the report kind attribute of the telemetry
+	kind func(logRecord *plog.LogRecord) string
+
+	// groupByResource reports whether output.group_by_resource applies to the security events,
+	// which requires a scoped Kubernetes object
+	groupByResource bool
+
+	// process appends the security events of a log record to dst
+	process func(ctx context.Context, logRecord *plog.LogRecord, resource pcommon.Resource, scopeLogs plog.ScopeLogs,
+		dst plog.LogRecordSlice) (processing.Outcome, error)
+}
+
+// match returns the first enabled sub-processor matching a log record, or nil
+func (p *securityEventProcessor) match(logRecord *plog.LogRecord) *subProcessor {
+	for i := range p.subProcessors {
+		if p.subProcessors[i].matches(logRecord) {
+			return &p.subProcessors[i]
+		}
 	}
+	return nil
+}
+
+// start starts the background refresh of enrichment data
+func (p *securityEventProcessor) start(ctx context.Context, _ component.Host) error {
+	return p.vulnIntel.Start(ctx)
+}
 
-	return &processorMetrics{
-		incomingLogs:     incomingLogs,
-		outgoingLogs:     outgoingLogs,
-		droppedLogs:      droppedLogs,
-		processingErrors: processingErrors,
-	}, nil
+// shutdown stops the background refresh of enrichment data
+func (p *securityEventProcessor) shutdown(ctx context.Context) error {
+	return p.vulnIntel.Shutdown(ctx)
 }
 
 // processLogs processes the incoming logs and transforms them into security events
 //
 //nolint:gocyclo // Complex transformation logic with multiple nested iterations and conditionals
 func (p *securityEventProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	startTime := time.Now()
+	telemetry := newBatchTelemetry()
+
+	// Per-record debug fields are only built when debug logging is enabled
+	debug := p.logger.Core().Enabled(zapcore.DebugLevel)
+	var reports reportCounts
+
 	// Count incoming logs
 	incomingCount := int64(0)
 	outgoingCount := int64(0)
 	droppedCount := int64(0)
 
-	p.logger.Debug("Processing logs batch",
-		zap.Int("resource_logs_count", ld.ResourceLogs().Len()))
+	if debug {
+		p.logger.Debug("Processing logs batch",
+			zap.Int("resource_logs_count", ld.ResourceLogs().Len()))
+	}
 
 	resourceLogs := ld.ResourceLogs()
 
+	// With group_by_resource, security events are moved to a new ResourceLogs per scoped object
+	var groups *resourceGroups
+	if p.config.Output.GroupByResource {
+		groups = newResourceGroups(p.config.Output.attributeNames())
+	}
+
 	for i := 0; i < resourceLogs.Len(); i++ {
 		resourceLog := resourceLogs.At(i)
 		scopeLogs := resourceLog.ScopeLogs()
@@ -133,147 +323,270 @@ func (p *securityEventProcessor) processLogs(ctx context.Context, ld plog.Logs)
 			scopeLog := scopeLogs.At(j)
 			logRecords := scopeLog.LogRecords()
 
-			// Collect records to replace in first pass
-			type replacement struct {
-				index      int
-				newRecords []plog.LogRecord
-			}
-			var replacements []replacement
-
-			// Track which indices will be replaced to avoid double-counting
-			replacementIndices := make(map[int]bool)
+			// Scopes containing a report are rebuilt into rebuilt: security events are built in place
+			// and the other records are moved rather than copied. Scopes without reports are left untouched
+			var rebuilt plog.LogRecordSlice
+			isRebuilding := false
 
-			p.logger.Debug("Processing scope logs",
-				zap.Int("scope_index", j),
-				zap.Int("log_records_count", logRecords.Len()))
+			if debug {
+				p.logger.Debug("Processing scope logs",
+					zap.Int("scope_index", j),
+					zap.Int("log_records_count", logRecords.Len()))
+			}
 
-			// First pass: identify records that need to be expanded
 			for k := 0; k < logRecords.Len(); k++ {
 				logRecord := logRecords.At(k)
 				incomingCount++ // Count each incoming log record
 
-				p.logger.Debug("Processing log record",
-					zap.Int("record_index", k),
-					zap.String("trace_id", logRecord.TraceID().String()),
-					zap.String("span_id", logRecord.SpanID().String()),
-					zap.Bool("openreports_enabled", p.openReports != nil))
-
-				// Process with OpenReports if enabled
-				if p.openReports != nil {
-					// Quick check if this log matches OpenReports before processing
-					if !isOpenReportsLog(&logRecord) {
-						p.logger.Debug("Log record does not match OpenReports - skipping processor",
+				if debug {
+					p.logger.Debug("Processing log record",
+						zap.Int("record_index", k),
+						zap.String("trace_id", logRecord.TraceID().String()),
+						zap.String("span_id", logRecord.SpanID().String()),
+						zap.Int("sub_processors", len(p.subProcessors)))
+				}
+
+				// Process with the first enabled sub-processor matching the log record
+				sub := p.match(&logRecord)
+				if sub == nil {
+					if debug && len(p.subProcessors) == 0 {
+						p.logger.Debug("No sub-processor enabled - log passes through unchanged",
+							zap.Int("record_index", k))
+					} else if debug {
+						p.logger.Debug("Log record does not match any sub-processor - skipping",
 							zap.Int("record_index", k),
 							zap.String("trace_id", logRecord.TraceID().String()),
-							zap.String("reason", "not_openreports_format"))
-						// Log passes through unchanged
-						outgoingCount++
-						continue
+							zap.String("reason", "not_matched"))
+					}
+					// Log passes through unchanged
+					outgoingCount++
+					telemetry.incoming[telemetryKey{processor: processorNone}]++
+					telemetry.outgoing[telemetryKey{processor: processorNone, reason: reasonNotMatched}]++
+					if isRebuilding {
+						logRecord.MoveTo(rebuilt.AppendEmpty())
 					}
+					continue
+				}
 
-					p.logger.Debug("Log record matches OpenReports processor - proceeding with transformation",
+				if !isRebuilding {
+					// Move the records already passed through, then build the rest of the scope in place
+					rebuilt = plog.NewLogRecordSlice()
+					rebuilt.EnsureCapacity(logRecords.Len())
+					for m := 0; m < k; m++ {
+						logRecords.At(m).MoveTo(rebuilt.AppendEmpty())
+					}
+					isRebuilding = true
+				}
+
+				reportKey := telemetryKey{processor: sub.name, reportKind: sub.kind(&logRecord)}
+				telemetry.incoming[reportKey]++
+
+				if debug {
+					p.logger.Debug("Log record matches sub-processor - proceeding with transformation",
+						zap.String("processor", sub.name),
 						zap.Int("record_index", k),
 						zap.String("trace_id", logRecord.TraceID().String()))
+				}
 
-					newRecords, err := p.openReports.ProcessLogRecord(ctx, &logRecord, resourceLog.Resource(), scopeLog)
-					if err != nil {
-						p.logger.Warn("Failed to process log record with OpenReports processor",
-							zap.Error(err))
-						p.metrics.processingErrors.Add(ctx, 1,
-							metric.WithAttributes(attribute.String("error_type", "processing_error")))
-						// Continue processing other records, but this log is effectively dropped
+				events := rebuilt
+				if groups != nil && sub.groupByResource {
+					events = groups.logRecords(i, j, resourceLog, scopeLog, &logRecord)
+				}
+				firstEvent := events.Len()
+				outcome, err := sub.process(ctx, &logRecord, resourceLog.Resource(), scopeLog, events)
+				if err != nil {
+					p.logger.Warn("Failed to process log record",
+						zap.String("processor", sub.name),
+						zap.String("error_mode", p.config.ErrorMode),
+						zap.Error(err))
+					reportKey.reason = errorReason(err)
+					telemetry.errors[reportKey]++
+					reports.failed++
+
+					switch p.config.ErrorMode {
+					case ErrorModePropagate:
+						telemetry.record(ctx, p.metrics)
+						return ld, fmt.Errorf("failed to process log record with %s processor: %w", sub.name, err)
+					case ErrorModePassthrough:
+						// The original log passes through unchanged
+						outgoingCount++
+						telemetry.outgoing[reportKey]++
+						logRecord.MoveTo(rebuilt.AppendEmpty())
+					case ErrorModeDeadLetter:
+						// The original log is kept, annotated for routing to a quarantine exporter
+						annotateDeadLetter(&logRecord, sub.name, err)
+						outgoingCount++
+						reportKey.reason = reasonDeadLetter
+						telemetry.outgoing[reportKey]++
+						logRecord.MoveTo(rebuilt.AppendEmpty())
+					default:
+						// Continue processing other records, but this log is dropped
 						droppedCount++
-						continue
+						telemetry.dropped[reportKey]++
 					}
+					continue
+				}
+				telemetry.addOutcome(reportKey, &outcome)
+				reports.add(&outcome)
 
-					// If new records were created (expanded), mark for replacement
-					if len(newRecords) > 0 {
+				p.metrics.resultsPerReport.Record(ctx, int64(outcome.Created), reportKey.attributes(attrReason))
+
+				if outcome.Created > 0 {
+					// The report is replaced by the security events appended to rebuilt or to its resource group
+					if debug {
 						p.logger.Debug("Log record expanded into multiple security events",
 							zap.Int("record_index", k),
-							zap.Int("expanded_count", len(newRecords)),
+							zap.Int("expanded_count", outcome.Created),
 							zap.String("trace_id", logRecord.TraceID().String()))
-						replacements = append(replacements, replacement{
-							index:      k,
-							newRecords: newRecords,
-						})
-						replacementIndices[k] = true
-						// Note: outgoingCount will be incremented in the replacement pass for expanded logs
-					} else {
-						// Log was processed but not expanded (not an OpenReports log or filtered out)
+					}
+					for e := firstEvent; e < events.Len(); e++ {
+						event := events.At(e)
+						eventKey := eventTelemetryKey(reportKey, &event, p.config.Output.profile())
+						if err := p.validate(&event); err != nil {
+							if debug {
+								p.logger.Debug("Security event failed validation",
+									zap.String("action", p.config.Output.Validation.Action),
+									zap.Error(err))
+							}
+							errorKey := reportKey
+							errorKey.reason = errorReason(err)
+							telemetry.errors[errorKey]++
+							reports.invalid++
+							if p.config.Output.Validation.Action == validation.ActionDeadLetter {
+								// The event is kept, annotated with the violated constraints for routing
+								annotateDeadLetter(&event, sub.name, err)
+								eventKey.reason = reasonDeadLetter
+							}
+						}
+						outgoingCount++ // Count each expanded log
+						telemetry.outgoing[eventKey]++
+					}
+				} else {
+					// Log was processed but not expanded (skipped or all its results filtered out)
+					if debug {
 						p.logger.Debug("Log record processed but not expanded - passing through unchanged",
 							zap.Int("record_index", k),
-							zap.String("reason", "not_openreports_or_filtered"),
+							zap.String("reason", "skipped_or_filtered"),
 							zap.String("trace_id", logRecord.TraceID().String()))
-						// This log passes through unchanged, so it counts as outgoing
-						outgoingCount++
 					}
-				} else {
-					// No processor enabled, log passes through unchanged
-					p.logger.Debug("No OpenReports processor enabled - log passes through unchanged",
-						zap.Int("record_index", k))
+					// This log passes through unchanged, so it counts as outgoing
 					outgoingCount++
+					reportKey.reason = reasonNotExpanded
+					telemetry.outgoing[reportKey]++
+					logRecord.MoveTo(rebuilt.AppendEmpty())
 				}
 			}
 
-			// Second pass: replace records
-			if len(replacements) > 0 {
-				p.logger.Debug("Replacing expanded log records",
-					zap.Int("replacements_count", len(replacements)),
-					zap.Int("original_records", logRecords.Len()))
-
-				// Build new log records array
-				newLogRecords := plog.NewLogRecordSlice()
-
-				// Copy records, replacing expanded ones
-				replacementIndex := 0
-				for k := 0; k < logRecords.Len(); k++ {
-					// Check if this index needs replacement
-					if replacementIndex < len(replacements) && replacements[replacementIndex].index == k {
-						// Replace with new records (expanded)
-						for _, newRecord := range replacements[replacementIndex].newRecords {
-							insertedRecord := newLogRecords.AppendEmpty()
-							newRecord.CopyTo(insertedRecord)
-							outgoingCount++ // Count each expanded log
-						}
-						replacementIndex++
-					} else if !replacementIndices[k] {
-						// Copy original record (unchanged) - only count if not already counted in first pass
-						// (Actually, if we're in this pass, we need to rebuild everything, so we count all)
-						originalRecord := logRecords.At(k)
-						insertedRecord := newLogRecords.AppendEmpty()
-						originalRecord.CopyTo(insertedRecord)
-						// Note: We already counted this in the first pass, so we don't count again
-					}
-				}
-
-				// Clear original and move new records
+			if isRebuilding {
+				// Swap the rebuilt records in; the originals were all moved or replaced
 				logRecords.RemoveIf(func(plog.LogRecord) bool { return true })
-				newLogRecords.MoveAndAppendTo(logRecords)
+				rebuilt.MoveAndAppendTo(logRecords)
+				if groups != nil && logRecords.Len() == 0 {
+					groups.markEmptied(i, j)
+				}
 
-				p.logger.Debug("Log records replacement completed",
-					zap.Int("new_records_count", logRecords.Len()),
-					zap.Int("outgoing_count", int(outgoingCount)))
+				if debug {
+					p.logger.Debug("Log records replacement completed",
+						zap.Int("new_records_count", logRecords.Len()),
+						zap.Int("outgoing_count", int(outgoingCount)))
+				}
 			}
 		}
 	}
 
-	p.logger.Debug("Batch processing completed",
-		zap.Int64("incoming_logs", incomingCount),
-		zap.Int64("outgoing_logs", outgoingCount),
-		zap.Int64("dropped_logs", droppedCount))
+	if groups != nil {
+		groups.moveTo(ld)
+	}
+
+	if debug {
+		p.logger.Debug("Batch processing completed",
+			zap.Int64("incoming_logs", incomingCount),
+			zap.Int64("outgoing_logs", outgoingCount),
+			zap.Int64("dropped_logs", droppedCount))
+	}
 
 	// Record metrics
-	if incomingCount > 0 {
-		p.metrics.incomingLogs.Add(ctx, incomingCount)
+	telemetry.record(ctx, p.metrics)
+	p.metrics.batchDuration.Record(ctx, time.Since(startTime).Seconds())
+	p.summary.record(p.logger, reports, time.Now())
+
+	return ld, nil
+}
+
+// validate checks a security event against the output schema
+// A *processing.StageError is returned if the event is invalid
+func (p *securityEventProcessor) validate(event *plog.LogRecord) error {
+	if p.validator == nil {
+		return nil
 	}
-	if outgoingCount > 0 {
-		p.metrics.outgoingLogs.Add(ctx, outgoingCount)
+	if err := p.validator.Validate(event.Attributes()); err != nil {
+		return processing.NewStageError(processing.StageValidate, err)
 	}
-	if droppedCount > 0 {
-		p.metrics.droppedLogs.Add(ctx, droppedCount)
+	return nil
+}
+
+// errorReason returns the reason attribute of a processing error: "<stage>_error" for stage errors
+func errorReason(err error) string {
+	var stageErr *processing.StageError
+	if errors.As(err, &stageErr) {
+		return stageErr.Stage + "_error"
 	}
+	return reasonProcessingError
+}
 
-	return ld, nil
+// annotateDeadLetter marks a log record that failed processing with the error and the failing stage
+func annotateDeadLetter(logRecord *plog.LogRecord, processorName string, err error) {
+	attrs := logRecord.Attributes()
+	attrs.PutStr(attrDeadLetterError, err.Error())
+	attrs.PutStr(attrDeadLetterProcessor, processorName)
+	stage := processing.StageTransform
+	var stageErr *processing.StageError
+	if errors.As(err, &stageErr) {
+		stage = stageErr.Stage
+	}
+	attrs.PutStr(attrDeadLetterStage, stage)
+}
+
+// eventTelemetryKey returns the telemetry key of a security event laid out in an output profile
+func eventTelemetryKey(reportKey telemetryKey, event *plog.LogRecord, profileName string) telemetryKey {
+	attrs := event.Attributes()
+	if status, exists := attrs.Get(profile.ComplianceStatusAttribute(profileName)); exists {
+		reportKey.complianceStatus = status.AsString()
+	}
+	if severity, exists := attrs.Get(profile.SeverityAttribute(profileName)); exists {
+		reportKey.severity = severity.AsString()
+	}
+	return reportKey
+}
+
+// isCEFLog performs a quick check to determine if the body of a log record holds a CEF or LEEF record
+func isCEFLog(logRecord *plog.LogRecord) bool {
+	return ceflog.Format(logRecord) != ""
+}
+
+// sarifKind returns the report kind of SARIF logs
+func sarifKind(*plog.LogRecord) string {
+	return sariflog.Kind
+}
+
+// isVulnScanLog performs a quick check to determine if the body of a log record holds a Grype report or a CycloneDX BOM
+func isVulnScanLog(logRecord *plog.LogRecord) bool {
+	return vulnscan.Format(logRecord) != ""
+}
+
+// isBenchmarkLog performs a quick check to determine if the body of a log record holds a kube-bench or Kubescape report
+func isBenchmarkLog(logRecord *plog.LogRecord) bool {
+	return benchmark.Format(logRecord) != ""
+}
+
+// gatekeeperKind returns the report kind of Gatekeeper constraints
+func gatekeeperKind(*plog.LogRecord) string {
+	return gatekeeper.Kind
+}
+
+// isRuntimeLog performs a quick check to determine if the body of a log record holds a Tetragon or Tracee event
+func isRuntimeLog(logRecord *plog.LogRecord) bool {
+	return runtimelog.Format(logRecord) != ""
 }
 
 // isOpenReportsLog performs a quick check to determine if a log record matches OpenReports format
@@ -0,0 +1,145 @@
+package securityevent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/openreports"
+)
+
+// newBenchmarkLogs returns a batch of one scope with the given number of reports interleaved with
+// otherLogs non-report logs, mimicking a k8sobjects batch mixing policy reports and other objects
+func newBenchmarkLogs(reports, resultsPerReport, otherLogs int) plog.Logs {
+	logs := plog.NewLogs()
+	resourceLog := logs.ResourceLogs().AppendEmpty()
+	resourceLog.Resource().Attributes().PutStr("k8s.cluster.name", "benchmark")
+	records := resourceLog.ScopeLogs().AppendEmpty().LogRecords()
+
+	otherPerReport := otherLogs
+	if reports > 0 {
+		otherPerReport = otherLogs / reports
+	}
+	other := 0
+	for r := 0; r < reports || other < otherLogs; r++ {
+		if r < reports {
+			appendBenchmarkReport(records, r, resultsPerReport)
+		}
+		for n := 0; n < otherPerReport && other < otherLogs; n++ {
+			pod := records.AppendEmpty()
+			pod.Attributes().PutStr("kind", "Pod")
+			pod.Attributes().PutStr("metadata.name", fmt.Sprintf("pod-%d", other))
+			pod.Body().SetStr("pod updated")
+			other++
+		}
+	}
+	return logs
+}
+
+// appendBenchmarkReport appends a report with resultsPerReport results, one in three failing
+func appendBenchmarkReport(records plog.LogRecordSlice, index, resultsPerReport int) {
+	attrs := records.AppendEmpty().Attributes()
+	attrs.PutStr("kind", "Report")
+	attrs.PutStr("apiVersion", "openreports.io/v1alpha1")
+	attrs.PutStr("metadata.name", fmt.Sprintf("report-%d", index))
+	attrs.PutStr("metadata.namespace", "default")
+	attrs.PutStr("scope.kind", "Pod")
+	attrs.PutStr("scope.name", fmt.Sprintf("app-%d-7d9f8b6c5d-x2k4p", index))
+	attrs.PutStr("scope.namespace", "default")
+	attrs.PutStr("scope.uid", fmt.Sprintf("uid-%d", index))
+	results := attrs.PutEmptySlice("results")
+	for i := 0; i < resultsPerReport; i++ {
+		status := "pass"
+		if i%3 == 0 {
+			status = "fail"
+		}
+		results.AppendEmpty().SetStr(fmt.Sprintf(
+			`{"policy": "pod-security-%d", "rule": "rule-%d", "result": %q, "severity": "high", `+
+				`"source": "kyverno", "message": "validation failure for rule %d", "properties": {"id": "CHECK-%d"}}`,
+			i%10, i, status, i, i))
+	}
+}
+
+func BenchmarkProcessLogs(b *testing.B) {
+	benchmarks := []struct {
+		reports          int
+		resultsPerReport int
+		otherLogs        int
+		workers          int
+	}{
+		{reports: 1, resultsPerReport: 10, otherLogs: 0},
+		{reports: 10, resultsPerReport: 50, otherLogs: 100},
+		{reports: 50, resultsPerReport: 200, otherLogs: 500},
+		{reports: 50, resultsPerReport: 200, otherLogs: 500, workers: 4},
+		{reports: 2, resultsPerReport: 5000, otherLogs: 0},
+		{reports: 2, resultsPerReport: 5000, otherLogs: 0, workers: 4},
+		{reports: 0, resultsPerReport: 0, otherLogs: 500},
+	}
+
+	for _, bm := range benchmarks {
+		name := fmt.Sprintf("reports=%d/results=%d/other=%d/workers=%d", bm.reports, bm.resultsPerReport, bm.otherLogs, bm.workers)
+		b.Run(name, func(b *testing.B) {
+			config := &Config{
+				Processors: ProcessorConfig{
+					OpenReports: openreports.Config{Enabled: true, Workers: bm.workers},
+				},
+			}
+			processor, err := newSecurityEventProcessor(zap.NewNop(), config, componenttest.NewNopTelemetrySettings())
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			template := newBenchmarkLogs(bm.reports, bm.resultsPerReport, bm.otherLogs)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				logs := plog.NewLogs()
+				template.CopyTo(logs)
+				b.StartTimer()
+
+				if _, err := processor.processLogs(context.Background(), logs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkProcessLogs_InfoLevel measures the logging overhead with a production-like logger at Info level
+func BenchmarkProcessLogs_InfoLevel(b *testing.B) {
+	logger := zap.New(zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		zapcore.AddSync(io.Discard),
+		zap.InfoLevel,
+	))
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true, StatusFilter: []string{"fail"}},
+		},
+	}
+	processor, err := newSecurityEventProcessor(logger, config, componenttest.NewNopTelemetrySettings())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	template := newBenchmarkLogs(100, 20, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		logs := plog.NewLogs()
+		template.CopyTo(logs)
+		b.StartTimer()
+
+		if _, err := processor.processLogs(context.Background(), logs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,14 +2,33 @@ package securityevent
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/henrikrexed/securitylogeventprocessor/internal/attack"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/benchmark"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/ceflog"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/compliance"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/gatekeeper"
 	"github.com/henrikrexed/securitylogeventprocessor/internal/openreports"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/profile"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/runtimelog"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/sariflog"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/semconv"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/validation"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/vulnintel"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/vulnscan"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -48,6 +67,126 @@ func TestNewSecurityEventProcessor_WithOpenReportsEnabled(t *testing.T) {
 	assert.NotNil(t, processor.openReports, "OpenReports processor should be created when enabled")
 }
 
+func TestNewSecurityEventProcessor_WithComplianceCatalog(t *testing.T) {
+	catalogFile := filepath.Join(t.TempDir(), "catalog.yaml")
+	require.NoError(t, os.WriteFile(catalogFile, []byte(`
+mappings:
+  - policy: disallow-privileged-containers
+    controls:
+      - framework: CIS
+        id: "5.2.2"
+`), 0o600))
+
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true},
+		},
+		Enrichment: EnrichmentConfig{
+			Compliance: compliance.Config{CatalogFile: catalogFile},
+		},
+	}
+
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	logRecord := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	logRecord.Attributes().PutStr("kind", "Report")
+	logRecord.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	logRecord.Attributes().PutEmptySlice("results").AppendEmpty().SetStr(
+		`{"policy": "disallow-privileged-containers", "rule": "privileged-containers", "result": "fail"}`)
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	records := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, records.Len())
+	assert.Equal(t, []any{"CIS"}, records.At(0).Attributes().AsRaw()["compliance.standards"])
+	assert.Equal(t, []any{"CIS 5.2.2"}, records.At(0).Attributes().AsRaw()["compliance.requirements"])
+}
+
+func TestNewSecurityEventProcessor_MissingComplianceCatalog(t *testing.T) {
+	config := &Config{
+		Enrichment: EnrichmentConfig{
+			Compliance: compliance.Config{CatalogFile: filepath.Join(t.TempDir(), "missing.yaml")},
+		},
+	}
+
+	_, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, componenttest.NewNopTelemetrySettings())
+	require.Error(t, err)
+}
+
+func TestNewSecurityEventProcessor_WithAttackMapping(t *testing.T) {
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true},
+		},
+		Enrichment: EnrichmentConfig{
+			Attack: attack.Config{Enabled: true},
+		},
+	}
+
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	logRecord := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	logRecord.Attributes().PutStr("kind", "Report")
+	logRecord.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	logRecord.Attributes().PutEmptySlice("results").AppendEmpty().SetStr(
+		`{"policy": "disallow-privileged-containers", "rule": "privileged-containers", "result": "fail"}`)
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	records := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, records.Len())
+	assert.Equal(t, []any{"T1611", "T1610"}, records.At(0).Attributes().AsRaw()["threat.technique.id"])
+}
+
+func TestNewSecurityEventProcessor_WithVulnerabilityIntel(t *testing.T) {
+	kevFile := filepath.Join(t.TempDir(), "kev.json")
+	require.NoError(t, os.WriteFile(kevFile, []byte(`{"vulnerabilities": [{"cveID": "CVE-2021-44228"}]}`), 0o600))
+
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true},
+		},
+		Enrichment: EnrichmentConfig{
+			Vulnerability: vulnintel.Config{KEVFile: kevFile, RefreshInterval: time.Minute},
+		},
+	}
+
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	require.NotNil(t, processor.vulnIntel)
+
+	require.NoError(t, processor.start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, processor.shutdown(context.Background())) }()
+
+	logs := plog.NewLogs()
+	logRecord := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	logRecord.Attributes().PutStr("kind", "Report")
+	logRecord.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	logRecord.Attributes().PutEmptySlice("results").AppendEmpty().SetStr(
+		`{"policy": "CVE-2021-44228", "rule": "log4j-core", "result": "fail", "severity": "critical"}`)
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	records := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, records.Len())
+	assert.Equal(t, true, records.At(0).Attributes().AsRaw()["vulnerability.kev"])
+}
+
+func TestSecurityEventProcessor_StartShutdownWithoutEnrichment(t *testing.T) {
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), &Config{}, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+
+	require.NoError(t, processor.start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, processor.shutdown(context.Background()))
+}
+
 func TestIsOpenReportsLog_ValidOpenReportsLog(t *testing.T) {
 	logRecord := plog.NewLogRecord()
 	attrs := logRecord.Attributes()
@@ -293,6 +432,8 @@ func TestCreateProcessorMetrics(t *testing.T) {
 	assert.NotNil(t, metrics.outgoingLogs)
 	assert.NotNil(t, metrics.droppedLogs)
 	assert.NotNil(t, metrics.processingErrors)
+	assert.NotNil(t, metrics.resultsPerReport)
+	assert.NotNil(t, metrics.batchDuration)
 }
 
 func TestProcessLogs_MetricsIncremented(t *testing.T) {
@@ -323,3 +464,801 @@ func TestProcessLogs_MetricsIncremented(t *testing.T) {
 	// Metrics should be incremented (though we can't easily test the exact values without a metrics exporter)
 	// The fact that processLogs didn't panic or error means metrics were handled correctly
 }
+
+// newErrorModeLogs returns a batch with a valid OpenReports log, a report with an invalid results field and a non-OpenReports log
+func newErrorModeLogs() plog.Logs {
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+
+	report := records.AppendEmpty()
+	report.Attributes().PutStr("kind", "Report")
+	report.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	report.Attributes().PutEmptySlice("results").AppendEmpty().SetStr(`{"policy": "p1", "rule": "r1", "result": "fail"}`)
+
+	// A CEF record whose header is cut short fails to parse
+	malformed := records.AppendEmpty()
+	malformed.Attributes().PutStr("kind", "Syslog")
+	malformed.Body().SetStr(malformedCEF)
+
+	records.AppendEmpty().Attributes().PutStr("kind", "Pod")
+	return logs
+}
+
+// malformedCEF is a CEF record with a truncated header
+const malformedCEF = "CEF:0|Palo Alto Networks|PAN-OS|10.2"
+
+// errorModeProcessors enables the sub-processors of the records of newErrorModeLogs
+var errorModeProcessors = ProcessorConfig{
+	OpenReports: openreports.Config{Enabled: true},
+	CEF:         ceflog.Config{Enabled: true},
+}
+
+func TestProcessLogs_ErrorMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		errorMode string
+		wantErr   bool
+		wantKinds []string
+	}{
+		{
+			name:      "default drops failing record",
+			errorMode: "",
+			wantKinds: []string{"SecurityEvent", "Pod"},
+		},
+		{
+			name:      "drop",
+			errorMode: ErrorModeDrop,
+			wantKinds: []string{"SecurityEvent", "Pod"},
+		},
+		{
+			name:      "passthrough",
+			errorMode: ErrorModePassthrough,
+			wantKinds: []string{"SecurityEvent", "Syslog", "Pod"},
+		},
+		{
+			name:      "dead letter",
+			errorMode: ErrorModeDeadLetter,
+			wantKinds: []string{"SecurityEvent", "Syslog", "Pod"},
+		},
+		{
+			name:      "propagate",
+			errorMode: ErrorModePropagate,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				ErrorMode:  tt.errorMode,
+				Processors: errorModeProcessors,
+			}
+			processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, componenttest.NewNopTelemetrySettings())
+			require.NoError(t, err)
+
+			result, err := processor.processLogs(context.Background(), newErrorModeLogs())
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "parse stage failed")
+				return
+			}
+			require.NoError(t, err)
+
+			records := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+			// Transformed security events no longer carry the report kind
+			kinds := make([]string, 0, records.Len())
+			for i := 0; i < records.Len(); i++ {
+				kind, exists := records.At(i).Attributes().Get("kind")
+				if !exists {
+					kinds = append(kinds, "SecurityEvent")
+					continue
+				}
+				kinds = append(kinds, kind.Str())
+			}
+			assert.Equal(t, tt.wantKinds, kinds)
+		})
+	}
+}
+
+func TestProcessLogs_MalformedResultsPassThrough(t *testing.T) {
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true},
+		},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	report := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	report.Attributes().PutStr("kind", "Report")
+	report.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	results := report.Attributes().PutEmptySlice("results")
+	results.AppendEmpty().SetStr(`{invalid json}`)
+	results.AppendEmpty().SetStr(`not json either`)
+
+	// A report whose results are all malformed is not an error: the default error mode keeps it
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+	records := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, records.Len())
+	kind, _ := records.At(0).Attributes().Get("kind")
+	assert.Equal(t, "Report", kind.Str())
+	assert.Len(t, records.At(0).Attributes().AsRaw()["results"], 2)
+}
+
+func TestProcessLogs_ErrorModeDeadLetterAnnotations(t *testing.T) {
+	config := &Config{
+		ErrorMode:  ErrorModeDeadLetter,
+		Processors: errorModeProcessors,
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+
+	result, err := processor.processLogs(context.Background(), newErrorModeLogs())
+	require.NoError(t, err)
+
+	records := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 3, records.Len())
+
+	// The transformed security event is not annotated
+	_, exists := records.At(0).Attributes().Get(attrDeadLetterError)
+	assert.False(t, exists)
+
+	attrs := records.At(1).Attributes()
+	errorValue, exists := attrs.Get(attrDeadLetterError)
+	require.True(t, exists)
+	assert.Contains(t, errorValue.Str(), "invalid CEF header")
+	stage, _ := attrs.Get(attrDeadLetterStage)
+	assert.Equal(t, processing.StageParse, stage.Str())
+	processorName, _ := attrs.Get(attrDeadLetterProcessor)
+	assert.Equal(t, processorCEF, processorName.Str())
+
+	// The original payload is kept
+	assert.Equal(t, malformedCEF, records.At(1).Body().Str())
+}
+
+func TestErrorReason(t *testing.T) {
+	assert.Equal(t, "parse_error", errorReason(processing.NewStageError(processing.StageParse, errors.New("boom"))))
+	assert.Equal(t, "transform_error", errorReason(fmt.Errorf("wrapped: %w", processing.NewStageError(processing.StageTransform, errors.New("boom")))))
+	assert.Equal(t, reasonProcessingError, errorReason(errors.New("boom")))
+}
+
+func TestProcessLogs_ExpansionPreservesOrder(t *testing.T) {
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true, StatusFilter: []string{"fail"}},
+		},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().Attributes().PutStr("metadata.name", "pod-1")
+
+	report := records.AppendEmpty()
+	report.Attributes().PutStr("kind", "Report")
+	report.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	results := report.Attributes().PutEmptySlice("results")
+	results.AppendEmpty().SetStr(`{"policy": "p1", "rule": "r1", "result": "fail"}`)
+	results.AppendEmpty().SetStr(`{"policy": "p1", "rule": "r2", "result": "fail"}`)
+
+	records.AppendEmpty().Attributes().PutStr("metadata.name", "pod-2")
+
+	// A report with only filtered results passes through unchanged
+	passedReport := records.AppendEmpty()
+	passedReport.Attributes().PutStr("kind", "Report")
+	passedReport.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	passedReport.Attributes().PutStr("metadata.name", "passed-report")
+	passedReport.Attributes().PutEmptySlice("results").AppendEmpty().SetStr(`{"policy": "p1", "rule": "r3", "result": "pass"}`)
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	// Security events take the place of their report
+	outRecords := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	names := make([]string, 0, outRecords.Len())
+	for i := 0; i < outRecords.Len(); i++ {
+		attrs := outRecords.At(i).Attributes()
+		if title, exists := attrs.Get("finding.title"); exists {
+			names = append(names, title.Str())
+			continue
+		}
+		name, _ := attrs.Get("metadata.name")
+		names = append(names, name.Str())
+	}
+	require.Len(t, names, 5)
+	assert.Equal(t, "pod-1", names[0])
+	assert.Contains(t, names[1], "r1")
+	assert.Contains(t, names[2], "r2")
+	assert.Equal(t, []string{"pod-2", "passed-report"}, names[3:])
+}
+
+func TestProcessLogs_Validation(t *testing.T) {
+	// finding.url is always empty, so every security event violates this schema
+	schemaFile := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(schemaFile, []byte(`{
+		"type": "object",
+		"required": ["finding.url"],
+		"properties": {"finding.url": {"type": "string", "minLength": 1}}
+	}`), 0o600))
+
+	tests := []struct {
+		name           string
+		attributeNames string
+		validation     validation.Config
+		wantAnnotation bool
+		wantErrors     int64
+	}{
+		{
+			name:       "embedded schema accepts security events",
+			validation: validation.Config{Enabled: true, Action: validation.ActionDeadLetter},
+		},
+		{
+			name:           "embedded schema accepts semconv security events",
+			attributeNames: semconv.ModeSemconv,
+			validation:     validation.Config{Enabled: true, Action: validation.ActionDeadLetter},
+		},
+		{
+			name:       "count",
+			validation: validation.Config{Enabled: true, SchemaFile: schemaFile},
+			wantErrors: 1,
+		},
+		{
+			name:           "dead letter",
+			validation:     validation.Config{Enabled: true, SchemaFile: schemaFile, Action: validation.ActionDeadLetter},
+			wantAnnotation: true,
+			wantErrors:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tel := componenttest.NewTelemetry()
+			t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+			config := &Config{
+				Processors: errorModeProcessors,
+				Output:     OutputConfig{AttributeNames: tt.attributeNames, Validation: tt.validation},
+			}
+			processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, tel.NewTelemetrySettings())
+			require.NoError(t, err)
+
+			result, err := processor.processLogs(context.Background(), newErrorModeLogs())
+			require.NoError(t, err)
+
+			// Invalid security events are never dropped
+			records := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+			require.Equal(t, 2, records.Len())
+			event := records.At(0).Attributes()
+			_, exists := event.Get("finding.id")
+			require.True(t, exists)
+
+			errorValue, exists := event.Get(attrDeadLetterError)
+			assert.Equal(t, tt.wantAnnotation, exists)
+			if tt.wantAnnotation {
+				assert.Contains(t, errorValue.Str(), "/properties/finding.url/minLength at '/finding.url'")
+				stage, _ := event.Get(attrDeadLetterStage)
+				assert.Equal(t, processing.StageValidate, stage.Str())
+			}
+
+			errorCounts := sumByAttributes(t, tel, metricProcessingErrors)
+			validateErrors := errorCounts[attrSet(
+				attribute.String(attrProcessor, processorOpenReports),
+				attribute.String(attrErrorType, "validate_error"),
+			)]
+			assert.Equal(t, tt.wantErrors, validateErrors)
+		})
+	}
+}
+
+func TestIsCEFLog(t *testing.T) {
+	cefRecord := plog.NewLogRecord()
+	cefRecord.Body().SetStr("<134>Oct 18 12:00:00 fw01 CEF:0|Vendor|Product|1.0|100|Port scan|8|src=10.0.0.5")
+	plainRecord := plog.NewLogRecord()
+	plainRecord.Body().SetStr("sshd[42]: Accepted publickey for alice")
+	mentionRecord := plog.NewLogRecord()
+	mentionRecord.Body().SetStr("level=error msg=failed to forward CEF: connection refused")
+
+	assert.True(t, isCEFLog(&cefRecord))
+	assert.False(t, isCEFLog(&plainRecord))
+	assert.False(t, isCEFLog(&mentionRecord))
+}
+
+func TestProcessLogs_CEF(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+	config := &Config{
+		ErrorMode: ErrorModeDeadLetter,
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true},
+			CEF:         ceflog.Config{Enabled: true},
+		},
+		Output: OutputConfig{
+			GroupByResource: true,
+			Validation:      validation.Config{Enabled: true, Action: validation.ActionDeadLetter},
+		},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, tel.NewTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	report := records.AppendEmpty()
+	report.Attributes().PutStr("kind", "Report")
+	report.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	report.Attributes().PutStr("scope.uid", "pod-uid-1")
+	report.Attributes().PutEmptySlice("results").AppendEmpty().SetStr(`{"policy": "p1", "rule": "r1", "result": "fail"}`)
+	records.AppendEmpty().Body().SetStr("CEF:0|Palo Alto Networks|PAN-OS|10.2|THREAT|Port scan detected|8|" +
+		"src=10.0.0.5 dst=192.168.1.20 suser=alice act=blocked outcome=failure")
+	records.AppendEmpty().Body().SetStr("CEF:0|Palo Alto Networks|PAN-OS|10.2")
+	records.AppendEmpty().Body().SetStr("sshd[42]: Accepted publickey for alice")
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	// The CEF security event stays in place; only the report security event is grouped by resource
+	require.Equal(t, 2, result.ResourceLogs().Len())
+	outRecords := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 3, outRecords.Len())
+
+	event := outRecords.At(0).Attributes()
+	eventType, _ := event.Get("event.type")
+	assert.Equal(t, "DETECTION_FINDING", eventType.Str())
+	user, _ := event.Get("user.name")
+	assert.Equal(t, "alice", user.Str())
+	_, exists := event.Get(attrDeadLetterError)
+	assert.False(t, exists, "the embedded schema accepts detection findings")
+
+	// The malformed record is dead-lettered by the CEF processor
+	malformed := outRecords.At(1).Attributes()
+	processorName, _ := malformed.Get(attrDeadLetterProcessor)
+	assert.Equal(t, processorCEF, processorName.Str())
+	stage, _ := malformed.Get(attrDeadLetterStage)
+	assert.Equal(t, processing.StageParse, stage.Str())
+
+	assert.Equal(t, "sshd[42]: Accepted publickey for alice", outRecords.At(2).Body().Str())
+
+	incoming := sumByAttributes(t, tel, metricIncomingLogs)
+	assert.Equal(t, int64(2), incoming[attrSet(
+		attribute.String(attrProcessor, processorCEF),
+		attribute.String(attrReportKind, "cef"),
+	)])
+	assert.Equal(t, int64(1), incoming[attrSet(
+		attribute.String(attrProcessor, processorOpenReports),
+	)])
+}
+
+func TestProcessLogs_SARIF(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+	config := &Config{
+		ErrorMode: ErrorModeDeadLetter,
+		Processors: ProcessorConfig{
+			CEF:   ceflog.Config{Enabled: true},
+			SARIF: sariflog.Config{Enabled: true},
+		},
+		Output: OutputConfig{
+			Validation: validation.Config{Enabled: true, Action: validation.ActionDeadLetter},
+		},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, tel.NewTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().Body().SetStr(`{"version": "2.1.0", "runs": [{
+		"tool": {"driver": {"name": "Semgrep OSS", "version": "1.90.0"}},
+		"results": [
+			{"ruleId": "eval-detected", "level": "error", "message": {"text": "Detected the use of eval()."},
+			 "locations": [{"physicalLocation": {"artifactLocation": {"uri": "scripts/config.py"}, "region": {"startLine": 12}}}],
+			 "fingerprints": {"matchBasedId/v1": "3b0f2e1d9c8a"}},
+			{"ruleId": "exec-detected", "level": "note", "message": {"text": "Detected the use of exec()."}}
+		]}]}`)
+	records.AppendEmpty().Body().SetStr(`{"version": "2.1.0", "runs": [{"results": "none"}]}`)
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	outRecords := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 3, outRecords.Len())
+
+	code := outRecords.At(0).Attributes().AsRaw()
+	assert.Equal(t, "Semgrep OSS", code["product.name"])
+	assert.Equal(t, "1.90.0", code["product.version"])
+	assert.Equal(t, "scripts/config.py", code["code.file.path"])
+	assert.Equal(t, int64(12), code["code.line.number"])
+	assert.Equal(t, "HIGH", code["finding.severity"])
+	assert.NotContains(t, code, attrDeadLetterError, "the embedded schema accepts code findings")
+	assert.Equal(t, "LOW", outRecords.At(1).Attributes().AsRaw()["finding.severity"])
+
+	// The undecodable log is dead-lettered by the SARIF processor
+	processorName, _ := outRecords.At(2).Attributes().Get(attrDeadLetterProcessor)
+	assert.Equal(t, processorSARIF, processorName.Str())
+
+	incoming := sumByAttributes(t, tel, metricIncomingLogs)
+	assert.Equal(t, int64(2), incoming[attrSet(
+		attribute.String(attrProcessor, processorSARIF),
+		attribute.String(attrReportKind, sariflog.Kind),
+	)])
+}
+
+func TestProcessLogs_VulnScan(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+	config := &Config{
+		Processors: ProcessorConfig{
+			SARIF:    sariflog.Config{Enabled: true},
+			VulnScan: vulnscan.Config{Enabled: true},
+		},
+		Output: OutputConfig{
+			Validation: validation.Config{Enabled: true, Action: validation.ActionDeadLetter},
+		},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, tel.NewTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().Body().SetStr(`{"matches": [{
+		"vulnerability": {"id": "CVE-2022-3602", "severity": "High", "fix": {"versions": ["3.0.7"], "state": "fixed"},
+			"cvss": [{"version": "3.1", "vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", "metrics": {"baseScore": 7.5}}]},
+		"artifact": {"name": "openssl", "version": "3.0.2", "type": "deb", "purl": "pkg:deb/ubuntu/openssl@3.0.2"}}],
+		"source": {"type": "image", "target": {"userInput": "shop/app:1.4.2", "manifestDigest": "sha256:9b4c"}},
+		"descriptor": {"name": "grype", "version": "0.74.0"}}`)
+	records.AppendEmpty().Body().SetStr(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "vulnerabilities": [
+		{"id": "CVE-2023-45288", "analysis": {"state": "not_affected"}, "affects": [{"ref": "pkg:golang/golang.org/x/net@v0.15.0"}]},
+		{"id": "CVE-2023-44487", "ratings": [{"severity": "high"}], "affects": [{"ref": "pkg:golang/golang.org/x/net@v0.15.0"}]}]}`)
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	outRecords := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, outRecords.Len())
+
+	vuln := outRecords.At(0).Attributes().AsRaw()
+	assert.Equal(t, "CVE-2022-3602", vuln["vulnerability.id"])
+	assert.Equal(t, "pkg:deb/ubuntu/openssl@3.0.2", vuln["software_component.purl"])
+	assert.Equal(t, 7.5, vuln["vulnerability.cvss.base_score"])
+	assert.Equal(t, "fixed", vuln["vulnerability.fix.state"])
+	assert.NotContains(t, vuln, attrDeadLetterError, "the embedded schema accepts vulnerability findings")
+
+	vex := outRecords.At(1).Attributes().AsRaw()
+	assert.Equal(t, "CVE-2023-44487", vex["vulnerability.id"])
+	assert.Equal(t, "net", vex["software_component.name"])
+
+	vulnScan := attribute.String(attrProcessor, processorVulnScan)
+	incoming := sumByAttributes(t, tel, metricIncomingLogs)
+	assert.Equal(t, int64(1), incoming[attrSet(vulnScan, attribute.String(attrReportKind, vulnscan.FormatGrype))])
+	assert.Equal(t, int64(1), incoming[attrSet(vulnScan, attribute.String(attrReportKind, vulnscan.FormatCycloneDX))])
+
+	// The not_affected finding is suppressed by its VEX analysis
+	assert.Equal(t, map[attribute.Distinct]int64{
+		attrSet(vulnScan, attribute.String(attrReportKind, vulnscan.FormatCycloneDX), attribute.String(attrReason, vulnscan.FilterReasonVEX)): 1,
+	}, sumByAttributes(t, tel, metricDroppedLogs))
+}
+
+func TestProcessLogs_VulnScanVEXAnnotate(t *testing.T) {
+	for _, attributeNames := range []string{semconv.ModeLegacy, semconv.ModeSemconv} {
+		t.Run(attributeNames, func(t *testing.T) {
+			config := &Config{
+				Processors: ProcessorConfig{
+					VulnScan: vulnscan.Config{Enabled: true, VEX: vulnscan.VEXAnnotate},
+				},
+				Output: OutputConfig{
+					AttributeNames: attributeNames,
+					Validation:     validation.Config{Enabled: true, Action: validation.ActionDeadLetter},
+				},
+			}
+			processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, componenttest.NewNopTelemetrySettings())
+			require.NoError(t, err)
+
+			logs := plog.NewLogs()
+			records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+			records.AppendEmpty().Body().SetStr(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "vulnerabilities": [
+				{"id": "CVE-2023-45288", "analysis": {"state": "not_affected"}, "affects": [{"ref": "pkg:golang/golang.org/x/net@v0.15.0"}]}]}`)
+
+			result, err := processor.processLogs(context.Background(), logs)
+			require.NoError(t, err)
+
+			outRecords := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+			require.Equal(t, 1, outRecords.Len())
+			vex := outRecords.At(0).Attributes().AsRaw()
+			assert.Equal(t, processing.SeverityInformational, vex["finding.severity"])
+			assert.NotContains(t, vex, attrDeadLetterError, "the embedded schema accepts annotated VEX findings")
+		})
+	}
+}
+
+func TestProcessLogs_Benchmark(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+	config := &Config{
+		Processors: ProcessorConfig{
+			VulnScan:  vulnscan.Config{Enabled: true},
+			Benchmark: benchmark.Config{Enabled: true, StatusFilter: []string{"fail"}},
+		},
+		Output: OutputConfig{
+			Validation: validation.Config{Enabled: true, Action: validation.ActionDeadLetter},
+		},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, tel.NewTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	resourceLogs := logs.ResourceLogs().AppendEmpty()
+	resourceLogs.Resource().Attributes().PutStr("k8s.node.name", "worker-1")
+	records := resourceLogs.ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().Body().SetStr(`{"Controls": [{"id": "4", "version": "cis-1.8", "node_type": "node", "tests": [
+		{"section": "4.2", "desc": "Kubelet", "results": [
+			{"test_number": "4.2.1", "test_desc": "Ensure that the --anonymous-auth argument is set to false (Automated)", "status": "FAIL"},
+			{"test_number": "4.2.2", "test_desc": "Ensure that the --authorization-mode argument is not set to AlwaysAllow (Automated)", "status": "PASS"}
+		]}]}], "Totals": {"total_pass": 1, "total_fail": 1}}`)
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	outRecords := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, outRecords.Len())
+
+	finding := outRecords.At(0).Attributes().AsRaw()
+	assert.Equal(t, "COMPLIANCE_FINDING", finding["event.type"])
+	assert.Equal(t, "4.2.1", finding["compliance.control"])
+	assert.Equal(t, []interface{}{"CIS"}, finding["compliance.standards"])
+	assert.Equal(t, "NON_COMPLIANT", finding["compliance.status"])
+	assert.Equal(t, "worker-1", finding["k8s.node.name"])
+	assert.NotContains(t, finding, attrDeadLetterError, "the embedded schema accepts benchmark findings")
+
+	kubeBench := attribute.String(attrProcessor, processorBenchmark)
+	kind := attribute.String(attrReportKind, benchmark.FormatKubeBench)
+	assert.Equal(t, int64(1), sumByAttributes(t, tel, metricIncomingLogs)[attrSet(kubeBench, kind)])
+	assert.Equal(t, map[attribute.Distinct]int64{
+		attrSet(kubeBench, kind, attribute.String(attrReason, benchmark.FilterReasonStatus)): 1,
+	}, sumByAttributes(t, tel, metricDroppedLogs))
+}
+
+func TestProcessLogs_Gatekeeper(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true},
+			Gatekeeper:  gatekeeper.Config{Enabled: true, EnforcementActionFilter: []string{"deny"}},
+		},
+		Output: OutputConfig{
+			Validation: validation.Config{Enabled: true, Action: validation.ActionDeadLetter},
+		},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, tel.NewTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	logRecord := records.AppendEmpty()
+	logRecord.Attributes().PutStr("k8s.cluster.name", "prod-eu-1")
+	require.NoError(t, logRecord.Body().SetEmptyMap().FromRaw(map[string]interface{}{
+		"type": "MODIFIED",
+		"object": map[string]interface{}{
+			"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+			"kind":       "K8sPSPPrivilegedContainer",
+			"metadata":   map[string]interface{}{"name": "psp-privileged-container"},
+			"status": map[string]interface{}{
+				"auditTimestamp":  "2025-10-18T12:00:00Z",
+				"totalViolations": 2,
+				"violations": []interface{}{
+					map[string]interface{}{
+						"enforcementAction": "deny", "kind": "Pod", "namespace": "dev", "name": "debug-5f7b8c9d6-q8w7e",
+						"message": "Privileged container is not allowed: shell, securityContext: {\"privileged\": true}",
+					},
+					map[string]interface{}{
+						"enforcementAction": "dryrun", "kind": "Pod", "namespace": "dev", "name": "tools",
+						"message": "Privileged container is not allowed: tools, securityContext: {\"privileged\": true}",
+					},
+				},
+			},
+		},
+	}))
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	outRecords := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, outRecords.Len())
+
+	finding := outRecords.At(0).Attributes().AsRaw()
+	assert.Equal(t, "COMPLIANCE_FINDING", finding["event.type"])
+	assert.Equal(t, "psp-privileged-container", finding["compliance.control"])
+	assert.Equal(t, "K8sPSPPrivilegedContainer", finding["finding.type"])
+	assert.Equal(t, "deny", finding["action.type"])
+	assert.Equal(t, "debug-5f7b8c9d6-q8w7e", finding["k8s.pod.name"])
+	assert.Equal(t, "debug", finding["k8s.deployment.name"])
+	assert.Equal(t, "prod-eu-1", finding["k8s.cluster.name"])
+	assert.NotContains(t, finding, attrDeadLetterError, "the embedded schema accepts Gatekeeper findings")
+
+	processorAttr := attribute.String(attrProcessor, processorGatekeeper)
+	kind := attribute.String(attrReportKind, gatekeeper.Kind)
+	assert.Equal(t, int64(1), sumByAttributes(t, tel, metricIncomingLogs)[attrSet(processorAttr, kind)])
+	assert.Equal(t, map[attribute.Distinct]int64{
+		attrSet(processorAttr, kind, attribute.String(attrReason, gatekeeper.FilterReasonEnforcementAction)): 1,
+	}, sumByAttributes(t, tel, metricDroppedLogs))
+}
+
+func TestProcessLogs_Runtime(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+	config := &Config{
+		Processors: ProcessorConfig{
+			CEF:     ceflog.Config{Enabled: true},
+			Runtime: runtimelog.Config{Enabled: true},
+		},
+		Output: OutputConfig{
+			Validation: validation.Config{Enabled: true, Action: validation.ActionDeadLetter},
+		},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, tel.NewTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	// A Tetragon policy match, and a process execution matched by no policy
+	records.AppendEmpty().Body().SetStr(`{"process_kprobe": {"process": {"pid": 52699, "binary": "/usr/bin/cat", ` +
+		`"arguments": "/etc/shadow", "pod": {"namespace": "default", "name": "xwing-6d9bd5c8b9-7kq2p", ` +
+		`"container": {"id": "containerd://551e161c47d8", "name": "spaceship"}}}, ` +
+		`"parent": {"pid": 52654, "binary": "/bin/bash"}, "function_name": "security_file_permission", ` +
+		`"policy_name": "file-monitoring", "action": "KPROBE_ACTION_POST"}, "node_name": "kind-control-plane", ` +
+		`"time": "2025-10-18T12:00:01Z"}`)
+	records.AppendEmpty().Body().SetStr(`{"process_exec": {"process": {"pid": 52699, "binary": "/usr/bin/cat"}}, ` +
+		`"time": "2025-10-18T12:00:01Z"}`)
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	outRecords := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, outRecords.Len(), "the process execution passes through unchanged")
+	assert.Equal(t, 0, outRecords.At(1).Attributes().Len())
+
+	detection := outRecords.At(0).Attributes().AsRaw()
+	assert.Equal(t, "DETECTION_FINDING", detection["event.type"])
+	assert.Equal(t, "Tetragon", detection["product.name"])
+	assert.Equal(t, "file-monitoring", detection["finding.type"])
+	assert.Equal(t, "/usr/bin/cat /etc/shadow", detection["process.command_line"])
+	assert.Equal(t, "/bin/bash", detection["process.parent.executable.path"])
+	assert.Equal(t, "551e161c47d8", detection["container.id"])
+	assert.Equal(t, "xwing-6d9bd5c8b9-7kq2p", detection["k8s.pod.name"])
+	assert.NotContains(t, detection, attrDeadLetterError, "the embedded schema accepts runtime detections")
+
+	processorAttr := attribute.String(attrProcessor, processorRuntime)
+	kind := attribute.String(attrReportKind, runtimelog.FormatTetragon)
+	assert.Equal(t, int64(2), sumByAttributes(t, tel, metricIncomingLogs)[attrSet(processorAttr, kind)])
+	assert.Equal(t, map[attribute.Distinct]int64{
+		attrSet(processorAttr, kind, attribute.String(attrReason, runtimelog.FilterReasonNoPolicy)): 1,
+	}, sumByAttributes(t, tel, metricDroppedLogs))
+}
+
+func TestProcessLogs_Profile(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true},
+			CEF:         ceflog.Config{Enabled: true},
+		},
+		Output: OutputConfig{
+			Profile:    profile.SplunkCIM,
+			Validation: validation.Config{Enabled: true, Action: validation.ActionDeadLetter},
+		},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, tel.NewTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	report := records.AppendEmpty()
+	report.Attributes().PutStr("kind", "Report")
+	report.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	report.Attributes().PutStr("scope.name", "app-1")
+	report.Attributes().PutEmptySlice("results").AppendEmpty().
+		SetStr(`{"policy": "p1", "rule": "r1", "result": "fail", "severity": "high"}`)
+	records.AppendEmpty().Body().SetStr("CEF:0|Palo Alto Networks|PAN-OS|10.2|THREAT|Port scan detected|8|" +
+		"src=10.0.0.5 dst=192.168.1.20 suser=alice act=blocked outcome=failure")
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	outRecords := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, outRecords.Len())
+
+	// Both security events are laid out as CIM alerts, valid against the embedded CIM schema
+	compliance := outRecords.At(0).Attributes().AsRaw()
+	assert.Equal(t, "app-1", compliance["dest"])
+	assert.Equal(t, "r1", compliance["signature_id"])
+	assert.Equal(t, "high", compliance["severity"])
+	assert.NotContains(t, compliance, "event.id")
+	assert.NotContains(t, compliance, attrDeadLetterError)
+
+	detection := outRecords.At(1).Attributes().AsRaw()
+	assert.Equal(t, "alice", detection["user"])
+	assert.Equal(t, "192.168.1.20", detection["dest"])
+	assert.NotContains(t, detection, attrDeadLetterError)
+
+	// The telemetry severity and compliance status are read from the profile attributes
+	outgoing := sumByAttributes(t, tel, metricOutgoingLogs)
+	assert.Equal(t, int64(1), outgoing[attrSet(
+		attribute.String(attrProcessor, processorOpenReports),
+		attribute.String(attrComplianceStatus, "NON_COMPLIANT"),
+		attribute.String(attrSeverity, "high"),
+	)])
+	assert.Equal(t, int64(1), outgoing[attrSet(
+		attribute.String(attrProcessor, processorCEF),
+		attribute.String(attrReportKind, "cef"),
+		attribute.String(attrSeverity, "high"),
+	)])
+}
+
+func TestProcessLogs_ProfileTargets(t *testing.T) {
+	// The security events encoded by output.siem identify their target by the resource custom extension
+	encoded, err := os.ReadFile(filepath.Join("internal", "cef", "testdata", "compliance.cef"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantDest string
+	}{
+		{
+			name: "kube-bench without node",
+			body: `{"Controls": [{"id": "1", "version": "cis-1.8", "node_type": "master", "tests": [{"section": "1.1", "results": [
+				{"test_number": "1.1.1", "test_desc": "Ensure that the API server pod specification file permissions are set", "status": "FAIL"}
+			]}]}]}`,
+		},
+		{
+			name: "CEF without destination",
+			body: "CEF:0|Acme|Sentinel|2.1|auth-100|Brute force attempt|Low|suser=alice",
+		},
+		{
+			name:     "encoded CEF",
+			body:     string(encoded),
+			wantDest: "app-7d9f8b6c5d-x2k4p",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Processors: ProcessorConfig{
+					CEF:       ceflog.Config{Enabled: true},
+					Benchmark: benchmark.Config{Enabled: true},
+				},
+				Output: OutputConfig{
+					Profile:    profile.SplunkCIM,
+					Validation: validation.Config{Enabled: true, Action: validation.ActionDeadLetter},
+				},
+			}
+			processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, componenttest.NewNopTelemetrySettings())
+			require.NoError(t, err)
+
+			logs := plog.NewLogs()
+			logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr(tt.body)
+			result, err := processor.processLogs(context.Background(), logs)
+			require.NoError(t, err)
+
+			outRecords := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+			require.Equal(t, 1, outRecords.Len())
+			attrs := outRecords.At(0).Attributes().AsRaw()
+			assert.Contains(t, attrs, "signature_id")
+			assert.NotContains(t, attrs, attrDeadLetterError, "the embedded CIM schema accepts events without dest")
+			if tt.wantDest != "" {
+				assert.Equal(t, tt.wantDest, attrs["dest"])
+			} else {
+				assert.NotContains(t, attrs, "dest")
+			}
+		})
+	}
+}
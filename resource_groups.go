@@ -0,0 +1,107 @@
+package securityevent
+
+import (
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/openreports"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/semconv"
+)
+
+// resourceGroupKey identifies the ResourceLogs holding the security events of a scoped object
+type resourceGroupKey struct {
+	resource int
+	object   string
+}
+
+// scopeGroupKey identifies the ScopeLogs of a ResourceLogs group, one per instrumentation scope of the input
+type scopeGroupKey struct {
+	group resourceGroupKey
+	scope int
+}
+
+// scopePosition is the position of a ScopeLogs in the incoming logs
+type scopePosition struct {
+	resource int
+	scope    int
+}
+
+// resourceGroups collects the security events of a batch into one ResourceLogs per scoped Kubernetes
+// object, with the Kubernetes fields of the object set as resource attributes
+type resourceGroups struct {
+	// attributeNames is the semconv attribute name mode of the resource attributes
+	attributeNames string
+
+	logs    plog.Logs
+	groups  map[resourceGroupKey]plog.ResourceLogs
+	scopes  map[scopeGroupKey]plog.ScopeLogs
+	emptied map[scopePosition]bool
+}
+
+// newResourceGroups creates an empty set of resource groups
+func newResourceGroups(attributeNames string) *resourceGroups {
+	return &resourceGroups{
+		attributeNames: attributeNames,
+		logs:           plog.NewLogs(),
+		groups:         make(map[resourceGroupKey]plog.ResourceLogs),
+		scopes:         make(map[scopeGroupKey]plog.ScopeLogs),
+		emptied:        make(map[scopePosition]bool),
+	}
+}
+
+// logRecords returns the log records receiving the security events of a report found at
+// resource i, scope j of the incoming logs, creating its resource group on first use
+func (g *resourceGroups) logRecords(i, j int, resourceLog plog.ResourceLogs, scopeLog plog.ScopeLogs, report *plog.LogRecord) plog.LogRecordSlice {
+	key := resourceGroupKey{resource: i, object: openreports.ObjectKey(report)}
+	group, exists := g.groups[key]
+	if !exists {
+		group = g.logs.ResourceLogs().AppendEmpty()
+		resourceLog.Resource().CopyTo(group.Resource())
+		group.SetSchemaUrl(resourceLog.SchemaUrl())
+		openreports.ResourceAttributes(report, group.Resource().Attributes())
+		semconv.Apply(group.Resource().Attributes(), g.attributeNames)
+		g.groups[key] = group
+	}
+
+	scopeKey := scopeGroupKey{group: key, scope: j}
+	scope, exists := g.scopes[scopeKey]
+	if !exists {
+		scope = group.ScopeLogs().AppendEmpty()
+		scopeLog.Scope().CopyTo(scope.Scope())
+		scope.SetSchemaUrl(scopeLog.SchemaUrl())
+		g.scopes[scopeKey] = scope
+	}
+	return scope.LogRecords()
+}
+
+// markEmptied records that all log records of the scope at resource i, scope j were moved to groups
+func (g *resourceGroups) markEmptied(i, j int) {
+	g.emptied[scopePosition{resource: i, scope: j}] = true
+}
+
+// moveTo removes the scopes emptied by the grouping from ld and appends the non-empty groups to it
+func (g *resourceGroups) moveTo(ld plog.Logs) {
+	if len(g.emptied) > 0 {
+		i := -1
+		ld.ResourceLogs().RemoveIf(func(resourceLog plog.ResourceLogs) bool {
+			i++
+			j := -1
+			removed := false
+			resourceLog.ScopeLogs().RemoveIf(func(plog.ScopeLogs) bool {
+				j++
+				emptied := g.emptied[scopePosition{resource: i, scope: j}]
+				removed = removed || emptied
+				return emptied
+			})
+			return removed && resourceLog.ScopeLogs().Len() == 0
+		})
+	}
+
+	// Reports that did not produce security events leave empty groups behind
+	g.logs.ResourceLogs().RemoveIf(func(group plog.ResourceLogs) bool {
+		group.ScopeLogs().RemoveIf(func(scope plog.ScopeLogs) bool {
+			return scope.LogRecords().Len() == 0
+		})
+		return group.ScopeLogs().Len() == 0
+	})
+	g.logs.ResourceLogs().MoveAndAppendTo(ld.ResourceLogs())
+}
@@ -0,0 +1,176 @@
+package securityevent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/openreports"
+)
+
+// appendPodReport appends a report scoped to the pod with the given uid and one result per status
+func appendPodReport(records plog.LogRecordSlice, podName, podUID string, statuses ...string) {
+	report := records.AppendEmpty()
+	report.Attributes().PutStr("kind", "Report")
+	report.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+	report.Attributes().PutStr("scope.kind", "Pod")
+	report.Attributes().PutStr("scope.name", podName)
+	report.Attributes().PutStr("scope.namespace", "default")
+	report.Attributes().PutStr("scope.uid", podUID)
+	report.Attributes().PutStr("k8s.node.name", "node-1")
+	results := report.Attributes().PutEmptySlice("results")
+	for _, status := range statuses {
+		results.AppendEmpty().SetStr(`{"policy": "p1", "rule": "r1", "result": "` + status + `"}`)
+	}
+}
+
+func newGroupByResourceProcessor(t *testing.T) *securityEventProcessor {
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true, StatusFilter: []string{"fail"}},
+		},
+		Output: OutputConfig{GroupByResource: true},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	return processor
+}
+
+func TestProcessLogs_GroupByResource(t *testing.T) {
+	processor := newGroupByResourceProcessor(t)
+
+	logs := plog.NewLogs()
+	resourceLog := logs.ResourceLogs().AppendEmpty()
+	resourceLog.Resource().Attributes().PutStr("k8s.cluster.name", "prod")
+	scopeLog := resourceLog.ScopeLogs().AppendEmpty()
+	scopeLog.Scope().SetName("k8sobjects")
+	records := scopeLog.LogRecords()
+
+	records.AppendEmpty().Attributes().PutStr("kind", "Pod")
+	appendPodReport(records, "app-7d9f8b6c5d-x2k4p", "uid-a", "fail", "fail")
+	appendPodReport(records, "app-7d9f8b6c5d-x2k4p", "uid-a", "fail", "pass")
+	appendPodReport(records, "db-0", "uid-b", "fail")
+	appendPodReport(records, "web-0", "uid-c", "pass")
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+	require.Equal(t, 3, result.ResourceLogs().Len())
+
+	// The original resource keeps the records that are not security events
+	original := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, original.Len())
+	kind, _ := original.At(0).Attributes().Get("kind")
+	assert.Equal(t, "Pod", kind.Str())
+	name, _ := original.At(1).Attributes().Get("scope.name")
+	assert.Equal(t, "web-0", name.Str())
+
+	// Each scoped object gets its own resource with the Kubernetes fields as resource attributes
+	for i, want := range []struct {
+		podName string
+		events  int
+	}{
+		{podName: "app-7d9f8b6c5d-x2k4p", events: 3},
+		{podName: "db-0", events: 1},
+	} {
+		group := result.ResourceLogs().At(i + 1)
+		resourceAttrs := group.Resource().Attributes()
+		cluster, _ := resourceAttrs.Get("k8s.cluster.name")
+		assert.Equal(t, "prod", cluster.Str())
+		podName, _ := resourceAttrs.Get("k8s.pod.name")
+		assert.Equal(t, want.podName, podName.Str())
+		namespace, _ := resourceAttrs.Get("k8s.namespace.name")
+		assert.Equal(t, "default", namespace.Str())
+		node, _ := resourceAttrs.Get("k8s.node.name")
+		assert.Equal(t, "node-1", node.Str())
+
+		require.Equal(t, 1, group.ScopeLogs().Len())
+		assert.Equal(t, "k8sobjects", group.ScopeLogs().At(0).Scope().Name())
+		events := group.ScopeLogs().At(0).LogRecords()
+		require.Equal(t, want.events, events.Len())
+		for e := 0; e < events.Len(); e++ {
+			_, exists := events.At(e).Attributes().Get("k8s.pod.name")
+			assert.False(t, exists, "Kubernetes fields should only be set on the resource")
+			_, exists = events.At(e).Attributes().Get("finding.title")
+			assert.True(t, exists)
+		}
+	}
+}
+
+func TestProcessLogs_GroupByResourceRemovesEmptiedScopes(t *testing.T) {
+	processor := newGroupByResourceProcessor(t)
+
+	logs := plog.NewLogs()
+	reportsOnly := logs.ResourceLogs().AppendEmpty()
+	appendPodReport(reportsOnly.ScopeLogs().AppendEmpty().LogRecords(), "db-0", "uid-b", "fail")
+
+	// Empty input scopes are left untouched
+	other := logs.ResourceLogs().AppendEmpty()
+	other.Resource().Attributes().PutStr("service.name", "other")
+	other.ScopeLogs().AppendEmpty()
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.ResourceLogs().Len())
+
+	serviceName, _ := result.ResourceLogs().At(0).Resource().Attributes().Get("service.name")
+	assert.Equal(t, "other", serviceName.Str())
+	assert.Equal(t, 1, result.ResourceLogs().At(0).ScopeLogs().Len())
+
+	podName, _ := result.ResourceLogs().At(1).Resource().Attributes().Get("k8s.pod.name")
+	assert.Equal(t, "db-0", podName.Str())
+	assert.Equal(t, 1, result.ResourceLogs().At(1).ScopeLogs().At(0).LogRecords().Len())
+}
+
+func TestProcessLogs_WithoutGroupByResourceKeepsK8sFieldsOnEvents(t *testing.T) {
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true},
+		},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	appendPodReport(logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords(), "db-0", "uid-b", "fail")
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.ResourceLogs().Len())
+	podName, exists := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get("k8s.pod.name")
+	require.True(t, exists)
+	assert.Equal(t, "db-0", podName.Str())
+}
+
+func TestProcessLogs_GroupByResourceSemconv(t *testing.T) {
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true},
+		},
+		Output: OutputConfig{GroupByResource: true, AttributeNames: "semconv"},
+	}
+	processor, err := newSecurityEventProcessor(zaptest.NewLogger(t), config, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	appendPodReport(logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords(), "app-7d9f8b6c5d-x2k4p", "uid-a", "fail")
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.ResourceLogs().Len())
+
+	resource := result.ResourceLogs().At(0).Resource().Attributes().AsRaw()
+	assert.Equal(t, "uid-a", resource["k8s.pod.uid"])
+	assert.Equal(t, "app", resource["k8s.deployment.name"])
+	assert.NotContains(t, resource, "k8s.resource.uid")
+	assert.NotContains(t, resource, "k8s.workload.kind")
+
+	event := result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "security.compliance_finding", event.EventName())
+	_, exists := event.Attributes().Get("event.name")
+	assert.False(t, exists)
+}
@@ -2,14 +2,27 @@
 // various log formats (e.g., OpenReports) into standardized security events.
 package schema
 
+import "strings"
+
+// Version is the version of the SecurityEvent model, carried by the JSON body as schema_version
+// It changes whenever a field is added, renamed or removed
+const Version = "1.5"
+
 // SecurityEvent represents a standardized security event log entry
-// This schema will be populated based on the examples provided
+// Transformers build a SecurityEvent from their source format; the serializer in this package
+// turns it into log record attributes or a structured JSON body
 type SecurityEvent struct {
-	// EventType indicates the type of security event
-	EventType string `json:"event_type"`
+	// SchemaVersion is the version of the model the event was built with
+	SchemaVersion string `json:"schema_version"`
+
+	// Event identifies and classifies the security event
+	Event Event `json:"event"`
 
-	// Timestamp when the event occurred
-	Timestamp string `json:"timestamp"`
+	// Timestamp when the event occurred, formatted as RFC3339Nano
+	Timestamp string `json:"timestamp,omitempty"`
+
+	// Message is the human readable message of the event, used as the log body
+	Message string `json:"message"`
 
 	// Source of the event
 	Source Source `json:"source"`
@@ -23,10 +36,55 @@ type SecurityEvent struct {
 	// Result of the action
 	Result Result `json:"result"`
 
+	// RiskScore is the risk of the event, from 0.0 to 10.0
+	RiskScore float64 `json:"risk_score"`
+
+	// Finding describes the security finding reported by the event
+	Finding Finding `json:"finding"`
+
+	// Compliance describes the compliance check behind the finding
+	Compliance Compliance `json:"compliance"`
+
+	// Vulnerability is set for findings about a known vulnerability
+	Vulnerability *Vulnerability `json:"vulnerability,omitempty"`
+
+	// SupplyChain is set for findings about the signature or attestations of a container image
+	SupplyChain *SupplyChain `json:"supply_chain,omitempty"`
+
+	// Process is set for runtime detections, the process that triggered them
+	Process *Process `json:"process,omitempty"`
+
+	// Container is set for runtime detections in a container
+	Container *Container `json:"container,omitempty"`
+
+	// Threat is set for findings mapped to MITRE ATT&CK techniques
+	Threat *Threat `json:"threat,omitempty"`
+
 	// Additional metadata
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// Event identifies and classifies a security event
+type Event struct {
+	// ID is the unique identifier of the event
+	ID string `json:"id"`
+
+	// Version of the event semantics
+	Version string `json:"version"`
+
+	// Category of the event (e.g., "COMPLIANCE")
+	Category string `json:"category"`
+
+	// Name is the display name of the event
+	Name string `json:"name"`
+
+	// Type of the event (e.g., "COMPLIANCE_FINDING")
+	Type string `json:"type"`
+
+	// Description of the event
+	Description string `json:"description"`
+}
+
 // Source represents the source of the security event
 type Source struct {
 	// User who initiated the event
@@ -35,8 +93,14 @@ type Source struct {
 	// IP address of the source
 	IPAddress string `json:"ip_address,omitempty"`
 
-	// Application/service name
-	Application string `json:"application,omitempty"`
+	// Application/service name, i.e. the product reporting the event
+	Application string `json:"application"`
+
+	// Vendor of the application
+	Vendor string `json:"vendor"`
+
+	// Version of the application
+	Version string `json:"version,omitempty"`
 
 	// Additional source fields
 	Additional map[string]interface{} `json:"additional,omitempty"`
@@ -44,12 +108,25 @@ type Source struct {
 
 // Target represents the target of the security event
 type Target struct {
+	// ID of the resource
+	ID string `json:"id,omitempty"`
+
 	// Resource being accessed
 	Resource string `json:"resource,omitempty"`
 
 	// Resource type
 	ResourceType string `json:"resource_type,omitempty"`
 
+	// IP address of the target
+	IPAddress string `json:"ip_address,omitempty"`
+
+	// EntityType is the Smartscape entity type of the resource (e.g., "K8S_POD")
+	EntityType string `json:"entity_type,omitempty"`
+
+	// Kubernetes holds the k8s.* fields of the resource, keyed by attribute name
+	// Values are raw attribute values: string, int64, float64, bool, []interface{} or map[string]interface{}
+	Kubernetes map[string]interface{} `json:"kubernetes,omitempty"`
+
 	// Additional target fields
 	Additional map[string]interface{} `json:"additional,omitempty"`
 }
@@ -80,3 +157,216 @@ type Result struct {
 	// Additional result fields
 	Additional map[string]interface{} `json:"additional,omitempty"`
 }
+
+// Finding describes a security finding
+type Finding struct {
+	// ID is the unique identifier of the finding
+	ID string `json:"id"`
+
+	// Title of the finding
+	Title string `json:"title"`
+
+	// Description of the finding
+	Description string `json:"description"`
+
+	// Severity of the finding (CRITICAL, HIGH, MEDIUM or LOW)
+	Severity string `json:"severity,omitempty"`
+
+	// Type of the finding (e.g., the policy name)
+	Type string `json:"type,omitempty"`
+
+	// URL with details about the finding
+	URL string `json:"url"`
+
+	// Location is set for findings in source code or configuration files
+	Location *Location `json:"location,omitempty"`
+}
+
+// Location is the position of a finding in a file
+type Location struct {
+	// FilePath is the path or URI of the file
+	FilePath string `json:"file_path"`
+
+	// Line is the 1-based line number, 0 if unknown
+	Line int `json:"line,omitempty"`
+
+	// Column is the 1-based column number, 0 if unknown
+	Column int `json:"column,omitempty"`
+}
+
+// Compliance describes the compliance check behind a finding
+type Compliance struct {
+	// Control is the control or rule that was checked
+	Control string `json:"control,omitempty"`
+
+	// Requirement is the requirement the control belongs to, when no framework controls are mapped
+	Requirement string `json:"requirement,omitempty"`
+
+	// Standard is the standard the control belongs to, when no framework controls are mapped
+	Standard string `json:"standard,omitempty"`
+
+	// Requirements are the framework controls mapped to the finding (e.g., "CIS 5.2.2")
+	// They take precedence over Requirement
+	Requirements []string `json:"requirements,omitempty"`
+
+	// Standards are the frameworks of the mapped controls (e.g., "CIS")
+	// They take precedence over Standard
+	Standards []string `json:"standards,omitempty"`
+
+	// Status of the check (COMPLIANT or NON_COMPLIANT)
+	Status string `json:"status"`
+}
+
+// RequirementList returns the mapped framework controls, or the single requirement, or nil
+func (c *Compliance) RequirementList() []string {
+	if len(c.Requirements) > 0 {
+		return c.Requirements
+	}
+	if c.Requirement != "" {
+		return []string{c.Requirement}
+	}
+	return nil
+}
+
+// StandardList returns the frameworks of the mapped controls, or the single standard, or nil
+func (c *Compliance) StandardList() []string {
+	if len(c.Standards) > 0 {
+		return c.Standards
+	}
+	if c.Standard != "" {
+		return []string{c.Standard}
+	}
+	return nil
+}
+
+// Vulnerability describes the vulnerability behind a finding
+type Vulnerability struct {
+	// ID of the vulnerability (e.g., "CVE-2024-3094")
+	ID string `json:"id"`
+
+	// EPSS is the exploit prediction score of the vulnerability, if known
+	EPSS *EPSS `json:"epss,omitempty"`
+
+	// KEV reports whether the vulnerability is in the CISA Known Exploited Vulnerabilities catalog
+	KEV bool `json:"kev,omitempty"`
+
+	// Package is the affected software package, if known
+	Package *Package `json:"package,omitempty"`
+
+	// CVSS is the CVSS rating of the vulnerability, if known
+	CVSS *CVSS `json:"cvss,omitempty"`
+
+	// FixState is the remediation status of the package (fixed, not_fixed, wont_fix or unknown)
+	FixState string `json:"fix_state,omitempty"`
+
+	// FixedVersions are the package versions that fix the vulnerability
+	FixedVersions []string `json:"fixed_versions,omitempty"`
+
+	// VEXState is the exploitability of the vulnerability stated by a VEX analysis (e.g., "not_affected")
+	VEXState string `json:"vex_state,omitempty"`
+}
+
+// Package identifies a software package
+type Package struct {
+	// Name of the package
+	Name string `json:"name"`
+
+	// Version of the package
+	Version string `json:"version,omitempty"`
+
+	// PURL is the package URL (e.g., "pkg:deb/ubuntu/openssl@3.0.2")
+	PURL string `json:"purl,omitempty"`
+
+	// Type of the package (e.g., "deb", "go-module")
+	Type string `json:"type,omitempty"`
+}
+
+// CVSS is a Common Vulnerability Scoring System rating
+type CVSS struct {
+	// Version of CVSS (e.g., "3.1")
+	Version string `json:"version,omitempty"`
+
+	// Vector is the CVSS vector string
+	Vector string `json:"vector,omitempty"`
+
+	// BaseScore is the base score, from 0.0 to 10.0
+	BaseScore float64 `json:"base_score"`
+}
+
+// EPSS is an Exploit Prediction Scoring System score
+type EPSS struct {
+	// Score is the probability of exploitation in the next 30 days
+	Score float64 `json:"score"`
+
+	// Percentile of the score among all scored vulnerabilities
+	Percentile float64 `json:"percentile"`
+}
+
+// SupplyChain describes the verification of the signature or attestations of a container image
+type SupplyChain struct {
+	// Image is the verified image reference, without digest (e.g., "ghcr.io/org/app:1.0")
+	Image string `json:"image,omitempty"`
+
+	// Digest of the image (e.g., "sha256:...")
+	Digest string `json:"digest,omitempty"`
+
+	// Signer is the expected signer: the certificate identity of keyless signatures, or the key
+	Signer string `json:"signer,omitempty"`
+
+	// Issuer is the expected OIDC issuer of the certificate of keyless signatures
+	Issuer string `json:"issuer,omitempty"`
+
+	// AttestationType is the predicate type of a verified attestation (e.g., "https://slsa.dev/provenance/v1"),
+	// empty for signatures
+	AttestationType string `json:"attestation_type,omitempty"`
+}
+
+// Process describes a process of a runtime detection
+type Process struct {
+	// PID is the process ID on the host, 0 if unknown
+	PID int64 `json:"pid,omitempty"`
+
+	// Binary is the path of the executable (e.g., "/usr/bin/curl")
+	Binary string `json:"binary,omitempty"`
+
+	// Arguments are the command line arguments, without the binary
+	Arguments string `json:"arguments,omitempty"`
+
+	// Parent is the parent process, if known
+	Parent *Process `json:"parent,omitempty"`
+}
+
+// CommandLine returns the binary followed by the arguments of the process
+func (p *Process) CommandLine() string {
+	if p.Arguments == "" {
+		return p.Binary
+	}
+	return strings.TrimSpace(p.Binary + " " + p.Arguments)
+}
+
+// Container describes the container a runtime detection occurred in
+type Container struct {
+	// ID of the container, without runtime prefix
+	ID string `json:"id,omitempty"`
+
+	// Name of the container in its pod
+	Name string `json:"name,omitempty"`
+
+	// Image reference of the container (e.g., "docker.io/library/nginx:1.27")
+	Image string `json:"image,omitempty"`
+}
+
+// Threat describes the adversary behavior a finding is associated with
+type Threat struct {
+	// Framework of the techniques (e.g., "MITRE ATT&CK")
+	Framework string `json:"framework"`
+
+	// TechniqueIDs are the technique identifiers (e.g., "T1611")
+	TechniqueIDs []string `json:"technique_ids"`
+
+	// TechniqueNames are the technique names, in the order of TechniqueIDs
+	TechniqueNames []string `json:"technique_names"`
+
+	// TacticNames are the distinct tactic names of the techniques
+	TacticNames []string `json:"tactic_names"`
+}
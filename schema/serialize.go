@@ -0,0 +1,392 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Attribute names of the security event fields
+const (
+	AttrEventID                  = "event.id"
+	AttrEventVersion             = "event.version"
+	AttrEventCategory            = "event.category"
+	AttrEventName                = "event.name"
+	AttrEventType                = "event.type"
+	AttrEventDescription         = "event.description"
+	AttrProductName              = "product.name"
+	AttrProductVendor            = "product.vendor"
+	AttrProductVersion           = "product.version"
+	AttrSmartscapeType           = "smartscape.type"
+	AttrUserName                 = "user.name"
+	AttrSourceAddress            = "source.address"
+	AttrDestinationAddress       = "destination.address"
+	AttrActionType               = "action.type"
+	AttrResultStatus             = "result.status"
+	AttrVulnerabilityID          = "vulnerability.id"
+	AttrVulnerabilityEPSSScore   = "vulnerability.epss.score"
+	AttrVulnerabilityEPSSPercent = "vulnerability.epss.percentile"
+	AttrVulnerabilityKEV         = "vulnerability.kev"
+	AttrVulnerabilityCVSSVersion = "vulnerability.cvss.version"
+	AttrVulnerabilityCVSSVector  = "vulnerability.cvss.vector"
+	AttrVulnerabilityCVSSScore   = "vulnerability.cvss.base_score"
+	AttrVulnerabilityFixState    = "vulnerability.fix.state"
+	AttrVulnerabilityFixVersions = "vulnerability.fix.versions"
+	AttrVulnerabilityVEXState    = "vulnerability.vex.state"
+	AttrPackageName              = "software_component.name"
+	AttrPackageVersion           = "software_component.version"
+	AttrPackagePURL              = "software_component.purl"
+	AttrPackageType              = "software_component.type"
+	AttrImageName                = "supply_chain.image.name"
+	AttrImageDigest              = "supply_chain.image.digest"
+	AttrSignerIdentity           = "supply_chain.signer.identity"
+	AttrSignerIssuer             = "supply_chain.signer.issuer"
+	AttrAttestationType          = "supply_chain.attestation.type"
+	AttrProcessPID               = "process.pid"
+	AttrProcessExecutable        = "process.executable.path"
+	AttrProcessCommandLine       = "process.command_line"
+	AttrParentPID                = "process.parent_pid"
+	AttrParentExecutable         = "process.parent.executable.path"
+	AttrParentCommandLine        = "process.parent.command_line"
+	AttrContainerID              = "container.id"
+	AttrContainerName            = "container.name"
+	AttrContainerImage           = "container.image.name"
+	AttrRiskScore                = "dt.security.risk.score"
+	AttrObjectID                 = "object.id"
+	AttrObjectType               = "object.type"
+	AttrFindingDescription       = "finding.description"
+	AttrFindingID                = "finding.id"
+	AttrFindingSeverity          = "finding.severity"
+	AttrFindingTimeCreated       = "finding.time.created"
+	AttrFindingTitle             = "finding.title"
+	AttrFindingType              = "finding.type"
+	AttrFindingURL               = "finding.url"
+	AttrCodeFilePath             = "code.file.path"
+	AttrCodeLineNumber           = "code.line.number"
+	AttrCodeColumnNumber         = "code.column.number"
+	AttrComplianceControl        = "compliance.control"
+	AttrComplianceRequirements   = "compliance.requirements"
+	AttrComplianceStandards      = "compliance.standards"
+	AttrComplianceStatus         = "compliance.status"
+	AttrThreatFramework          = "threat.framework"
+	AttrThreatTechniqueID        = "threat.technique.id"
+	AttrThreatTechniqueName      = "threat.technique.name"
+	AttrThreatTacticName         = "threat.tactic.name"
+)
+
+// Body formats of the security event log records
+const (
+	// BodyFormatMessage sets the body to the event message
+	BodyFormatMessage = "message"
+	// BodyFormatMap sets the body to a map holding all the event fields
+	BodyFormatMap = "map"
+	// BodyFormatJSON sets the body to the event serialized as a JSON object string
+	BodyFormatJSON = "json"
+)
+
+// ValidBodyFormat reports whether format is a known body format
+func ValidBodyFormat(format string) bool {
+	return format == BodyFormatMessage || format == BodyFormatMap || format == BodyFormatJSON
+}
+
+// CopyTo writes the event into a log record: its fields as attributes and the body in the given format
+// An empty format is BodyFormatMessage
+func (e *SecurityEvent) CopyTo(logRecord plog.LogRecord, bodyFormat string) error {
+	e.PutAttributes(logRecord.Attributes())
+	return e.PutBody(logRecord.Body(), bodyFormat)
+}
+
+// PutBody sets a log record body to the event in the given format
+// The map and JSON formats hold the same fields, named as in the JSON serialization of SecurityEvent
+func (e *SecurityEvent) PutBody(body pcommon.Value, format string) error {
+	switch format {
+	case "", BodyFormatMessage:
+		body.SetStr(e.Message)
+		return nil
+	case BodyFormatMap, BodyFormatJSON:
+	default:
+		return fmt.Errorf("unknown body format %q", format)
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to serialize security event: %w", err)
+	}
+	if format == BodyFormatJSON {
+		body.SetStr(string(data))
+		return nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("failed to serialize security event: %w", err)
+	}
+	return body.SetEmptyMap().FromRaw(fields)
+}
+
+// PutActionAttributes writes the action type and result status of the event, when set
+// They are not part of PutAttributes: the OpenReports findings only carry them in the model
+func (e *SecurityEvent) PutActionAttributes(attrs pcommon.Map) {
+	if e.Action.Type != "" {
+		attrs.PutStr(AttrActionType, e.Action.Type)
+	}
+	if e.Result.Status != "" {
+		attrs.PutStr(AttrResultStatus, e.Result.Status)
+	}
+}
+
+// PutAttributes writes the event fields as flat attributes
+// Optional fields are only written when set; the Kubernetes fields are written in key order
+//
+//nolint:gocyclo // One branch per optional field of the schema
+func (e *SecurityEvent) PutAttributes(attrs pcommon.Map) {
+	// Event fields
+	attrs.PutStr(AttrEventID, e.Event.ID)
+	attrs.PutStr(AttrEventVersion, e.Event.Version)
+	attrs.PutStr(AttrEventCategory, e.Event.Category)
+	attrs.PutStr(AttrEventName, e.Event.Name)
+	attrs.PutStr(AttrEventType, e.Event.Type)
+	attrs.PutStr(AttrEventDescription, e.Event.Description)
+
+	// Product fields are always present, even when unknown
+	attrs.PutStr(AttrProductName, e.Source.Application)
+	attrs.PutStr(AttrProductVendor, e.Source.Vendor)
+	if e.Source.Version != "" {
+		attrs.PutStr(AttrProductVersion, e.Source.Version)
+	}
+
+	if e.Target.EntityType != "" {
+		attrs.PutStr(AttrSmartscapeType, e.Target.EntityType)
+	}
+
+	// Source and target fields of network and access events
+	if e.Source.User != "" {
+		attrs.PutStr(AttrUserName, e.Source.User)
+	}
+	if e.Source.IPAddress != "" {
+		attrs.PutStr(AttrSourceAddress, e.Source.IPAddress)
+	}
+	if e.Target.IPAddress != "" {
+		attrs.PutStr(AttrDestinationAddress, e.Target.IPAddress)
+	}
+	// Vulnerability fields
+	if e.Vulnerability != nil {
+		attrs.PutStr(AttrVulnerabilityID, e.Vulnerability.ID)
+		if e.Vulnerability.EPSS != nil {
+			attrs.PutDouble(AttrVulnerabilityEPSSScore, e.Vulnerability.EPSS.Score)
+			attrs.PutDouble(AttrVulnerabilityEPSSPercent, e.Vulnerability.EPSS.Percentile)
+		}
+		if e.Vulnerability.KEV {
+			attrs.PutBool(AttrVulnerabilityKEV, true)
+		}
+		e.Vulnerability.putDetails(attrs)
+	}
+
+	// Supply chain fields of image verification findings
+	if e.SupplyChain != nil {
+		e.SupplyChain.putAttributes(attrs)
+	}
+
+	// Process tree and container of runtime detections
+	if e.Process != nil {
+		e.Process.putAttributes(attrs)
+	}
+	if e.Container != nil {
+		e.Container.putAttributes(attrs)
+	}
+	attrs.PutDouble(AttrRiskScore, e.RiskScore)
+
+	// Object fields
+	if e.Target.ID != "" {
+		attrs.PutStr(AttrObjectID, e.Target.ID)
+	}
+	if e.Target.ResourceType != "" {
+		attrs.PutStr(AttrObjectType, e.Target.ResourceType)
+	}
+
+	// Finding fields
+	attrs.PutStr(AttrFindingDescription, e.Finding.Description)
+	attrs.PutStr(AttrFindingID, e.Finding.ID)
+	if e.Finding.Severity != "" {
+		attrs.PutStr(AttrFindingSeverity, e.Finding.Severity)
+	}
+	if e.Timestamp != "" {
+		attrs.PutStr(AttrFindingTimeCreated, e.Timestamp)
+	}
+	attrs.PutStr(AttrFindingTitle, e.Finding.Title)
+	if e.Finding.Type != "" {
+		attrs.PutStr(AttrFindingType, e.Finding.Type)
+	}
+	attrs.PutStr(AttrFindingURL, e.Finding.URL)
+
+	// Code location fields of findings in files
+	if e.Finding.Location != nil {
+		attrs.PutStr(AttrCodeFilePath, e.Finding.Location.FilePath)
+		if e.Finding.Location.Line > 0 {
+			attrs.PutInt(AttrCodeLineNumber, int64(e.Finding.Location.Line))
+		}
+		if e.Finding.Location.Column > 0 {
+			attrs.PutInt(AttrCodeColumnNumber, int64(e.Finding.Location.Column))
+		}
+	}
+
+	// Compliance fields; mapped framework controls are written as string arrays, the single requirement and
+	// standard of unmapped findings as strings
+	if e.Compliance.Control != "" {
+		attrs.PutStr(AttrComplianceControl, e.Compliance.Control)
+	}
+	if len(e.Compliance.Requirements) > 0 {
+		putStrSlice(attrs, AttrComplianceRequirements, e.Compliance.Requirements)
+	} else if e.Compliance.Requirement != "" {
+		attrs.PutStr(AttrComplianceRequirements, e.Compliance.Requirement)
+	}
+	if len(e.Compliance.Standards) > 0 {
+		putStrSlice(attrs, AttrComplianceStandards, e.Compliance.Standards)
+	} else if e.Compliance.Standard != "" {
+		attrs.PutStr(AttrComplianceStandards, e.Compliance.Standard)
+	}
+	if e.Compliance.Status != "" {
+		attrs.PutStr(AttrComplianceStatus, e.Compliance.Status)
+	}
+
+	// Threat fields
+	if e.Threat != nil {
+		attrs.PutStr(AttrThreatFramework, e.Threat.Framework)
+		putStrSlice(attrs, AttrThreatTechniqueID, e.Threat.TechniqueIDs)
+		putStrSlice(attrs, AttrThreatTechniqueName, e.Threat.TechniqueNames)
+		putStrSlice(attrs, AttrThreatTacticName, e.Threat.TacticNames)
+	}
+
+	// Kubernetes fields
+	PutFields(attrs, e.Target.Kubernetes)
+}
+
+// PutFields writes raw field values as attributes, in key order
+// Values of unsupported types are skipped
+func PutFields(attrs pcommon.Map, fields map[string]interface{}) {
+	if len(fields) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch value := fields[key].(type) {
+		case string:
+			attrs.PutStr(key, value)
+		case int64:
+			attrs.PutInt(key, value)
+		case float64:
+			attrs.PutDouble(key, value)
+		case bool:
+			attrs.PutBool(key, value)
+		case []interface{}:
+			// FromRaw only fails on unsupported element types, which leave the slice partially filled
+			_ = attrs.PutEmptySlice(key).FromRaw(value)
+		case map[string]interface{}:
+			_ = attrs.PutEmptyMap(key).FromRaw(value)
+		}
+	}
+}
+
+// putDetails writes the package, CVSS, fix and VEX fields of a vulnerability scanner finding, when set
+func (v *Vulnerability) putDetails(attrs pcommon.Map) {
+	if v.Package != nil {
+		attrs.PutStr(AttrPackageName, v.Package.Name)
+		if v.Package.Version != "" {
+			attrs.PutStr(AttrPackageVersion, v.Package.Version)
+		}
+		if v.Package.PURL != "" {
+			attrs.PutStr(AttrPackagePURL, v.Package.PURL)
+		}
+		if v.Package.Type != "" {
+			attrs.PutStr(AttrPackageType, v.Package.Type)
+		}
+	}
+	if v.CVSS != nil {
+		if v.CVSS.Version != "" {
+			attrs.PutStr(AttrVulnerabilityCVSSVersion, v.CVSS.Version)
+		}
+		if v.CVSS.Vector != "" {
+			attrs.PutStr(AttrVulnerabilityCVSSVector, v.CVSS.Vector)
+		}
+		attrs.PutDouble(AttrVulnerabilityCVSSScore, v.CVSS.BaseScore)
+	}
+	if v.FixState != "" {
+		attrs.PutStr(AttrVulnerabilityFixState, v.FixState)
+	}
+	if len(v.FixedVersions) > 0 {
+		putStrSlice(attrs, AttrVulnerabilityFixVersions, v.FixedVersions)
+	}
+	if v.VEXState != "" {
+		attrs.PutStr(AttrVulnerabilityVEXState, v.VEXState)
+	}
+}
+
+// putAttributes writes the image, signer and attestation fields of an image verification finding, when set
+func (s *SupplyChain) putAttributes(attrs pcommon.Map) {
+	for _, field := range []struct{ key, value string }{
+		{AttrImageName, s.Image},
+		{AttrImageDigest, s.Digest},
+		{AttrSignerIdentity, s.Signer},
+		{AttrSignerIssuer, s.Issuer},
+		{AttrAttestationType, s.AttestationType},
+	} {
+		if field.value != "" {
+			attrs.PutStr(field.key, field.value)
+		}
+	}
+}
+
+// putAttributes writes the process and parent process fields of a runtime detection, when set
+func (p *Process) putAttributes(attrs pcommon.Map) {
+	if p.PID != 0 {
+		attrs.PutInt(AttrProcessPID, p.PID)
+	}
+	if p.Binary != "" {
+		attrs.PutStr(AttrProcessExecutable, p.Binary)
+	}
+	if commandLine := p.CommandLine(); commandLine != "" {
+		attrs.PutStr(AttrProcessCommandLine, commandLine)
+	}
+	if p.Parent == nil {
+		return
+	}
+	if p.Parent.PID != 0 {
+		attrs.PutInt(AttrParentPID, p.Parent.PID)
+	}
+	if p.Parent.Binary != "" {
+		attrs.PutStr(AttrParentExecutable, p.Parent.Binary)
+	}
+	if commandLine := p.Parent.CommandLine(); commandLine != "" {
+		attrs.PutStr(AttrParentCommandLine, commandLine)
+	}
+}
+
+// putAttributes writes the container fields of a runtime detection, when set
+func (c *Container) putAttributes(attrs pcommon.Map) {
+	for _, field := range []struct{ key, value string }{
+		{AttrContainerID, c.ID},
+		{AttrContainerName, c.Name},
+		{AttrContainerImage, c.Image},
+	} {
+		if field.value != "" {
+			attrs.PutStr(field.key, field.value)
+		}
+	}
+}
+
+// putStrSlice sets a string slice attribute
+func putStrSlice(target pcommon.Map, key string, values []string) {
+	slice := target.PutEmptySlice(key)
+	slice.EnsureCapacity(len(values))
+	for _, value := range values {
+		slice.AppendEmpty().SetStr(value)
+	}
+}
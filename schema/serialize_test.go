@@ -0,0 +1,336 @@
+package schema
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func newTestEvent() *SecurityEvent {
+	return &SecurityEvent{
+		SchemaVersion: Version,
+		Event: Event{
+			ID:          "event-1",
+			Version:     "1.309",
+			Category:    "COMPLIANCE",
+			Name:        "Compliance finding event",
+			Type:        "COMPLIANCE_FINDING",
+			Description: "Policy violation on app-1 for rule require-limits",
+		},
+		Timestamp: "2024-01-01T00:00:00Z",
+		Message:   "resource limits are required",
+		Action:    Action{Type: "policy_evaluation"},
+		Result:    Result{Status: "fail"},
+		Target: Target{
+			ID:           "pod-uid-1",
+			Resource:     "app-1",
+			ResourceType: "Pod",
+			EntityType:   "K8S_POD",
+			Kubernetes: map[string]interface{}{
+				"k8s.pod.name":       "app-1",
+				"k8s.namespace.name": "default",
+				"k8s.node.count":     int64(3),
+				"k8s.labels":         map[string]interface{}{"app": "web"},
+				"k8s.ports":          []interface{}{int64(80), int64(443)},
+			},
+		},
+		RiskScore: 6.9,
+		Finding: Finding{
+			ID:          "finding-1",
+			Title:       "pod-security - require-limits",
+			Description: "resource limits are required",
+			Severity:    "MEDIUM",
+			Type:        "pod-security",
+		},
+		Compliance: Compliance{
+			Control:     "require-limits",
+			Requirement: "pod-security",
+			Standard:    "Pod Security Standards (Baseline)",
+			Status:      "NON_COMPLIANT",
+		},
+	}
+}
+
+func TestPutAttributes(t *testing.T) {
+	logRecord := plog.NewLogRecord()
+	require.NoError(t, newTestEvent().CopyTo(logRecord, ""))
+
+	attrs := logRecord.Attributes().AsRaw()
+	assert.Equal(t, "event-1", attrs["event.id"])
+	assert.Equal(t, "COMPLIANCE_FINDING", attrs["event.type"])
+	assert.Equal(t, "", attrs["product.name"])
+	assert.Equal(t, "", attrs["product.vendor"])
+	assert.Equal(t, "K8S_POD", attrs["smartscape.type"])
+	assert.Equal(t, 6.9, attrs["dt.security.risk.score"])
+	assert.Equal(t, "pod-uid-1", attrs["object.id"])
+	assert.Equal(t, "Pod", attrs["object.type"])
+	assert.Equal(t, "MEDIUM", attrs["finding.severity"])
+	assert.Equal(t, "2024-01-01T00:00:00Z", attrs["finding.time.created"])
+	assert.Equal(t, "", attrs["finding.url"])
+	assert.Equal(t, "pod-security", attrs["compliance.requirements"])
+	assert.Equal(t, "Pod Security Standards (Baseline)", attrs["compliance.standards"])
+	assert.Equal(t, "NON_COMPLIANT", attrs["compliance.status"])
+	assert.Equal(t, "default", attrs["k8s.namespace.name"])
+	assert.Equal(t, int64(3), attrs["k8s.node.count"])
+	assert.Equal(t, map[string]interface{}{"app": "web"}, attrs["k8s.labels"])
+	assert.Equal(t, []interface{}{int64(80), int64(443)}, attrs["k8s.ports"])
+	assert.Equal(t, "resource limits are required", logRecord.Body().Str())
+
+	for _, key := range []string{"vulnerability.id", "vulnerability.kev", "threat.framework", "user.name", "source.address", "destination.address", "action.type", "result.status"} {
+		assert.NotContains(t, attrs, key)
+	}
+}
+
+func TestPutAttributes_OptionalFields(t *testing.T) {
+	event := newTestEvent()
+	event.Compliance.Requirements = []string{"CIS 5.2.2"}
+	event.Compliance.Standards = []string{"CIS"}
+	event.Vulnerability = &Vulnerability{ID: "CVE-2024-3094", EPSS: &EPSS{Score: 0.9, Percentile: 0.99}, KEV: true}
+	event.Threat = &Threat{
+		Framework:      "MITRE ATT&CK",
+		TechniqueIDs:   []string{"T1611"},
+		TechniqueNames: []string{"Escape to Host"},
+		TacticNames:    []string{"Privilege Escalation"},
+	}
+
+	logRecord := plog.NewLogRecord()
+	event.PutAttributes(logRecord.Attributes())
+
+	attrs := logRecord.Attributes().AsRaw()
+	assert.Equal(t, []interface{}{"CIS 5.2.2"}, attrs["compliance.requirements"])
+	assert.Equal(t, []interface{}{"CIS"}, attrs["compliance.standards"])
+	assert.Equal(t, "CVE-2024-3094", attrs["vulnerability.id"])
+	assert.Equal(t, 0.9, attrs["vulnerability.epss.score"])
+	assert.Equal(t, 0.99, attrs["vulnerability.epss.percentile"])
+	assert.Equal(t, true, attrs["vulnerability.kev"])
+	assert.Equal(t, "MITRE ATT&CK", attrs["threat.framework"])
+	assert.Equal(t, []interface{}{"T1611"}, attrs["threat.technique.id"])
+	assert.Equal(t, []interface{}{"Escape to Host"}, attrs["threat.technique.name"])
+	assert.Equal(t, []interface{}{"Privilege Escalation"}, attrs["threat.tactic.name"])
+}
+
+func TestPutAttributes_NetworkFields(t *testing.T) {
+	event := newTestEvent()
+	event.Source.User = "alice"
+	event.Source.IPAddress = "10.0.0.5"
+	event.Target.IPAddress = "192.168.1.20"
+	event.Compliance = Compliance{}
+
+	logRecord := plog.NewLogRecord()
+	event.PutAttributes(logRecord.Attributes())
+
+	attrs := logRecord.Attributes().AsRaw()
+	assert.Equal(t, "alice", attrs["user.name"])
+	assert.Equal(t, "10.0.0.5", attrs["source.address"])
+	assert.Equal(t, "192.168.1.20", attrs["destination.address"])
+	// Events that are not compliance checks have no compliance status
+	assert.NotContains(t, attrs, "compliance.status")
+}
+
+func TestPutActionAttributes(t *testing.T) {
+	attrs := pcommon.NewMap()
+	newTestEvent().PutActionAttributes(attrs)
+	assert.Equal(t, map[string]interface{}{"action.type": "policy_evaluation", "result.status": "fail"}, attrs.AsRaw())
+
+	attrs = pcommon.NewMap()
+	(&SecurityEvent{}).PutActionAttributes(attrs)
+	assert.Zero(t, attrs.Len())
+}
+
+func TestPutAttributes_CodeLocation(t *testing.T) {
+	event := newTestEvent()
+	event.Source = Source{Application: "CodeQL", Vendor: "GitHub", Version: "2.19.0"}
+	event.Finding.Location = &Location{FilePath: "src/app/handler.go", Line: 42}
+
+	logRecord := plog.NewLogRecord()
+	event.PutAttributes(logRecord.Attributes())
+
+	attrs := logRecord.Attributes().AsRaw()
+	assert.Equal(t, "2.19.0", attrs["product.version"])
+	assert.Equal(t, "src/app/handler.go", attrs["code.file.path"])
+	assert.Equal(t, int64(42), attrs["code.line.number"])
+	assert.NotContains(t, attrs, "code.column.number")
+
+	// Events without version or location have neither attribute
+	logRecord = plog.NewLogRecord()
+	newTestEvent().PutAttributes(logRecord.Attributes())
+	assert.NotContains(t, logRecord.Attributes().AsRaw(), "product.version")
+	assert.NotContains(t, logRecord.Attributes().AsRaw(), "code.file.path")
+}
+
+func TestPutAttributes_VulnerabilityDetails(t *testing.T) {
+	event := newTestEvent()
+	event.Vulnerability = &Vulnerability{
+		ID:            "CVE-2022-3602",
+		Package:       &Package{Name: "openssl", Version: "3.0.2", PURL: "pkg:deb/ubuntu/openssl@3.0.2"},
+		CVSS:          &CVSS{Version: "3.1", Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", BaseScore: 7.5},
+		FixState:      "fixed",
+		FixedVersions: []string{"3.0.7"},
+		VEXState:      "exploitable",
+	}
+
+	logRecord := plog.NewLogRecord()
+	event.PutAttributes(logRecord.Attributes())
+
+	attrs := logRecord.Attributes().AsRaw()
+	assert.Equal(t, "openssl", attrs["software_component.name"])
+	assert.Equal(t, "3.0.2", attrs["software_component.version"])
+	assert.Equal(t, "pkg:deb/ubuntu/openssl@3.0.2", attrs["software_component.purl"])
+	assert.NotContains(t, attrs, "software_component.type")
+	assert.Equal(t, "3.1", attrs["vulnerability.cvss.version"])
+	assert.Equal(t, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", attrs["vulnerability.cvss.vector"])
+	assert.Equal(t, 7.5, attrs["vulnerability.cvss.base_score"])
+	assert.Equal(t, "fixed", attrs["vulnerability.fix.state"])
+	assert.Equal(t, []interface{}{"3.0.7"}, attrs["vulnerability.fix.versions"])
+	assert.Equal(t, "exploitable", attrs["vulnerability.vex.state"])
+
+	// Vulnerabilities without scanner details only have their ID
+	event.Vulnerability = &Vulnerability{ID: "CVE-2022-3602"}
+	logRecord = plog.NewLogRecord()
+	event.PutAttributes(logRecord.Attributes())
+	for key := range logRecord.Attributes().AsRaw() {
+		assert.NotContains(t, []string{"software_component.name", "vulnerability.cvss.base_score", "vulnerability.fix.state"}, key)
+	}
+}
+
+func TestPutAttributes_SupplyChain(t *testing.T) {
+	event := newTestEvent()
+	event.SupplyChain = &SupplyChain{
+		Image:  "ghcr.io/example/app:1.0",
+		Digest: "sha256:5b0c1f2e2d8c4c7e9a510d7f1c2b3a415b0c1f2e2d8c4c7e9a510d7f1c2b3a41",
+		Signer: "https://github.com/example/app/.github/workflows/release.yml@refs/tags/v1.0",
+		Issuer: "https://token.actions.githubusercontent.com",
+	}
+
+	logRecord := plog.NewLogRecord()
+	event.PutAttributes(logRecord.Attributes())
+
+	attrs := logRecord.Attributes().AsRaw()
+	assert.Equal(t, "ghcr.io/example/app:1.0", attrs["supply_chain.image.name"])
+	assert.Equal(t, "sha256:5b0c1f2e2d8c4c7e9a510d7f1c2b3a415b0c1f2e2d8c4c7e9a510d7f1c2b3a41", attrs["supply_chain.image.digest"])
+	assert.Equal(t, "https://github.com/example/app/.github/workflows/release.yml@refs/tags/v1.0", attrs["supply_chain.signer.identity"])
+	assert.Equal(t, "https://token.actions.githubusercontent.com", attrs["supply_chain.signer.issuer"])
+	assert.NotContains(t, attrs, "supply_chain.attestation.type", "signature findings have no attestation type")
+}
+
+func TestPutAttributes_Process(t *testing.T) {
+	event := newTestEvent()
+	event.Process = &Process{
+		PID:       52699,
+		Binary:    "/usr/bin/curl",
+		Arguments: "https://example.com",
+		Parent:    &Process{PID: 52654, Binary: "/bin/bash"},
+	}
+	event.Container = &Container{ID: "551e161c47d8", Name: "app", Image: "docker.io/library/nginx:1.27"}
+
+	logRecord := plog.NewLogRecord()
+	event.PutAttributes(logRecord.Attributes())
+
+	attrs := logRecord.Attributes().AsRaw()
+	assert.Equal(t, int64(52699), attrs["process.pid"])
+	assert.Equal(t, "/usr/bin/curl", attrs["process.executable.path"])
+	assert.Equal(t, "/usr/bin/curl https://example.com", attrs["process.command_line"])
+	assert.Equal(t, int64(52654), attrs["process.parent_pid"])
+	assert.Equal(t, "/bin/bash", attrs["process.parent.executable.path"])
+	assert.Equal(t, "/bin/bash", attrs["process.parent.command_line"])
+	assert.Equal(t, "551e161c47d8", attrs["container.id"])
+	assert.Equal(t, "app", attrs["container.name"])
+	assert.Equal(t, "docker.io/library/nginx:1.27", attrs["container.image.name"])
+}
+
+func TestPutBody_JSON(t *testing.T) {
+	event := newTestEvent()
+	logRecord := plog.NewLogRecord()
+	require.NoError(t, event.PutBody(logRecord.Body(), BodyFormatJSON))
+
+	var decoded SecurityEvent
+	require.NoError(t, json.Unmarshal([]byte(logRecord.Body().Str()), &decoded))
+	assert.Equal(t, Version, decoded.SchemaVersion)
+	assert.Equal(t, event.Event, decoded.Event)
+	assert.Equal(t, event.Finding, decoded.Finding)
+	assert.Equal(t, event.Compliance, decoded.Compliance)
+	assert.Equal(t, "default", decoded.Target.Kubernetes["k8s.namespace.name"])
+	assert.Nil(t, decoded.Vulnerability)
+	assert.Zero(t, logRecord.Attributes().Len())
+}
+
+func TestPutFields_KeyOrder(t *testing.T) {
+	logRecord := plog.NewLogRecord()
+	PutFields(logRecord.Attributes(), map[string]interface{}{
+		"k8s.pod.name":       "app-1",
+		"k8s.cluster.name":   "prod",
+		"k8s.namespace.name": "default",
+		"k8s.unsupported":    []byte("skipped"),
+	})
+
+	var keys []string
+	logRecord.Attributes().Range(func(key string, _ pcommon.Value) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, []string{"k8s.cluster.name", "k8s.namespace.name", "k8s.pod.name"}, keys)
+}
+
+func TestPutBody_Map(t *testing.T) {
+	event := newTestEvent()
+	event.Threat = &Threat{Framework: "MITRE ATT&CK", TechniqueIDs: []string{"T1611"}}
+	logRecord := plog.NewLogRecord()
+	require.NoError(t, event.CopyTo(logRecord, BodyFormatMap))
+
+	require.Equal(t, pcommon.ValueTypeMap, logRecord.Body().Type())
+	body := logRecord.Body().Map().AsRaw()
+	assert.Equal(t, Version, body["schema_version"])
+	assert.Equal(t, "resource limits are required", body["message"])
+	assert.Equal(t, 6.9, body["risk_score"])
+	assert.Equal(t, "COMPLIANCE_FINDING", body["event"].(map[string]interface{})["type"])
+	assert.Equal(t, []interface{}{"T1611"}, body["threat"].(map[string]interface{})["technique_ids"])
+	kubernetes := body["target"].(map[string]interface{})["kubernetes"].(map[string]interface{})
+	assert.Equal(t, "default", kubernetes["k8s.namespace.name"])
+	assert.NotContains(t, body, "vulnerability")
+
+	// The attributes are written in every body format
+	_, exists := logRecord.Attributes().Get("event.id")
+	assert.True(t, exists)
+
+	// The map and JSON bodies hold the same fields
+	jsonRecord := plog.NewLogRecord()
+	require.NoError(t, event.PutBody(jsonRecord.Body(), BodyFormatJSON))
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(jsonRecord.Body().Str()), &decoded))
+	assert.Equal(t, decoded, body)
+}
+
+func TestPutBody_Message(t *testing.T) {
+	for _, format := range []string{"", BodyFormatMessage} {
+		logRecord := plog.NewLogRecord()
+		require.NoError(t, newTestEvent().PutBody(logRecord.Body(), format))
+		assert.Equal(t, "resource limits are required", logRecord.Body().Str())
+	}
+}
+
+func TestPutBody_Errors(t *testing.T) {
+	logRecord := plog.NewLogRecord()
+	err := newTestEvent().PutBody(logRecord.Body(), "xml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown body format "xml"`)
+
+	// JSON has no representation for NaN
+	event := newTestEvent()
+	event.Target.Kubernetes["k8s.ratio"] = math.NaN()
+	err = event.PutBody(logRecord.Body(), BodyFormatJSON)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to serialize security event")
+}
+
+func TestValidBodyFormat(t *testing.T) {
+	assert.True(t, ValidBodyFormat(BodyFormatMessage))
+	assert.True(t, ValidBodyFormat(BodyFormatMap))
+	assert.True(t, ValidBodyFormat(BodyFormatJSON))
+	assert.False(t, ValidBodyFormat(""))
+	assert.False(t, ValidBodyFormat("xml"))
+}
@@ -0,0 +1,84 @@
+package securityevent
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+)
+
+// summaryInterval is the minimum interval between two report processing summaries logged at Info level
+const summaryInterval = time.Minute
+
+// reportCounts aggregates the outcomes of the reports processed in a batch
+type reportCounts struct {
+	reports   int
+	failed    int
+	results   int
+	events    int
+	filtered  int
+	malformed int
+	invalid   int
+}
+
+// add adds the outcome of a report that was processed successfully
+func (c *reportCounts) add(outcome *processing.Outcome) {
+	c.reports++
+	c.results += outcome.Results
+	c.events += outcome.Created
+	c.filtered += outcome.FilteredTotal()
+	c.malformed += outcome.Malformed
+}
+
+// merge adds the counts of another batch
+func (c *reportCounts) merge(other reportCounts) {
+	c.reports += other.reports
+	c.failed += other.failed
+	c.results += other.results
+	c.events += other.events
+	c.filtered += other.filtered
+	c.malformed += other.malformed
+	c.invalid += other.invalid
+}
+
+// reportSummary replaces a per-report Info log line with a periodic summary of the reports
+// processed across batches. It is safe for concurrent use
+type reportSummary struct {
+	mu     sync.Mutex
+	since  time.Time
+	counts reportCounts
+}
+
+// newReportSummary creates a summary whose first interval starts at now
+func newReportSummary(now time.Time) *reportSummary {
+	return &reportSummary{since: now}
+}
+
+// record adds the counts of a batch and logs the summary if summaryInterval elapsed since the last one
+func (s *reportSummary) record(logger *zap.Logger, batch reportCounts, now time.Time) {
+	if batch.reports == 0 && batch.failed == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	s.counts.merge(batch)
+	if now.Sub(s.since) < summaryInterval {
+		s.mu.Unlock()
+		return
+	}
+	counts, since := s.counts, s.since
+	s.counts, s.since = reportCounts{}, now
+	s.mu.Unlock()
+
+	logger.Info("Security event processing summary",
+		zap.Duration("interval", now.Sub(since)),
+		zap.Int("reports", counts.reports),
+		zap.Int("failed_reports", counts.failed),
+		zap.Int("total_results", counts.results),
+		zap.Int("security_events_created", counts.events),
+		zap.Int("filtered_results", counts.filtered),
+		zap.Int("malformed_results", counts.malformed),
+		zap.Int("invalid_security_events", counts.invalid))
+}
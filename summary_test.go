@@ -0,0 +1,78 @@
+package securityevent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/henrikrexed/securitylogeventprocessor/internal/openreports"
+	"github.com/henrikrexed/securitylogeventprocessor/internal/processing"
+)
+
+func TestReportSummary_Record(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core)
+	start := time.Now()
+	summary := newReportSummary(start)
+
+	batch := reportCounts{}
+	batch.add(&processing.Outcome{Results: 5, Parsed: 4, Malformed: 1, Created: 3, Filtered: map[string]int{"status_filter": 1}})
+
+	// Batches are aggregated until the interval elapses
+	summary.record(logger, batch, start.Add(time.Second))
+	summary.record(logger, reportCounts{failed: 1}, start.Add(2*time.Second))
+	assert.Equal(t, 0, logs.Len())
+
+	summary.record(logger, batch, start.Add(summaryInterval))
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, "Security event processing summary", logs.All()[0].Message)
+	assert.Equal(t, int64(2), fields["reports"])
+	assert.Equal(t, int64(1), fields["failed_reports"])
+	assert.Equal(t, int64(10), fields["total_results"])
+	assert.Equal(t, int64(6), fields["security_events_created"])
+	assert.Equal(t, int64(2), fields["filtered_results"])
+	assert.Equal(t, int64(2), fields["malformed_results"])
+
+	// The counts are reset for the next interval
+	summary.record(logger, batch, start.Add(2*summaryInterval))
+	require.Equal(t, 2, logs.Len())
+	assert.Equal(t, int64(1), logs.All()[1].ContextMap()["reports"])
+
+	// Batches without reports do not trigger a summary
+	summary.record(logger, reportCounts{}, start.Add(4*summaryInterval))
+	assert.Equal(t, 2, logs.Len())
+}
+
+func TestProcessLogs_NoPerReportInfoLogs(t *testing.T) {
+	core, observed := observer.New(zap.InfoLevel)
+	config := &Config{
+		Processors: ProcessorConfig{
+			OpenReports: openreports.Config{Enabled: true},
+		},
+	}
+	processor, err := newSecurityEventProcessor(zap.New(core), config, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	startupLogs := observed.Len()
+
+	for i := 0; i < 10; i++ {
+		logs := plog.NewLogs()
+		report := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+		report.Attributes().PutStr("kind", "Report")
+		report.Attributes().PutStr("apiVersion", "openreports.io/v1alpha1")
+		report.Attributes().PutEmptySlice("results").AppendEmpty().SetStr(`{"policy": "p1", "rule": "r1", "result": "fail"}`)
+
+		_, err = processor.processLogs(context.Background(), logs)
+		require.NoError(t, err)
+	}
+
+	// Reports are only reported in the periodic summary
+	assert.Equal(t, startupLogs, observed.Len())
+}